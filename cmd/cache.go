@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "Maintain the redis cache.",
+	}
+	cacheFlushCmd = &cobra.Command{
+		Use:   "flush <key>...",
+		Short: "Delete the cache keys.",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			release := prepare()
+			defer release()
+
+			num, err := cache.RedisDel(args...)
+			if err != nil {
+				release()
+				exitWithErr(err)
+			}
+			fmt.Printf("del keys %v, affect num: %d\n", args, num)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheFlushCmd)
+	addConfigFlag(cacheCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addChains       string
+	addTransferData bool
+	chainCmd        = &cobra.Command{
+		Use:   "chain",
+		Short: "Maintain the chains.",
+	}
+	chainAddCmd = &cobra.Command{
+		Use:   "add",
+		Short: "Update the ibc txs and denoms related to the new chains, the chain config must be added first.",
+		Run: func(cmd *cobra.Command, args []string) {
+			if addChains == "" {
+				exitWithErr(fmt.Errorf("--chains is required"))
+			}
+			release := prepare()
+			defer release()
+
+			if res := new(task.AddChainTask).RunWithParam(addChains); res != 1 {
+				release()
+				exitWithErr(fmt.Errorf("add chain %s failed", addChains))
+			}
+			if addTransferData {
+				if res := new(task.AddTransferDataTask).RunWithParam(addChains); res != 1 {
+					release()
+					exitWithErr(fmt.Errorf("add transfer data of %s failed", addChains))
+				}
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(chainCmd)
+	chainCmd.AddCommand(chainAddCmd)
+	addConfigFlag(chainCmd)
+	chainAddCmd.Flags().StringVar(&addChains, "chains", "", "new chain ids, separated by comma")
+	chainAddCmd.Flags().BoolVar(&addTransferData, "transfer-data", false, "sync the transfer txs of the new chains")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/spf13/cobra"
+)
+
+// addConfigFlag add the config file flag to the maintenance command. If the flag is not set, the config file path is
+// read from env CONFIG_FILE_PATH, the same as the start command.
+func addConfigFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&ConfigFilePath, "CONFIG", "c", "", "conf path: /opt/local.toml")
+}
+
+func loadConfig() (*conf.Config, error) {
+	if ConfigFilePath == "" {
+		filepath, found := os.LookupEnv(constant.EnvNameConfigFilePath)
+		if !found {
+			return nil, fmt.Errorf("not found CONFIG_FILE_PATH")
+		}
+		ConfigFilePath = filepath
+	}
+
+	data, err := ioutil.ReadFile(ConfigFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return conf.ReadConfig(data)
+}
+
+// prepare load config and init db, redis and task config for the maintenance command.
+// The returned function releases the resources.
+func prepare() func() {
+	config, err := loadConfig()
+	if err != nil {
+		exitWithErr(err)
+	}
+	return app.Prepare(config)
+}
+
+func exitWithErr(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the ibc txs from ex_ibc_tx_latest to ex_ibc_tx, switch_ibc_tx_migrate_task must be on.",
+	Run: func(cmd *cobra.Command, args []string) {
+		runTask(new(task.IbcTxMigrateTask).Name(), taskParam{})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	addConfigFlag(migrateCmd)
+}
@@ -69,5 +69,5 @@ func online() {
 }
 
 func run(cfg *conf.Config) {
-	app.Serve(cfg)
+	app.Serve(cfg, ConfigFilePath)
 }
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task"
+	"github.com/spf13/cobra"
+)
+
+// taskParam flags of the task run command
+type taskParam struct {
+	startTime   int64
+	endTime     int64
+	startHeight int64
+	endHeight   int64
+	chains      string
+	endHeights  string
+	domain      string
+	dryRun      bool
+}
+
+// runnableTask task which can be run by the task run command
+type runnableTask struct {
+	name string
+	desc string
+	task interface{}
+	run  func(t interface{}, param taskParam) int // t is the task, or a new instance of the task in dry-run mode
+}
+
+var (
+	taskRunParam    taskParam
+	oneOffTaskCache cache.OneOffTaskCacheRepo
+
+	taskCmd = &cobra.Command{
+		Use:   "task",
+		Short: "Run or list the tasks.",
+	}
+	taskListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the tasks which can be run by the task run command.",
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, v := range runnableTasks() {
+				fmt.Printf("%-45s %s\n", v.name, v.desc)
+			}
+		},
+	}
+	taskRunCmd = &cobra.Command{
+		Use:   "run <name>",
+		Short: "Run a task once, exit with code 1 if the task fails.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runTask(args[0], taskRunParam)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(taskCmd)
+	taskCmd.AddCommand(taskListCmd, taskRunCmd)
+	addConfigFlag(taskCmd)
+	taskRunCmd.Flags().Int64Var(&taskRunParam.startTime, "start-time", 0, "start unix time of the data to be handled")
+	taskRunCmd.Flags().Int64Var(&taskRunParam.endTime, "end-time", 0, "end unix time of the data to be handled")
+	taskRunCmd.Flags().Int64Var(&taskRunParam.startHeight, "start-height", 0, "start height of the chain to be handled")
+	taskRunCmd.Flags().Int64Var(&taskRunParam.endHeight, "end-height", 0, "end height of the chain to be handled")
+	taskRunCmd.Flags().StringVar(&taskRunParam.chains, "chains", "", "chain ids, separated by comma")
+	taskRunCmd.Flags().StringVar(&taskRunParam.endHeights, "end-heights", "", "end heights of chains, format: <chain_id:end_height>, separated by comma")
+	taskRunCmd.Flags().StringVar(&taskRunParam.domain, "domain", "", "fix domain of fix_ibc_tx_task: all, partly")
+	taskRunCmd.Flags().BoolVar(&taskRunParam.dryRun, "dry-run", false, "record the intended updates instead of writing data, only for fix tasks")
+}
+
+func runnableTasks() []runnableTask {
+	var (
+		addChainTask                 task.AddChainTask
+		addTransferDataTask          task.AddTransferDataTask
+		fixDcChainIdTask             task.FixDcChainIdTask
+		fixBaseDenomChainIdTask      task.FixBaseDenomChainIdTask
+		fixPacketMemoTask            task.FixPacketMemoTask
+		fixFailureCategoryTask       task.FixFailureCategoryTask
+		fixDenomTraceDataTask        task.FixDenomTraceDataTask
+		fixDenomTraceHistoryDataTask task.FixDenomTraceHistoryDataTask
+		fixFailRecvPacketTask        task.FixFailRecvPacketTask
+		fixFailTxTask                task.FixFailTxTask
+		fixAcknowledgeTxTask         task.FixAcknowledgeTxTask
+		fixAckTxPacketIdTask         task.FixAckTxPacketIdTask
+		fixIbxTxTask                 task.FixIbxTxTask
+		tokenStatisticsTask          task.TokenStatisticsTask
+		channelStatisticsTask        task.ChannelStatisticsTask
+		chainFlowStatisticsTask      task.ChainFlowStatisticsTask
+		relayerStatisticsTask        task.RelayerStatisticsTask
+		relayerDataTask              task.RelayerDataTask
+		ibcNodeLcdCronTask           task.IbcNodeLcdCronTask
+		ibcStatisticCronTask         task.IbcStatisticCronTask
+		ibcReindexTask               task.IbcReindexTask
+		ibcTxArchiveTask             task.IbcTxArchiveTask
+	)
+
+	list := []runnableTask{
+		{name: addChainTask.Name(), desc: "update ibc txs and denoms of new chains, --chains", task: &addChainTask,
+			run: func(_ interface{}, p taskParam) int { return addChainTask.RunWithParam(p.chains) }},
+		{name: addTransferDataTask.Name(), desc: "sync transfer txs of new chains, --chains", task: &addTransferDataTask,
+			run: func(_ interface{}, p taskParam) int { return addTransferDataTask.RunWithParam(p.chains) }},
+		{name: fixDcChainIdTask.Name(), desc: "fix empty dc chain id", task: &fixDcChainIdTask,
+			run: func(t interface{}, p taskParam) int { return t.(*task.FixDcChainIdTask).Run() }},
+		{name: fixBaseDenomChainIdTask.Name(), desc: "fix base denom chain id", task: &fixBaseDenomChainIdTask,
+			run: func(t interface{}, p taskParam) int { return t.(*task.FixBaseDenomChainIdTask).Run() }},
+		{name: fixPacketMemoTask.Name(), desc: "decode packet memo of the synced txs", task: &fixPacketMemoTask,
+			run: func(t interface{}, p taskParam) int { return t.(*task.FixPacketMemoTask).Run() }},
+		{name: fixFailureCategoryTask.Name(), desc: "categorize the failure of the synced failed and refunded txs", task: &fixFailureCategoryTask,
+			run: func(t interface{}, p taskParam) int { return t.(*task.FixFailureCategoryTask).Run() }},
+		{name: fixDenomTraceDataTask.Name(), desc: "fix denom trace of latest txs, --start-time --end-time", task: &fixDenomTraceDataTask,
+			run: func(t interface{}, p taskParam) int {
+				return t.(*task.FixDenomTraceDataTask).RunWithParam(p.startTime, p.endTime)
+			}},
+		{name: fixDenomTraceHistoryDataTask.Name(), desc: "fix denom trace of history txs, --start-time --end-time", task: &fixDenomTraceHistoryDataTask,
+			run: func(t interface{}, p taskParam) int {
+				return t.(*task.FixDenomTraceHistoryDataTask).RunWithParam(p.startTime, p.endTime)
+			}},
+		{name: fixFailRecvPacketTask.Name(), desc: "fix recv packet of refunded txs", task: &fixFailRecvPacketTask,
+			run: func(t interface{}, p taskParam) int { return t.(*task.FixFailRecvPacketTask).Run() }},
+		{name: fixFailTxTask.Name(), desc: "fix failed txs, [--start-time]", task: &fixFailTxTask,
+			run: func(t interface{}, p taskParam) int {
+				if p.startTime > 0 {
+					return t.(*task.FixFailTxTask).RunWithParam(p.startTime)
+				}
+				return t.(*task.FixFailTxTask).Run()
+			}},
+		{name: fixAcknowledgeTxTask.Name(), desc: "fix acknowledge tx of success txs", task: &fixAcknowledgeTxTask,
+			run: func(t interface{}, p taskParam) int { return t.(*task.FixAcknowledgeTxTask).Run() }},
+		{name: fixAckTxPacketIdTask.Name(), desc: "fix packet id of acknowledge txs, --chains --end-heights", task: &fixAckTxPacketIdTask,
+			run: func(t interface{}, p taskParam) int {
+				return t.(*task.FixAckTxPacketIdTask).RunWithParam(p.chains, p.endHeights)
+			}},
+		{name: fixIbxTxTask.Name(), desc: "fix client and connection of ibc txs, --domain", task: &fixIbxTxTask,
+			run: func(t interface{}, p taskParam) int { return t.(*task.FixIbxTxTask).RunWithParam(p.domain) }},
+		{name: tokenStatisticsTask.Name(), desc: "init token statistics", task: &tokenStatisticsTask,
+			run: func(_ interface{}, p taskParam) int { return tokenStatisticsTask.Run() }},
+		{name: channelStatisticsTask.Name(), desc: "init channel statistics", task: &channelStatisticsTask,
+			run: func(_ interface{}, p taskParam) int { return channelStatisticsTask.Run() }},
+		{name: chainFlowStatisticsTask.Name(), desc: "init chain flow statistics", task: &chainFlowStatisticsTask,
+			run: func(_ interface{}, p taskParam) int { return chainFlowStatisticsTask.Run() }},
+		{name: relayerStatisticsTask.Name(), desc: "init relayer statistics", task: &relayerStatisticsTask,
+			run: func(_ interface{}, p taskParam) int { return relayerStatisticsTask.Run() }},
+		{name: relayerDataTask.Name(), desc: "init relayer data", task: &relayerDataTask,
+			run: func(_ interface{}, p taskParam) int { return relayerDataTask.Run() }},
+		{name: ibcNodeLcdCronTask.Name(), desc: "check trace source lcd of chains, [--chains]", task: &ibcNodeLcdCronTask,
+			run: func(_ interface{}, p taskParam) int {
+				if p.chains != "" {
+					return ibcNodeLcdCronTask.RunWithParam(p.chains)
+				}
+				return ibcNodeLcdCronTask.Run()
+			}},
+		{name: ibcStatisticCronTask.Name(), desc: "recalculate the statistics of home page", task: &ibcStatisticCronTask,
+			run: func(_ interface{}, p taskParam) int { return ibcStatisticCronTask.NewRun() }},
+		{name: ibcReindexTask.Name(), desc: "re-index ibc txs of a chain, --chains --start-height --end-height or --start-time --end-time", task: &ibcReindexTask,
+			run: func(_ interface{}, p taskParam) int {
+				return ibcReindexTask.RunWithRange(p.chains, p.startHeight, p.endHeight, p.startTime, p.endTime)
+			}},
+		{name: ibcTxArchiveTask.Name(), desc: "archive aged ibc txs, or restore the archived month of a chain with --chains --start-time", task: &ibcTxArchiveTask,
+			run: func(_ interface{}, p taskParam) int {
+				if p.chains != "" {
+					return ibcTxArchiveTask.RestoreWithParam(p.chains, p.startTime)
+				}
+				return ibcTxArchiveTask.Run()
+			}},
+	}
+
+	// cron tasks, run once
+	for _, v := range []task.Task{
+		&task.TokenTask{},
+		&task.ChannelTask{},
+		&task.IbcChainCronTask{},
+		&task.IbcRelayerCronTask{},
+		&task.TokenPriceTask{},
+		&task.IbcSyncAcknowledgeTxTask{},
+		&task.IbcChainConfigTask{},
+		&task.IbcDenomCalculateTask{},
+		&task.IbcDenomUpdateTask{},
+		&task.IbcSyncTransferTxTask{},
+		&task.IbcTxRelateTask{},
+		&task.IbcTxRelateHistoryTask{},
+		&task.IbcTxMigrateTask{},
+		&task.IbcChainFlowTask{},
+		&task.IbcNftTransferTask{},
+		&task.IbcIcaTask{},
+		&task.IbcPacketTask{},
+		&task.IbcEscrowReconcileTask{},
+		&task.IbcLargeTransferTask{},
+		&task.IbcChainRegistrySyncTask{},
+		&task.IbcIngestTask{},
+	} {
+		cronTask := v
+		list = append(list, runnableTask{name: cronTask.Name(), desc: "cron task", task: cronTask,
+			run: func(_ interface{}, p taskParam) int { return cronTask.Run() }})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+	return list
+}
+
+func runTask(name string, param taskParam) {
+	var target *runnableTask
+	for _, v := range runnableTasks() {
+		if v.name == name {
+			t := v
+			target = &t
+			break
+		}
+	}
+	if target == nil {
+		exitWithErr(fmt.Errorf("unknown task %s, see `task list`", name))
+	}
+
+	release := prepare()
+	defer release()
+
+	oneOffTask, isOneOff := target.task.(task.OneOffTask)
+	if _, isCron := target.task.(task.Task); isCron {
+		isOneOff = false
+	}
+	if _, ok := target.task.(task.DryRunOneOffTask); param.dryRun && !ok {
+		exitWithErr(fmt.Errorf("task %s doesn't support dry run", name))
+	}
+
+	var unlock func()
+	if isOneOff {
+		// the same running lock and done marker as the one-off task of the server
+		unlockOneOff, err := task.LockOneOffTask(name)
+		if err != nil {
+			exitWithErr(fmt.Errorf("task %s is running, %v", name, err))
+		}
+		unlock = unlockOneOff
+		if !param.dryRun {
+			done, err := oneOffTaskCache.IsDone(name)
+			if err != nil {
+				unlock()
+				exitWithErr(err)
+			}
+			if done {
+				unlock()
+				exitWithErr(fmt.Errorf("task %s has been executed, reset its checkpoint to run it again", name))
+			}
+		}
+	} else {
+		// the same lock as the cron task, avoid running with the server at the same time
+		lockKey := fmt.Sprintf("%s:%s", "task", name)
+		if err := cache.GetRedisClient().Lock(lockKey, time.Now().Unix(), time.Hour); err != nil {
+			exitWithErr(fmt.Errorf("task %s is running, %v", name, err))
+		}
+		unlock = func() { cache.GetRedisClient().Del(lockKey) }
+	}
+	defer unlock()
+
+	// the dry-run mode is enabled on a new instance of the task after the lock is held
+	runTarget := target.task
+	if param.dryRun {
+		dryRunTask, err := task.NewDryRunTask(oneOffTask)
+		if err != nil {
+			unlock()
+			exitWithErr(err)
+		}
+		runTarget = dryRunTask
+	}
+
+	st := time.Now().Unix()
+	res := target.run(runTarget, param)
+	fmt.Printf("task %s end, time use %d(s), exec status: %d\n", name, time.Now().Unix()-st, res)
+	if res != 1 {
+		unlock()
+		release()
+		exitWithErr(fmt.Errorf("task %s failed", name))
+	}
+	if isOneOff && !param.dryRun {
+		if err := oneOffTaskCache.SetDone(name, task.OneOffTaskLockTime*time.Second); err != nil {
+			fmt.Printf("task %s set done error, %v\n", name, err)
+		}
+	}
+}
@@ -21,6 +21,8 @@ require (
 	github.com/swaggo/gin-swagger v1.5.0
 	github.com/weichang-bianjie/metric-sdk v1.0.1
 	go.mongodb.org/mongo-driver v1.9.0
+	golang.org/x/net v0.0.0-20220520000938-2e3eb7b945c2
+	google.golang.org/protobuf v1.28.0
 	gorm.io/driver/mysql v1.3.4
 	gorm.io/gorm v1.23.6
 )
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/gin-gonic/gin"
+)
+
+type AlertController struct {
+}
+
+func (ctl *AlertController) LargeTransfers(c *gin.Context) {
+	var req vo.LargeTransfersReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	if req.UseCount {
+		count, err := alertService.LargeTransfersCount(&req)
+		if err != nil {
+			c.JSON(http.StatusOK, response.FailError(err))
+			return
+		}
+		c.JSON(http.StatusOK, response.Success(count))
+		return
+	}
+	resp, err := alertService.LargeTransfers(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsController struct {
+}
+
+// Series bucketed transfer txs, value, success txs and refunded txs
+func (ctl *AnalyticsController) Series(c *gin.Context) {
+	var req vo.AnalyticsSeriesReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+
+	res, err := analyticsService.Series(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(res))
+}
+
+// Flows directional transfer flows between chains, in sankey node/link format
+func (ctl *AnalyticsController) Flows(c *gin.Context) {
+	var req vo.AnalyticsFlowsReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+
+	res, err := analyticsService.Flows(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(res))
+}
+
+// Failures failed and refunded txs by failure category, per chain or channel
+func (ctl *AnalyticsController) Failures(c *gin.Context) {
+	var req vo.AnalyticsFailuresReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+
+	res, err := analyticsService.Failures(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(res))
+}
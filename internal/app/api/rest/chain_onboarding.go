@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ChainOnboardingController struct {
+}
+
+// Onboard validate and save the chain, then run the remaining onboarding steps in background
+func (ctl *ChainOnboardingController) Onboard(c *gin.Context) {
+	var req vo.ChainOnboardReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	resp, err := chainOnboardingService.Prepare(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+
+	go func() {
+		st := time.Now().Unix()
+		res := chainOnboardingTask.RunWithParam(resp.ChainId)
+		logrus.Infof("ChainOnboardingController chain %s onboarding end, time use %d(s), exec status: %d", resp.ChainId, time.Now().Unix()-st, res)
+	}()
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *ChainOnboardingController) Progress(c *gin.Context) {
+	resp, err := chainOnboardingService.Progress(c.Param("chain_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/gin-gonic/gin"
+)
+
+type ChainRegistryController struct {
+}
+
+func (ctl *ChainRegistryController) Diffs(c *gin.Context) {
+	var req vo.ChainRegistryDiffsReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	if req.UseCount {
+		count, err := chainRegistryService.DiffsCount(&req)
+		if err != nil {
+			c.JSON(http.StatusOK, response.FailError(err))
+			return
+		}
+		c.JSON(http.StatusOK, response.Success(count))
+		return
+	}
+	resp, err := chainRegistryService.Diffs(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *ChainRegistryController) Approve(c *gin.Context) {
+	resp, err := chainRegistryService.Approve(c.Param("diff_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *ChainRegistryController) Reject(c *gin.Context) {
+	resp, err := chainRegistryService.Reject(c.Param("diff_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
@@ -34,3 +34,12 @@ func (ctl *ChannelController) List(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, response.Success(res))
 }
+
+func (ctl *ChannelController) ChannelTypes(c *gin.Context) {
+	res, err := channelService.ChannelTypes()
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(res))
+}
@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/gin-gonic/gin"
+)
+
+type EscrowController struct {
+}
+
+func (ctl *EscrowController) Reconciles(c *gin.Context) {
+	var req vo.EscrowReconcilesReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	if req.UseCount {
+		count, err := escrowService.ReconcilesCount(&req)
+		if err != nil {
+			c.JSON(http.StatusOK, response.FailError(err))
+			return
+		}
+		c.JSON(http.StatusOK, response.Success(count))
+		return
+	}
+	resp, err := escrowService.Reconciles(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *EscrowController) Discrepancies(c *gin.Context) {
+	var req vo.EscrowDiscrepanciesReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	if req.UseCount {
+		count, err := escrowService.DiscrepanciesCount(&req)
+		if err != nil {
+			c.JSON(http.StatusOK, response.FailError(err))
+			return
+		}
+		c.JSON(http.StatusOK, response.Success(count))
+		return
+	}
+	resp, err := escrowService.Discrepancies(&req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
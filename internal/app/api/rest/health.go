@@ -0,0 +1,26 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+}
+
+// Healthz liveness probe
+func (ctl *HealthController) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, monitor.Liveness())
+}
+
+// Readyz readiness probe, respond 503 if any dependency is unavailable
+func (ctl *HealthController) Readyz(c *gin.Context) {
+	resp, ok := monitor.Readiness()
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
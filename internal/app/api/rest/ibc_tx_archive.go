@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ArchiveController struct {
+}
+
+func (ctl *ArchiveController) Partitions(c *gin.Context) {
+	resp, err := archiveService.Partitions(c.Param("chain_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+// Restore restore the archived partition of the chain and the month in background
+func (ctl *ArchiveController) Restore(c *gin.Context) {
+	chainId, month := c.Param("chain_id"), c.Param("month")
+	if err := archiveService.CheckRestore(chainId, month); err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+
+	go func() {
+		st := time.Now().Unix()
+		res := archiveTask.Restore(chainId, month)
+		logrus.Infof("ArchiveController restore %s %s end, time use %d(s), exec status: %d", chainId, month, time.Now().Unix()-st, res)
+	}()
+	c.JSON(http.StatusOK, response.Success(nil))
+}
+
+func (ctl *ArchiveController) Lookup(c *gin.Context) {
+	resp, err := archiveService.Lookup(c.Param("hash"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
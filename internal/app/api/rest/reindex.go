@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ReindexController struct {
+}
+
+// Create save the re-index job of the chain, then run it in background
+func (ctl *ReindexController) Create(c *gin.Context) {
+	var req vo.ReindexReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	resp, err := reindexService.Create(c.Param("chain_id"), &req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+
+	go func() {
+		st := time.Now().Unix()
+		res := reindexTask.RunWithParam(resp.JobId)
+		logrus.Infof("ReindexController job %s end, time use %d(s), exec status: %d", resp.JobId, time.Now().Unix()-st, res)
+	}()
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+// Resume run the failed or stale job again in background, its succeeded steps are skipped
+func (ctl *ReindexController) Resume(c *gin.Context) {
+	resp, err := reindexService.Resume(c.Param("job_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+
+	go func() {
+		st := time.Now().Unix()
+		res := reindexTask.RunWithParam(resp.JobId)
+		logrus.Infof("ReindexController resume job %s end, time use %d(s), exec status: %d", resp.JobId, time.Now().Unix()-st, res)
+	}()
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *ReindexController) Jobs(c *gin.Context) {
+	resp, err := reindexService.Jobs(c.Param("chain_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *ReindexController) Progress(c *gin.Context) {
+	resp, err := reindexService.Progress(c.Param("job_id"))
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/response"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -21,24 +23,54 @@ func (ctl *TaskController) Run(c *gin.Context) {
 		c.JSON(http.StatusOK, response.FailBadRequest(fmt.Errorf("task name is empty")))
 		return
 	}
+	dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
+	oneOffTask, isOneOff := ctl.oneOffTask(taskName)
+	if dryRun && !isOneOff {
+		c.JSON(http.StatusOK, response.FailBadRequest(fmt.Errorf("task %s doesn't support dry run", taskName)))
+		return
+	}
+	// the fix tasks share the running lock with the one-off runs on startup and by the command line
+	unlockOneOff := func() {}
+	if isOneOff {
+		unlock, err := task.LockOneOffTask(taskName)
+		if err != nil {
+			c.JSON(http.StatusTooManyRequests, response.FailMsg("Please try again later"))
+			return
+		}
+		unlockOneOff = unlock
+	}
 	lockKey := fmt.Sprintf("%s:%s", "TaskController", taskName)
 	if err := cache.GetRedisClient().Lock(lockKey, time.Now().Unix(), time.Hour); err != nil {
+		unlockOneOff()
 		c.JSON(http.StatusTooManyRequests, response.FailMsg("Please try again later"))
 		return
 	}
 
 	go func() {
+		defer unlockOneOff()
 		st := time.Now().Unix()
 		res := 0
-		logrus.Infof("TaskController task %s start", taskName)
+		logrus.Infof("TaskController task %s start, dry run: %t", taskName, dryRun)
+		if dryRun {
+			dryRunTask, err := task.NewDryRunTask(oneOffTask)
+			if err != nil {
+				logrus.Errorf("TaskController run %s err, %v", taskName, err)
+				return
+			}
+			oneOffTask = dryRunTask
+		}
 
 		switch taskName {
 		case addChainTask.Name():
 			res = addChainTask.RunWithParam(c.PostForm("new_chains"))
 		case fixDcChainIdTask.Name():
-			res = fixDcChainIdTask.Run()
+			res = oneOffTask.Run()
 		case fixBaseDenomChainIdTask.Name():
-			res = fixBaseDenomChainIdTask.Run()
+			res = oneOffTask.Run()
+		case fixPacketMemoTask.Name():
+			res = oneOffTask.Run()
+		case fixFailureCategoryTask.Name():
+			res = oneOffTask.Run()
 		case fixDenomTraceDataTask.Name():
 			startTime, err := strconv.ParseInt(c.PostForm("start_time"), 10, 64)
 			if err != nil {
@@ -50,7 +82,7 @@ func (ctl *TaskController) Run(c *gin.Context) {
 				logrus.Errorf("TaskController run %s err, %v", taskName, err)
 				return
 			}
-			res = fixDenomTraceDataTask.RunWithParam(startTime, endTime)
+			res = oneOffTask.(*task.FixDenomTraceDataTask).RunWithParam(startTime, endTime)
 		case fixDenomTraceHistoryDataTask.Name():
 			startTime, err := strconv.ParseInt(c.PostForm("start_time"), 10, 64)
 			if err != nil {
@@ -62,17 +94,19 @@ func (ctl *TaskController) Run(c *gin.Context) {
 				logrus.Errorf("TaskController run %s err, %v", taskName, err)
 				return
 			}
-			res = fixDenomTraceHistoryDataTask.RunWithParam(startTime, endTime)
+			res = oneOffTask.(*task.FixDenomTraceHistoryDataTask).RunWithParam(startTime, endTime)
 		case tokenStatisticsTask.Name():
 			res = tokenStatisticsTask.Run()
 		case channelStatisticsTask.Name():
 			res = channelStatisticsTask.Run()
+		case chainFlowStatisticsTask.Name():
+			res = chainFlowStatisticsTask.Run()
 		case relayerStatisticsTask.Name():
 			res = relayerStatisticsTask.Run()
 		case relayerDataTask.Name():
 			res = relayerDataTask.Run()
 		case fixFailRecvPacketTask.Name():
-			fixFailRecvPacketTask.Run()
+			oneOffTask.Run()
 		case addTransferDataTask.Name():
 			addTransferDataTask.RunWithParam(c.PostForm("new_chains"))
 		case fixFailTxTask.Name():
@@ -83,17 +117,17 @@ func (ctl *TaskController) Run(c *gin.Context) {
 					logrus.Errorf("TaskController run %s err, %v", taskName, err)
 					return
 				}
-				fixFailTxTask.RunWithParam(startTime)
+				oneOffTask.(*task.FixFailTxTask).RunWithParam(startTime)
 			} else {
-				fixFailTxTask.Run()
+				oneOffTask.Run()
 			}
 
 		case fixAcknowledgeTxTask.Name():
-			fixAcknowledgeTxTask.Run()
+			oneOffTask.Run()
 		case fixAckTxPacketIdTask.Name():
-			fixAckTxPacketIdTask.RunWithParam(c.PostForm("chains"), c.PostForm("end_height"))
+			oneOffTask.(*task.FixAckTxPacketIdTask).RunWithParam(c.PostForm("chains"), c.PostForm("end_height"))
 		case fixIbxTxTask.Name():
-			fixIbxTxTask.RunWithParam(c.PostForm("domain"))
+			oneOffTask.(*task.FixIbxTxTask).RunWithParam(c.PostForm("domain"))
 		case ibcNodeLcdCronTask.Name():
 			value := c.PostForm("chains")
 			if len(value) > 0 {
@@ -113,3 +147,51 @@ func (ctl *TaskController) Run(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Success("task is running"))
 
 }
+
+// oneOffTask find the one-off fix task by name, the fix tasks support dry-run mode
+func (ctl *TaskController) oneOffTask(taskName string) (task.OneOffTask, bool) {
+	oneOffTasks := []task.OneOffTask{&fixDcChainIdTask, &fixBaseDenomChainIdTask, &fixDenomTraceDataTask, &fixDenomTraceHistoryDataTask,
+		&fixFailRecvPacketTask, &fixFailTxTask, &fixAcknowledgeTxTask, &fixAckTxPacketIdTask, &fixIbxTxTask, &fixPacketMemoTask,
+		&fixFailureCategoryTask}
+	for _, v := range oneOffTasks {
+		if v.Name() == taskName {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (ctl *TaskController) DryRunReport(c *gin.Context) {
+	taskName := c.Param("task_name")
+	var req vo.TaskDryRunReportReq
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusOK, response.FailBadRequest(err))
+		return
+	}
+	resp, err := taskService.DryRunReport(taskName, &req)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *TaskController) Progress(c *gin.Context) {
+	taskName := c.Param("task_name")
+	resp, err := taskService.Progress(taskName)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(resp))
+}
+
+func (ctl *TaskController) ResetCheckpoint(c *gin.Context) {
+	taskName := c.Param("task_name")
+	num, err := taskService.ResetCheckpoint(taskName)
+	if err != nil {
+		c.JSON(http.StatusOK, response.FailError(err))
+		return
+	}
+	c.JSON(http.StatusOK, response.Success(fmt.Sprintf("reset task %s checkpoint, affect num: %d", taskName, num)))
+}
@@ -6,22 +6,37 @@ import (
 )
 
 var (
-	tokenService    service.ITokenService    = new(service.TokenService)
-	channelService  service.IChannelService  = new(service.ChannelService)
-	chainService    service.IChainService    = new(service.ChainService)
-	relayerService  service.IRelayerService  = new(service.RelayerService)
-	homeService     service.IHomeService     = new(service.HomeService)
-	transferService service.ITransferService = new(service.TransferService)
-	cacheService    service.CacheService
+	tokenService     service.ITokenService     = new(service.TokenService)
+	channelService   service.IChannelService   = new(service.ChannelService)
+	chainService     service.IChainService     = new(service.ChainService)
+	relayerService   service.IRelayerService   = new(service.RelayerService)
+	homeService      service.IHomeService      = new(service.HomeService)
+	transferService  service.ITransferService  = new(service.TransferService)
+	taskService      service.ITaskService      = new(service.TaskService)
+	analyticsService service.IAnalyticsService = new(service.AnalyticsService)
+	nftService       service.INftService       = new(service.NftService)
+	icaService       service.IIcaService       = new(service.IcaService)
+	packetService    service.IPacketService    = new(service.PacketService)
+	escrowService    service.IEscrowService    = new(service.EscrowService)
+	alertService     service.IAlertService     = new(service.AlertService)
+	cacheService     service.CacheService
+
+	chainOnboardingService service.IChainOnboardingService = new(service.ChainOnboardingService)
+	chainRegistryService   service.IChainRegistryService   = new(service.ChainRegistryService)
+	reindexService         service.IReindexService         = new(service.ReindexService)
+	archiveService         service.IArchiveService         = new(service.ArchiveService)
 
 	// task
 	addChainTask                 task.AddChainTask
 	fixDcChainIdTask             task.FixDcChainIdTask
 	fixBaseDenomChainIdTask      task.FixBaseDenomChainIdTask
+	fixPacketMemoTask            task.FixPacketMemoTask
+	fixFailureCategoryTask       task.FixFailureCategoryTask
 	fixDenomTraceDataTask        task.FixDenomTraceDataTask
 	fixDenomTraceHistoryDataTask task.FixDenomTraceHistoryDataTask
 	tokenStatisticsTask          task.TokenStatisticsTask
 	channelStatisticsTask        task.ChannelStatisticsTask
+	chainFlowStatisticsTask      task.ChainFlowStatisticsTask
 	relayerStatisticsTask        task.RelayerStatisticsTask
 	relayerDataTask              task.RelayerDataTask
 	fixFailRecvPacketTask        task.FixFailRecvPacketTask
@@ -32,4 +47,7 @@ var (
 	fixIbxTxTask                 task.FixIbxTxTask
 	ibcNodeLcdCronTask           task.IbcNodeLcdCronTask
 	ibcStatisticCronTask         task.IbcStatisticCronTask
+	chainOnboardingTask          task.ChainOnboardingTask
+	reindexTask                  task.IbcReindexTask
+	archiveTask                  task.IbcTxArchiveTask
 )
@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api/middleware"
@@ -15,68 +16,119 @@ import (
 )
 
 var (
-	store        = persistence.NewInMemoryStore(time.Second)
-	aliveSeconds = 3
+	store              = persistence.NewInMemoryStore(time.Second)
+	aliveSeconds int64 = 3
 )
 
 func SetApiCacheAliveTime(duration int) {
-	aliveSeconds = duration
+	atomic.StoreInt64(&aliveSeconds, int64(duration))
+}
+
+// cachePage cache the response of the handle, the alive time can be changed by SetApiCacheAliveTime at runtime
+func cachePage(handle gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cache.CachePage(store, time.Duration(atomic.LoadInt64(&aliveSeconds))*time.Second, handle)(c)
+	}
 }
 
 func Routers(Router *gin.Engine) {
 	Router.Use(middleware.Cors())
 	Router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	Router.GET("", func(c *gin.Context) {
-		c.JSON(http.StatusOK, global.Config.App.Version)
+		c.JSON(http.StatusOK, global.Config().App.Version)
 	})
+	healthCheck(Router)
 
 	ibcRouter := Router.Group("ibc")
 	homePage(ibcRouter)
 	txsPage(ibcRouter)
+	nftPage(ibcRouter)
+	icaPage(ibcRouter)
+	packetPage(ibcRouter)
+	escrowPage(ibcRouter)
+	alertPage(ibcRouter)
 	tokenPage(ibcRouter)
 	channelPage(ibcRouter)
 	chainPage(ibcRouter)
 	relayerPage(ibcRouter)
 	cacheTools(ibcRouter)
 	taskTools(ibcRouter)
+	adminTools(ibcRouter)
+	analyticsPage(ibcRouter)
+}
+
+func healthCheck(r *gin.Engine) {
+	ctl := rest.HealthController{}
+	r.GET("/healthz", ctl.Healthz)
+	r.GET("/readyz", ctl.Readyz)
 }
 
 func homePage(r *gin.RouterGroup) {
 	ctl := rest.HomeController{}
-	r.GET("/chains", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.DailyChains))
-	r.GET("/baseDenoms", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.IbcBaseDenoms))
-	r.GET("/denoms", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.IbcDenoms))
-	r.GET("/statistics", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.Statistics))
+	r.GET("/chains", cachePage(ctl.DailyChains))
+	r.GET("/baseDenoms", cachePage(ctl.IbcBaseDenoms))
+	r.GET("/denoms", cachePage(ctl.IbcDenoms))
+	r.GET("/statistics", cachePage(ctl.Statistics))
 	r.POST("/searchPoint", ctl.SearchPoint)
 }
 
 func txsPage(r *gin.RouterGroup) {
 	ctl := rest.IbcTransferController{}
-	r.GET("/txs", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TransferTxs))
-	r.GET("/txs/:hash", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TransferTxDetail))
-	r.GET("/txs_detail/:hash", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TransferTxDetailNew))
-	r.GET("/trace_source/:hash", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.TraceSource))
+	r.GET("/txs", cachePage(ctl.TransferTxs))
+	r.GET("/txs/:hash", cachePage(ctl.TransferTxDetail))
+	r.GET("/txs_detail/:hash", cachePage(ctl.TransferTxDetailNew))
+	r.GET("/trace_source/:hash", cachePage(ctl.TraceSource))
+}
+
+func nftPage(r *gin.RouterGroup) {
+	ctl := rest.NftController{}
+	r.GET("/nft/txs", cachePage(ctl.NftTxs))
+	r.GET("/nft/txs/:hash", cachePage(ctl.NftTxDetail))
+}
+
+func icaPage(r *gin.RouterGroup) {
+	ctl := rest.IcaController{}
+	r.GET("/ica/accounts", cachePage(ctl.Accounts))
+	r.GET("/ica/txs", cachePage(ctl.IcaTxs))
+}
+
+func packetPage(r *gin.RouterGroup) {
+	ctl := rest.PacketController{}
+	r.GET("/packets", cachePage(ctl.Packets))
+	r.GET("/packets/:chain_id/:port/:channel/:sequence", cachePage(ctl.PacketDetail))
+}
+
+func escrowPage(r *gin.RouterGroup) {
+	ctl := rest.EscrowController{}
+	r.GET("/escrow/reconciles", cachePage(ctl.Reconciles))
+	r.GET("/escrow/discrepancies", cachePage(ctl.Discrepancies))
+}
+
+func alertPage(r *gin.RouterGroup) {
+	ctl := rest.AlertController{}
+	r.GET("/alerts/large_transfers", cachePage(ctl.LargeTransfers))
 }
 
 func tokenPage(r *gin.RouterGroup) {
 	ctl := rest.TokenController{}
-	r.GET("/tokenList", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.List))
-	r.GET("/ibcTokenList", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.IBCTokenList))
+	r.GET("/tokenList", cachePage(ctl.List))
+	r.GET("/ibcTokenList", cachePage(ctl.IBCTokenList))
 }
 
 func channelPage(r *gin.RouterGroup) {
 	ctl := rest.ChannelController{}
-	r.GET("/channelList", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.List))
+	r.GET("/channelList", cachePage(ctl.List))
+	r.GET("/channelTypes", cachePage(ctl.ChannelTypes))
 }
 
 func chainPage(r *gin.RouterGroup) {
 	ctl := rest.ChainController{}
-	r.GET("/chainList", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.List))
+	r.GET("/chainList", cachePage(ctl.List))
 }
 
 func relayerPage(r *gin.RouterGroup) {
 	ctl := rest.RelayerController{}
-	r.GET("/relayerList", cache.CachePage(store, time.Duration(aliveSeconds)*time.Second, ctl.List))
+	r.GET("/relayerList", cachePage(ctl.List))
 	r.POST("/relayerCollect", ctl.Collect)
 }
 
@@ -88,4 +140,36 @@ func cacheTools(r *gin.RouterGroup) {
 func taskTools(r *gin.RouterGroup) {
 	ctl := rest.TaskController{}
 	r.POST("/task/:task_name", ctl.Run)
+	r.GET("/task/:task_name/progress", ctl.Progress)
+	r.DELETE("/task/:task_name/checkpoint", ctl.ResetCheckpoint)
+	r.GET("/task/:task_name/dry_run", ctl.DryRunReport)
+}
+
+func adminTools(r *gin.RouterGroup) {
+	ctl := rest.ChainOnboardingController{}
+	r.POST("/admin/chains", ctl.Onboard)
+	r.GET("/admin/chains/:chain_id/onboarding", ctl.Progress)
+
+	reindexCtl := rest.ReindexController{}
+	r.POST("/admin/chains/:chain_id/reindex", reindexCtl.Create)
+	r.GET("/admin/chains/:chain_id/reindex", reindexCtl.Jobs)
+	r.GET("/admin/reindex/:job_id", reindexCtl.Progress)
+	r.POST("/admin/reindex/:job_id/resume", reindexCtl.Resume)
+
+	archiveCtl := rest.ArchiveController{}
+	r.GET("/admin/chains/:chain_id/archive", archiveCtl.Partitions)
+	r.POST("/admin/chains/:chain_id/archive/:month/restore", archiveCtl.Restore)
+	r.GET("/admin/archive/txs/:hash", archiveCtl.Lookup)
+
+	registryCtl := rest.ChainRegistryController{}
+	r.GET("/admin/chain_registry/diffs", registryCtl.Diffs)
+	r.POST("/admin/chain_registry/diffs/:diff_id/approve", registryCtl.Approve)
+	r.POST("/admin/chain_registry/diffs/:diff_id/reject", registryCtl.Reject)
+}
+
+func analyticsPage(r *gin.RouterGroup) {
+	ctl := rest.AnalyticsController{}
+	r.GET("/analytics/series", cachePage(ctl.Series))
+	r.GET("/analytics/flows", cachePage(ctl.Flows))
+	r.GET("/analytics/failures", cachePage(ctl.Failures))
 }
@@ -12,6 +12,10 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/datasource"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcdpool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/objstore"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task"
@@ -20,13 +24,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func Serve(cfg *conf.Config) {
+// Serve start the api server and tasks. If cfgFilePath is not empty, the safe settings are reloaded when the config
+// file is modified or SIGHUP is received.
+func Serve(cfg *conf.Config, cfgFilePath string) {
 	initCore(cfg)
 	defer repository.Close()
 
 	if cfg.App.ApiCacheAliveSeconds > 0 {
 		api.SetApiCacheAliveTime(cfg.App.ApiCacheAliveSeconds)
 	}
+	if cfgFilePath != "" {
+		go watchConfig(cfgFilePath)
+	}
 
 	r := gin.Default()
 	api.Routers(r)
@@ -42,12 +51,59 @@ func Serve(cfg *conf.Config) {
 	logrus.Fatal(r.Run(cfg.App.Addr))
 }
 
+// Prepare init the core components(config, logger, mongo, redis) without starting the api server and tasks, it's used
+// by the command line tools. The returned function releases the resources.
+func Prepare(cfg *conf.Config) func() {
+	initCore(cfg)
+	return repository.Close
+}
+
 func initCore(cfg *conf.Config) {
-	global.Config = cfg
+	global.SetConfig(cfg)
 	initLogger(&cfg.Log)
 	repository.InitMgo(cfg.Mongo, context.Background())
 	cache.InitRedisClient(cfg.Redis)
 	task.LoadTaskConf(cfg.Task)
+	lcdpool.SetOptions(lcdpool.Options{
+		Timeout:         time.Duration(cfg.Lcd.TimeoutSeconds) * time.Second,
+		MaxAttempts:     cfg.Lcd.MaxAttempts,
+		BreakerFailures: cfg.Lcd.BreakerFailures,
+		BreakerCooldown: time.Duration(cfg.Lcd.BreakerCooldownSeconds) * time.Second,
+		MaxLagBlocks:    cfg.Lcd.MaxLagBlocks,
+	})
+	lcd.SetOptions(lcd.Options{
+		Timeout:       time.Duration(cfg.Lcd.CallTimeoutSeconds) * time.Second,
+		Retries:       cfg.Lcd.Retries,
+		RetryInterval: time.Duration(cfg.Lcd.RetryIntervalMillis) * time.Millisecond,
+	})
+	lcd.SetVersionCache(new(cache.LcdApiVersionCacheRepo))
+	lcdpool.SetSeed(lcdPoolSeed)
+	datasource.SetOptions(datasource.Options{
+		Timeout:       time.Duration(cfg.Lcd.CallTimeoutSeconds) * time.Second,
+		Retries:       cfg.Lcd.Retries,
+		RetryInterval: time.Duration(cfg.Lcd.RetryIntervalMillis) * time.Millisecond,
+	})
+	objstore.SetOptions(objstore.Options{
+		Path:      cfg.Archive.Path,
+		Endpoint:  cfg.Archive.Endpoint,
+		Region:    cfg.Archive.Region,
+		AccessKey: cfg.Archive.AccessKey,
+		SecretKey: cfg.Archive.SecretKey,
+	})
+}
+
+// lcdPoolSeed the lcds of the chain registry and the trace source lcds of the chain, which seed its lcd pool
+func lcdPoolSeed(chainId string) []string {
+	var lcds []string
+	if registry, err := new(repository.ChainRegistryRepo).FindOne(chainId); err == nil {
+		lcds = append(lcds, registry.Lcds...)
+	}
+	if traceSourceLcds, err := new(cache.LcdAddrCacheRepo).Get(chainId); err == nil {
+		for _, v := range traceSourceLcds {
+			lcds = append(lcds, v.LcdAddr)
+		}
+	}
+	return lcds
 }
 
 func initLogger(logCfg *conf.Log) {
@@ -97,6 +153,15 @@ func startTask() {
 		&task.IbcTxRelateHistoryTask{},
 		&task.IbcTxMigrateTask{},
 		&task.IbcNodeLcdCronTask{},
+		&task.IbcChainFlowTask{},
+		&task.IbcNftTransferTask{},
+		&task.IbcIcaTask{},
+		&task.IbcPacketTask{},
+		&task.IbcEscrowReconcileTask{},
+		&task.IbcLargeTransferTask{},
+		&task.IbcChainRegistrySyncTask{},
+		&task.IbcIngestTask{},
+		&task.IbcTxArchiveTask{},
 	)
 	task.Start()
 }
@@ -107,11 +172,14 @@ func startOneOffTask() {
 		&task.ChannelStatisticsTask{},
 		&task.RelayerStatisticsTask{},
 		&task.TokenStatisticsTask{},
+		&task.ChainFlowStatisticsTask{},
 		//&task.FixDenomTraceHistoryDataTask{},
 		//&task.FixDenomTraceDataTask{},
 		//&task.AddChainTask{},
 		//&task.FixDcChainIdTask{},
 		//&task.FixBaseDenomChainIdTask{},
+		//&task.FixPacketMemoTask{},
+		//&task.FixFailureCategoryTask{},
 		//&task.RelayerDataTask{},
 		//&task.AddTransferDataTask{},
 		//&task.FixFailRecvPacketTask{},
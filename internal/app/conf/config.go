@@ -2,11 +2,17 @@ package conf
 
 import (
 	"bytes"
+	"reflect"
+	"strings"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/enum"
 	"github.com/spf13/viper"
 )
 
+// EnvPrefix prefix of the env variables which override the config fields, the env name is the upper case of
+// <prefix>_<section>_<field>, e.g. IBC_EXPLORER_MONGO_URL, IBC_EXPLORER_TASK_CRON_TIME_CHAIN_TASK
+const EnvPrefix = "IBC_EXPLORER"
+
 type Config struct {
 	App         App
 	Mongo       Mongo
@@ -16,6 +22,10 @@ type Config struct {
 	Spi         Spi
 	Task        Task
 	ChainConfig ChainConfig `mapstructure:"chain_config"`
+	Health      Health
+	Lcd         Lcd
+	Tiering     Tiering
+	Archive     Archive
 }
 
 type Mysql struct {
@@ -64,40 +74,64 @@ type Log struct {
 }
 
 type Task struct {
-	CronJobRelayerAddr                string `mapstructure:"cron_job_relayer_addr"`
-	CronTimeChainTask                 int    `mapstructure:"cron_time_chain_task"`
-	CronTimeChannelTask               int    `mapstructure:"cron_time_channel_task"`
-	CronTimeRelayerTask               int    `mapstructure:"cron_time_relayer_task"`
-	CronTimeStatisticTask             int    `mapstructure:"cron_time_statistic_task"`
-	CronTimeTokenTask                 int    `mapstructure:"cron_time_token_task"`
-	CronTimeTokenPriceTask            int    `mapstructure:"cron_time_token_price_task"`
-	CronTimeChainConfigTask           int    `mapstructure:"cron_time_chain_config_task"`
-	CronTimeDenomCalculateTask        int    `mapstructure:"cron_time_denom_calculate_task"`
-	CronTimeDenomUpdateTask           int    `mapstructure:"cron_time_denom_update_task"`
-	CronTimeSyncTransferTxTask        int    `mapstructure:"cron_time_sync_transfer_tx_task"`
-	CronTimeIbcTxRelateTask           int    `mapstructure:"cron_time_ibc_tx_relate_task"`
-	CronTimeIbcTxMigrateTask          int    `mapstructure:"cron_time_ibc_tx_migrate_task"`
-	RedisLockExpireTime               int    `mapstructure:"redis_lock_expire_time"`
-	SingleChainSyncTransferTxMax      int    `mapstructure:"single_chain_sync_transfer_tx_max"`
-	SingleChainIbcTxRelateMax         int    `mapstructure:"single_chain_ibc_tx_relate_max"`
-	FixDenomTraceDataStartTime        int64  `mapstructure:"fix_denom_trace_data_start_time"`
-	FixDenomTraceDataEndTime          int64  `mapstructure:"fix_denom_trace_data_end_time"`
-	FixDenomTraceHistoryDataStartTime int64  `mapstructure:"fix_denom_trace_history_data_start_time"`
-	FixDenomTraceHistoryDataEndTime   int64  `mapstructure:"fix_denom_trace_history_data_end_time"`
-	CronTimeSyncAckTxTask             int    `mapstructure:"cron_time_sync_ack_tx_task"`
+	CronJobRelayerAddr                string  `mapstructure:"cron_job_relayer_addr"`
+	CronTimeChainTask                 int     `mapstructure:"cron_time_chain_task"`
+	CronTimeChannelTask               int     `mapstructure:"cron_time_channel_task"`
+	CronTimeRelayerTask               int     `mapstructure:"cron_time_relayer_task"`
+	CronTimeStatisticTask             int     `mapstructure:"cron_time_statistic_task"`
+	CronTimeTokenTask                 int     `mapstructure:"cron_time_token_task"`
+	CronTimeTokenPriceTask            int     `mapstructure:"cron_time_token_price_task"`
+	CronTimeChainConfigTask           int     `mapstructure:"cron_time_chain_config_task"`
+	CronTimeDenomCalculateTask        int     `mapstructure:"cron_time_denom_calculate_task"`
+	CronTimeDenomUpdateTask           int     `mapstructure:"cron_time_denom_update_task"`
+	CronTimeSyncTransferTxTask        int     `mapstructure:"cron_time_sync_transfer_tx_task"`
+	CronTimeIbcTxRelateTask           int     `mapstructure:"cron_time_ibc_tx_relate_task"`
+	CronTimeIbcTxMigrateTask          int     `mapstructure:"cron_time_ibc_tx_migrate_task"`
+	CronTimeIbcTxArchiveTask          int     `mapstructure:"cron_time_ibc_tx_archive_task"`
+	RedisLockExpireTime               int     `mapstructure:"redis_lock_expire_time"`
+	SingleChainSyncTransferTxMax      int     `mapstructure:"single_chain_sync_transfer_tx_max"`
+	SingleChainIbcTxRelateMax         int     `mapstructure:"single_chain_ibc_tx_relate_max"`
+	FixDenomTraceDataStartTime        int64   `mapstructure:"fix_denom_trace_data_start_time"`
+	FixDenomTraceDataEndTime          int64   `mapstructure:"fix_denom_trace_data_end_time"`
+	FixDenomTraceHistoryDataStartTime int64   `mapstructure:"fix_denom_trace_history_data_start_time"`
+	FixDenomTraceHistoryDataEndTime   int64   `mapstructure:"fix_denom_trace_history_data_end_time"`
+	CronTimeSyncAckTxTask             int     `mapstructure:"cron_time_sync_ack_tx_task"`
+	CronTimeChainFlowTask             int     `mapstructure:"cron_time_chain_flow_task"`
+	CronTimeNftTransferTask           int     `mapstructure:"cron_time_nft_transfer_task"`
+	CronTimeIcaTask                   int     `mapstructure:"cron_time_ica_task"`
+	CronTimePacketTask                int     `mapstructure:"cron_time_packet_task"`
+	CronTimeEscrowReconcileTask       int     `mapstructure:"cron_time_escrow_reconcile_task"`
+	EscrowMismatchAlertTimes          int64   `mapstructure:"escrow_mismatch_alert_times"`
+	CronTimeLargeTransferTask         int     `mapstructure:"cron_time_large_transfer_task"`
+	LargeTransferUsdThreshold         float64 `mapstructure:"large_transfer_usd_threshold"`
+	LargeTransferMinUsd               float64 `mapstructure:"large_transfer_min_usd"`
+	LargeTransferStddevTimes          float64 `mapstructure:"large_transfer_stddev_times"`
+	LargeTransferMinSamples           int64   `mapstructure:"large_transfer_min_samples"`
+	CronTimeChainRegistrySyncTask     int     `mapstructure:"cron_time_chain_registry_sync_task"`
+	ChainRegistryAutoApply            bool    `mapstructure:"chain_registry_auto_apply"`
+	CronTimeIngestTask                int     `mapstructure:"cron_time_ingest_task"`
+	IngestChains                      string  `mapstructure:"ingest_chains"`
+	IngestStartHeight                 int64   `mapstructure:"ingest_start_height"`
+	IngestMaxBlocks                   int     `mapstructure:"ingest_max_blocks"`
+	SyncVerifyBlocks                  int     `mapstructure:"sync_verify_blocks"`
 
 	SwitchFixDenomTraceHistoryDataTask bool `mapstructure:"switch_fix_denom_trace_history_data_task"`
 	SwitchFixDenomTraceDataTask        bool `mapstructure:"switch_fix_denom_trace_data_task"`
 	SwitchAddChainTask                 bool `mapstructure:"switch_add_chain_task"`
 	SwitchOnlyInitRelayerData          bool `mapstructure:"switch_only_init_relayer_data"`
 	SwitchIbcTxMigrateTask             bool `mapstructure:"switch_ibc_tx_migrate_task"`
+	SwitchIbcTxArchiveTask             bool `mapstructure:"switch_ibc_tx_archive_task"`
 	SwitchIbcTokenStatisticsTask       bool `mapstructure:"switch_ibc_token_statistics_task"`
 	SwitchIbcChannelStatisticsTask     bool `mapstructure:"switch_ibc_channel_statistics_task"`
 	SwitchIbcRelayerStatisticsTask     bool `mapstructure:"switch_ibc_relayer_statistics_task"`
+	SwitchIbcChainFlowStatisticsTask   bool `mapstructure:"switch_ibc_chain_flow_statistics_task"`
 	SwitchAddTransferDataTask          bool `mapstructure:"switch_add_transfer_data_task"`
 	SwitchFixFailRecvPacketTask        bool `mapstructure:"switch_fix_fail_recv_packet_task"`
 	SwitchFixDcChainIdTask             bool `mapstructure:"switch_fix_dc_chain_id_task"`
 	SwitchFixBaseDenomChainIdTask      bool `mapstructure:"switch_fix_base_denom_chain_id_task"`
+	SwitchFixPacketMemoTask            bool `mapstructure:"switch_fix_packet_memo_task"`
+	SwitchFixFailureCategoryTask       bool `mapstructure:"switch_fix_failure_category_task"`
+	OneOffTaskDryRun                   bool `mapstructure:"one_off_task_dry_run"`
 
 	SyncTransferTxWorkerNum    int `mapstructure:"sync_transfer_tx_worker_num"`
 	IbcTxRelateWorkerNum       int `mapstructure:"ibc_tx_relate_worker_num"`
@@ -110,6 +144,49 @@ type Spi struct {
 	CoingeckoPriceUrl string `mapstructure:"coingecko_price_url"`
 }
 
+type Health struct {
+	CriticalTasks       string `mapstructure:"critical_tasks"`
+	TaskMaxDelaySeconds int64  `mapstructure:"task_max_delay_seconds"`
+	MaxSyncLagBlocks    int64  `mapstructure:"max_sync_lag_blocks"`
+}
+
+// Lcd the options of the lcd endpoint pools, the zero fields use the default values
+type Lcd struct {
+	TimeoutSeconds         int   `mapstructure:"timeout_seconds"`
+	MaxAttempts            int   `mapstructure:"max_attempts"`
+	BreakerFailures        int   `mapstructure:"breaker_failures"`
+	BreakerCooldownSeconds int   `mapstructure:"breaker_cooldown_seconds"`
+	MaxLagBlocks           int64 `mapstructure:"max_lag_blocks"`
+	CallTimeoutSeconds     int   `mapstructure:"call_timeout_seconds"`
+	Retries                int   `mapstructure:"retries"`
+	RetryIntervalMillis    int   `mapstructure:"retry_interval_millis"`
+}
+
+// Tiering the policy of moving the ibc txs from the hot tier ex_ibc_tx_latest to the cold tier ex_ibc_tx by
+// ibc_tx_migrate_task, the zero fields use the default values
+type Tiering struct {
+	HotMaxTxs     int64  `mapstructure:"hot_max_txs"`
+	HotMaxAgeDays int    `mapstructure:"hot_max_age_days"`
+	ColdStatus    string `mapstructure:"cold_status"`
+	BatchSize     int    `mapstructure:"batch_size"`
+}
+
+// TieringStatus the status names of the ibc txs which can be set in tiering.cold_status
+var TieringStatus = []string{"success", "failed", "processing", "refunded"}
+
+// Archive the options of ibc_tx_archive_task, which exports the ibc txs of ex_ibc_tx older than max_age_days to the
+// object storage of path, a local directory or s3://<bucket>/<prefix>
+type Archive struct {
+	Path            string
+	Endpoint        string
+	Region          string
+	AccessKey       string `mapstructure:"access_key"`
+	SecretKey       string `mapstructure:"secret_key" json:"-"`
+	MaxAgeDays      int    `mapstructure:"max_age_days"`
+	BatchSize       int    `mapstructure:"batch_size"`
+	RestoreKeepDays int    `mapstructure:"restore_keep_days"`
+}
+
 type ChainConfig struct {
 	NewChains         string `mapstructure:"new_chains"`
 	AddTransferChains string `mapstructure:"add_transfer_chains"`
@@ -123,9 +200,48 @@ func ReadConfig(data []byte) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = bindEnvs(v, reflect.TypeOf(Config{}), ""); err != nil {
+		return nil, err
+	}
 	var conf Config
 	if err := v.Unmarshal(&conf); err != nil {
 		return nil, err
 	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
 	return &conf, nil
 }
+
+// bindEnvs bind every field of the config to its env variable, so that the fields missing in the config file can
+// also be overridden
+func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := fieldKey(field, prefix)
+		if field.Type.Kind() == reflect.Struct {
+			if err := bindEnvs(v, field.Type, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		env := EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if err := v.BindEnv(key, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldKey return the config key of the field, e.g. task.cron_time_chain_task
+func fieldKey(field reflect.StructField, prefix string) string {
+	key := strings.ToLower(field.Name)
+	if tag := field.Tag.Get("mapstructure"); tag != "" {
+		key = tag
+	}
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+	return key
+}
@@ -0,0 +1,110 @@
+package conf
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+var testConfigData = []byte(`
+[app]
+addr = "0.0.0.0:8000"
+api_cache_alive_seconds = 3
+max_page_size = 3000
+
+[log]
+log_level = "debug"
+
+[mongo]
+url = "mongodb://127.0.0.1:27017"
+database = "iobscan-ibc"
+
+[redis]
+addrs = "127.0.0.1:6379"
+mode = "single"
+
+[task]
+cron_job_relayer_addr = "0 0 */6 * * ?"
+cron_time_chain_task = 5
+sync_transfer_tx_worker_num = 5
+`)
+
+func TestReadConfig(t *testing.T) {
+	cfg, err := ReadConfig(testConfigData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Task.CronTimeChainTask != 5 || cfg.Redis.Mode != "single" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestReadConfigEnvOverride(t *testing.T) {
+	os.Setenv("IBC_EXPLORER_MONGO_DATABASE", "iobscan-ibc-env")
+	os.Setenv("IBC_EXPLORER_TASK_CRON_TIME_TOKEN_TASK", "30")
+	defer os.Unsetenv("IBC_EXPLORER_MONGO_DATABASE")
+	defer os.Unsetenv("IBC_EXPLORER_TASK_CRON_TIME_TOKEN_TASK")
+
+	cfg, err := ReadConfig(testConfigData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Mongo.Database != "iobscan-ibc-env" {
+		t.Fatalf("mongo.database not overridden, got %s", cfg.Mongo.Database)
+	}
+	if cfg.Task.CronTimeTokenTask != 30 {
+		t.Fatalf("task.cron_time_token_task not overridden, got %d", cfg.Task.CronTimeTokenTask)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg, err := ReadConfig(testConfigData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Mongo.Url = ""
+	cfg.Redis.Mode = "sentinel"
+	cfg.Log.LogLevel = "verbose"
+	cfg.Task.CronJobRelayerAddr = "every 6 hours"
+	cfg.Task.IbcTxRelateWorkerNum = -1
+	cfg.Tiering.ColdStatus = "success,archived"
+	cfg.Archive.MaxAgeDays = 365
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("expect validate error")
+	}
+	for _, key := range []string{"mongo.url", "redis.mode", "log.log_level", "task.cron_job_relayer_addr", "task.ibc_tx_relate_worker_num",
+		"tiering.cold_status", "archive.path"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expect %s in the error: %v", key, err)
+		}
+	}
+}
+
+func TestMergeReloadable(t *testing.T) {
+	current, err := ReadConfig(testConfigData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest := *current
+	latest.App.MaxPageSize = 100
+	latest.Log.LogLevel = "info"
+	latest.Task.CronTimeChainTask = 10
+	latest.Task.SyncTransferTxWorkerNum = 8
+	latest.Tiering.HotMaxAgeDays = 90
+	latest.Mongo.Database = "iobscan-ibc-new"
+
+	merged, restartRequired := MergeReloadable(current, &latest)
+	if merged.App.MaxPageSize != 100 || merged.Log.LogLevel != "info" || merged.Task.CronTimeChainTask != 10 ||
+		merged.Task.SyncTransferTxWorkerNum != 8 || merged.Tiering.HotMaxAgeDays != 90 {
+		t.Fatalf("safe settings not reloaded: %+v", merged)
+	}
+	if merged.Mongo.Database != current.Mongo.Database {
+		t.Fatalf("mongo.database should not be reloaded")
+	}
+	if len(restartRequired) != 1 || restartRequired[0] != "mongo.database" {
+		t.Fatalf("unexpected restart required settings: %v", restartRequired)
+	}
+}
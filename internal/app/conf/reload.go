@@ -0,0 +1,47 @@
+package conf
+
+import (
+	"reflect"
+	"strings"
+)
+
+// MergeReloadable copy the settings which are safe to be reloaded without restarting (task intervals, worker nums,
+// app.api_cache_alive_seconds, app.max_page_size, log.log_level, health, tiering) from latest to a copy of current. The changed
+// settings which require restarting are returned as well.
+func MergeReloadable(current, latest *Config) (*Config, []string) {
+	merged := *current
+	merged.App.ApiCacheAliveSeconds = latest.App.ApiCacheAliveSeconds
+	merged.App.MaxPageSize = latest.App.MaxPageSize
+	merged.Log.LogLevel = latest.Log.LogLevel
+	merged.Health = latest.Health
+	merged.Tiering = latest.Tiering
+
+	mergedTask := reflect.ValueOf(&merged.Task).Elem()
+	latestTask := reflect.ValueOf(latest.Task)
+	for i := 0; i < mergedTask.NumField(); i++ {
+		if isReloadableTaskField(mergedTask.Type().Field(i).Tag.Get("mapstructure")) {
+			mergedTask.Field(i).Set(latestTask.Field(i))
+		}
+	}
+
+	return &merged, diffFields(reflect.ValueOf(merged), reflect.ValueOf(*latest), "")
+}
+
+func isReloadableTaskField(key string) bool {
+	return strings.HasPrefix(key, "cron_time_") || strings.HasSuffix(key, "_worker_num")
+}
+
+// diffFields return the keys of the fields which are different between a and b
+func diffFields(a, b reflect.Value, prefix string) []string {
+	var keys []string
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		key := fieldKey(field, prefix)
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, diffFields(a.Field(i), b.Field(i), key)...)
+		} else if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
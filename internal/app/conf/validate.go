@@ -0,0 +1,116 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/enum"
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// Validate check the config, all the invalid fields are reported in one error
+func (c *Config) Validate() error {
+	var errs []string
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if c.App.Addr == "" {
+		addErr("app.addr is required")
+	}
+	if c.App.ApiCacheAliveSeconds < 0 {
+		addErr("app.api_cache_alive_seconds must not be negative, got %d", c.App.ApiCacheAliveSeconds)
+	}
+	if c.App.MaxPageSize < 0 {
+		addErr("app.max_page_size must not be negative, got %d", c.App.MaxPageSize)
+	}
+
+	if c.Mongo.Url == "" {
+		addErr("mongo.url is required")
+	}
+	if c.Mongo.Database == "" {
+		addErr("mongo.database is required")
+	}
+
+	if c.Redis.Addrs == "" {
+		addErr("redis.addrs is required")
+	}
+	if c.Redis.Mode != enum.RedisSingle && c.Redis.Mode != enum.RedisCluster {
+		addErr("redis.mode must be %s or %s, got %q", enum.RedisSingle, enum.RedisCluster, c.Redis.Mode)
+	}
+
+	if c.Log.LogLevel != "" {
+		if _, err := logrus.ParseLevel(c.Log.LogLevel); err != nil {
+			addErr("log.log_level is invalid, %v", err)
+		}
+	}
+
+	if c.Task.CronJobRelayerAddr != "" {
+		if _, err := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor).
+			Parse(c.Task.CronJobRelayerAddr); err != nil {
+			addErr("task.cron_job_relayer_addr is invalid, %v", err)
+		}
+	}
+	// cron time, worker num, max num 等配置为 0 时使用默认值，不能为负数
+	taskValue := reflect.ValueOf(c.Task)
+	taskType := taskValue.Type()
+	for i := 0; i < taskType.NumField(); i++ {
+		if kind := taskType.Field(i).Type.Kind(); kind != reflect.Int && kind != reflect.Int64 {
+			continue
+		}
+		if v := taskValue.Field(i).Int(); v < 0 {
+			addErr("task.%s must not be negative, got %d", taskType.Field(i).Tag.Get("mapstructure"), v)
+		}
+	}
+	if c.Task.FixDenomTraceDataEndTime < c.Task.FixDenomTraceDataStartTime {
+		addErr("task.fix_denom_trace_data_end_time must not be less than task.fix_denom_trace_data_start_time")
+	}
+	if c.Task.FixDenomTraceHistoryDataEndTime < c.Task.FixDenomTraceHistoryDataStartTime {
+		addErr("task.fix_denom_trace_history_data_end_time must not be less than task.fix_denom_trace_history_data_start_time")
+	}
+
+	if c.Health.TaskMaxDelaySeconds < 0 {
+		addErr("health.task_max_delay_seconds must not be negative, got %d", c.Health.TaskMaxDelaySeconds)
+	}
+	if c.Health.MaxSyncLagBlocks < 0 {
+		addErr("health.max_sync_lag_blocks must not be negative, got %d", c.Health.MaxSyncLagBlocks)
+	}
+
+	lcdValue := reflect.ValueOf(c.Lcd)
+	lcdType := lcdValue.Type()
+	for i := 0; i < lcdType.NumField(); i++ {
+		if v := lcdValue.Field(i).Int(); v < 0 {
+			addErr("lcd.%s must not be negative, got %d", lcdType.Field(i).Tag.Get("mapstructure"), v)
+		}
+	}
+
+	if c.Tiering.HotMaxTxs < 0 || c.Tiering.HotMaxAgeDays < 0 || c.Tiering.BatchSize < 0 {
+		addErr("tiering.hot_max_txs, tiering.hot_max_age_days and tiering.batch_size must not be negative")
+	}
+	for _, v := range strings.Split(c.Tiering.ColdStatus, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		var valid bool
+		for _, status := range TieringStatus {
+			valid = valid || v == status
+		}
+		if !valid {
+			addErr("tiering.cold_status must be in %v, got %q", TieringStatus, v)
+		}
+	}
+
+	if c.Archive.MaxAgeDays < 0 || c.Archive.BatchSize < 0 || c.Archive.RestoreKeepDays < 0 {
+		addErr("archive.max_age_days, archive.batch_size and archive.restore_keep_days must not be negative")
+	}
+	if c.Archive.MaxAgeDays > 0 && c.Archive.Path == "" {
+		addErr("archive.path is required when archive.max_age_days is set")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
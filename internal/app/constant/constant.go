@@ -21,6 +21,9 @@ const (
 	Cosmos                = "cosmos"
 	Iris                  = "iris"
 	PortTransfer          = "transfer"
+	PortNftTransfer       = "nft-transfer"
+	PortIcaHost           = "icahost"
+	PortIcaController     = "icacontroller-"
 	DefaultUnboundTime    = 1209600
 
 	DefaultLimit = 500
@@ -29,11 +32,13 @@ const (
 	DisplayIbcRecordMax = 500000
 
 	MsgTypeTransfer           = "transfer"
+	MsgTypeNftTransfer        = "nft_transfer"
 	MsgTypeRecvPacket         = "recv_packet"
 	MsgTypeTimeoutPacket      = "timeout_packet"
 	MsgTypeAcknowledgement    = "acknowledge_packet"
 	MsgTypeUpdateClient       = "update_client"
 	MsgTypeChannelOpenConfirm = "channel_open_confirm"
+	MsgTypeChannelOpenAck     = "channel_open_ack"
 
 	ChannelOpenStatisticName  = "channel_opened"
 	ChannelCloseStatisticName = "channel_closed"
@@ -2,6 +2,7 @@ package errors
 
 const (
 	ErrInvalidParams = 40000 // 错误的请求参数
+	ErrNotFound      = 40400 // 数据不存在
 	ErrSystemError   = 50000 // 系统异常
 	ErrLcdNodeError  = 60000 // lcd节点异常
 )
@@ -36,6 +36,13 @@ func WrapBadRequest(err error) Error {
 	}
 }
 
+func WrapNotFound(err error) Error {
+	return vsErr{
+		code: ErrNotFound,
+		msg:  err.Error(),
+	}
+}
+
 func WrapLcdNodeErr(errMsg string) Error {
 	return vsErr{
 		code: ErrLcdNodeError,
@@ -1,7 +1,21 @@
 package global
 
 import (
+	"sync/atomic"
+
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
 )
 
-var Config *conf.Config
+var config atomic.Value
+
+// Config the current config. It's replaced as a whole when the config is reloaded and never modified in place, so
+// the returned one can be read by any goroutine without locks.
+func Config() *conf.Config {
+	cfg, _ := config.Load().(*conf.Config)
+	return cfg
+}
+
+// SetConfig publish the config to the readers of Config
+func SetConfig(cfg *conf.Config) {
+	config.Store(cfg)
+}
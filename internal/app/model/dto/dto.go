@@ -103,6 +103,8 @@ type AggrIBCChannelTxsDTO struct {
 	ScChannel        string  `bson:"sc_channel"`
 	DcChannel        string  `bson:"dc_channel"`
 	Count            int64   `bson:"count"`
+	SuccessCount     int64   `bson:"success_count"`
+	RefundedCount    int64   `bson:"refunded_count"`
 	Amount           float64 `bson:"amount"`
 }
 
@@ -111,6 +113,8 @@ type ChannelStatisticsDTO struct {
 	BaseDenom        string          `bson:"base_denom"`
 	BaseDenomChainId string          `bson:"base_denom_chain_id"`
 	TxsCount         int64           `bson:"count"`
+	SuccessCount     int64           `bson:"success_count"`
+	RefundedCount    int64           `bson:"refunded_count"`
 	TxsAmount        decimal.Decimal `bson:"amount"`
 }
 
@@ -170,4 +174,144 @@ type IbcTxQuery struct {
 	BaseDenom        []string
 	BaseDenomChainId string
 	Denom            string
+	MemoType         []string
+	FailureCategory  []string
+}
+
+type IbcNftTxQuery struct {
+	StartTime        int64
+	EndTime          int64
+	ChainId          string
+	Status           []int
+	ClassId          string
+	BaseClassId      string
+	BaseClassChainId string
+	TokenId          string
+	Address          string
+}
+
+type AggrDryRunReportDTO struct {
+	Coll    string `bson:"coll"`
+	Field   string `bson:"field"`
+	Records int64  `bson:"records"`
+}
+
+// AnalyticsSeriesCondDTO condition of the analytics series aggregation
+type AnalyticsSeriesCondDTO struct {
+	StartTime        int64
+	EndTime          int64
+	BucketFormat     string // format of $dateToString, e.g. %Y-%m-%d
+	Timezone         string // e.g. +08:00
+	ChainId          string
+	ChannelIds       []string // chainA|channelA|chainB|channelB and chainB|channelB|chainA|channelA
+	BaseDenom        string
+	BaseDenomChainId string
+	StatisticIds     []string // statistic ids of the relayer
+	Addresses        []string // addresses of the relayer
+}
+
+type AggrSeriesDTO struct {
+	Bucket           string  `bson:"bucket"`
+	BaseDenom        string  `bson:"base_denom"`
+	BaseDenomChainId string  `bson:"base_denom_chain_id"`
+	Count            int64   `bson:"count"`
+	SuccessCount     int64   `bson:"success_count"`
+	RefundedCount    int64   `bson:"refunded_count"`
+	Amount           float64 `bson:"amount"`
+}
+
+type AggrChainFlowDTO struct {
+	ScChainId        string  `bson:"sc_chain_id"`
+	DcChainId        string  `bson:"dc_chain_id"`
+	BaseDenom        string  `bson:"base_denom"`
+	BaseDenomChainId string  `bson:"base_denom_chain_id"`
+	Count            int64   `bson:"count"`
+	Amount           float64 `bson:"amount"`
+}
+
+type AggrFailureCategoryDTO struct {
+	ScChainId       string `bson:"sc_chain_id"`
+	ScChannel       string `bson:"sc_channel"`
+	DcChainId       string `bson:"dc_chain_id"`
+	DcChannel       string `bson:"dc_channel"`
+	FailureCategory string `bson:"failure_category"`
+	Count           int64  `bson:"count"`
+}
+
+type IbcIcaTxQuery struct {
+	StartTime  int64
+	EndTime    int64
+	ChainId    string
+	Owner      string
+	IcaAddress string
+	Status     []int
+	MsgType    string
+}
+
+type IcaAccountQuery struct {
+	ChainId    string
+	Owner      string
+	IcaAddress string
+}
+
+type AggrIcaActivityDTO struct {
+	HostChainId  string `bson:"host_chain_id"`
+	IcaAddress   string `bson:"ica_address"`
+	Txs          int64  `bson:"txs"`
+	SuccessTxs   int64  `bson:"success_txs"`
+	FailedTxs    int64  `bson:"failed_txs"`
+	TimeoutTxs   int64  `bson:"timeout_txs"`
+	PendingTxs   int64  `bson:"pending_txs"`
+	LatestTxTime int64  `bson:"latest_tx_time"`
+}
+
+type AggrChannelTypeDTO struct {
+	ChannelType string `bson:"channel_type"`
+	Status      int    `bson:"status"`
+	Count       int64  `bson:"count"`
+}
+
+type IbcPacketQuery struct {
+	StartTime int64
+	EndTime   int64
+	ChainId   string
+	Port      string
+	Channel   string
+	Status    []int
+	AckResult string
+}
+
+type EscrowReconcileQuery struct {
+	ChainId   string
+	ChannelId string
+	Denom     string
+	Status    []int
+	Alerting  *bool
+}
+
+type EscrowDiscrepancyQuery struct {
+	StartTime int64
+	EndTime   int64
+	ChainId   string
+	ChannelId string
+	Denom     string
+}
+
+type LargeTransferAlertQuery struct {
+	StartTime int64
+	EndTime   int64
+	ChainId   string
+	BaseDenom string
+	Reason    string
+}
+
+type ChainRegistryDiffQuery struct {
+	ChainId string
+	Coll    string
+	Status  string
+}
+
+type ArchivePartitionDTO struct {
+	ChainId string `bson:"chain_id"`
+	Month   string `bson:"month"`
 }
@@ -6,6 +6,16 @@ const (
 	ApiBalancesPathPlaceholder  = "{address}"
 	ParamsModulePathPlaceholder = "{module}"
 	StakeModule                 = "staking"
+	// the placeholders of the client state path
+	ApiChannelPathPlaceholder = "CHANNEL"
+	ApiPortPathPlaceholder    = "PORT"
+)
+
+// the data sources of the chain data, see pkg/datasource
+const (
+	DataSourceLcd  = "lcd"
+	DataSourceRpc  = "rpc"
+	DataSourceGrpc = "grpc"
 )
 
 type ChainStatus int
@@ -26,6 +36,9 @@ type (
 		IbcInfo        []*IbcInfo  `bson:"ibc_info"`
 		IbcInfoHashLcd string      `bson:"ibc_info_hash_lcd"`
 		Status         ChainStatus `bson:"status"`
+		DataSource     string      `bson:"data_source,omitempty"` // lcd(default), rpc or grpc
+		Rpc            string      `bson:"rpc,omitempty"`
+		Grpc           string      `bson:"grpc,omitempty"`
 	}
 	ApiPath struct {
 		ChannelsPath    string `bson:"channels_path"`
@@ -1,8 +1,10 @@
 package entity
 
 type ChainRegistry struct {
-	ChainId      string `bson:"chain_id"`
-	ChainJsonUrl string `bson:"chain_json_url"`
+	ChainId      string   `bson:"chain_id"`
+	ChainJsonUrl string   `bson:"chain_json_url"`
+	Lcds         []string `bson:"lcds,omitempty"` // synced from the apis.rest of the chain.json
+	Rpcs         []string `bson:"rpcs,omitempty"` // synced from the apis.rpc of the chain.json
 }
 
 func (c ChainRegistry) CollectionName() string {
@@ -0,0 +1,49 @@
+package entity
+
+const (
+	CollectionNameExIbcIcaTx = "ex_ibc_ica_tx"
+	IcaHostTaskNameFmt       = "sync_%s_ica_host"
+	IcaControllerTaskNameFmt = "sync_%s_ica_controller"
+	IcaSendTaskNameFmt       = "sync_%s_ica_send"
+	IcaAccountTaskNameFmt    = "sync_%s_ica_account"
+)
+
+type IcaTxStatus int
+
+const (
+	IcaTxStatusSuccess IcaTxStatus = 1 // executed on the host chain
+	IcaTxStatusFailed  IcaTxStatus = 2 // failed to execute on the host chain, the error is returned in the ack
+	IcaTxStatusTimeout IcaTxStatus = 3
+	IcaTxStatusPending IcaTxStatus = 4 // sent by the controller chain, not received, acked or timed out yet
+)
+
+// ExIbcIcaTx the ics-27 packet sent by MsgSendTx of the controller chain, and its execution result on the host chain
+type ExIbcIcaTx struct {
+	RecordId          string      `bson:"record_id"`
+	PacketId          string      `bson:"packet_id"`
+	Sequence          string      `bson:"sequence"`
+	ControllerChainId string      `bson:"controller_chain_id"`
+	ControllerPort    string      `bson:"controller_port"`
+	ControllerChannel string      `bson:"controller_channel"`
+	Owner             string      `bson:"owner"`
+	HostChainId       string      `bson:"host_chain_id"`
+	HostPort          string      `bson:"host_port"`
+	HostChannel       string      `bson:"host_channel"`
+	IcaAddress        string      `bson:"ica_address"`
+	Status            IcaTxStatus `bson:"status"`
+	PacketType        string      `bson:"packet_type"`
+	MsgTypes          []string    `bson:"msg_types"`
+	Memo              string      `bson:"memo"`
+	ErrorLog          string      `bson:"error_log"`
+	SendTxInfo        *TxInfo     `bson:"send_tx_info"`
+	HostTxInfo        *TxInfo     `bson:"host_tx_info"`
+	AckTxInfo         *TxInfo     `bson:"ack_tx_info"`
+	TimeoutTxInfo     *TxInfo     `bson:"timeout_tx_info"`
+	TxTime            int64       `bson:"tx_time"`
+	CreateAt          int64       `bson:"create_at"`
+	UpdateAt          int64       `bson:"update_at"`
+}
+
+func (i ExIbcIcaTx) CollectionName() string {
+	return CollectionNameExIbcIcaTx
+}
@@ -0,0 +1,46 @@
+package entity
+
+const (
+	CollectionNameExIbcNftTx = "ex_ibc_nft_tx"
+	NftTaskNameFmt           = "sync_%s_nft_transfer"
+)
+
+type (
+	// ExIbcNftTx ics-721 nft transfer
+	ExIbcNftTx struct {
+		RecordId         string      `bson:"record_id"`
+		TxTime           int64       `bson:"tx_time"`
+		ScAddr           string      `bson:"sc_addr"`
+		DcAddr           string      `bson:"dc_addr"`
+		ScPort           string      `bson:"sc_port"`
+		ScChannel        string      `bson:"sc_channel"`
+		ScConnectionId   string      `bson:"sc_connection_id"`
+		ScChainId        string      `bson:"sc_chain_id"`
+		DcPort           string      `bson:"dc_port"`
+		DcChannel        string      `bson:"dc_channel"`
+		DcConnectionId   string      `bson:"dc_connection_id"`
+		DcChainId        string      `bson:"dc_chain_id"`
+		Sequence         string      `bson:"sequence"`
+		Status           IbcTxStatus `bson:"status"`
+		ScTxInfo         *TxInfo     `bson:"sc_tx_info"`
+		DcTxInfo         *TxInfo     `bson:"dc_tx_info"`
+		RefundedTxInfo   *TxInfo     `bson:"refunded_tx_info"`
+		ClassIds         *ClassIds   `bson:"class_ids"`
+		TokenIds         []string    `bson:"token_ids"`
+		BaseClassId      string      `bson:"base_class_id"`
+		BaseClassChainId string      `bson:"base_class_chain_id"`
+		ProcessInfo      string      `bson:"process_info"`
+		RetryTimes       int64       `bson:"retry_times"`
+		NextTryTime      int64       `bson:"next_try_time"`
+		CreateAt         int64       `bson:"create_at"`
+		UpdateAt         int64       `bson:"update_at"`
+	}
+	ClassIds struct {
+		ScClassId string `bson:"sc_class_id"`
+		DcClassId string `bson:"dc_class_id"`
+	}
+)
+
+func (i ExIbcNftTx) CollectionName() string {
+	return CollectionNameExIbcNftTx
+}
@@ -0,0 +1,53 @@
+package entity
+
+const (
+	CollectionNameExIbcPacket = "ex_ibc_packet"
+	PacketSendTaskNameFmt     = "sync_%s_packet_send"
+	PacketRelayTaskNameFmt    = "sync_%s_packet_relay"
+)
+
+// PacketStatus the status only moves forward, the packet may be synced from the chains in any order
+type PacketStatus int
+
+const (
+	PacketStatusSent         PacketStatus = 1
+	PacketStatusReceived     PacketStatus = 2
+	PacketStatusAcknowledged PacketStatus = 3
+	PacketStatusTimeout      PacketStatus = 4
+)
+
+const (
+	PacketAckResultSuccess = "success"
+	PacketAckResultError   = "error"
+)
+
+// ExIbcPacket the packet of any ibc application, keyed by (sc_chain_id, sc_port, sc_channel, sequence)
+type ExIbcPacket struct {
+	RecordId         string       `bson:"record_id"`
+	PacketId         string       `bson:"packet_id"`
+	ScChainId        string       `bson:"sc_chain_id"`
+	ScPort           string       `bson:"sc_port"`
+	ScChannel        string       `bson:"sc_channel"`
+	DcChainId        string       `bson:"dc_chain_id"`
+	DcPort           string       `bson:"dc_port"`
+	DcChannel        string       `bson:"dc_channel"`
+	Sequence         string       `bson:"sequence"`
+	Status           PacketStatus `bson:"status"`
+	Data             string       `bson:"data"`
+	DataHex          string       `bson:"data_hex"`
+	TimeoutHeight    string       `bson:"timeout_height"`
+	TimeoutTimestamp string       `bson:"timeout_timestamp"`
+	Ack              string       `bson:"ack"`
+	AckResult        string       `bson:"ack_result"`
+	SendTxInfo       *TxInfo      `bson:"send_tx_info"`
+	RecvTxInfo       *TxInfo      `bson:"recv_tx_info"`
+	AckTxInfo        *TxInfo      `bson:"ack_tx_info"`
+	TimeoutTxInfo    *TxInfo      `bson:"timeout_tx_info"`
+	TxTime           int64        `bson:"tx_time"`
+	CreateAt         int64        `bson:"create_at"`
+	UpdateAt         int64        `bson:"update_at"`
+}
+
+func (i ExIbcPacket) CollectionName() string {
+	return CollectionNameExIbcPacket
+}
@@ -12,6 +12,9 @@ const (
 	IbcTxStatusProcessing IbcTxStatus = 3
 	IbcTxStatusRefunded   IbcTxStatus = 4
 	IbcTxStatusSetting    IbcTxStatus = 5
+	// IbcTxStatusReindexing the tx sent from the chain being re-indexed, it's replaced or removed when the transfer
+	// txs of the chain are parsed again
+	IbcTxStatusReindexing IbcTxStatus = 6
 )
 
 var IbcTxUsefulStatus = []IbcTxStatus{IbcTxStatusSuccess, IbcTxStatusFailed, IbcTxStatusProcessing, IbcTxStatusRefunded}
@@ -43,14 +46,18 @@ type (
 		DcTxInfo       *TxInfo     `bson:"dc_tx_info"`
 		RefundedTxInfo *TxInfo     `bson:"refunded_tx_info"`
 		//Log              *Log        `bson:"log"`
-		Denoms           *Denoms `bson:"denoms"`
-		BaseDenom        string  `bson:"base_denom"`
-		BaseDenomChainId string  `bson:"base_denom_chain_id"`
-		ProcessInfo      string  `bson:"process_info"`
-		RetryTimes       int64   `bson:"retry_times"`
-		NextTryTime      int64   `bson:"next_try_time"`
-		CreateAt         int64   `bson:"create_at"`
-		UpdateAt         int64   `bson:"update_at"`
+		Denoms           *Denoms           `bson:"denoms"`
+		BaseDenom        string            `bson:"base_denom"`
+		BaseDenomChainId string            `bson:"base_denom_chain_id"`
+		PacketMemo       *model.PacketMemo `bson:"packet_memo,omitempty"`
+		FailureCategory  string            `bson:"failure_category,omitempty"`
+		FailureReason    string            `bson:"failure_reason,omitempty"`
+		ProcessInfo      string            `bson:"process_info"`
+		RetryTimes       int64             `bson:"retry_times"`
+		NextTryTime      int64             `bson:"next_try_time"`
+		ReindexJobId     string            `bson:"reindex_job_id,omitempty"`
+		CreateAt         int64             `bson:"create_at"`
+		UpdateAt         int64             `bson:"update_at"`
 	}
 	Log struct {
 		ScLog string `bson:"sc_log"`
@@ -0,0 +1,27 @@
+package entity
+
+const (
+	IBCChainFlowStatisticsCollName    = "ibc_chain_flow_statistics"
+	IBCChainFlowStatisticsNewCollName = "ibc_chain_flow_statistics_new"
+)
+
+// IBCChainFlowStatistics the successful transfers from sc chain to dc chain in the segment
+type IBCChainFlowStatistics struct {
+	ScChainId        string `bson:"sc_chain_id"`
+	DcChainId        string `bson:"dc_chain_id"`
+	BaseDenom        string `bson:"base_denom"`
+	BaseDenomChainId string `bson:"base_denom_chain_id"`
+	TransferTxs      int64  `bson:"transfer_txs"`
+	TransferAmount   string `bson:"transfer_amount"`
+	SegmentStartTime int64  `bson:"segment_start_time"`
+	SegmentEndTime   int64  `bson:"segment_end_time"`
+	CreateAt         int64  `bson:"create_at"`
+	UpdateAt         int64  `bson:"update_at"`
+}
+
+func (i IBCChainFlowStatistics) CollectionName(isNew bool) string {
+	if isNew {
+		return IBCChainFlowStatisticsNewCollName
+	}
+	return IBCChainFlowStatisticsCollName
+}
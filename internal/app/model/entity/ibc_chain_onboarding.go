@@ -0,0 +1,103 @@
+package entity
+
+import "time"
+
+const CollectionNameIBCChainOnboarding = "ibc_chain_onboarding"
+
+type ChainOnboardingStatus string
+
+const (
+	ChainOnboardingStatusPending ChainOnboardingStatus = "pending"
+	ChainOnboardingStatusRunning ChainOnboardingStatus = "running"
+	ChainOnboardingStatusSuccess ChainOnboardingStatus = "success"
+	ChainOnboardingStatusFailed  ChainOnboardingStatus = "failed"
+)
+
+const (
+	ChainOnboardingStepValidate        = "validate"
+	ChainOnboardingStepSaveChainConfig = "save_chain_config"
+	ChainOnboardingStepSaveRegistry    = "save_chain_registry"
+	ChainOnboardingStepSyncIbcInfo     = "sync_ibc_info"
+	ChainOnboardingStepAddChain        = "add_chain"
+	ChainOnboardingStepAddTransferData = "add_transfer_data"
+)
+
+// ChainOnboardingStaleTime the running onboarding not updated for the seconds is stale, its process is considered exited.
+// The update_at of a running onboarding is refreshed every minute.
+const ChainOnboardingStaleTime = 600
+
+// ChainOnboardingSteps the steps of onboarding a chain in order
+var ChainOnboardingSteps = []string{ChainOnboardingStepValidate, ChainOnboardingStepSaveChainConfig, ChainOnboardingStepSaveRegistry,
+	ChainOnboardingStepSyncIbcInfo, ChainOnboardingStepAddChain, ChainOnboardingStepAddTransferData}
+
+// IBCChainOnboarding the progress of onboarding the chain by the admin api
+type IBCChainOnboarding struct {
+	ChainId      string                 `bson:"chain_id"`
+	ChainJsonUrl string                 `bson:"chain_json_url"`
+	Status       ChainOnboardingStatus  `bson:"status"`
+	Steps        []*ChainOnboardingStep `bson:"steps"`
+	CreateAt     int64                  `bson:"create_at"`
+	UpdateAt     int64                  `bson:"update_at"`
+}
+
+type ChainOnboardingStep struct {
+	Name      string                `bson:"name"`
+	Status    ChainOnboardingStatus `bson:"status"`
+	Message   string                `bson:"message"`
+	StartTime int64                 `bson:"start_time"`
+	EndTime   int64                 `bson:"end_time"`
+}
+
+func (i IBCChainOnboarding) CollectionName() string {
+	return CollectionNameIBCChainOnboarding
+}
+
+func NewChainOnboarding(chainId, chainJsonUrl string) *IBCChainOnboarding {
+	steps := make([]*ChainOnboardingStep, 0, len(ChainOnboardingSteps))
+	for _, v := range ChainOnboardingSteps {
+		steps = append(steps, &ChainOnboardingStep{Name: v, Status: ChainOnboardingStatusPending})
+	}
+	return &IBCChainOnboarding{
+		ChainId:      chainId,
+		ChainJsonUrl: chainJsonUrl,
+		Status:       ChainOnboardingStatusRunning,
+		Steps:        steps,
+	}
+}
+
+func (i *IBCChainOnboarding) IsStale(now int64) bool {
+	return i.Status == ChainOnboardingStatusRunning && i.UpdateAt < now-ChainOnboardingStaleTime
+}
+
+func (i *IBCChainOnboarding) step(name string) *ChainOnboardingStep {
+	for _, v := range i.Steps {
+		if v.Name == name {
+			return v
+		}
+	}
+	step := &ChainOnboardingStep{Name: name}
+	i.Steps = append(i.Steps, step)
+	return step
+}
+
+func (i *IBCChainOnboarding) StartStep(name string) {
+	step := i.step(name)
+	step.Status = ChainOnboardingStatusRunning
+	step.Message = ""
+	step.StartTime = time.Now().Unix()
+	step.EndTime = 0
+}
+
+// FinishStep finish the step, the onboarding is failed if the step is failed
+func (i *IBCChainOnboarding) FinishStep(name string, err error, message string) {
+	step := i.step(name)
+	step.EndTime = time.Now().Unix()
+	step.Message = message
+	if err != nil {
+		step.Status = ChainOnboardingStatusFailed
+		step.Message = err.Error()
+		i.Status = ChainOnboardingStatusFailed
+		return
+	}
+	step.Status = ChainOnboardingStatusSuccess
+}
@@ -0,0 +1,32 @@
+package entity
+
+const CollectionNameIBCChainRegistryDiff = "ibc_chain_registry_diff"
+
+type ChainRegistryDiffStatus string
+
+const (
+	ChainRegistryDiffStatusPending  ChainRegistryDiffStatus = "pending"
+	ChainRegistryDiffStatusApplied  ChainRegistryDiffStatus = "applied"
+	ChainRegistryDiffStatusRejected ChainRegistryDiffStatus = "rejected"
+	ChainRegistryDiffStatusOutdated ChainRegistryDiffStatus = "outdated" // the local value has been the same as upstream
+)
+
+// IBCChainRegistryDiff the difference between the local metadata and the chain-registry of a field, each field of the
+// chain(or the base denom) has one diff record
+type IBCChainRegistryDiff struct {
+	DiffId    string                  `bson:"diff_id"`
+	ChainId   string                  `bson:"chain_id"`
+	Coll      string                  `bson:"coll"`  // chain_config, chain_registry, ibc_base_denom
+	Denom     string                  `bson:"denom"` // the base denom if coll is ibc_base_denom
+	Field     string                  `bson:"field"`
+	OldValue  interface{}             `bson:"old_value"`
+	NewValue  interface{}             `bson:"new_value"`
+	Status    ChainRegistryDiffStatus `bson:"status"`
+	ApplyTime int64                   `bson:"apply_time"`
+	CreateAt  int64                   `bson:"create_at"`
+	UpdateAt  int64                   `bson:"update_at"`
+}
+
+func (i IBCChainRegistryDiff) CollectionName() string {
+	return CollectionNameIBCChainRegistryDiff
+}
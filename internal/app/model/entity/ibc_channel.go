@@ -1,5 +1,10 @@
 package entity
 
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+)
+
 type ChannelStatus int
 
 const (
@@ -7,6 +12,14 @@ const (
 	ChannelStatusClosed = 2
 )
 
+// the type of the channel is decided by the ports of the both sides
+const (
+	ChannelTypeTransfer = "transfer"
+	ChannelTypeIca      = "ica"
+	ChannelTypeNft      = "nft"
+	ChannelTypeOther    = "other"
+)
+
 type IBCChannel struct {
 	ChannelId        string        `bson:"channel_id"`
 	ChainA           string        `bson:"chain_a"`
@@ -20,6 +33,7 @@ type IBCChannel struct {
 	Relayers         int           `bson:"relayers"`
 	TransferTxs      int64         `bson:"transfer_txs"`
 	TransferTxsValue string        `bson:"transfer_txs_value"`
+	ChannelType      string        `bson:"channel_type"`
 	CreateAt         int64         `bson:"create_at"`
 	UpdateAt         int64         `bson:"update_at"`
 }
@@ -28,6 +42,19 @@ func (i IBCChannel) CollectionName() string {
 	return "ibc_channel"
 }
 
+func GetChannelType(portA, portB string) string {
+	switch {
+	case portA == constant.PortTransfer && portB == constant.PortTransfer:
+		return ChannelTypeTransfer
+	case model.IsIcaPort(portA) || model.IsIcaPort(portB):
+		return ChannelTypeIca
+	case portA == constant.PortNftTransfer || portB == constant.PortNftTransfer:
+		return ChannelTypeNft
+	default:
+		return ChannelTypeOther
+	}
+}
+
 type IBCChannelList []*IBCChannel
 
 func (l IBCChannelList) ConvertToMap() map[string]*IBCChannel {
@@ -11,6 +11,8 @@ type IBCChannelStatistics struct {
 	BaseDenomChainId string `bson:"base_denom_chain_id"`
 	TransferTxs      int64  `bson:"transfer_txs"`
 	TransferAmount   string `bson:"transfer_amount"`
+	SuccessTxs       int64  `bson:"success_txs"`
+	RefundedTxs      int64  `bson:"refunded_txs"`
 	SegmentStartTime int64  `bson:"segment_start_time"`
 	SegmentEndTime   int64  `bson:"segment_end_time"`
 	CreateAt         int64  `bson:"create_at"`
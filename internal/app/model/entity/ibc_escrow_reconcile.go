@@ -0,0 +1,60 @@
+package entity
+
+const (
+	CollectionNameIBCEscrowReconcile   = "ibc_escrow_reconcile"
+	CollectionNameIBCEscrowDiscrepancy = "ibc_escrow_discrepancy"
+)
+
+type EscrowReconcileStatus int
+
+const (
+	EscrowReconcileStatusMatched    EscrowReconcileStatus = 1
+	EscrowReconcileStatusMismatched EscrowReconcileStatus = 2
+)
+
+// IBCEscrowReconcile the latest reconciliation of the denom escrowed in the channel, the escrowed amount on the chain
+// must be equal to the supply of the voucher on the counterparty chain
+type IBCEscrowReconcile struct {
+	ChainId               string                `bson:"chain_id"`
+	PortId                string                `bson:"port_id"`
+	ChannelId             string                `bson:"channel_id"`
+	EscrowAddress         string                `bson:"escrow_address"`
+	Denom                 string                `bson:"denom"`
+	DenomFullPath         string                `bson:"denom_full_path"`
+	CounterpartyChainId   string                `bson:"counterparty_chain_id"`
+	CounterpartyChannelId string                `bson:"counterparty_channel_id"`
+	VoucherDenom          string                `bson:"voucher_denom"`
+	EscrowAmount          string                `bson:"escrow_amount"`
+	VoucherSupply         string                `bson:"voucher_supply"`
+	Diff                  string                `bson:"diff"` // escrow_amount - voucher_supply
+	Status                EscrowReconcileStatus `bson:"status"`
+	MismatchTimes         int64                 `bson:"mismatch_times"` // 连续不一致的次数
+	FirstMismatchTime     int64                 `bson:"first_mismatch_time"`
+	Alerting              bool                  `bson:"alerting"`
+	CheckTime             int64                 `bson:"check_time"`
+	CreateAt              int64                 `bson:"create_at"`
+	UpdateAt              int64                 `bson:"update_at"`
+}
+
+func (i IBCEscrowReconcile) CollectionName() string {
+	return CollectionNameIBCEscrowReconcile
+}
+
+// IBCEscrowDiscrepancy the mismatched reconciliation of each check, the divergence over time is kept
+type IBCEscrowDiscrepancy struct {
+	ChainId             string `bson:"chain_id"`
+	ChannelId           string `bson:"channel_id"`
+	Denom               string `bson:"denom"`
+	CounterpartyChainId string `bson:"counterparty_chain_id"`
+	VoucherDenom        string `bson:"voucher_denom"`
+	EscrowAmount        string `bson:"escrow_amount"`
+	VoucherSupply       string `bson:"voucher_supply"`
+	Diff                string `bson:"diff"`
+	MismatchTimes       int64  `bson:"mismatch_times"`
+	CheckTime           int64  `bson:"check_time"`
+	CreateAt            int64  `bson:"create_at"`
+}
+
+func (i IBCEscrowDiscrepancy) CollectionName() string {
+	return CollectionNameIBCEscrowDiscrepancy
+}
@@ -0,0 +1,23 @@
+package entity
+
+// IBCIcaAccount the ics-27 interchain account, registered by the owner on the controller chain and derived on the host chain
+type IBCIcaAccount struct {
+	ControllerChainId      string `bson:"controller_chain_id"`
+	ControllerPort         string `bson:"controller_port"`
+	ControllerChannel      string `bson:"controller_channel"`
+	ControllerConnectionId string `bson:"controller_connection_id"`
+	Owner                  string `bson:"owner"`
+	HostChainId            string `bson:"host_chain_id"`
+	HostPort               string `bson:"host_port"`
+	HostChannel            string `bson:"host_channel"`
+	HostConnectionId       string `bson:"host_connection_id"`
+	IcaAddress             string `bson:"ica_address"`
+	RegisterTxHash         string `bson:"register_tx_hash"`
+	RegisterTime           int64  `bson:"register_time"`
+	CreateAt               int64  `bson:"create_at"`
+	UpdateAt               int64  `bson:"update_at"`
+}
+
+func (i IBCIcaAccount) CollectionName() string {
+	return "ibc_ica_account"
+}
@@ -0,0 +1,71 @@
+package entity
+
+import "math"
+
+const (
+	CollectionNameIBCLargeTransferAlert    = "ibc_large_transfer_alert"
+	CollectionNameIBCLargeTransferBaseline = "ibc_large_transfer_baseline"
+
+	LargeTransferTaskName = "sync_large_transfer"
+)
+
+const (
+	LargeTransferReasonUsdThreshold = "usd_threshold"
+	LargeTransferReasonStatistical  = "statistical"
+)
+
+// IBCLargeTransferAlert the transfer whose value exceeds the usd threshold or whose amount deviates from the baseline of the denom
+type IBCLargeTransferAlert struct {
+	RecordId         string      `bson:"record_id"`
+	ScChainId        string      `bson:"sc_chain_id"`
+	ScChannel        string      `bson:"sc_channel"`
+	ScAddr           string      `bson:"sc_addr"`
+	DcChainId        string      `bson:"dc_chain_id"`
+	DcChannel        string      `bson:"dc_channel"`
+	DcAddr           string      `bson:"dc_addr"`
+	ScTxHash         string      `bson:"sc_tx_hash"`
+	Denom            string      `bson:"denom"`
+	BaseDenom        string      `bson:"base_denom"`
+	BaseDenomChainId string      `bson:"base_denom_chain_id"`
+	Amount           string      `bson:"amount"`
+	Value            string      `bson:"value"` // usd, empty if the price is unknown
+	Reasons          []string    `bson:"reasons"`
+	BaselineMean     float64     `bson:"baseline_mean"`
+	BaselineStddev   float64     `bson:"baseline_stddev"`
+	Status           IbcTxStatus `bson:"status"`
+	TxTime           int64       `bson:"tx_time"`
+	CreateAt         int64       `bson:"create_at"`
+}
+
+func (i IBCLargeTransferAlert) CollectionName() string {
+	return CollectionNameIBCLargeTransferAlert
+}
+
+// IBCLargeTransferBaseline the running mean and variance(welford) of the transfer amount of the base denom
+type IBCLargeTransferBaseline struct {
+	BaseDenom        string  `bson:"base_denom"`
+	BaseDenomChainId string  `bson:"base_denom_chain_id"`
+	Count            int64   `bson:"count"`
+	Mean             float64 `bson:"mean"`
+	M2               float64 `bson:"m2"` // sum of squares of differences from the mean
+	CreateAt         int64   `bson:"create_at"`
+	UpdateAt         int64   `bson:"update_at"`
+}
+
+func (i IBCLargeTransferBaseline) CollectionName() string {
+	return CollectionNameIBCLargeTransferBaseline
+}
+
+func (i *IBCLargeTransferBaseline) Add(amount float64) {
+	i.Count++
+	delta := amount - i.Mean
+	i.Mean += delta / float64(i.Count)
+	i.M2 += delta * (amount - i.Mean)
+}
+
+func (i IBCLargeTransferBaseline) Stddev() float64 {
+	if i.Count < 2 {
+		return 0
+	}
+	return math.Sqrt(i.M2 / float64(i.Count-1))
+}
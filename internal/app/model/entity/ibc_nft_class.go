@@ -0,0 +1,22 @@
+package entity
+
+// IBCNftClass the ics-721 nft class on the chain, traced like IBCDenom
+type IBCNftClass struct {
+	ChainId          string `bson:"chain_id"`
+	ClassId          string `bson:"class_id"`
+	PrevClassId      string `bson:"prev_class_id"`
+	PrevChainId      string `bson:"prev_chain_id"`
+	BaseClassId      string `bson:"base_class_id"`
+	BaseClassChainId string `bson:"base_class_chain_id"`
+	ClassPath        string `bson:"class_path"`
+	RootClassId      string `bson:"root_class_id"`
+	IsBaseClass      bool   `bson:"is_base_class"`
+	CreateAt         int64  `bson:"create_at"`
+	UpdateAt         int64  `bson:"update_at"`
+}
+
+func (i IBCNftClass) CollectionName() string {
+	return "ibc_nft_class"
+}
+
+type IBCNftClassList []*IBCNftClass
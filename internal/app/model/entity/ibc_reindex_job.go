@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+const CollectionNameIBCReindexJob = "ibc_reindex_job"
+
+type ReindexJobStatus string
+
+const (
+	ReindexJobStatusPending ReindexJobStatus = "pending"
+	ReindexJobStatusRunning ReindexJobStatus = "running"
+	ReindexJobStatusSuccess ReindexJobStatus = "success"
+	ReindexJobStatusFailed  ReindexJobStatus = "failed"
+)
+
+const (
+	ReindexStepResolveRange = "resolve_range"
+	ReindexStepResetTxs     = "reset_txs"
+	ReindexStepReplayTxs    = "replay_txs"
+	ReindexStepRelateTxs    = "relate_txs"
+	ReindexStepStatistics   = "statistics"
+)
+
+// ReindexJobStaleTime the active job not updated for the seconds is stale, its process is considered exited. The update_at
+// of a running job is refreshed every minute.
+const ReindexJobStaleTime = 600
+
+// ReindexSteps the steps of re-indexing a chain in order
+var ReindexSteps = []string{ReindexStepResolveRange, ReindexStepResetTxs, ReindexStepReplayTxs, ReindexStepRelateTxs, ReindexStepStatistics}
+
+// IBCReindexJob the progress of re-indexing the ibc txs of the chain between the heights or the times
+type IBCReindexJob struct {
+	JobId       string            `bson:"job_id"`
+	ChainId     string            `bson:"chain_id"`
+	StartHeight int64             `bson:"start_height"`
+	EndHeight   int64             `bson:"end_height"`
+	StartTime   int64             `bson:"start_time"`
+	EndTime     int64             `bson:"end_time"`
+	Status      ReindexJobStatus  `bson:"status"`
+	Active      bool              `bson:"active"` // pending or running, at most one active job of a chain
+	Steps       []*ReindexJobStep `bson:"steps"`
+	MinCreateAt int64             `bson:"min_create_at"` // the earliest create_at of the affected ibc txs
+	CreateAt    int64             `bson:"create_at"`
+	UpdateAt    int64             `bson:"update_at"`
+}
+
+type ReindexJobStep struct {
+	Name      string           `bson:"name"`
+	Status    ReindexJobStatus `bson:"status"`
+	Message   string           `bson:"message"`
+	Done      int64            `bson:"done"`
+	Total     int64            `bson:"total"`
+	StartTime int64            `bson:"start_time"`
+	EndTime   int64            `bson:"end_time"`
+}
+
+func (i IBCReindexJob) CollectionName() string {
+	return CollectionNameIBCReindexJob
+}
+
+// NewReindexJob the range is either the heights or the unix times, the times are resolved to the heights by the blocks
+// of the chain when the job runs
+func NewReindexJob(chainId string, startHeight, endHeight, startTime, endTime int64) (*IBCReindexJob, error) {
+	if chainId == "" {
+		return nil, fmt.Errorf("chain_id is required")
+	}
+	byHeight := startHeight > 0 || endHeight > 0
+	byTime := startTime > 0 || endTime > 0
+	switch {
+	case byHeight && byTime:
+		return nil, fmt.Errorf("either the heights or the times can be set")
+	case byHeight && (startHeight <= 0 || endHeight < startHeight):
+		return nil, fmt.Errorf("invalid height range %d-%d", startHeight, endHeight)
+	case byTime && (startTime <= 0 || endTime < startTime):
+		return nil, fmt.Errorf("invalid time range %d-%d", startTime, endTime)
+	case !byHeight && !byTime:
+		return nil, fmt.Errorf("the heights or the times are required")
+	}
+
+	steps := make([]*ReindexJobStep, 0, len(ReindexSteps))
+	for _, v := range ReindexSteps {
+		steps = append(steps, &ReindexJobStep{Name: v, Status: ReindexJobStatusPending})
+	}
+	return &IBCReindexJob{
+		JobId:       fmt.Sprintf("%s_%d", chainId, time.Now().UnixNano()),
+		ChainId:     chainId,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Status:      ReindexJobStatusPending,
+		Active:      true,
+		Steps:       steps,
+	}, nil
+}
+
+func (i *IBCReindexJob) Step(name string) *ReindexJobStep {
+	for _, v := range i.Steps {
+		if v.Name == name {
+			return v
+		}
+	}
+	step := &ReindexJobStep{Name: name}
+	i.Steps = append(i.Steps, step)
+	return step
+}
+
+func (i *IBCReindexJob) StartStep(name string) {
+	step := i.Step(name)
+	step.Status = ReindexJobStatusRunning
+	step.Message = ""
+	step.Done, step.Total = 0, 0
+	step.StartTime = time.Now().Unix()
+	step.EndTime = 0
+}
+
+// FinishStep finish the step, the job is failed if the step is failed
+func (i *IBCReindexJob) FinishStep(name string, err error, message string) {
+	step := i.Step(name)
+	step.EndTime = time.Now().Unix()
+	step.Message = message
+	if err != nil {
+		step.Status = ReindexJobStatusFailed
+		step.Message = err.Error()
+		i.Status = ReindexJobStatusFailed
+		i.Active = false
+		return
+	}
+	step.Status = ReindexJobStatusSuccess
+}
+
+func (i *IBCReindexJob) IsStale(now int64) bool {
+	return i.Active && i.UpdateAt < now-ReindexJobStaleTime
+}
+
+// Interrupt fail the job whose process exited while it was pending or running
+func (i *IBCReindexJob) Interrupt() {
+	for _, v := range i.Steps {
+		if v.Status == ReindexJobStatusRunning {
+			i.FinishStep(v.Name, fmt.Errorf("interrupted"), "")
+			return
+		}
+	}
+	i.Status = ReindexJobStatusFailed
+	i.Active = false
+}
+
+// AffectCreateAt keep the earliest create_at of the affected ibc txs, the statistics are recalculated from its day
+func (i *IBCReindexJob) AffectCreateAt(createAt int64) {
+	if i.MinCreateAt == 0 || createAt < i.MinCreateAt {
+		i.MinCreateAt = createAt
+	}
+}
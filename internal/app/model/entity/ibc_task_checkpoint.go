@@ -0,0 +1,27 @@
+package entity
+
+type TaskCheckpointStatus string
+
+const (
+	TaskCheckpointStatusRunning  TaskCheckpointStatus = "running"
+	TaskCheckpointStatusFinished TaskCheckpointStatus = "finished"
+)
+
+// IbcTaskCheckpoint one-off task worker progress, used to resume the task after restart
+type IbcTaskCheckpoint struct {
+	TaskName  string               `bson:"task_name"`
+	Scope     string               `bson:"scope"` // latest, history or chain id
+	Worker    string               `bson:"worker"`
+	WorkerNum int                  `bson:"worker_num"`
+	Begin     int64                `bson:"begin"` // first segment start time or start height of the workload
+	Total     int64                `bson:"total"`
+	Done      int64                `bson:"done"`
+	Cursor    int64                `bson:"cursor"` // last processed segment start time or height
+	Status    TaskCheckpointStatus `bson:"status"`
+	CreateAt  int64                `bson:"create_at"`
+	UpdateAt  int64                `bson:"update_at"`
+}
+
+func (t IbcTaskCheckpoint) CollectionName() string {
+	return "ibc_task_checkpoint"
+}
@@ -0,0 +1,19 @@
+package entity
+
+// DryRunFieldInsert the field name of a record which will be inserted
+const DryRunFieldInsert = "*"
+
+// IbcTaskDryRunReport intended update of a one-off task in dry-run mode
+type IbcTaskDryRunReport struct {
+	TaskName string      `bson:"task_name"`
+	Coll     string      `bson:"coll"`
+	RecordId string      `bson:"record_id"`
+	Field    string      `bson:"field"`
+	OldValue interface{} `bson:"old_value"`
+	NewValue interface{} `bson:"new_value"`
+	CreateAt int64       `bson:"create_at"`
+}
+
+func (t IbcTaskDryRunReport) CollectionName() string {
+	return "ibc_task_dry_run_report"
+}
@@ -13,8 +13,10 @@ type IbcTaskRecord struct {
 	TaskName string           `bson:"task_name"`
 	Height   int64            `bson:"height"`
 	Status   TaskRecordStatus `bson:"status"`
-	CreateAt int64            `bson:"create_at"`
-	UpdateAt int64            `bson:"update_at"`
+	// RollbackTime the earliest create time of the rolled back ibc txs, whose statistics are not recalculated yet
+	RollbackTime int64 `bson:"rollback_time"`
+	CreateAt     int64 `bson:"create_at"`
+	UpdateAt     int64 `bson:"update_at"`
 }
 
 func (t IbcTaskRecord) CollectionName() string {
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	CollectionNameIBCTxArchive     = "ibc_tx_archive"
+	CollectionNameIBCTxArchiveHash = "ibc_tx_archive_hash"
+
+	// ArchiveMonthFormat the month of a partition, in UTC
+	ArchiveMonthFormat = "2006-01"
+)
+
+type ArchiveStatus string
+
+const (
+	// ArchiveStatusPending the file is being archived, its txs may be still in mongo, see IbcTxArchiveTask
+	ArchiveStatusPending   ArchiveStatus = "pending"
+	ArchiveStatusArchived  ArchiveStatus = "archived"
+	ArchiveStatusRestoring ArchiveStatus = "restoring"
+	ArchiveStatusRestored  ArchiveStatus = "restored"
+)
+
+// IBCTxArchive a file of the archived ibc txs. The ibc txs of ex_ibc_tx are partitioned by the sc chain and the month
+// of the tx time, a partition has a file for every run of the archive task which found its txs. The statistics of the
+// days until MaxCreateAt are not recalculated from mongo any more.
+type IBCTxArchive struct {
+	Key         string        `bson:"key" json:"key"`
+	ChainId     string        `bson:"chain_id" json:"chain_id"`
+	Month       string        `bson:"month" json:"month"`
+	Txs         int64         `bson:"txs" json:"txs"`
+	MinTxTime   int64         `bson:"min_tx_time" json:"min_tx_time"`
+	MaxTxTime   int64         `bson:"max_tx_time" json:"max_tx_time"`
+	MaxCreateAt int64         `bson:"max_create_at" json:"max_create_at"`
+	Size        int64         `bson:"size" json:"size"`
+	Sha256      string        `bson:"sha256" json:"sha256"`
+	Status      ArchiveStatus `bson:"status" json:"status"`
+	RestoreAt   int64         `bson:"restore_at" json:"restore_at"`
+	CreateAt    int64         `bson:"create_at" json:"create_at"`
+	UpdateAt    int64         `bson:"update_at" json:"update_at"`
+}
+
+func (i IBCTxArchive) CollectionName() string {
+	return CollectionNameIBCTxArchive
+}
+
+// IBCTxArchiveHash the file of an archived ibc tx by its sc, dc or refunded tx hash, the tx is in the gzip member at the
+// offset of the file
+type IBCTxArchiveHash struct {
+	Hash     string `bson:"hash"`
+	RecordId string `bson:"record_id"`
+	ChainId  string `bson:"chain_id"`
+	Month    string `bson:"month"`
+	Key      string `bson:"key"`
+	Offset   int64  `bson:"offset"`
+}
+
+func (i IBCTxArchiveHash) CollectionName() string {
+	return CollectionNameIBCTxArchiveHash
+}
+
+// ArchiveManifest the manifest.json of a partition, it lists the files of the partition, so that the archive can be
+// used without mongo
+type ArchiveManifest struct {
+	ChainId  string          `json:"chain_id"`
+	Month    string          `json:"month"`
+	Format   string          `json:"format"`
+	Files    []*IBCTxArchive `json:"files"`
+	UpdateAt int64           `json:"update_at"`
+}
+
+// ArchivePartitionPath the path of the partition in the object storage
+func ArchivePartitionPath(chainId, month string) string {
+	return fmt.Sprintf("%s/%s/%s", CollectionNameExIbcTx, chainId, month)
+}
+
+// ArchiveMonthRange the first second and the last second of the month
+func ArchiveMonthRange(month string) (int64, int64, error) {
+	start, err := time.ParseInLocation(ArchiveMonthFormat, month, time.UTC)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start.Unix(), start.AddDate(0, 1, 0).Unix() - 1, nil
+}
@@ -0,0 +1,14 @@
+package entity
+
+// IbcVerifiedBlock the hash of a block of sync_<chain>_block when its txs were parsed into ex_ibc_tx_latest, it's
+// compared with the current hash to detect the blocks re-indexed or rolled back by the sync service
+type IbcVerifiedBlock struct {
+	ChainId  string `bson:"chain_id"`
+	Height   int64  `bson:"height"`
+	Hash     string `bson:"hash"`
+	CreateAt int64  `bson:"create_at"`
+}
+
+func (b IbcVerifiedBlock) CollectionName() string {
+	return "ibc_verified_block"
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// the failure categories of the ibc transfers
+const (
+	FailureCategoryInsufficientFunds  = "insufficient_funds"
+	FailureCategoryInvalidReceiver    = "invalid_receiver"
+	FailureCategoryDenomTraceMissing  = "denom_trace_missing"
+	FailureCategoryTimeout            = "timeout"
+	FailureCategoryOutOfGas           = "out_of_gas"
+	FailureCategoryMiddlewareRejected = "middleware_rejected"
+	FailureCategoryUnknown            = "unknown"
+)
+
+var FailureCategories = []string{FailureCategoryInsufficientFunds, FailureCategoryInvalidReceiver, FailureCategoryDenomTraceMissing,
+	FailureCategoryTimeout, FailureCategoryOutOfGas, FailureCategoryMiddlewareRejected, FailureCategoryUnknown}
+
+// failureKeywords the keywords of the error logs, matched in order
+var failureKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{FailureCategoryOutOfGas, []string{"out of gas"}},
+	{FailureCategoryTimeout, []string{"packet timeout", "timeout elapsed", "timed out"}},
+	{FailureCategoryDenomTraceMissing, []string{"denomination trace not found", "denom trace not found", "trace not found",
+		"invalid denomination for cross-chain transfer"}},
+	{FailureCategoryInsufficientFunds, []string{"insufficient funds", "insufficient escrow", "insufficient balance",
+		"spendable balance", "unable to unescrow"}},
+	{FailureCategoryInvalidReceiver, []string{"invalid receiver", "invalid address", "decoding bech32 failed",
+		"is not allowed to receive", "blocked address", "invalid bech32"}},
+	{FailureCategoryMiddlewareRejected, []string{"packet-forward", "forward packet", "ibc-hooks", "ibc hooks",
+		"wasm hook", "wasm-hook", "execute wasm contract", "rate limit", "ratelimit", "middleware", "quota exceeded"}},
+}
+
+// abciCodeRegexp the redacted ack error of ibc-go, eg: ABCI code: 5: error handling packet: see events for details
+var abciCodeRegexp = regexp.MustCompile(`ABCI code: (\d+)`)
+
+// abciCodeCategories the sdk error codes which could be told from the redacted ack error
+var abciCodeCategories = map[string]string{
+	"5":  FailureCategoryInsufficientFunds,
+	"7":  FailureCategoryInvalidReceiver,
+	"11": FailureCategoryOutOfGas,
+}
+
+// ParseFailureCategory categorize the failure by the error logs, the logs are the ack error and the tx log in order.
+// The detailed logs are matched first, the abci code of the redacted ack error is the fallback.
+func ParseFailureCategory(logs ...string) string {
+	for _, v := range failureKeywords {
+		for _, log := range logs {
+			lower := strings.ToLower(log)
+			for _, keyword := range v.keywords {
+				if strings.Contains(lower, keyword) {
+					return v.category
+				}
+			}
+		}
+	}
+
+	for _, log := range logs {
+		if match := abciCodeRegexp.FindStringSubmatch(log); len(match) == 2 {
+			if category, ok := abciCodeCategories[match[1]]; ok {
+				return category
+			}
+		}
+	}
+	return FailureCategoryUnknown
+}
+
+// LogError the error of the tx log. The log of the failed tx is the error itself, the log of the successful tx is
+// the json of the events, the error attributes of the events are returned, e.g. the error of fungible_token_packet.
+func LogError(log string) string {
+	var msgLogs []struct {
+		Events []struct {
+			Type       string `json:"type"`
+			Attributes []struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			} `json:"attributes"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal([]byte(log), &msgLogs); err != nil {
+		return log
+	}
+
+	var errs []string
+	for _, msgLog := range msgLogs {
+		for _, evt := range msgLog.Events {
+			for _, attr := range evt.Attributes {
+				if attr.Key == "error" && attr.Value != "" {
+					errs = append(errs, attr.Value)
+				}
+			}
+		}
+	}
+	return strings.Join(errs, "; ")
+}
@@ -0,0 +1,37 @@
+package model
+
+import "testing"
+
+func TestParseFailureCategory(t *testing.T) {
+	for _, v := range []struct {
+		logs     []string
+		category string
+	}{
+		{[]string{"out of gas in location: WriteFlat; gasWanted: 200000, gasUsed: 200785: out of gas"}, FailureCategoryOutOfGas},
+		{[]string{"ABCI code: 5: error handling packet: see events for details", "unable to unescrow tokens: 10uatom is smaller than 20uatom: insufficient funds"}, FailureCategoryInsufficientFunds},
+		{[]string{"ABCI code: 1: error handling packet: see events for details", "failed to decode receiver address: decoding bech32 failed: invalid checksum"}, FailureCategoryInvalidReceiver},
+		{[]string{"", "denomination trace not found: 27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"}, FailureCategoryDenomTraceMissing},
+		{[]string{"", "failed to forward packet: channel not found"}, FailureCategoryMiddlewareRejected},
+		{[]string{"", "ibc-hooks: error in wasm hook: execute wasm contract failed"}, FailureCategoryMiddlewareRejected},
+		{[]string{"", "forwarder account not found"}, FailureCategoryUnknown},
+		{[]string{"", "contract: account sequence mismatch"}, FailureCategoryUnknown},
+		{[]string{"ABCI code: 5: error handling packet: see events for details"}, FailureCategoryInsufficientFunds},
+		{[]string{"ABCI code: 1: error handling packet: see events for details"}, FailureCategoryUnknown},
+		{nil, FailureCategoryUnknown},
+	} {
+		if category := ParseFailureCategory(v.logs...); category != v.category {
+			t.Fatalf("logs %q, expect %s, got %s", v.logs, v.category, category)
+		}
+	}
+}
+
+func TestLogError(t *testing.T) {
+	log := `[{"msg_index":0,"events":[{"type":"fungible_token_packet","attributes":[{"key":"module","value":"transfer"},` +
+		`{"key":"memo","value":"{\"forward\":{}}"},{"key":"success","value":"false"},{"key":"error","value":"insufficient funds"}]}]}]`
+	if res := LogError(log); res != "insufficient funds" {
+		t.Fatalf("unexpected error: %s", res)
+	}
+	if res := LogError("failed to execute message; message index: 0: insufficient funds"); res != "failed to execute message; message index: 0: insufficient funds" {
+		t.Fatalf("unexpected error: %s", res)
+	}
+}
@@ -0,0 +1,208 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+)
+
+const (
+	IcaPacketTypeExecuteTx = "TYPE_EXECUTE_TX"
+	IcaPacketTypeUnknown   = "TYPE_UNSPECIFIED"
+)
+
+type (
+	ChannelOpenAckMsg struct {
+		PortId                string `bson:"port_id" json:"port_id"`
+		ChannelId             string `bson:"channel_id" json:"channel_id"`
+		CounterpartyChannelId string `bson:"counterparty_channel_id" json:"counterparty_channel_id"`
+		CounterpartyVersion   string `bson:"counterparty_version" json:"counterparty_version"`
+		Signer                string `bson:"signer" json:"signer"`
+	}
+
+	// IcaMetadata the ics-27 channel version, the interchain account address is set by the host chain in the open try handshake
+	IcaMetadata struct {
+		Version                string `json:"version"`
+		ControllerConnectionId string `json:"controller_connection_id"`
+		HostConnectionId       string `json:"host_connection_id"`
+		Address                string `json:"address"`
+		Encoding               string `json:"encoding"`
+		TxType                 string `json:"tx_type"`
+	}
+
+	// IcaPacket the ics-27 packet of recv_packet, acknowledge_packet and timeout_packet msg
+	IcaPacket struct {
+		Sequence           int64           `json:"sequence"`
+		SourcePort         string          `json:"source_port"`
+		SourceChannel      string          `json:"source_channel"`
+		DestinationPort    string          `json:"destination_port"`
+		DestinationChannel string          `json:"destination_channel"`
+		Data               json.RawMessage `json:"data"`
+	}
+
+	// IcaPacketData the ics-27 packet data, MsgTypes are the type urls of the msgs executed by the interchain account
+	IcaPacketData struct {
+		Type     string
+		Memo     string
+		MsgTypes []string
+	}
+)
+
+func (m TxMsg) ChannelOpenAckMsg() ChannelOpenAckMsg {
+	var msg ChannelOpenAckMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
+func (m TxMsg) IcaPacket() IcaPacket {
+	var msg struct {
+		Packet IcaPacket `json:"packet"`
+	}
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg.Packet
+}
+
+// IcaOwner the owner of the interchain account is encoded in the controller port, eg: icacontroller-{owner}
+func IcaOwner(controllerPort string) string {
+	return strings.TrimPrefix(controllerPort, constant.PortIcaController)
+}
+
+// IsIcaPort whether the port is a controller or host port of ics-27
+func IsIcaPort(port string) bool {
+	return port == constant.PortIcaHost || strings.HasPrefix(port, constant.PortIcaController)
+}
+
+// ParseIcaMetadata parse the ics-27 metadata from the channel version, the version wrapped by ics-29 fee middleware is supported
+func ParseIcaMetadata(version string) *IcaMetadata {
+	var feeVersion struct {
+		FeeVersion string `json:"fee_version"`
+		AppVersion string `json:"app_version"`
+	}
+	if err := json.Unmarshal([]byte(version), &feeVersion); err == nil && feeVersion.AppVersion != "" {
+		version = feeVersion.AppVersion
+	}
+
+	var metadata IcaMetadata
+	if err := json.Unmarshal([]byte(version), &metadata); err != nil || metadata.Address == "" {
+		return nil
+	}
+	return &metadata
+}
+
+func (p IcaPacket) PacketData() IcaPacketData {
+	return ParseIcaPacketData(p.Data)
+}
+
+// ParseIcaPacketData the data may be decoded as an object, or kept as the json string or base64 string of the object
+func ParseIcaPacketData(bz []byte) IcaPacketData {
+	var res IcaPacketData
+	bz = bytes.TrimSpace(bz)
+	if len(bz) == 0 {
+		return res
+	}
+	if bz[0] == '"' {
+		var s string
+		if err := json.Unmarshal(bz, &s); err != nil {
+			return res
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(s); err == nil {
+			s = string(decoded)
+		}
+		bz = []byte(s)
+	}
+
+	var data struct {
+		Type json.RawMessage `json:"type"`
+		Data []byte          `json:"data"`
+		Memo string          `json:"memo"`
+	}
+	if err := json.Unmarshal(bz, &data); err != nil {
+		return res
+	}
+
+	res.Memo = data.Memo
+	res.Type = IcaPacketTypeUnknown
+	if typ := strings.Trim(string(data.Type), `"`); typ == IcaPacketTypeExecuteTx || typ == "1" {
+		res.Type = IcaPacketTypeExecuteTx
+	}
+	res.MsgTypes = decodeCosmosTxTypeUrls(data.Data)
+	return res
+}
+
+// decodeCosmosTxTypeUrls decode the type urls of the msgs from the proto3 encoded CosmosTx:
+//
+//	message CosmosTx { repeated google.protobuf.Any messages = 1; }
+//	message Any { string type_url = 1; bytes value = 2; }
+func decodeCosmosTxTypeUrls(bz []byte) []string {
+	var res []string
+	for _, msg := range readProtoBytesFields(bz, 1) {
+		if typeUrls := readProtoBytesFields(msg, 1); len(typeUrls) > 0 {
+			res = append(res, string(typeUrls[0]))
+		}
+	}
+	return res
+}
+
+// readProtoBytesFields read the length-delimited fields with the field number, nil is returned if the bytes are malformed
+func readProtoBytesFields(bz []byte, fieldNum uint64) [][]byte {
+	var res [][]byte
+	for len(bz) > 0 {
+		key, n := binary.Uvarint(bz)
+		if n <= 0 {
+			return nil
+		}
+		bz = bz[n:]
+
+		switch key & 7 {
+		case 0: // varint
+			if _, n = binary.Uvarint(bz); n <= 0 {
+				return nil
+			}
+			bz = bz[n:]
+		case 1: // 64-bit
+			if len(bz) < 8 {
+				return nil
+			}
+			bz = bz[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(bz)
+			if n <= 0 || uint64(len(bz)-n) < l {
+				return nil
+			}
+			if key>>3 == fieldNum {
+				res = append(res, bz[n:n+int(l)])
+			}
+			bz = bz[n+int(l):]
+		case 5: // 32-bit
+			if len(bz) < 4 {
+				return nil
+			}
+			bz = bz[4:]
+		default:
+			return nil
+		}
+	}
+	return res
+}
+
+// AckError the error of the ack, e.g. the host execution error of ica, empty if the ack is successful
+func AckError(ack string) string {
+	var res struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(ack), &res); err == nil {
+		return res.Error
+	}
+	if strings.Contains(ack, "error") {
+		return ack
+	}
+	return ""
+}
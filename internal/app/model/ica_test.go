@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseIcaPacketData(t *testing.T) {
+	// CosmosTx{messages: [Any{type_url: "/cosmos.staking.v1beta1.MsgDelegate", value: 0x0a01}]}
+	typeUrl := "/cosmos.staking.v1beta1.MsgDelegate"
+	anyBz := append([]byte{0x0a, byte(len(typeUrl))}, typeUrl...)
+	anyBz = append(anyBz, 0x12, 0x02, 0x0a, 0x00)
+	cosmosTx := append([]byte{0x0a, byte(len(anyBz))}, anyBz...)
+	cosmosTx = append(cosmosTx, cosmosTx...)
+
+	bz, _ := json.Marshal(map[string]interface{}{
+		"type": "TYPE_EXECUTE_TX",
+		"data": base64.StdEncoding.EncodeToString(cosmosTx),
+		"memo": "stride",
+	})
+	data := ParseIcaPacketData(bz)
+	if data.Type != IcaPacketTypeExecuteTx || data.Memo != "stride" || len(data.MsgTypes) != 2 || data.MsgTypes[0] != typeUrl {
+		t.Fatalf("unexpected packet data: %+v", data)
+	}
+
+	str, _ := json.Marshal(base64.StdEncoding.EncodeToString(bz))
+	if data = ParseIcaPacketData(str); len(data.MsgTypes) != 2 {
+		t.Fatalf("unexpected base64 packet data: %+v", data)
+	}
+
+	if res := decodeCosmosTxTypeUrls([]byte{0x0a, 0x10, 0x01}); res != nil {
+		t.Fatalf("expect nil type urls of malformed bytes, got %v", res)
+	}
+}
+
+func TestParseIcaMetadata(t *testing.T) {
+	version := `{"version":"ics27-1","controller_connection_id":"connection-0","host_connection_id":"connection-1",` +
+		`"address":"cosmos1ica","encoding":"proto3","tx_type":"sdk_multi_msg"}`
+	metadata := ParseIcaMetadata(version)
+	if metadata == nil || metadata.Address != "cosmos1ica" || metadata.HostConnectionId != "connection-1" {
+		t.Fatalf("unexpected metadata: %+v", metadata)
+	}
+
+	feeVersion, _ := json.Marshal(map[string]string{"fee_version": "ics29-1", "app_version": version})
+	if metadata = ParseIcaMetadata(string(feeVersion)); metadata == nil || metadata.Address != "cosmos1ica" {
+		t.Fatalf("unexpected metadata of fee version: %+v", metadata)
+	}
+
+	if metadata = ParseIcaMetadata("ics20-1"); metadata != nil {
+		t.Fatalf("expect nil metadata, got %+v", metadata)
+	}
+}
+
+func TestAckError(t *testing.T) {
+	if res := AckError(`{"result":"EgA="}`); res != "" {
+		t.Fatalf("expect no error, got %s", res)
+	}
+	if res := AckError(`{"error":"ABCI code: 5: error handling packet: see events for details"}`); res == "" {
+		t.Fatal("expect error")
+	}
+}
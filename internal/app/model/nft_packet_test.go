@@ -0,0 +1,26 @@
+package model
+
+import (
+	"encoding/base64"
+	"strconv"
+	"testing"
+)
+
+func TestParseNftPacketData(t *testing.T) {
+	data := `{"classId":"nft-transfer/channel-1/kitty","classUri":"ipfs://class","tokenIds":["1","2"],"sender":"stars1sender","receiver":"iaa1receiver"}`
+	for _, bz := range []string{
+		data,
+		strconv.Quote(data),
+		strconv.Quote(base64.StdEncoding.EncodeToString([]byte(data))),
+	} {
+		res := ParseNftPacketData([]byte(bz))
+		if res.ClassId != "nft-transfer/channel-1/kitty" || len(res.TokenIds) != 2 || res.Sender != "stars1sender" ||
+			res.Receiver != "iaa1receiver" {
+			t.Fatalf("unexpected packet data of %s: %+v", bz, res)
+		}
+	}
+
+	if res := ParseNftPacketData([]byte("")); res.ClassId != "" {
+		t.Fatalf("expect empty packet data, got %+v", res)
+	}
+}
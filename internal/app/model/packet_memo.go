@@ -0,0 +1,141 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	PacketMemoTypeForward = "forward" // packet-forward-middleware
+	PacketMemoTypeWasm    = "wasm"    // ibc hooks
+
+	packetMemoMaxDepth = 10
+)
+
+type (
+	// PacketMemo the decoded intent of the ics-20 packet memo
+	PacketMemo struct {
+		Type          string `bson:"type" json:"type"`
+		FinalReceiver string `bson:"final_receiver" json:"final_receiver"`
+		NextPort      string `bson:"next_port" json:"next_port,omitempty"`
+		NextChannel   string `bson:"next_channel" json:"next_channel,omitempty"`
+		ForwardHops   int    `bson:"forward_hops" json:"forward_hops"`
+		Contract      string `bson:"contract" json:"contract,omitempty"`
+		ContractMsg   string `bson:"contract_msg" json:"contract_msg,omitempty"`
+	}
+
+	// PacketForward the forward metadata of packet-forward-middleware
+	PacketForward struct {
+		Receiver string `json:"receiver"`
+		Port     string `json:"port"`
+		Channel  string `json:"channel"`
+	}
+
+	packetMemo struct {
+		Forward *packetMemoForward `json:"forward"`
+		Wasm    *packetMemoWasm    `json:"wasm"`
+	}
+	packetMemoForward struct {
+		PacketForward
+		Next json.RawMessage `json:"next"`
+	}
+	packetMemoWasm struct {
+		Contract string          `json:"contract"`
+		Msg      json.RawMessage `json:"msg"`
+	}
+)
+
+// ParsePacketMemo decode the forward(packet-forward-middleware) or wasm(ibc hooks) intent of the packet,
+// nil is returned if the memo is a plain text.
+func ParsePacketMemo(memo, receiver string) *PacketMemo {
+	m := unmarshalPacketMemo([]byte(memo))
+	if m != nil && m.Wasm != nil && m.Wasm.Contract != "" {
+		return &PacketMemo{
+			Type:          PacketMemoTypeWasm,
+			FinalReceiver: receiver,
+			Contract:      m.Wasm.Contract,
+			ContractMsg:   string(m.Wasm.Msg),
+		}
+	}
+
+	forward := ParsePacketForward(memo, receiver)
+	if forward == nil {
+		return nil
+	}
+	res := &PacketMemo{
+		Type:          PacketMemoTypeForward,
+		FinalReceiver: forward.Receiver,
+		NextPort:      forward.Port,
+		NextChannel:   forward.Channel,
+		ForwardHops:   1,
+	}
+
+	// the forward or wasm call of the next hops
+	for i := 0; i < packetMemoMaxDepth && m != nil && m.Forward != nil; i++ {
+		m = unmarshalPacketMemo(m.Forward.Next)
+		if m == nil {
+			break
+		}
+		if m.Wasm != nil && m.Wasm.Contract != "" {
+			res.Contract = m.Wasm.Contract
+			res.ContractMsg = string(m.Wasm.Msg)
+			break
+		}
+		if m.Forward != nil && m.Forward.Channel != "" {
+			res.FinalReceiver = m.Forward.Receiver
+			res.ForwardHops++
+		}
+	}
+	return res
+}
+
+// ParsePacketForward parse the forward metadata from the packet memo, e.g. {"forward":{"receiver":"","port":"transfer","channel":"channel-0"}},
+// or from the receiver of the legacy version, e.g. "intermediate|transfer/channel-0:receiver".
+// nil is returned if the packet is not forwarded.
+func ParsePacketForward(memo, receiver string) *PacketForward {
+	if m := unmarshalPacketMemo([]byte(memo)); m != nil && m.Forward != nil && m.Forward.Channel != "" {
+		return &m.Forward.PacketForward
+	}
+
+	split := strings.SplitN(receiver, "|", 2)
+	if len(split) != 2 {
+		return nil
+	}
+	path := strings.SplitN(split[1], ":", 2)
+	if len(path) != 2 {
+		return nil
+	}
+	portChannel := strings.SplitN(path[0], "/", 2)
+	if len(portChannel) != 2 || portChannel[1] == "" {
+		return nil
+	}
+	return &PacketForward{
+		Receiver: path[1],
+		Port:     portChannel[0],
+		Channel:  portChannel[1],
+	}
+}
+
+// unmarshalPacketMemo the nested memo may be a json object or a json string of the object
+func unmarshalPacketMemo(bz []byte) *packetMemo {
+	bz = []byte(strings.TrimSpace(string(bz)))
+	if len(bz) == 0 {
+		return nil
+	}
+	if bz[0] == '"' {
+		var s string
+		if err := json.Unmarshal(bz, &s); err != nil {
+			return nil
+		}
+		return unmarshalPacketMemo([]byte(s))
+	}
+	if bz[0] != '{' {
+		return nil
+	}
+
+	var m packetMemo
+	if err := json.Unmarshal(bz, &m); err != nil {
+		return nil
+	}
+	return &m
+}
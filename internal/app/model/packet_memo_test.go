@@ -0,0 +1,49 @@
+package model
+
+import "testing"
+
+func TestParsePacketForward(t *testing.T) {
+	forward := ParsePacketForward(`{"forward":{"receiver":"cosmos1receiver","port":"transfer","channel":"channel-141"}}`, "osmo1intermediate")
+	if forward == nil || forward.Receiver != "cosmos1receiver" || forward.Port != "transfer" || forward.Channel != "channel-141" {
+		t.Fatalf("unexpected forward: %+v", forward)
+	}
+
+	forward = ParsePacketForward("", "osmo1intermediate|transfer/channel-0:cosmos1receiver")
+	if forward == nil || forward.Receiver != "cosmos1receiver" || forward.Port != "transfer" || forward.Channel != "channel-0" {
+		t.Fatalf("unexpected legacy forward: %+v", forward)
+	}
+
+	for _, v := range [][2]string{
+		{"", "osmo1receiver"},
+		{"swap", "osmo1receiver"},
+		{`{"wasm":{"contract":"osmo1contract"}}`, "osmo1contract"},
+	} {
+		if forward := ParsePacketForward(v[0], v[1]); forward != nil {
+			t.Fatalf("expect nil forward for memo %q receiver %q, got %+v", v[0], v[1], forward)
+		}
+	}
+}
+
+func TestParsePacketMemo(t *testing.T) {
+	memo := ParsePacketMemo(`{"forward":{"receiver":"osmo1intermediate","port":"transfer","channel":"channel-0",`+
+		`"next":"{\"forward\":{\"receiver\":\"juno1receiver\",\"port\":\"transfer\",\"channel\":\"channel-42\"}}"}}`, "cosmos1intermediate")
+	if memo == nil || memo.Type != PacketMemoTypeForward || memo.FinalReceiver != "juno1receiver" || memo.NextChannel != "channel-0" ||
+		memo.ForwardHops != 2 {
+		t.Fatalf("unexpected forward memo: %+v", memo)
+	}
+
+	memo = ParsePacketMemo(`{"forward":{"receiver":"osmo1contract","port":"transfer","channel":"channel-0",`+
+		`"next":{"wasm":{"contract":"osmo1contract","msg":{"swap":{}}}}}}`, "cosmos1intermediate")
+	if memo == nil || memo.Type != PacketMemoTypeForward || memo.Contract != "osmo1contract" || memo.ContractMsg != `{"swap":{}}` {
+		t.Fatalf("unexpected forward memo with wasm: %+v", memo)
+	}
+
+	memo = ParsePacketMemo(`{"wasm":{"contract":"osmo1contract","msg":{"osmosis_swap":{"output_denom":"uosmo"}}}}`, "osmo1contract")
+	if memo == nil || memo.Type != PacketMemoTypeWasm || memo.Contract != "osmo1contract" || memo.FinalReceiver != "osmo1contract" {
+		t.Fatalf("unexpected wasm memo: %+v", memo)
+	}
+
+	if memo = ParsePacketMemo("hello", "osmo1receiver"); memo != nil {
+		t.Fatalf("expect nil memo, got %+v", memo)
+	}
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -37,6 +39,20 @@ type (
 		Receiver         string        `bson:"receiver" json:"receiver"`
 		TimeoutHeight    TimeoutHeight `bson:"timeout_height" json:"timeout_height"`
 		TimeoutTimestamp int64         `bson:"timeout_timestamp" json:"timeout_timestamp"`
+		Memo             string        `bson:"memo" json:"memo"`
+	}
+
+	NftTransferTxMsg struct {
+		PacketId         string        `bson:"packet_id" json:"packet_id"`
+		SourcePort       string        `bson:"source_port" json:"source_port"`
+		SourceChannel    string        `bson:"source_channel" json:"source_channel"`
+		ClassId          string        `bson:"class_id" json:"class_id"`
+		TokenIds         []string      `bson:"token_ids" json:"token_ids"`
+		Sender           string        `bson:"sender" json:"sender"`
+		Receiver         string        `bson:"receiver" json:"receiver"`
+		TimeoutHeight    TimeoutHeight `bson:"timeout_height" json:"timeout_height"`
+		TimeoutTimestamp int64         `bson:"timeout_timestamp" json:"timeout_timestamp"`
+		Memo             string        `bson:"memo" json:"memo"`
 	}
 
 	TimeoutHeight struct {
@@ -107,6 +123,25 @@ func (m TxMsg) TransferMsg() TransferTxMsg {
 	return msg
 }
 
+func (m TxMsg) NftTransferMsg() NftTransferTxMsg {
+	var msg NftTransferTxMsg
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg
+}
+
+// NftPacket the ics-721 packet of recv_packet, acknowledge_packet and timeout_packet msg
+func (m TxMsg) NftPacket() NftPacket {
+	var msg struct {
+		Packet NftPacket `json:"packet"`
+	}
+	bz, _ := json.Marshal(m.Msg)
+	_ = json.Unmarshal(bz, &msg)
+
+	return msg.Packet
+}
+
 func (m TxMsg) RecvPacketMsg() RecvPacketMsg {
 	var msg RecvPacketMsg
 	bz, _ := json.Marshal(m.Msg)
@@ -137,3 +172,48 @@ type TransferTxPacketData struct {
 	Receiver string `json:"receiver"`
 	Sender   string `json:"sender"`
 }
+
+// NftPacketData the ics-721 packet data
+type NftPacketData struct {
+	ClassId   string   `json:"classId"`
+	ClassUri  string   `json:"classUri"`
+	TokenIds  []string `json:"tokenIds"`
+	TokenUris []string `json:"tokenUris"`
+	Sender    string   `json:"sender"`
+	Receiver  string   `json:"receiver"`
+	Memo      string   `json:"memo"`
+}
+
+type NftPacket struct {
+	Sequence           int64           `json:"sequence"`
+	SourcePort         string          `json:"source_port"`
+	SourceChannel      string          `json:"source_channel"`
+	DestinationPort    string          `json:"destination_port"`
+	DestinationChannel string          `json:"destination_channel"`
+	Data               json.RawMessage `json:"data"`
+}
+
+// PacketData the data may be decoded as an object, or kept as the json string or base64 string of the object
+func (p NftPacket) PacketData() NftPacketData {
+	return ParseNftPacketData(p.Data)
+}
+
+func ParseNftPacketData(bz []byte) NftPacketData {
+	var data NftPacketData
+	bz = bytes.TrimSpace(bz)
+	if len(bz) == 0 {
+		return data
+	}
+	if bz[0] == '"' {
+		var s string
+		if err := json.Unmarshal(bz, &s); err != nil {
+			return data
+		}
+		if decoded, err := base64.StdEncoding.DecodeString(s); err == nil {
+			s = string(decoded)
+		}
+		bz = []byte(s)
+	}
+	_ = json.Unmarshal(bz, &data)
+	return data
+}
@@ -0,0 +1,100 @@
+package vo
+
+const (
+	SeriesIntervalHour  = "hour"
+	SeriesIntervalDay   = "day"
+	SeriesIntervalWeek  = "week"
+	SeriesIntervalMonth = "month"
+)
+
+const (
+	FailuresGroupByChain   = "chain"
+	FailuresGroupByChannel = "channel"
+)
+
+type AnalyticsSeriesReq struct {
+	Interval         string `json:"interval" form:"interval" binding:"required"`
+	StartTime        int64  `json:"start_time" form:"start_time"`
+	EndTime          int64  `json:"end_time" form:"end_time"`
+	Chain            string `json:"chain" form:"chain"`
+	Channel          string `json:"channel" form:"channel"` // chainA|channelA|chainB|channelB
+	Relayer          string `json:"relayer" form:"relayer"` // relayer id
+	BaseDenom        string `json:"base_denom" form:"base_denom"`
+	BaseDenomChainId string `json:"base_denom_chain_id" form:"base_denom_chain_id"`
+}
+
+type AnalyticsSeriesResp struct {
+	Interval string                `json:"interval"`
+	Currency string                `json:"currency"`
+	Items    []AnalyticsSeriesItem `json:"items"`
+}
+
+type AnalyticsSeriesItem struct {
+	Bucket           string `json:"bucket"`
+	StartTime        int64  `json:"start_time"`
+	TransferTxs      int64  `json:"transfer_txs"`
+	TransferTxsValue string `json:"transfer_txs_value"`
+	SuccessTxs       int64  `json:"success_txs"`
+	RefundedTxs      int64  `json:"refunded_txs"`
+}
+
+type AnalyticsFlowsReq struct {
+	StartTime        int64  `json:"start_time" form:"start_time"`
+	EndTime          int64  `json:"end_time" form:"end_time"`
+	Chain            string `json:"chain" form:"chain"`
+	BaseDenom        string `json:"base_denom" form:"base_denom"`
+	BaseDenomChainId string `json:"base_denom_chain_id" form:"base_denom_chain_id"`
+}
+
+// AnalyticsFlowsResp nodes and links of the Sankey diagram, the links are directional(source -> target)
+type AnalyticsFlowsResp struct {
+	StartTime int64               `json:"start_time"`
+	EndTime   int64               `json:"end_time"`
+	Currency  string              `json:"currency"`
+	Nodes     []AnalyticsFlowNode `json:"nodes"`
+	Links     []AnalyticsFlowLink `json:"links"`
+}
+
+type AnalyticsFlowNode struct {
+	Name         string `json:"name"`
+	InflowTxs    int64  `json:"inflow_txs"`
+	OutflowTxs   int64  `json:"outflow_txs"`
+	InflowValue  string `json:"inflow_value"`
+	OutflowValue string `json:"outflow_value"`
+	NetflowValue string `json:"netflow_value"` // inflow - outflow, positive means net importer
+}
+
+type AnalyticsFlowLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Txs    int64  `json:"txs"`
+	Amount string `json:"amount,omitempty"` // only if base denom is specified
+	Value  string `json:"value"`
+}
+
+type AnalyticsFailuresReq struct {
+	StartTime int64  `json:"start_time" form:"start_time"`
+	EndTime   int64  `json:"end_time" form:"end_time"`
+	GroupBy   string `json:"group_by" form:"group_by"` // chain or channel, default chain
+	Chain     string `json:"chain" form:"chain"`
+}
+
+type AnalyticsFailuresResp struct {
+	StartTime int64                  `json:"start_time"`
+	EndTime   int64                  `json:"end_time"`
+	GroupBy   string                 `json:"group_by"`
+	Items     []AnalyticsFailureItem `json:"items"`
+}
+
+// AnalyticsFailureItem the failed and refunded txs sent from the chain or through the channel, by failure category
+type AnalyticsFailureItem struct {
+	ChainId    string                     `json:"chain_id"`
+	Channel    string                     `json:"channel,omitempty"` // chainA|channelA|chainB|channelB, chainA is the sc chain
+	FailedTxs  int64                      `json:"failed_txs"`
+	Categories []AnalyticsFailureCategory `json:"categories"`
+}
+
+type AnalyticsFailureCategory struct {
+	Category string `json:"category"`
+	Txs      int64  `json:"txs"`
+}
@@ -46,8 +46,8 @@ func ParseParamPage(pageNum int64, pageSize int64) (skip int64, limit int64) {
 		pageSize = 10
 	}
 	//limit max pagesize
-	if global.Config.App.MaxPageSize > 0 && pageSize > global.Config.App.MaxPageSize {
-		pageSize = global.Config.App.MaxPageSize
+	if global.Config().App.MaxPageSize > 0 && pageSize > global.Config().App.MaxPageSize {
+		pageSize = global.Config().App.MaxPageSize
 	}
 	return (pageNum - 1) * pageSize, pageSize
 }
@@ -0,0 +1,57 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	// ChainOnboardReq the chain definition, the empty fields are filled from the chain.json of the chain registry
+	ChainOnboardReq struct {
+		ChainId      string `json:"chain_id"`
+		ChainName    string `json:"chain_name"`
+		Icon         string `json:"icon"`
+		Lcd          string `json:"lcd"`
+		AddrPrefix   string `json:"addr_prefix"`
+		ChainJsonUrl string `json:"chain_json_url"`
+		DataSource   string `json:"data_source"` // lcd(default), rpc or grpc
+		Rpc          string `json:"rpc"`
+		Grpc         string `json:"grpc"`
+	}
+
+	ChainOnboardingResp struct {
+		ChainId      string                   `json:"chain_id"`
+		ChainJsonUrl string                   `json:"chain_json_url"`
+		Status       string                   `json:"status"`
+		Steps        []ChainOnboardingStepDto `json:"steps"`
+		CreateAt     int64                    `json:"create_at"`
+		UpdateAt     int64                    `json:"update_at"`
+	}
+	ChainOnboardingStepDto struct {
+		Name      string `json:"name"`
+		Status    string `json:"status"`
+		Message   string `json:"message"`
+		StartTime int64  `json:"start_time"`
+		EndTime   int64  `json:"end_time"`
+	}
+)
+
+func (dto ChainOnboardingResp) LoadDto(onboarding *entity.IBCChainOnboarding) ChainOnboardingResp {
+	steps := make([]ChainOnboardingStepDto, 0, len(onboarding.Steps))
+	for _, v := range onboarding.Steps {
+		steps = append(steps, ChainOnboardingStepDto{
+			Name:      v.Name,
+			Status:    string(v.Status),
+			Message:   v.Message,
+			StartTime: v.StartTime,
+			EndTime:   v.EndTime,
+		})
+	}
+	return ChainOnboardingResp{
+		ChainId:      onboarding.ChainId,
+		ChainJsonUrl: onboarding.ChainJsonUrl,
+		Status:       string(onboarding.Status),
+		Steps:        steps,
+		CreateAt:     onboarding.CreateAt,
+		UpdateAt:     onboarding.UpdateAt,
+	}
+}
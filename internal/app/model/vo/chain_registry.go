@@ -0,0 +1,47 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	ChainRegistryDiffsReq struct {
+		Page
+		UseCount bool   `json:"use_count" form:"use_count"`
+		ChainId  string `json:"chain_id" form:"chain_id"`
+		Coll     string `json:"coll" form:"coll"`
+		Status   string `json:"status" form:"status"`
+	}
+	ChainRegistryDiffsResp struct {
+		Items     []ChainRegistryDiffDto `json:"items"`
+		PageInfo  PageInfo               `json:"page_info"`
+		TimeStamp int64                  `json:"time_stamp"`
+	}
+	ChainRegistryDiffDto struct {
+		DiffId    string      `json:"diff_id"`
+		ChainId   string      `json:"chain_id"`
+		Coll      string      `json:"coll"`
+		Denom     string      `json:"denom"`
+		Field     string      `json:"field"`
+		OldValue  interface{} `json:"old_value"`
+		NewValue  interface{} `json:"new_value"`
+		Status    string      `json:"status"`
+		ApplyTime int64       `json:"apply_time"`
+		UpdateAt  int64       `json:"update_at"`
+	}
+)
+
+func (dto ChainRegistryDiffDto) LoadDto(diff *entity.IBCChainRegistryDiff) ChainRegistryDiffDto {
+	return ChainRegistryDiffDto{
+		DiffId:    diff.DiffId,
+		ChainId:   diff.ChainId,
+		Coll:      diff.Coll,
+		Denom:     diff.Denom,
+		Field:     diff.Field,
+		OldValue:  diff.OldValue,
+		NewValue:  diff.NewValue,
+		Status:    string(diff.Status),
+		ApplyTime: diff.ApplyTime,
+		UpdateAt:  diff.UpdateAt,
+	}
+}
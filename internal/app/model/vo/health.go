@@ -0,0 +1,19 @@
+package vo
+
+const (
+	HealthStatusOk   = "ok"
+	HealthStatusFail = "fail"
+)
+
+type HealthResp struct {
+	Status  string        `json:"status"`
+	Version string        `json:"version"`
+	Checks  []HealthCheck `json:"checks,omitempty"`
+}
+
+type HealthCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	Latency int64  `json:"latency_ms"`
+}
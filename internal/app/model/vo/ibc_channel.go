@@ -4,9 +4,10 @@ import "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/ent
 
 type ChannelListReq struct {
 	Page
-	Chain    string               `json:"chain" form:"chain"`
-	Status   entity.ChannelStatus `json:"status" form:"status"`
-	UseCount bool                 `json:"use_count" form:"use_count"`
+	Chain       string               `json:"chain" form:"chain"`
+	Status      entity.ChannelStatus `json:"status" form:"status"`
+	ChannelType string               `json:"channel_type" form:"channel_type"`
+	UseCount    bool                 `json:"use_count" form:"use_count"`
 }
 
 type ChannelListResp struct {
@@ -26,4 +27,17 @@ type ChannelItem struct {
 	IbcTransferTxs      int64                `json:"ibc_transfer_txs"`
 	Currency            string               `json:"currency"`
 	Status              entity.ChannelStatus `json:"status"`
+	ChannelType         string               `json:"channel_type"`
+}
+
+type ChannelTypesResp struct {
+	Items     []ChannelTypeItem `json:"items"`
+	TimeStamp int64             `json:"time_stamp"`
+}
+
+type ChannelTypeItem struct {
+	ChannelType    string `json:"channel_type"`
+	Channels       int64  `json:"channels"`
+	OpenedChannels int64  `json:"opened_channels"`
+	ClosedChannels int64  `json:"closed_channels"`
 }
@@ -0,0 +1,104 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	EscrowReconcilesReq struct {
+		Page
+		UseCount  bool   `json:"use_count" form:"use_count"`
+		ChainId   string `json:"chain_id" form:"chain_id"`
+		ChannelId string `json:"channel_id" form:"channel_id"`
+		Denom     string `json:"denom" form:"denom"`
+		Status    string `json:"status" form:"status"`
+		Alerting  string `json:"alerting" form:"alerting"`
+	}
+	EscrowReconcilesResp struct {
+		Items     []EscrowReconcileDto `json:"items"`
+		PageInfo  PageInfo             `json:"page_info"`
+		TimeStamp int64                `json:"time_stamp"`
+	}
+	EscrowReconcileDto struct {
+		ChainId               string `json:"chain_id"`
+		PortId                string `json:"port_id"`
+		ChannelId             string `json:"channel_id"`
+		EscrowAddress         string `json:"escrow_address"`
+		Denom                 string `json:"denom"`
+		DenomFullPath         string `json:"denom_full_path"`
+		CounterpartyChainId   string `json:"counterparty_chain_id"`
+		CounterpartyChannelId string `json:"counterparty_channel_id"`
+		VoucherDenom          string `json:"voucher_denom"`
+		EscrowAmount          string `json:"escrow_amount"`
+		VoucherSupply         string `json:"voucher_supply"`
+		Diff                  string `json:"diff"`
+		Status                int    `json:"status"`
+		MismatchTimes         int64  `json:"mismatch_times"`
+		FirstMismatchTime     int64  `json:"first_mismatch_time"`
+		Alerting              bool   `json:"alerting"`
+		CheckTime             int64  `json:"check_time"`
+	}
+
+	EscrowDiscrepanciesReq struct {
+		Page
+		UseCount  bool   `json:"use_count" form:"use_count"`
+		DateRange string `json:"date_range" form:"date_range"`
+		ChainId   string `json:"chain_id" form:"chain_id"`
+		ChannelId string `json:"channel_id" form:"channel_id"`
+		Denom     string `json:"denom" form:"denom"`
+	}
+	EscrowDiscrepanciesResp struct {
+		Items     []EscrowDiscrepancyDto `json:"items"`
+		PageInfo  PageInfo               `json:"page_info"`
+		TimeStamp int64                  `json:"time_stamp"`
+	}
+	EscrowDiscrepancyDto struct {
+		ChainId             string `json:"chain_id"`
+		ChannelId           string `json:"channel_id"`
+		Denom               string `json:"denom"`
+		CounterpartyChainId string `json:"counterparty_chain_id"`
+		VoucherDenom        string `json:"voucher_denom"`
+		EscrowAmount        string `json:"escrow_amount"`
+		VoucherSupply       string `json:"voucher_supply"`
+		Diff                string `json:"diff"`
+		MismatchTimes       int64  `json:"mismatch_times"`
+		CheckTime           int64  `json:"check_time"`
+	}
+)
+
+func (dto EscrowReconcileDto) LoadDto(reconcile *entity.IBCEscrowReconcile) EscrowReconcileDto {
+	return EscrowReconcileDto{
+		ChainId:               reconcile.ChainId,
+		PortId:                reconcile.PortId,
+		ChannelId:             reconcile.ChannelId,
+		EscrowAddress:         reconcile.EscrowAddress,
+		Denom:                 reconcile.Denom,
+		DenomFullPath:         reconcile.DenomFullPath,
+		CounterpartyChainId:   reconcile.CounterpartyChainId,
+		CounterpartyChannelId: reconcile.CounterpartyChannelId,
+		VoucherDenom:          reconcile.VoucherDenom,
+		EscrowAmount:          reconcile.EscrowAmount,
+		VoucherSupply:         reconcile.VoucherSupply,
+		Diff:                  reconcile.Diff,
+		Status:                int(reconcile.Status),
+		MismatchTimes:         reconcile.MismatchTimes,
+		FirstMismatchTime:     reconcile.FirstMismatchTime,
+		Alerting:              reconcile.Alerting,
+		CheckTime:             reconcile.CheckTime,
+	}
+}
+
+func (dto EscrowDiscrepancyDto) LoadDto(discrepancy *entity.IBCEscrowDiscrepancy) EscrowDiscrepancyDto {
+	return EscrowDiscrepancyDto{
+		ChainId:             discrepancy.ChainId,
+		ChannelId:           discrepancy.ChannelId,
+		Denom:               discrepancy.Denom,
+		CounterpartyChainId: discrepancy.CounterpartyChainId,
+		VoucherDenom:        discrepancy.VoucherDenom,
+		EscrowAmount:        discrepancy.EscrowAmount,
+		VoucherSupply:       discrepancy.VoucherSupply,
+		Diff:                discrepancy.Diff,
+		MismatchTimes:       discrepancy.MismatchTimes,
+		CheckTime:           discrepancy.CheckTime,
+	}
+}
@@ -0,0 +1,114 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	IcaAccountsReq struct {
+		Page
+		UseCount   bool   `json:"use_count" form:"use_count"`
+		ChainId    string `json:"chain_id" form:"chain_id"`
+		Owner      string `json:"owner" form:"owner"`
+		IcaAddress string `json:"ica_address" form:"ica_address"`
+	}
+	IcaAccountsResp struct {
+		Items     []IcaAccountDto `json:"items"`
+		PageInfo  PageInfo        `json:"page_info"`
+		TimeStamp int64           `json:"time_stamp"`
+	}
+	IcaAccountDto struct {
+		ControllerChainId      string `json:"controller_chain_id"`
+		ControllerPort         string `json:"controller_port"`
+		ControllerChannel      string `json:"controller_channel"`
+		ControllerConnectionId string `json:"controller_connection_id"`
+		Owner                  string `json:"owner"`
+		HostChainId            string `json:"host_chain_id"`
+		HostChannel            string `json:"host_channel"`
+		HostConnectionId       string `json:"host_connection_id"`
+		IcaAddress             string `json:"ica_address"`
+		RegisterTxHash         string `json:"register_tx_hash"`
+		RegisterTime           int64  `json:"register_time"`
+		Txs                    int64  `json:"txs"`
+		SuccessTxs             int64  `json:"success_txs"`
+		FailedTxs              int64  `json:"failed_txs"`
+		TimeoutTxs             int64  `json:"timeout_txs"`
+		PendingTxs             int64  `json:"pending_txs"`
+		LatestTxTime           int64  `json:"latest_tx_time"`
+	}
+
+	IcaTxsReq struct {
+		Page
+		UseCount   bool   `json:"use_count" form:"use_count"`
+		DateRange  string `json:"date_range" form:"date_range"`
+		Status     string `json:"status" form:"status"`
+		ChainId    string `json:"chain_id" form:"chain_id"`
+		Owner      string `json:"owner" form:"owner"`
+		IcaAddress string `json:"ica_address" form:"ica_address"`
+		MsgType    string `json:"msg_type" form:"msg_type"`
+	}
+	IcaTxsResp struct {
+		Items     []IcaTxDto `json:"items"`
+		PageInfo  PageInfo   `json:"page_info"`
+		TimeStamp int64      `json:"time_stamp"`
+	}
+	IcaTxDto struct {
+		RecordId          string    `json:"record_id"`
+		Sequence          string    `json:"sequence"`
+		ControllerChainId string    `json:"controller_chain_id"`
+		ControllerChannel string    `json:"controller_channel"`
+		Owner             string    `json:"owner"`
+		HostChainId       string    `json:"host_chain_id"`
+		HostChannel       string    `json:"host_channel"`
+		IcaAddress        string    `json:"ica_address"`
+		Status            int       `json:"status"`
+		PacketType        string    `json:"packet_type"`
+		MsgTypes          []string  `json:"msg_types"`
+		Memo              string    `json:"memo"`
+		ErrorLog          string    `json:"error_log"`
+		SendTxInfo        TxInfoDto `json:"send_tx_info"`
+		HostTxInfo        TxInfoDto `json:"host_tx_info"`
+		AckTxInfo         TxInfoDto `json:"ack_tx_info"`
+		TimeoutTxInfo     TxInfoDto `json:"timeout_tx_info"`
+		TxTime            int64     `json:"tx_time"`
+	}
+)
+
+func (dto IcaAccountDto) LoadDto(account *entity.IBCIcaAccount) IcaAccountDto {
+	return IcaAccountDto{
+		ControllerChainId:      account.ControllerChainId,
+		ControllerPort:         account.ControllerPort,
+		ControllerChannel:      account.ControllerChannel,
+		ControllerConnectionId: account.ControllerConnectionId,
+		Owner:                  account.Owner,
+		HostChainId:            account.HostChainId,
+		HostChannel:            account.HostChannel,
+		HostConnectionId:       account.HostConnectionId,
+		IcaAddress:             account.IcaAddress,
+		RegisterTxHash:         account.RegisterTxHash,
+		RegisterTime:           account.RegisterTime,
+	}
+}
+
+func (dto IcaTxDto) LoadDto(icaTx *entity.ExIbcIcaTx) IcaTxDto {
+	return IcaTxDto{
+		RecordId:          icaTx.RecordId,
+		Sequence:          icaTx.Sequence,
+		ControllerChainId: icaTx.ControllerChainId,
+		ControllerChannel: icaTx.ControllerChannel,
+		Owner:             icaTx.Owner,
+		HostChainId:       icaTx.HostChainId,
+		HostChannel:       icaTx.HostChannel,
+		IcaAddress:        icaTx.IcaAddress,
+		Status:            int(icaTx.Status),
+		PacketType:        icaTx.PacketType,
+		MsgTypes:          icaTx.MsgTypes,
+		Memo:              icaTx.Memo,
+		ErrorLog:          icaTx.ErrorLog,
+		SendTxInfo:        loadTxInfoDto(icaTx.SendTxInfo),
+		HostTxInfo:        loadTxInfoDto(icaTx.HostTxInfo),
+		AckTxInfo:         loadTxInfoDto(icaTx.AckTxInfo),
+		TimeoutTxInfo:     loadTxInfoDto(icaTx.TimeoutTxInfo),
+		TxTime:            icaTx.TxTime,
+	}
+}
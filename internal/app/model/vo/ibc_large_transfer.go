@@ -0,0 +1,64 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	LargeTransfersReq struct {
+		Page
+		UseCount  bool   `json:"use_count" form:"use_count"`
+		DateRange string `json:"date_range" form:"date_range"`
+		ChainId   string `json:"chain_id" form:"chain_id"`
+		BaseDenom string `json:"base_denom" form:"base_denom"`
+		Reason    string `json:"reason" form:"reason"`
+	}
+	LargeTransfersResp struct {
+		Items     []LargeTransferDto `json:"items"`
+		PageInfo  PageInfo           `json:"page_info"`
+		TimeStamp int64              `json:"time_stamp"`
+	}
+	LargeTransferDto struct {
+		RecordId         string   `json:"record_id"`
+		ScChainId        string   `json:"sc_chain_id"`
+		ScChannel        string   `json:"sc_channel"`
+		ScAddr           string   `json:"sc_addr"`
+		DcChainId        string   `json:"dc_chain_id"`
+		DcChannel        string   `json:"dc_channel"`
+		DcAddr           string   `json:"dc_addr"`
+		ScTxHash         string   `json:"sc_tx_hash"`
+		Denom            string   `json:"denom"`
+		BaseDenom        string   `json:"base_denom"`
+		BaseDenomChainId string   `json:"base_denom_chain_id"`
+		Amount           string   `json:"amount"`
+		Value            string   `json:"value"`
+		Reasons          []string `json:"reasons"`
+		BaselineMean     float64  `json:"baseline_mean"`
+		BaselineStddev   float64  `json:"baseline_stddev"`
+		Status           int      `json:"status"`
+		TxTime           int64    `json:"tx_time"`
+	}
+)
+
+func (dto LargeTransferDto) LoadDto(alert *entity.IBCLargeTransferAlert) LargeTransferDto {
+	return LargeTransferDto{
+		RecordId:         alert.RecordId,
+		ScChainId:        alert.ScChainId,
+		ScChannel:        alert.ScChannel,
+		ScAddr:           alert.ScAddr,
+		DcChainId:        alert.DcChainId,
+		DcChannel:        alert.DcChannel,
+		DcAddr:           alert.DcAddr,
+		ScTxHash:         alert.ScTxHash,
+		Denom:            alert.Denom,
+		BaseDenom:        alert.BaseDenom,
+		BaseDenomChainId: alert.BaseDenomChainId,
+		Amount:           alert.Amount,
+		Value:            alert.Value,
+		Reasons:          alert.Reasons,
+		BaselineMean:     alert.BaselineMean,
+		BaselineStddev:   alert.BaselineStddev,
+		Status:           int(alert.Status),
+		TxTime:           alert.TxTime,
+	}
+}
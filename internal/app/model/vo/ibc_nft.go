@@ -0,0 +1,125 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	NftTxsReq struct {
+		Page
+		UseCount         bool   `json:"use_count" form:"use_count"`
+		DateRange        string `json:"date_range" form:"date_range"`
+		Status           string `json:"status" form:"status"`
+		ChainId          string `json:"chain_id" form:"chain_id"`
+		ClassId          string `json:"class_id" form:"class_id"`
+		BaseClassId      string `json:"base_class_id" form:"base_class_id"`
+		BaseClassChainId string `json:"base_class_chain_id" form:"base_class_chain_id"`
+		TokenId          string `json:"token_id" form:"token_id"`
+		Address          string `json:"address" form:"address"`
+	}
+	NftTxsResp struct {
+		Items     []NftTxDto `json:"items"`
+		PageInfo  PageInfo   `json:"page_info"`
+		TimeStamp int64      `json:"time_stamp"`
+	}
+	NftTxDto struct {
+		RecordId         string    `json:"record_id"`
+		ScAddr           string    `json:"sc_addr"`
+		DcAddr           string    `json:"dc_addr"`
+		Status           int       `json:"status"`
+		ScChainId        string    `json:"sc_chain_id"`
+		DcChainId        string    `json:"dc_chain_id"`
+		ScPort           string    `json:"sc_port"`
+		DcPort           string    `json:"dc_port"`
+		ScChannel        string    `json:"sc_channel"`
+		DcChannel        string    `json:"dc_channel"`
+		Sequence         string    `json:"sequence"`
+		ScTxInfo         TxInfoDto `json:"sc_tx_info"`
+		DcTxInfo         TxInfoDto `json:"dc_tx_info"`
+		ClassIds         ClassIds  `json:"class_ids"`
+		TokenIds         []string  `json:"token_ids"`
+		BaseClassId      string    `json:"base_class_id"`
+		BaseClassChainId string    `json:"base_class_chain_id"`
+		TxTime           int64     `json:"tx_time"`
+		EndTime          int64     `json:"end_time"`
+	}
+	ClassIds struct {
+		ScClassId string `json:"sc_class_id"`
+		DcClassId string `json:"dc_class_id"`
+	}
+
+	NftTxDetailResp struct {
+		Items     []NftTxDetailDto `json:"items"`
+		TimeStamp int64            `json:"time_stamp"`
+	}
+	NftTxDetailDto struct {
+		NftTxDto
+		ScConnect    string         `json:"sc_connect"`
+		DcConnect    string         `json:"dc_connect"`
+		RefundTxInfo *TxDetailDto   `json:"refund_tx_info"`
+		ClassTrace   []NftClassInfo `json:"class_trace"`
+		ProcessInfo  string         `json:"process_info"`
+	}
+	// NftClassInfo one hop of the class trace, from the class on the chain back to the base class
+	NftClassInfo struct {
+		ChainId   string `json:"chain_id"`
+		ClassId   string `json:"class_id"`
+		ClassPath string `json:"class_path"`
+	}
+)
+
+func (dto NftTxDto) LoadDto(nftTx *entity.ExIbcNftTx) NftTxDto {
+	var classIds ClassIds
+	if nftTx.ClassIds != nil {
+		classIds = ClassIds{
+			ScClassId: nftTx.ClassIds.ScClassId,
+			DcClassId: nftTx.ClassIds.DcClassId,
+		}
+	}
+	var endTime int64
+	switch nftTx.Status {
+	case entity.IbcTxStatusSuccess:
+		if nftTx.DcTxInfo != nil {
+			endTime = nftTx.DcTxInfo.Time
+		}
+	case entity.IbcTxStatusRefunded:
+		if nftTx.RefundedTxInfo != nil {
+			endTime = nftTx.RefundedTxInfo.Time
+		}
+	}
+
+	return NftTxDto{
+		RecordId:         nftTx.RecordId,
+		ScAddr:           nftTx.ScAddr,
+		DcAddr:           nftTx.DcAddr,
+		Status:           int(nftTx.Status),
+		ScChainId:        nftTx.ScChainId,
+		DcChainId:        nftTx.DcChainId,
+		ScPort:           nftTx.ScPort,
+		DcPort:           nftTx.DcPort,
+		ScChannel:        nftTx.ScChannel,
+		DcChannel:        nftTx.DcChannel,
+		Sequence:         nftTx.Sequence,
+		ScTxInfo:         loadTxInfoDto(nftTx.ScTxInfo),
+		DcTxInfo:         loadTxInfoDto(nftTx.DcTxInfo),
+		ClassIds:         classIds,
+		TokenIds:         nftTx.TokenIds,
+		BaseClassId:      nftTx.BaseClassId,
+		BaseClassChainId: nftTx.BaseClassChainId,
+		TxTime:           nftTx.TxTime,
+		EndTime:          endTime,
+	}
+}
+
+func (dto NftTxDetailDto) LoadDto(nftTx *entity.ExIbcNftTx) NftTxDetailDto {
+	res := NftTxDetailDto{
+		NftTxDto:    NftTxDto{}.LoadDto(nftTx),
+		ScConnect:   nftTx.ScConnectionId,
+		DcConnect:   nftTx.DcConnectionId,
+		ProcessInfo: nftTx.ProcessInfo,
+	}
+	if nftTx.Status == entity.IbcTxStatusRefunded && nftTx.RefundedTxInfo != nil {
+		res.RefundTxInfo = loadTxDetailDto(nftTx.RefundedTxInfo)
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	PacketsReq struct {
+		Page
+		UseCount  bool   `json:"use_count" form:"use_count"`
+		DateRange string `json:"date_range" form:"date_range"`
+		Status    string `json:"status" form:"status"`
+		ChainId   string `json:"chain_id" form:"chain_id"`
+		Port      string `json:"port" form:"port"`
+		Channel   string `json:"channel" form:"channel"`
+		AckResult string `json:"ack_result" form:"ack_result"`
+	}
+	PacketsResp struct {
+		Items     []PacketDto `json:"items"`
+		PageInfo  PageInfo    `json:"page_info"`
+		TimeStamp int64       `json:"time_stamp"`
+	}
+	PacketDto struct {
+		RecordId      string    `json:"record_id"`
+		ScChainId     string    `json:"sc_chain_id"`
+		ScPort        string    `json:"sc_port"`
+		ScChannel     string    `json:"sc_channel"`
+		DcChainId     string    `json:"dc_chain_id"`
+		DcPort        string    `json:"dc_port"`
+		DcChannel     string    `json:"dc_channel"`
+		Sequence      string    `json:"sequence"`
+		Status        int       `json:"status"`
+		AckResult     string    `json:"ack_result"`
+		SendTxInfo    TxInfoDto `json:"send_tx_info"`
+		RecvTxInfo    TxInfoDto `json:"recv_tx_info"`
+		AckTxInfo     TxInfoDto `json:"ack_tx_info"`
+		TimeoutTxInfo TxInfoDto `json:"timeout_tx_info"`
+		TxTime        int64     `json:"tx_time"`
+	}
+
+	PacketDetailResp struct {
+		PacketDetailDto
+		TimeStamp int64 `json:"time_stamp"`
+	}
+	PacketDetailDto struct {
+		PacketDto
+		PacketId         string       `json:"packet_id"`
+		Data             string       `json:"data"`
+		DataHex          string       `json:"data_hex"`
+		TimeoutHeight    string       `json:"timeout_height"`
+		TimeoutTimestamp string       `json:"timeout_timestamp"`
+		Ack              string       `json:"ack"`
+		SendTxDetail     *TxDetailDto `json:"send_tx_detail"`
+		RecvTxDetail     *TxDetailDto `json:"recv_tx_detail"`
+		AckTxDetail      *TxDetailDto `json:"ack_tx_detail"`
+		TimeoutTxDetail  *TxDetailDto `json:"timeout_tx_detail"`
+	}
+)
+
+func (dto PacketDto) LoadDto(packet *entity.ExIbcPacket) PacketDto {
+	return PacketDto{
+		RecordId:      packet.RecordId,
+		ScChainId:     packet.ScChainId,
+		ScPort:        packet.ScPort,
+		ScChannel:     packet.ScChannel,
+		DcChainId:     packet.DcChainId,
+		DcPort:        packet.DcPort,
+		DcChannel:     packet.DcChannel,
+		Sequence:      packet.Sequence,
+		Status:        int(packet.Status),
+		AckResult:     packet.AckResult,
+		SendTxInfo:    loadTxInfoDto(packet.SendTxInfo),
+		RecvTxInfo:    loadTxInfoDto(packet.RecvTxInfo),
+		AckTxInfo:     loadTxInfoDto(packet.AckTxInfo),
+		TimeoutTxInfo: loadTxInfoDto(packet.TimeoutTxInfo),
+		TxTime:        packet.TxTime,
+	}
+}
+
+func (dto PacketDetailDto) LoadDto(packet *entity.ExIbcPacket) PacketDetailDto {
+	res := PacketDetailDto{
+		PacketDto:        PacketDto{}.LoadDto(packet),
+		PacketId:         packet.PacketId,
+		Data:             packet.Data,
+		DataHex:          packet.DataHex,
+		TimeoutHeight:    packet.TimeoutHeight,
+		TimeoutTimestamp: packet.TimeoutTimestamp,
+		Ack:              packet.Ack,
+	}
+	if packet.SendTxInfo != nil {
+		res.SendTxDetail = loadTxDetailDto(packet.SendTxInfo)
+	}
+	if packet.RecvTxInfo != nil {
+		res.RecvTxDetail = loadTxDetailDto(packet.RecvTxInfo)
+	}
+	if packet.AckTxInfo != nil {
+		res.AckTxDetail = loadTxDetailDto(packet.AckTxInfo)
+	}
+	if packet.TimeoutTxInfo != nil {
+		res.TimeoutTxDetail = loadTxDetailDto(packet.TimeoutTxInfo)
+	}
+	return res
+}
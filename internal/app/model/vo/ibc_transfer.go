@@ -7,6 +7,12 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 )
 
+// how the hop is linked to the previous hop of the route
+const (
+	RouteLinkForward = "forward" // forwarded by packet-forward-middleware in the recv tx
+	RouteLinkAddress = "address" // sent by the receiver of the previous hop
+)
+
 type (
 	TranaferTxsReq struct {
 		Page
@@ -17,6 +23,8 @@ type (
 		Denom            string `json:"denom" form:"denom"`
 		BaseDenom        string `json:"base_denom" form:"base_denom"`
 		BaseDenomChainId string `json:"base_denom_chain_id" form:"base_denom_chain_id"`
+		MemoType         string `json:"memo_type" form:"memo_type"`
+		FailureCategory  string `json:"failure_category" form:"failure_category"`
 	}
 	TranaferTxsResp struct {
 		Items     []IbcTxDto `json:"items"`
@@ -45,6 +53,8 @@ type (
 		Denoms           Denoms    `json:"denoms"`
 		TxTime           int64     `json:"tx_time"`
 		EndTime          int64     `json:"end_time"`
+		MemoType         string    `json:"memo_type"`
+		FailureCategory  string    `json:"failure_category"`
 	}
 	IbcTxDetailDto struct {
 		ScSigners        []string  `json:"sc_signers"`
@@ -71,17 +81,48 @@ type (
 	}
 
 	TranaferTxDetailNewResp struct {
-		Items       []IbcTxDto   `json:"items,omitempty"`
-		IsList      bool         `json:"is_list"`
-		ScInfo      *ChainInfo   `json:"sc_info"`
-		DcInfo      *ChainInfo   `json:"dc_info"`
-		TokenInfo   *TokenInfo   `json:"token_info"`
-		RelayerInfo *RelayerInfo `json:"relayer_info"`
-		IbcTxInfo   *IbcTxInfo   `json:"ibc_tx_info"`
-		Status      int          `json:"status"`
-		Sequence    string       `json:"sequence"`
-		ErrorLog    string       `json:"error_log"`
-		TimeStamp   int64        `json:"time_stamp"`
+		Items           []IbcTxDto        `json:"items,omitempty"`
+		IsList          bool              `json:"is_list"`
+		ScInfo          *ChainInfo        `json:"sc_info"`
+		DcInfo          *ChainInfo        `json:"dc_info"`
+		TokenInfo       *TokenInfo        `json:"token_info"`
+		RelayerInfo     *RelayerInfo      `json:"relayer_info"`
+		IbcTxInfo       *IbcTxInfo        `json:"ibc_tx_info"`
+		Status          int               `json:"status"`
+		Sequence        string            `json:"sequence"`
+		ErrorLog        string            `json:"error_log"`
+		FailureCategory string            `json:"failure_category"`
+		FailureReason   string            `json:"failure_reason"`
+		PacketMemo      *model.PacketMemo `json:"packet_memo"`
+		Route           *TransferRoute    `json:"route"`
+		TimeStamp       int64             `json:"time_stamp"`
+	}
+
+	// TransferRoute the end-to-end journey of the token, stitched by the txs hopped through the chains
+	TransferRoute struct {
+		Hops         []TransferRouteHop   `json:"hops"`
+		CurrentIndex int                  `json:"current_index"`
+		Complete     bool                 `json:"complete"`
+		NextForward  *model.PacketForward `json:"next_forward,omitempty"`
+	}
+	TransferRouteHop struct {
+		RecordId  string `json:"record_id"`
+		LinkType  string `json:"link_type"`
+		Status    int    `json:"status"`
+		ScChainId string `json:"sc_chain_id"`
+		ScChannel string `json:"sc_channel"`
+		ScAddr    string `json:"sc_addr"`
+		ScTxHash  string `json:"sc_tx_hash"`
+		DcChainId string `json:"dc_chain_id"`
+		DcChannel string `json:"dc_channel"`
+		DcAddr    string `json:"dc_addr"`
+		DcTxHash  string `json:"dc_tx_hash"`
+		Sequence  string `json:"sequence"`
+		Denoms    Denoms `json:"denoms"`
+		Amount    string `json:"amount"`
+		TxTime    int64  `json:"tx_time"`
+		EndTime   int64  `json:"end_time"`
+		CostTime  int64  `json:"cost_time"`
 	}
 
 	TraceSourceReq struct {
@@ -230,6 +271,9 @@ func loadTxDetailDto(info *entity.TxInfo) *TxDetailDto {
 		case constant.MsgTypeTransfer:
 			dto.TimeoutTimestamp = info.Msg.TransferMsg().TimeoutTimestamp
 			dto.TimeoutHeight = timeHeightString(info.Msg.TransferMsg().TimeoutHeight)
+		case constant.MsgTypeNftTransfer:
+			dto.TimeoutTimestamp = info.Msg.NftTransferMsg().TimeoutTimestamp
+			dto.TimeoutHeight = timeHeightString(info.Msg.NftTransferMsg().TimeoutHeight)
 		}
 	}
 
@@ -258,6 +302,10 @@ func (dto IbcTxDto) LoadDto(ibcTx *entity.ExIbcTx) IbcTxDto {
 			endTime = ibcTx.RefundedTxInfo.Time
 		}
 	}
+	var memoType string
+	if ibcTx.PacketMemo != nil {
+		memoType = ibcTx.PacketMemo.Type
+	}
 	return IbcTxDto{
 		RecordId:         ibcTx.RecordId,
 		ScAddr:           ibcTx.ScAddr,
@@ -275,6 +323,8 @@ func (dto IbcTxDto) LoadDto(ibcTx *entity.ExIbcTx) IbcTxDto {
 		Denoms:           Denoms{ScDenom: ibcTx.Denoms.ScDenom, DcDenom: ibcTx.Denoms.DcDenom},
 		TxTime:           ibcTx.TxTime,
 		EndTime:          endTime,
+		MemoType:         memoType,
+		FailureCategory:  ibcTx.FailureCategory,
 	}
 }
 
@@ -309,12 +359,14 @@ func LoadTranaferTxDetail(ibcTx *entity.ExIbcTx) TranaferTxDetailNewResp {
 		ibcTxInfo.RefundTxInfo = loadTxDetailDto(ibcTx.RefundedTxInfo)
 	}
 	return TranaferTxDetailNewResp{
-		ErrorLog:  errLog,
-		Status:    int(ibcTx.Status),
-		Sequence:  ibcTx.Sequence,
-		ScInfo:    scChainInfo,
-		DcInfo:    dcChainInfo,
-		IbcTxInfo: ibcTxInfo,
+		ErrorLog:        errLog,
+		FailureCategory: ibcTx.FailureCategory,
+		FailureReason:   ibcTx.FailureReason,
+		Status:          int(ibcTx.Status),
+		Sequence:        ibcTx.Sequence,
+		ScInfo:          scChainInfo,
+		DcInfo:          dcChainInfo,
+		IbcTxInfo:       ibcTxInfo,
 	}
 }
 
@@ -338,3 +390,31 @@ func (dto IbcTxDetailDto) LoadDto(ibcTx *entity.ExIbcTx) IbcTxDetailDto {
 		TxTime:           ibcTx.TxTime,
 	}
 }
+
+func LoadTransferRouteHop(ibcTx *entity.ExIbcTx, linkType string) TransferRouteHop {
+	dto := IbcTxDto{}.LoadDto(ibcTx)
+	hop := TransferRouteHop{
+		RecordId:  ibcTx.RecordId,
+		LinkType:  linkType,
+		Status:    int(ibcTx.Status),
+		ScChainId: ibcTx.ScChainId,
+		ScChannel: ibcTx.ScChannel,
+		ScAddr:    ibcTx.ScAddr,
+		ScTxHash:  dto.ScTxInfo.Hash,
+		DcChainId: ibcTx.DcChainId,
+		DcChannel: ibcTx.DcChannel,
+		DcAddr:    ibcTx.DcAddr,
+		DcTxHash:  dto.DcTxInfo.Hash,
+		Sequence:  ibcTx.Sequence,
+		Denoms:    dto.Denoms,
+		TxTime:    ibcTx.TxTime,
+		EndTime:   dto.EndTime,
+	}
+	if ibcTx.ScTxInfo != nil && ibcTx.ScTxInfo.MsgAmount != nil {
+		hop.Amount = ibcTx.ScTxInfo.MsgAmount.Amount
+	}
+	if hop.EndTime > 0 {
+		hop.CostTime = hop.EndTime - hop.TxTime
+	}
+	return hop
+}
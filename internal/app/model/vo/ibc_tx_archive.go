@@ -0,0 +1,47 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	// ArchivePartitionResp the archived files of a partition
+	ArchivePartitionResp struct {
+		ChainId string                 `json:"chain_id"`
+		Month   string                 `json:"month"`
+		Txs     int64                  `json:"txs"`
+		Size    int64                  `json:"size"`
+		Status  string                 `json:"status"`
+		Files   []*entity.IBCTxArchive `json:"files"`
+	}
+
+	// ArchivedTxResp an archived ibc tx of the tx hash, Tx is read from the archived file
+	ArchivedTxResp struct {
+		RecordId string                   `json:"record_id"`
+		ChainId  string                   `json:"chain_id"`
+		Month    string                   `json:"month"`
+		Key      string                   `json:"key"`
+		Status   string                   `json:"status"`
+		Tx       *TranaferTxDetailNewResp `json:"tx"`
+	}
+)
+
+// LoadArchivePartitions group the files by the partition, the status of a partition is the status of its latest file
+func LoadArchivePartitions(files []*entity.IBCTxArchive) []ArchivePartitionResp {
+	var res []ArchivePartitionResp
+	index := make(map[string]int)
+	for _, v := range files {
+		key := v.ChainId + "/" + v.Month
+		i, ok := index[key]
+		if !ok {
+			i = len(res)
+			index[key] = i
+			res = append(res, ArchivePartitionResp{ChainId: v.ChainId, Month: v.Month})
+		}
+		res[i].Txs += v.Txs
+		res[i].Size += v.Size
+		res[i].Status = string(v.Status)
+		res[i].Files = append(res[i].Files, v)
+	}
+	return res
+}
@@ -1,10 +1,18 @@
 package vo
 
+import (
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type LcdCoin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
 type SupplyResp struct {
-	Supply []struct {
-		Denom  string `json:"denom"`
-		Amount string `json:"amount"`
-	} `json:"supply"`
+	Supply     []LcdCoin `json:"supply"`
 	Pagination struct {
 		NextKey *string `json:"next_key"`
 		Total   string  `json:"total"`
@@ -12,10 +20,7 @@ type SupplyResp struct {
 }
 
 type BalancesResp struct {
-	Balances []struct {
-		Denom  string `json:"denom"`
-		Amount string `json:"amount"`
-	} `json:"balances"`
+	Balances   []LcdCoin `json:"balances"`
 	Pagination struct {
 		NextKey *string `json:"next_key"`
 		Total   string  `json:"total"`
@@ -29,18 +34,7 @@ type StakeParams struct {
 }
 
 type IbcChannelsResp struct {
-	Channels []struct {
-		State        string `json:"state"`
-		Ordering     string `json:"ordering"`
-		Counterparty struct {
-			PortId    string `json:"port_id"`
-			ChannelId string `json:"channel_id"`
-		} `json:"counterparty"`
-		ConnectionHops []string `json:"connection_hops"`
-		Version        string   `json:"version"`
-		PortId         string   `json:"port_id"`
-		ChannelId      string   `json:"channel_id"`
-	} `json:"channels"`
+	Channels   []LcdChannel `json:"channels"`
 	Pagination struct {
 		NextKey *string `json:"next_key"`
 		Total   string  `json:"total"`
@@ -109,15 +103,20 @@ type Connections struct {
 
 type (
 	ConnectionChannels struct {
-		Channels []LcdChannel `json:"channels"`
+		Channels   []LcdChannel `json:"channels"`
+		Pagination struct {
+			NextKey *string `json:"next_key"`
+		} `json:"pagination"`
 	}
 	LcdChannel struct {
 		State        string `json:"state"`
+		Ordering     string `json:"ordering"`
 		Counterparty struct {
 			PortId    string `json:"port_id"`
 			ChannelId string `json:"channel_id"`
 		} `json:"counterparty"`
 		ConnectionHops []string `json:"connection_hops"`
+		Version        string   `json:"version"`
 		PortId         string   `json:"port_id"`
 		ChannelId      string   `json:"channel_id"`
 	}
@@ -147,6 +146,10 @@ type ChainRegisterResp struct {
 			Provider string `json:"provider"`
 		} `json:"grpc"`
 	} `json:"apis"`
+	LogoURIs struct {
+		Png string `json:"png"`
+		Svg string `json:"svg"`
+	} `json:"logo_URIs"`
 }
 
 type StatusResp struct {
@@ -166,3 +169,73 @@ type StatusResp struct {
 		} `json:"sync_info"`
 	} `json:"result"`
 }
+
+type NodeInfoResp struct {
+	DefaultNodeInfo struct {
+		Network string `json:"network"`
+		Version string `json:"version"`
+	} `json:"default_node_info"`
+}
+
+type AssetListResp struct {
+	ChainName string `json:"chain_name"`
+	Assets    []struct {
+		Base       string `json:"base"`
+		Name       string `json:"name"`
+		Display    string `json:"display"`
+		Symbol     string `json:"symbol"`
+		DenomUnits []struct {
+			Denom    string `json:"denom"`
+			Exponent int    `json:"exponent"`
+		} `json:"denom_units"`
+		LogoURIs struct {
+			Png string `json:"png"`
+			Svg string `json:"svg"`
+		} `json:"logo_URIs"`
+		CoingeckoId string `json:"coingecko_id"`
+	} `json:"assets"`
+}
+
+type (
+	LcdTxData struct {
+		TxResponse struct {
+			Logs []LcdTxLog `json:"logs"`
+			Tx   struct {
+				Body struct {
+					Messages []LcdMessage `json:"messages"`
+				} `json:"body"`
+			} `json:"tx"`
+			Timestamp time.Time `json:"timestamp"`
+		} `json:"tx_response"`
+	}
+	LcdTxLog struct {
+		MsgIndex int            `json:"msg_index"`
+		Log      string         `json:"log"`
+		Events   []entity.Event `json:"events"`
+	}
+	LcdMessage struct {
+		Type            string      `json:"@type"`
+		Packet          interface{} `json:"packet,omitempty"`
+		ProofCommitment string      `json:"proof_commitment,omitempty"`
+		ProofHeight     interface{} `json:"proof_height,omitempty"`
+		Signer          string      `json:"signer,omitempty"`
+
+		SourcePort       string      `json:"source_port,omitempty"`
+		SourceChannel    string      `json:"source_channel,omitempty"`
+		Token            interface{} `json:"token,omitempty"`
+		Sender           string      `json:"sender,omitempty"`
+		Receiver         string      `json:"receiver,omitempty"`
+		TimeoutHeight    interface{} `json:"timeout_height,omitempty"`
+		TimeoutTimestamp string      `json:"timeout_timestamp,omitempty"`
+
+		ProofUnreceived  string `json:"proof_unreceived,omitempty"`
+		NextSequenceRecv string `json:"next_sequence_recv,omitempty"`
+		Acknowledgement  string `json:"acknowledgement,omitempty"`
+		ProofAcked       string `json:"proof_acked,omitempty"`
+	}
+	LcdErrRespond struct {
+		Code    int           `json:"code"`
+		Message string        `json:"message"`
+		Details []interface{} `json:"details"`
+	}
+)
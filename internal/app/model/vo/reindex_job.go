@@ -0,0 +1,64 @@
+package vo
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+type (
+	// ReindexReq the range to be re-indexed, either the heights or the unix times
+	ReindexReq struct {
+		StartHeight int64 `json:"start_height"`
+		EndHeight   int64 `json:"end_height"`
+		StartTime   int64 `json:"start_time"`
+		EndTime     int64 `json:"end_time"`
+	}
+
+	ReindexJobResp struct {
+		JobId       string              `json:"job_id"`
+		ChainId     string              `json:"chain_id"`
+		StartHeight int64               `json:"start_height"`
+		EndHeight   int64               `json:"end_height"`
+		StartTime   int64               `json:"start_time"`
+		EndTime     int64               `json:"end_time"`
+		Status      string              `json:"status"`
+		Steps       []ReindexJobStepDto `json:"steps"`
+		CreateAt    int64               `json:"create_at"`
+		UpdateAt    int64               `json:"update_at"`
+	}
+	ReindexJobStepDto struct {
+		Name      string `json:"name"`
+		Status    string `json:"status"`
+		Message   string `json:"message"`
+		Done      int64  `json:"done"`
+		Total     int64  `json:"total"`
+		StartTime int64  `json:"start_time"`
+		EndTime   int64  `json:"end_time"`
+	}
+)
+
+func (dto ReindexJobResp) LoadDto(job *entity.IBCReindexJob) ReindexJobResp {
+	steps := make([]ReindexJobStepDto, 0, len(job.Steps))
+	for _, v := range job.Steps {
+		steps = append(steps, ReindexJobStepDto{
+			Name:      v.Name,
+			Status:    string(v.Status),
+			Message:   v.Message,
+			Done:      v.Done,
+			Total:     v.Total,
+			StartTime: v.StartTime,
+			EndTime:   v.EndTime,
+		})
+	}
+	return ReindexJobResp{
+		JobId:       job.JobId,
+		ChainId:     job.ChainId,
+		StartHeight: job.StartHeight,
+		EndHeight:   job.EndHeight,
+		StartTime:   job.StartTime,
+		EndTime:     job.EndTime,
+		Status:      string(job.Status),
+		Steps:       steps,
+		CreateAt:    job.CreateAt,
+		UpdateAt:    job.UpdateAt,
+	}
+}
@@ -0,0 +1,92 @@
+package vo
+
+import "time"
+
+// the results of the tendermint rpc, the int64 values are encoded as strings
+
+type (
+	RpcAbciQueryResult struct {
+		Response struct {
+			Code      uint32 `json:"code"`
+			Log       string `json:"log"`
+			Info      string `json:"info"`
+			Value     []byte `json:"value"`
+			Height    int64  `json:"height,string"`
+			Codespace string `json:"codespace"`
+		} `json:"response"`
+	}
+
+	RpcStatusResult struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+			Version string `json:"version"`
+		} `json:"node_info"`
+		SyncInfo struct {
+			LatestBlockHash     string    `json:"latest_block_hash"`
+			LatestBlockHeight   int64     `json:"latest_block_height,string"`
+			LatestBlockTime     time.Time `json:"latest_block_time"`
+			EarliestBlockHeight int64     `json:"earliest_block_height,string"`
+			CatchingUp          bool      `json:"catching_up"`
+		} `json:"sync_info"`
+	}
+
+	RpcBlockResult struct {
+		BlockId struct {
+			Hash string `json:"hash"`
+		} `json:"block_id"`
+		Block struct {
+			Header struct {
+				ChainId         string    `json:"chain_id"`
+				Height          int64     `json:"height,string"`
+				Time            time.Time `json:"time"`
+				ProposerAddress string    `json:"proposer_address"`
+				LastBlockId     struct {
+					Hash string `json:"hash"`
+				} `json:"last_block_id"`
+			} `json:"header"`
+			Data struct {
+				Txs [][]byte `json:"txs"`
+			} `json:"data"`
+		} `json:"block"`
+	}
+
+	RpcBlockResultsResult struct {
+		Height           int64         `json:"height,string"`
+		TxsResults       []RpcTxResult `json:"txs_results"`
+		BeginBlockEvents []RpcEvent    `json:"begin_block_events"`
+		EndBlockEvents   []RpcEvent    `json:"end_block_events"`
+	}
+
+	RpcTxSearchResult struct {
+		Txs        []RpcTx `json:"txs"`
+		TotalCount int     `json:"total_count,string"`
+	}
+
+	RpcTx struct {
+		Hash     string      `json:"hash"`
+		Height   int64       `json:"height,string"`
+		Index    uint32      `json:"index"`
+		TxResult RpcTxResult `json:"tx_result"`
+		Tx       []byte      `json:"tx"`
+	}
+
+	RpcTxResult struct {
+		Code      uint32     `json:"code"`
+		Log       string     `json:"log"`
+		Info      string     `json:"info"`
+		GasWanted int64      `json:"gas_wanted,string"`
+		GasUsed   int64      `json:"gas_used,string"`
+		Events    []RpcEvent `json:"events"`
+		Codespace string     `json:"codespace"`
+	}
+
+	// RpcEvent the keys and values of the attributes are base64 encoded before tendermint v0.35
+	RpcEvent struct {
+		Type       string `json:"type"`
+		Attributes []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+			Index bool   `json:"index"`
+		} `json:"attributes"`
+	}
+)
@@ -0,0 +1,85 @@
+package vo
+
+import "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+
+type TaskProgressResp struct {
+	TaskName string              `json:"task_name"`
+	Status   string              `json:"status"`
+	Total    int64               `json:"total"`
+	Done     int64               `json:"done"`
+	Percent  float64             `json:"percent"`
+	Workers  []TaskCheckpointDto `json:"workers"`
+}
+
+type TaskCheckpointDto struct {
+	Scope    string  `json:"scope"`
+	Worker   string  `json:"worker"`
+	Status   string  `json:"status"`
+	Total    int64   `json:"total"`
+	Done     int64   `json:"done"`
+	Percent  float64 `json:"percent"`
+	Cursor   int64   `json:"cursor"`
+	UpdateAt int64   `json:"update_at"`
+}
+
+func (dto TaskCheckpointDto) LoadDto(checkpoint *entity.IbcTaskCheckpoint) TaskCheckpointDto {
+	return TaskCheckpointDto{
+		Scope:    checkpoint.Scope,
+		Worker:   checkpoint.Worker,
+		Status:   string(checkpoint.Status),
+		Total:    checkpoint.Total,
+		Done:     checkpoint.Done,
+		Percent:  CalculatePercent(checkpoint.Done, checkpoint.Total),
+		Cursor:   checkpoint.Cursor,
+		UpdateAt: checkpoint.UpdateAt,
+	}
+}
+
+// CalculatePercent done/total in percentage, keep two decimal places
+func CalculatePercent(done, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	if done >= total {
+		return 100
+	}
+	return float64(done*10000/total) / 100
+}
+
+type TaskDryRunReportReq struct {
+	Page
+}
+
+type TaskDryRunReportResp struct {
+	TaskName     string                `json:"task_name"`
+	TotalRecords int64                 `json:"total_records"`
+	Fields       []TaskDryRunFieldDto  `json:"fields"`
+	Items        []TaskDryRunReportDto `json:"items"`
+	PageInfo     PageInfo              `json:"page_info"`
+}
+
+type TaskDryRunFieldDto struct {
+	Coll    string `json:"coll"`
+	Field   string `json:"field"`
+	Records int64  `json:"records"`
+}
+
+type TaskDryRunReportDto struct {
+	Coll     string      `json:"coll"`
+	RecordId string      `json:"record_id"`
+	Field    string      `json:"field"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+	CreateAt int64       `json:"create_at"`
+}
+
+func (dto TaskDryRunReportDto) LoadDto(report *entity.IbcTaskDryRunReport) TaskDryRunReportDto {
+	return TaskDryRunReportDto{
+		Coll:     report.Coll,
+		RecordId: report.RecordId,
+		Field:    report.Field,
+		OldValue: report.OldValue,
+		NewValue: report.NewValue,
+		CreateAt: report.CreateAt,
+	}
+}
@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"github.com/qiniu/qmgo"
+)
+
+const (
+	mgoPingTimeout             = 3
+	defaultCriticalTasks       = "ibc_sync_transfer_tx_task,ibc_tx_relate_task"
+	defaultTaskMaxDelaySeconds = 1800
+	defaultMaxSyncLagBlocks    = 1000
+)
+
+var (
+	taskRecordRepo  repository.ITaskRecordRepo = new(repository.TaskRecordRepo)
+	syncBlockRepo   repository.ISyncBlockRepo  = new(repository.SyncBlockRepo)
+	taskStatusCache cache.TaskStatusCacheRepo
+)
+
+// Liveness the process is able to serve requests, the dependencies are not checked
+func Liveness() vo.HealthResp {
+	return vo.HealthResp{
+		Status:  vo.HealthStatusOk,
+		Version: global.Config().App.Version,
+	}
+}
+
+// Readiness check mongo, redis, the last successful run of the critical tasks and the sync lag of the chains.
+// The returned bool is true only if all the checks pass.
+func Readiness() (vo.HealthResp, bool) {
+	checks := []vo.HealthCheck{
+		runHealthCheck("mongo", checkMongo),
+		runHealthCheck("redis", cache.RedisPing),
+		runHealthCheck("critical_tasks", checkCriticalTasks),
+		runHealthCheck("sync_lag", checkSyncLag),
+	}
+
+	resp := vo.HealthResp{
+		Status:  vo.HealthStatusOk,
+		Version: global.Config().App.Version,
+		Checks:  checks,
+	}
+	for _, v := range checks {
+		if v.Status != vo.HealthStatusOk {
+			resp.Status = vo.HealthStatusFail
+			return resp, false
+		}
+	}
+	return resp, true
+}
+
+func runHealthCheck(name string, check func() error) vo.HealthCheck {
+	startTime := time.Now()
+	err := check()
+	res := vo.HealthCheck{
+		Name:    name,
+		Status:  vo.HealthStatusOk,
+		Latency: time.Since(startTime).Milliseconds(),
+	}
+	if err != nil {
+		res.Status = vo.HealthStatusFail
+		res.Message = err.Error()
+	}
+	return res
+}
+
+func checkMongo() error {
+	return repository.MgoPing(mgoPingTimeout)
+}
+
+func checkCriticalTasks() error {
+	healthCfg := global.Config().Health
+	criticalTasks := healthCfg.CriticalTasks
+	if criticalTasks == "" {
+		criticalTasks = defaultCriticalTasks
+	}
+	maxDelay := healthCfg.TaskMaxDelaySeconds
+	if maxDelay == 0 {
+		maxDelay = defaultTaskMaxDelaySeconds
+	}
+
+	var delayed []string
+	now := time.Now().Unix()
+	for _, taskName := range strings.Split(criticalTasks, ",") {
+		taskName = strings.TrimSpace(taskName)
+		if taskName == "" {
+			continue
+		}
+		lastSuccess, err := taskStatusCache.GetLastSuccess(taskName)
+		if err != nil {
+			delayed = append(delayed, fmt.Sprintf("%s(never succeeded)", taskName))
+			continue
+		}
+		if now-lastSuccess > maxDelay {
+			delayed = append(delayed, fmt.Sprintf("%s(last success %ds ago)", taskName, now-lastSuccess))
+		}
+	}
+
+	if len(delayed) > 0 {
+		return fmt.Errorf("tasks delayed: %s", strings.Join(delayed, ", "))
+	}
+	return nil
+}
+
+func checkSyncLag() error {
+	maxLag := global.Config().Health.MaxSyncLagBlocks
+	if maxLag == 0 {
+		maxLag = defaultMaxSyncLagBlocks
+	}
+
+	chainCfgs, err := chainConfigRepo.FindAllOpenChainInfos()
+	if err != nil {
+		return err
+	}
+
+	var lagged []string
+	for _, v := range chainCfgs {
+		taskRecord, err := taskRecordRepo.FindByTaskName(fmt.Sprintf(entity.TaskNameFmt, v.ChainId))
+		if err != nil {
+			if err == qmgo.ErrNoSuchDocuments { // 新链还未开始同步
+				continue
+			}
+			return err
+		}
+		block, err := syncBlockRepo.FindLatestBlock(v.ChainId)
+		if err != nil {
+			if err == qmgo.ErrNoSuchDocuments {
+				continue
+			}
+			return err
+		}
+
+		if lag := block.Height - taskRecord.Height; lag > maxLag {
+			lagged = append(lagged, fmt.Sprintf("%s(%d blocks)", v.ChainId, lag))
+		}
+	}
+
+	if len(lagged) > 0 {
+		return fmt.Errorf("chains lagged: %s", strings.Join(lagged, ", "))
+	}
+	return nil
+}
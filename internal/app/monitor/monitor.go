@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -10,6 +11,8 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor/metrics"
+	lcdclient "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcdpool"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
@@ -21,19 +24,24 @@ var (
 	lcdConnectStatsMetric    metrics.Guage
 	redisStatusMetric        metrics.Guage
 	relayerStatusCheckMetric metrics.Guage
+	escrowMismatchMetric     metrics.Guage
+	largeTransferMetric      metrics.Counter
+	lcdEndpointScoreMetric   metrics.Guage
 	TagName                  = "taskname"
 	ChainTag                 = "chain_id"
 	relayerTag               = "relayer_id"
+	channelTag               = "channel_id"
+	denomTag                 = "denom"
+	reasonTag                = "reason"
+	lcdTag                   = "lcd"
 
 	chainConfigRepo   repository.IChainConfigRepo   = new(repository.ChainConfigRepo)
 	chainRegistryRepo repository.IChainRegistryRepo = new(repository.ChainRegistryRepo)
 	relayerRepo       repository.IRelayerRepo       = new(repository.IbcRelayerRepo)
+	lcdAddrCache      cache.LcdAddrCacheRepo
 )
 
 const (
-	v1beta1        = "v1beta1"
-	v1             = "v1"
-	v1Channels     = "/ibc/core/channel/v1/channels?pagination.limit=1"
 	apiChannels    = "/ibc/core/channel/%s/channels?pagination.offset=OFFSET&pagination.limit=LIMIT&pagination.count_total=true"
 	apiClientState = "/ibc/core/channel/%s/channels/CHANNEL/ports/PORT/client_state"
 )
@@ -91,6 +99,54 @@ func NewMetricRelayerStatusCheck() metrics.Guage {
 	return connectionStatus
 }
 
+func NewMetricEscrowMismatch() metrics.Guage {
+	escrowMismatchMetric := metrics.NewGuage(
+		"ibc_explorer_backend",
+		"escrow",
+		"supply_mismatch",
+		"ibc_explorer_backend escrowed amount diverges from the voucher supply of the counterparty chain (1:Mismatch  0:Normal)",
+		[]string{ChainTag, channelTag, denomTag},
+	)
+	escrowMismatch, _ := metrics.CovertGuage(escrowMismatchMetric)
+	return escrowMismatch
+}
+
+func SetEscrowMismatchMetricValue(chainId, channelId, denom string, value float64) {
+	if escrowMismatchMetric != nil {
+		escrowMismatchMetric.With(ChainTag, chainId, channelTag, channelId, denomTag, denom).Set(value)
+	}
+}
+
+func NewMetricLargeTransfer() metrics.Counter {
+	largeTransferMetric := metrics.NewCounter(
+		"ibc_explorer_backend",
+		"transfer",
+		"large_transfer_alerts",
+		"ibc_explorer_backend alerts of the large transfers sent from the chain",
+		[]string{ChainTag, denomTag, reasonTag},
+	)
+	largeTransfer, _ := metrics.CovertCounter(largeTransferMetric)
+	return largeTransfer
+}
+
+func AddLargeTransferMetricValue(chainId, baseDenom, reason string) {
+	if largeTransferMetric != nil {
+		largeTransferMetric.With(ChainTag, chainId, denomTag, baseDenom, reasonTag, reason).Add(1)
+	}
+}
+
+func NewMetricLcdEndpointScore() metrics.Guage {
+	lcdEndpointScoreMetric := metrics.NewGuage(
+		"ibc_explorer_backend",
+		"lcd",
+		"endpoint_score",
+		"ibc_explorer_backend score of the lcd endpoint by the latency, error rate and block height freshness (0:Circuit open)",
+		[]string{ChainTag, lcdTag},
+	)
+	lcdEndpointScore, _ := metrics.CovertGuage(lcdEndpointScoreMetric)
+	return lcdEndpointScore
+}
+
 func SetCronTaskStatusMetricValue(taskName string, value float64) {
 	if cronTaskStatusMetric != nil {
 		cronTaskStatusMetric.With(TagName, taskName).Set(value)
@@ -108,10 +164,11 @@ func lcdConnectionStatus(quit chan bool) {
 				return
 			}
 			for _, val := range chainCfgs {
-				if checkAndUpdateLcd(val.Lcd, val) {
+				pool := refreshLcdPool(val)
+				if pool.IsHealthy(val.Lcd) && checkAndUpdateLcd(val.Lcd, val) {
 					lcdConnectStatsMetric.With(ChainTag, val.ChainId).Set(float64(1))
 				} else {
-					if switchLcd(val) {
+					if switchPoolLcd(pool, val) || switchLcd(val) {
 						lcdConnectStatsMetric.With(ChainTag, val.ChainId).Set(float64(1))
 					} else {
 						lcdConnectStatsMetric.With(ChainTag, val.ChainId).Set(float64(-1))
@@ -128,6 +185,46 @@ func lcdConnectionStatus(quit chan bool) {
 	}
 }
 
+// refreshLcdPool add the lcds of the chain registry and the trace source lcds into the pool of the chain, then probe
+// all the endpoints of the pool and report their scores
+func refreshLcdPool(cf *entity.ChainConfig) *lcdpool.Pool {
+	pool := lcdpool.Of(cf.ChainId, cf.Lcd)
+	var lcds []string
+	if registry, err := chainRegistryRepo.FindOne(cf.ChainId); err == nil {
+		lcds = append(lcds, registry.Lcds...)
+	}
+	if traceSourceLcds, err := lcdAddrCache.Get(cf.ChainId); err == nil {
+		for _, v := range traceSourceLcds {
+			lcds = append(lcds, v.LcdAddr)
+		}
+	}
+	for _, v := range lcds {
+		if !utils.InArray(unbelievableLcd[cf.ChainId], v) {
+			pool.Add(v)
+		}
+	}
+
+	pool.Probe()
+	for _, v := range pool.Scores() {
+		lcdEndpointScoreMetric.With(ChainTag, v.ChainId, lcdTag, v.Endpoint).Set(v.Score)
+	}
+	return pool
+}
+
+// switchPoolLcd switch the lcd of the chain config to the best available endpoint of the pool
+func switchPoolLcd(pool *lcdpool.Pool, cf *entity.ChainConfig) bool {
+	for _, lcd := range pool.Available() {
+		if lcd == strings.TrimRight(cf.Lcd, "/") || !pool.IsHealthy(lcd) {
+			continue
+		}
+		if checkAndUpdateLcd(lcd, cf) {
+			logrus.Infof("monitor chain %s switch lcd to %s", cf.ChainId, lcd)
+			return true
+		}
+	}
+	return false
+}
+
 // checkAndUpdateLcd If lcd is ok, update db and return true. Else return false
 func checkAndUpdateLcd(lcd string, cf *entity.ChainConfig) bool {
 	unLcds, ex := unbelievableLcd[cf.ChainId]
@@ -135,19 +232,9 @@ func checkAndUpdateLcd(lcd string, cf *entity.ChainConfig) bool {
 		return false
 	}
 
-	var ok bool
-	var version string
-	if _, err := utils.HttpGet(fmt.Sprintf("%s%s", lcd, v1Channels)); err == nil {
-		ok = true
-		version = v1
-	} else if strings.Contains(err.Error(), "501 Not Implemented") {
-		ok = true
-		version = v1beta1
-	} else {
-		ok = false
-	}
-
-	if ok {
+	// the negotiated version is cached for the lcd clients of the chain
+	version, err := lcdclient.ForEndpoint(cf.ChainId, lcd).RefreshApiVersion(context.Background())
+	if err == nil {
 		if cf.Lcd == lcd && cf.LcdApiPath.ChannelsPath == fmt.Sprintf(apiChannels, version) && cf.LcdApiPath.ClientStatePath == fmt.Sprintf(apiClientState, version) {
 			return true
 		}
@@ -183,6 +270,7 @@ func switchLcd(chainConf *entity.ChainConfig) bool {
 	var chainRegisterResp vo.ChainRegisterResp
 	_ = json.Unmarshal(bz, &chainRegisterResp)
 	for _, v := range chainRegisterResp.Apis.Rest {
+		lcdpool.Of(chainConf.ChainId).Add(v.Address)
 		if ok := checkAndUpdateLcd(v.Address, chainConf); ok {
 			return true
 		}
@@ -274,6 +362,9 @@ func Start(port string) {
 	redisStatusMetric = NewMetricRedisStatus()
 	lcdConnectStatsMetric = NewMetricLcdStatus()
 	relayerStatusCheckMetric = NewMetricRelayerStatusCheck()
+	escrowMismatchMetric = NewMetricEscrowMismatch()
+	largeTransferMetric = NewMetricLargeTransfer()
+	lcdEndpointScoreMetric = NewMetricLcdEndpointScore()
 	server.Report(func() {
 		go redisClientStatus(quit)
 		go lcdConnectionStatus(quit)
@@ -0,0 +1,122 @@
+// Package datasource is the common interface of the chain data queried by the tasks. The data can be queried from the
+// lcd (default), the tendermint rpc or the cosmos grpc of the chain, which is selected by the data_source of
+// chain_config, so the chains whose public lcds are flaky or rate-limited can still be queried.
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
+)
+
+// DataSource the chain data queried by the tasks
+type DataSource interface {
+	Channels(ctx context.Context) ([]vo.LcdChannel, error)
+	ClientState(ctx context.Context, port, channel string) (*vo.ClientStateResp, error)
+	ClientConnections(ctx context.Context, clientId string) ([]string, error)
+	ConnectionChannels(ctx context.Context, connectionId string) ([]vo.LcdChannel, error)
+	Supply(ctx context.Context) ([]vo.LcdCoin, error)
+	Balances(ctx context.Context, address string) ([]vo.LcdCoin, error)
+	StakingParams(ctx context.Context) (*vo.StakeParams, error)
+}
+
+var (
+	_ DataSource = new(lcd.Client)
+	_ DataSource = new(RpcClient)
+	_ DataSource = new(GrpcClient)
+)
+
+// Of the data source of the chain, the lcd is used if the rpc or grpc address is not configured
+func Of(cf *entity.ChainConfig) DataSource {
+	switch cf.DataSource {
+	case entity.DataSourceRpc:
+		if cf.Rpc != "" {
+			return NewRpcClient(cf.ChainId, cf.Rpc)
+		}
+	case entity.DataSourceGrpc:
+		if cf.Grpc != "" {
+			return NewGrpcClient(cf.ChainId, cf.Grpc)
+		}
+	}
+	return lcd.ForChain(cf)
+}
+
+const (
+	defaultTimeout       = 30 * time.Second
+	defaultRetries       = 2
+	defaultRetryInterval = 500 * time.Millisecond
+)
+
+// Options of the rpc and grpc clients, the zero fields use the default values
+type Options struct {
+	Timeout       time.Duration // timeout of a call, including the retries
+	Retries       int           // max retries of a request
+	RetryInterval time.Duration // the interval before the nth retry is RetryInterval * 2^(n-1) plus a jitter
+}
+
+var options = Options{}.withDefault()
+
+func (o Options) withDefault() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+	if o.Retries <= 0 {
+		o.Retries = defaultRetries
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = defaultRetryInterval
+	}
+	return o
+}
+
+// SetOptions set the options of the clients, it should be called before the clients are used
+func SetOptions(opts Options) {
+	options = opts.withDefault()
+}
+
+// QueryError the query is rejected by the chain, e.g. the address is invalid, it's not retried
+type QueryError struct {
+	Method  string
+	Code    uint32
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query %s error, code: %d, message: %s", e.Method, e.Code, e.Message)
+}
+
+// withRetry call fn with the timeout, the errors other than QueryError are retried with jitter
+func withRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	var err error
+	for i := 0; i <= options.Retries; i++ {
+		if i > 0 {
+			interval := options.RetryInterval<<uint(i-1) + time.Duration(rand.Int63n(int64(options.RetryInterval)))
+			t := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			case <-t.C:
+			}
+		}
+
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if _, ok := err.(*QueryError); ok || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,310 @@
+package datasource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/pbcodec"
+)
+
+// chainHandler answer the grpc method with the decoded request, a QueryError is returned as the error code of the chain
+type chainHandler func(method string, req pbcodec.Message) (pbcodec.Encoder, error)
+
+func testChain(method string, req pbcodec.Message) (pbcodec.Encoder, error) {
+	channel := func(id string) pbcodec.Encoder {
+		counterparty := pbcodec.Encoder{}.String(1, "transfer").String(2, "channel-9")
+		return pbcodec.Encoder{}.Uint64(1, 3).Uint64(2, 1).Message(3, counterparty).String(4, "connection-0").
+			String(5, "ics20-1").String(6, "transfer").String(7, id)
+	}
+	duration := func(seconds uint64) pbcodec.Encoder {
+		return pbcodec.Encoder{}.Uint64(1, seconds)
+	}
+
+	switch method {
+	case methodChannels:
+		page, _ := req.Message(1)
+		if len(page.Bytes(1)) == 0 {
+			return pbcodec.Encoder{}.Message(1, channel("channel-0")).Message(1, channel("channel-1")).
+				Message(2, pbcodec.Encoder{}.Bytes(1, []byte("/channel-1"))), nil
+		}
+		return pbcodec.Encoder{}.Message(1, channel("channel-2")).Message(2, nil), nil
+	case methodChannelClientState:
+		height := pbcodec.Encoder{}.Uint64(1, 4).Uint64(2, 100)
+		cs := pbcodec.Encoder{}.String(1, "osmosis-1").Message(2, pbcodec.Encoder{}.Uint64(1, 1).Uint64(2, 3)).
+			Message(3, duration(1209600)).Message(4, duration(1814400)).
+			Message(5, pbcodec.Encoder{}.Uint64(1, 20).Uint64(2, 500000000)).Message(7, height)
+		clientState := pbcodec.Encoder{}.String(1, typeTendermintClientState).Bytes(2, cs)
+		return pbcodec.Encoder{}.Message(1, pbcodec.Encoder{}.String(1, "07-tendermint-"+req.String(2)).Message(2, clientState)).
+			Message(3, height), nil
+	case methodTotalSupply:
+		return pbcodec.Encoder{}.Message(1, pbcodec.Encoder{}.String(1, "uatom").String(2, "1000")), nil
+	case methodAllBalances:
+		if !strings.HasPrefix(req.String(1), "cosmos1") {
+			return nil, &QueryError{Code: 3, Message: "invalid address"}
+		}
+		return pbcodec.Encoder{}.Message(1, pbcodec.Encoder{}.String(1, "uatom").String(2, "7")), nil
+	case methodStakingParams:
+		return pbcodec.Encoder{}.Message(1, pbcodec.Encoder{}.Message(1, duration(1814400))), nil
+	}
+	return nil, &QueryError{Code: 12, Message: "unknown method " + method}
+}
+
+func newRpcServer(handler chainHandler) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, _ := strconv.Unquote(r.URL.Query().Get("path"))
+		data, _ := hex.DecodeString(strings.TrimPrefix(r.URL.Query().Get("data"), "0x"))
+		req, _ := pbcodec.Decode(data)
+		resp, err := handler(path, req)
+
+		var code uint32
+		var log string
+		var queryErr *QueryError
+		if errors.As(err, &queryErr) {
+			code, log = queryErr.Code, queryErr.Message
+		}
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":-1,"result":{"response":{"code":%d,"log":%q,"value":%q,"height":"100"}}}`,
+			code, log, base64.StdEncoding.EncodeToString(resp))
+	}))
+}
+
+func newGrpcServer(handler chainHandler) (*httptest.Server, *GrpcClient) {
+	return startGrpcServer(func(w http.ResponseWriter, r *http.Request) {
+		bz, _ := ioutil.ReadAll(r.Body)
+		req, _ := pbcodec.Decode(bz[5:])
+		resp, err := handler(r.URL.Path, req)
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		var queryErr *QueryError
+		if errors.As(err, &queryErr) {
+			w.Header().Set("Grpc-Status", strconv.Itoa(int(queryErr.Code)))
+			w.Header().Set("Grpc-Message", queryErr.Message)
+			return
+		}
+		frame := make([]byte, 5+len(resp))
+		binary.BigEndian.PutUint32(frame[1:5], uint32(len(resp)))
+		copy(frame[5:], resp)
+		_, _ = w.Write(frame)
+		w.Header().Set("Grpc-Status", "0")
+	})
+}
+
+// startGrpcServer start the http2 server of the handler and the grpc client of it
+func startGrpcServer(handler http.HandlerFunc) (*httptest.Server, *GrpcClient) {
+	server := httptest.NewUnstartedServer(handler)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+
+	client := NewGrpcClient("test_grpc", server.URL)
+	client.client = server.Client()
+	return server, client
+}
+
+func TestRpcClient(t *testing.T) {
+	server := newRpcServer(testChain)
+	defer server.Close()
+	testDataSource(t, NewRpcClient("test_rpc", server.URL))
+}
+
+func TestGrpcClient(t *testing.T) {
+	server, client := newGrpcServer(testChain)
+	defer server.Close()
+	testDataSource(t, client)
+}
+
+func testDataSource(t *testing.T, ds DataSource) {
+	ctx := context.Background()
+	channels, err := ds.Channels(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 3 || channels[2].ChannelId != "channel-2" || channels[0].State != "STATE_OPEN" ||
+		channels[0].Ordering != "ORDER_UNORDERED" || channels[0].Counterparty.ChannelId != "channel-9" {
+		t.Fatalf("unexpected channels %+v", channels)
+	}
+
+	state, err := ds.ClientState(ctx, "transfer", "channel-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := state.IdentifiedClientState.ClientState
+	if state.IdentifiedClientState.ClientId != "07-tendermint-channel-0" || cs.ChainId != "osmosis-1" ||
+		cs.TrustingPeriod != "1209600s" || cs.MaxClockDrift != "20.5s" || cs.TrustLevel.Denominator != "3" ||
+		cs.LatestHeight.RevisionHeight != "100" {
+		t.Fatalf("unexpected client state %+v", state)
+	}
+
+	supply, err := ds.Supply(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(supply) != 1 || supply[0].Amount != "1000" {
+		t.Fatalf("unexpected supply %+v", supply)
+	}
+
+	params, err := ds.StakingParams(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Params.UnbondingTime != "1814400s" {
+		t.Fatalf("unexpected params %+v", params)
+	}
+
+	balances, err := ds.Balances(ctx, "cosmos1escrow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 || balances[0].Amount != "7" {
+		t.Fatalf("unexpected balances %+v", balances)
+	}
+
+	// the query errors are not retried
+	start := time.Now()
+	_, err = ds.Balances(ctx, "osmo1escrow")
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) || queryErr.Code != 3 || queryErr.Message != "invalid address" {
+		t.Fatalf("expect the query error, got %v", err)
+	}
+	if time.Since(start) >= defaultRetryInterval {
+		t.Fatal("the query error must not be retried")
+	}
+}
+
+func TestGrpcClient_Unavailable(t *testing.T) {
+	SetOptions(Options{Retries: 2, RetryInterval: time.Millisecond})
+	defer SetOptions(Options{})
+
+	calls := 0
+	server, client := newGrpcServer(func(method string, req pbcodec.Message) (pbcodec.Encoder, error) {
+		calls++
+		if calls <= 2 {
+			return nil, &QueryError{Code: grpcStatusUnavailable, Message: "connection refused"}
+		}
+		return testChain(method, req)
+	})
+	defer server.Close()
+
+	if _, err := client.StakingParams(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expect 3 calls, got %d", calls)
+	}
+}
+
+func TestGrpcClient_Status(t *testing.T) {
+	SetOptions(Options{Retries: 1, RetryInterval: time.Millisecond})
+	defer SetOptions(Options{})
+
+	for _, c := range []struct {
+		code  uint32
+		calls int
+	}{
+		{grpcStatusDeadlineExceeded, 2},
+		{grpcStatusResourceExhausted, 2},
+		{grpcStatusAborted, 2},
+		{grpcStatusPermissionDenied, 1},
+		{grpcStatusInternal, 1},
+	} {
+		calls := 0
+		server, client := newGrpcServer(func(method string, req pbcodec.Message) (pbcodec.Encoder, error) {
+			calls++
+			return nil, &QueryError{Code: c.code, Message: "failed"}
+		})
+		_, err := client.StakingParams(context.Background())
+		server.Close()
+
+		var queryErr *QueryError
+		if err == nil || errors.As(err, &queryErr) != (c.calls == 1) {
+			t.Fatalf("status %d: unexpected error %v", c.code, err)
+		}
+		if calls != c.calls {
+			t.Fatalf("status %d: expect %d calls, got %d", c.code, c.calls, calls)
+		}
+	}
+}
+
+func TestGrpcClient_HttpStatus(t *testing.T) {
+	SetOptions(Options{Retries: 1, RetryInterval: time.Millisecond})
+	defer SetOptions(Options{})
+
+	calls := 0
+	server, client := startGrpcServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	_, err := client.StakingParams(context.Background())
+	var queryErr *QueryError
+	if !errors.As(err, &queryErr) || queryErr.Code != grpcStatusUnimplemented {
+		t.Fatalf("expect the unimplemented error, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expect 2 calls, got %d", calls)
+	}
+}
+
+func TestGrpcClient_Gzip(t *testing.T) {
+	server, client := startGrpcServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Grpc-Timeout") == "" || !strings.Contains(r.Header.Get("Grpc-Accept-Encoding"), "gzip") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write(pbcodec.Encoder{}.Message(1, pbcodec.Encoder{}.Message(1, pbcodec.Encoder{}.Uint64(1, 1814400))))
+		_ = gz.Close()
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Encoding", "gzip")
+		w.Header().Set("Trailer", "Grpc-Status")
+		frame := make([]byte, 5+buf.Len())
+		frame[0] = 1
+		binary.BigEndian.PutUint32(frame[1:5], uint32(buf.Len()))
+		copy(frame[5:], buf.Bytes())
+		_, _ = w.Write(frame)
+		w.Header().Set("Grpc-Status", "0")
+	})
+	defer server.Close()
+
+	params, err := client.StakingParams(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Params.UnbondingTime != "1814400s" {
+		t.Fatalf("unexpected params %+v", params)
+	}
+}
+
+func TestEncodeGrpcTimeout(t *testing.T) {
+	for d, expect := range map[time.Duration]string{
+		-time.Second:             "1n",
+		1500 * time.Microsecond:  "2m",
+		30 * time.Second:         "30000m",
+		100000 * time.Second:     "100000S",
+		200000000 * time.Second:  "3333334M",
+		time.Duration(1<<63 - 1): "2562048H",
+	} {
+		if got := encodeGrpcTimeout(d); got != expect {
+			t.Fatalf("%v: expect %s, got %s", d, expect, got)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package datasource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// the grpc status codes, see https://github.com/grpc/grpc/blob/master/doc/statuscodes.md
+const (
+	grpcStatusOk                = 0
+	grpcStatusUnknown           = 2
+	grpcStatusDeadlineExceeded  = 4
+	grpcStatusPermissionDenied  = 7
+	grpcStatusResourceExhausted = 8
+	grpcStatusAborted           = 10
+	grpcStatusUnimplemented     = 12
+	grpcStatusInternal          = 13
+	grpcStatusUnavailable       = 14
+	grpcStatusUnauthenticated   = 16
+)
+
+const (
+	// grpcMaxRecvSize the max size of a response message
+	grpcMaxRecvSize = 64 << 20
+	grpcDialTimeout = 10 * time.Second
+	// grpcKeepaliveTime a ping is sent if nothing is received on the connection for it, the connection is closed if
+	// the ping is not answered in grpcKeepaliveTimeout
+	grpcKeepaliveTime    = 30 * time.Second
+	grpcKeepaliveTimeout = 10 * time.Second
+)
+
+var (
+	// h2cClient the plaintext http2 client of the grpc endpoints without tls
+	h2cClient = &http.Client{Transport: newGrpcTransport(false)}
+	h2Client  = &http.Client{Transport: newGrpcTransport(true)}
+
+	// grpcRetryableStatus the status codes of the transient errors, they are retried. The others are returned as
+	// QueryError.
+	grpcRetryableStatus = map[int]bool{
+		grpcStatusDeadlineExceeded:  true,
+		grpcStatusResourceExhausted: true,
+		grpcStatusAborted:           true,
+		grpcStatusUnavailable:       true,
+	}
+)
+
+// newGrpcTransport the http2 transport of the grpc endpoints, the connections are kept alive by the http2 pings
+func newGrpcTransport(useTLS bool) *http2.Transport {
+	dialer := &net.Dialer{Timeout: grpcDialTimeout, KeepAlive: grpcKeepaliveTime}
+	transport := &http2.Transport{
+		TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+		ReadIdleTimeout: grpcKeepaliveTime,
+		PingTimeout:     grpcKeepaliveTimeout,
+	}
+	if useTLS {
+		transport.DialTLS = func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return tls.DialWithDialer(dialer, network, addr, cfg)
+		}
+	} else {
+		transport.AllowHTTP = true
+		transport.DialTLS = func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+			return dialer.Dial(network, addr)
+		}
+	}
+	return transport
+}
+
+// GrpcClient the client of the cosmos grpc, the messages are encoded by pbcodec instead of the generated grpc clients
+type GrpcClient struct {
+	protoSource
+	addr   string
+	client *http.Client
+}
+
+// NewGrpcClient the addr is host:port or with the scheme. The https scheme or port 443 uses tls, the others use
+// plaintext http2.
+func NewGrpcClient(chainId, addr string) *GrpcClient {
+	addr = strings.TrimRight(addr, "/")
+	client := h2cClient
+	switch {
+	case strings.HasPrefix(addr, "https://"):
+		client = h2Client
+	case strings.HasPrefix(addr, "http://"):
+	case strings.HasSuffix(addr, ":443"):
+		addr = "https://" + addr
+		client = h2Client
+	default:
+		addr = "http://" + addr
+	}
+
+	c := &GrpcClient{addr: addr, client: client}
+	c.protoSource = protoSource{chainId: chainId, querier: c}
+	return c
+}
+
+// query call the unary method. The deadline of ctx is sent as grpc-timeout, the response may be compressed by gzip.
+func (c *GrpcClient) query(ctx context.Context, method string, req []byte) ([]byte, error) {
+	// the message is framed by the compressed flag and the length
+	frame := make([]byte, 5+len(req))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(req)))
+	copy(frame[5:], req)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+method, bytes.NewReader(frame))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	httpReq.Header.Set("TE", "trailers")
+	httpReq.Header.Set("Grpc-Accept-Encoding", "gzip")
+	if deadline, ok := ctx.Deadline(); ok {
+		httpReq.Header.Set("Grpc-Timeout", encodeGrpcTimeout(time.Until(deadline)))
+	}
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	bz, err := ioutil.ReadAll(io.LimitReader(resp.Body, grpcMaxRecvSize+6))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, grpcStatusError(method, httpStatusToGrpc(resp.StatusCode),
+			fmt.Sprintf("StatusCode(%d %s) != 200, url: %s%s", resp.StatusCode, http.StatusText(resp.StatusCode), c.addr, method))
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/grpc") {
+		return nil, grpcStatusError(method, grpcStatusUnknown, fmt.Sprintf("invalid content type %q", contentType))
+	}
+
+	// the status is in the trailers, or in the headers if there is no message
+	status, message := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil {
+		return nil, fmt.Errorf("grpc %s invalid status %q", method, status)
+	}
+	if code != grpcStatusOk {
+		message, _ = url.PathUnescape(message)
+		return nil, grpcStatusError(method, code, message)
+	}
+
+	if len(bz) < 5 {
+		return nil, fmt.Errorf("grpc %s invalid response length %d", method, len(bz))
+	}
+	length := binary.BigEndian.Uint32(bz[1:5])
+	if length > grpcMaxRecvSize {
+		return nil, fmt.Errorf("grpc %s response of %d bytes exceeds the max %d", method, length, grpcMaxRecvSize)
+	}
+	if int(length) > len(bz)-5 {
+		return nil, fmt.Errorf("grpc %s invalid response length %d", method, length)
+	}
+	msg := bz[5 : 5+length]
+	if bz[0] == 0 {
+		return msg, nil
+	}
+	if encoding := resp.Header.Get("Grpc-Encoding"); encoding != "gzip" {
+		return nil, fmt.Errorf("grpc %s compressed response of %q is not supported", method, encoding)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(io.LimitReader(gz, grpcMaxRecvSize))
+}
+
+// grpcStatusError the transient errors are retried, the others are returned as QueryError
+func grpcStatusError(method string, code int, message string) error {
+	if grpcRetryableStatus[code] {
+		return fmt.Errorf("grpc %s status %d, %s", method, code, message)
+	}
+	return &QueryError{Method: method, Code: uint32(code), Message: message}
+}
+
+// httpStatusToGrpc the grpc status of the http status of a response without grpc status, see
+// https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md
+func httpStatusToGrpc(status int) int {
+	switch status {
+	case http.StatusBadRequest:
+		return grpcStatusInternal
+	case http.StatusUnauthorized:
+		return grpcStatusUnauthenticated
+	case http.StatusForbidden:
+		return grpcStatusPermissionDenied
+	case http.StatusNotFound:
+		return grpcStatusUnimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return grpcStatusUnavailable
+	}
+	return grpcStatusUnknown
+}
+
+// encodeGrpcTimeout the grpc-timeout header of the duration, it's at most 8 digits with the unit
+func encodeGrpcTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "1n"
+	}
+	const maxValue = 99999999
+	for _, v := range []struct {
+		unit     time.Duration
+		notation string
+	}{
+		{time.Millisecond, "m"},
+		{time.Second, "S"},
+		{time.Minute, "M"},
+		{time.Hour, "H"},
+	} {
+		// round up, so that the server doesn't time out before the client
+		value := d / v.unit
+		if d%v.unit != 0 {
+			value++
+		}
+		if value <= maxValue {
+			return fmt.Sprintf("%d%s", value, v.notation)
+		}
+	}
+	return fmt.Sprintf("%dH", maxValue)
+}
@@ -0,0 +1,276 @@
+package datasource
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/pbcodec"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// the grpc methods, they are also the paths of the abci_query of rpc
+const (
+	methodChannels           = "/ibc.core.channel.v1.Query/Channels"
+	methodChannelClientState = "/ibc.core.channel.v1.Query/ChannelClientState"
+	methodConnectionChannels = "/ibc.core.channel.v1.Query/ConnectionChannels"
+	methodClientConnections  = "/ibc.core.connection.v1.Query/ClientConnections"
+	methodTotalSupply        = "/cosmos.bank.v1beta1.Query/TotalSupply"
+	methodAllBalances        = "/cosmos.bank.v1beta1.Query/AllBalances"
+	methodStakingParams      = "/cosmos.staking.v1beta1.Query/Params"
+
+	typeTendermintClientState = "/ibc.lightclients.tendermint.v1.ClientState"
+
+	pageLimit = 500
+)
+
+var (
+	channelStates = map[uint64]string{
+		0: "STATE_UNINITIALIZED_UNSPECIFIED",
+		1: "STATE_INIT",
+		2: "STATE_TRYOPEN",
+		3: "STATE_OPEN",
+		4: "STATE_CLOSED",
+	}
+	channelOrders = map[uint64]string{
+		0: "ORDER_NONE_UNSPECIFIED",
+		1: "ORDER_UNORDERED",
+		2: "ORDER_ORDERED",
+	}
+)
+
+// querier query the grpc method with the encoded request, and return the encoded response
+type querier interface {
+	query(ctx context.Context, method string, req []byte) ([]byte, error)
+}
+
+// protoSource the DataSource querying the grpc methods of the chain, the requests and responses are protobuf encoded
+type protoSource struct {
+	chainId string
+	querier querier
+}
+
+func (s *protoSource) ChainId() string {
+	return s.chainId
+}
+
+func (s *protoSource) call(ctx context.Context, method string, req pbcodec.Encoder) (pbcodec.Message, error) {
+	var bz []byte
+	err := withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		bz, err = s.querier.query(ctx, method, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pbcodec.Decode(bz)
+}
+
+// list query all the pages of the method. The PageRequest is the paginationField of the request, decode returns the
+// PageResponse of the response.
+func (s *protoSource) list(ctx context.Context, method string, req pbcodec.Encoder, paginationField protowire.Number,
+	decode func(resp pbcodec.Message) (pagination pbcodec.Message, err error)) error {
+	var key []byte
+	for {
+		page := pbcodec.Encoder{}.Bytes(1, key).Uint64(3, pageLimit)
+		pageReq := append(pbcodec.Encoder{}, req...).Message(paginationField, page)
+		resp, err := s.call(ctx, method, pageReq)
+		if err != nil {
+			return err
+		}
+		pagination, err := decode(resp)
+		if err != nil {
+			return err
+		}
+		key = pagination.Bytes(1)
+		if len(key) == 0 {
+			return nil
+		}
+	}
+}
+
+func (s *protoSource) Channels(ctx context.Context) ([]vo.LcdChannel, error) {
+	var res []vo.LcdChannel
+	err := s.list(ctx, methodChannels, nil, 1, func(resp pbcodec.Message) (pbcodec.Message, error) {
+		channels, err := decodeChannels(resp)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, channels...)
+		return resp.Message(2)
+	})
+	return res, err
+}
+
+func (s *protoSource) ClientState(ctx context.Context, port, channel string) (*vo.ClientStateResp, error) {
+	resp, err := s.call(ctx, methodChannelClientState, pbcodec.Encoder{}.String(1, port).String(2, channel))
+	if err != nil {
+		return nil, err
+	}
+
+	identified, err := resp.Message(1)
+	if err != nil {
+		return nil, err
+	}
+	var res vo.ClientStateResp
+	res.IdentifiedClientState.ClientId = identified.String(1)
+	typeUrl, value, err := identified.Any(2)
+	if err != nil {
+		return nil, err
+	}
+	state := &res.IdentifiedClientState.ClientState
+	state.Type = typeUrl
+	if typeUrl == typeTendermintClientState {
+		cs, err := pbcodec.Decode(value)
+		if err != nil {
+			return nil, err
+		}
+		state.ChainId = cs.String(1)
+		trustLevel, err := cs.Message(2)
+		if err != nil {
+			return nil, err
+		}
+		state.TrustLevel.Numerator = strconv.FormatUint(trustLevel.Uint64(1), 10)
+		state.TrustLevel.Denominator = strconv.FormatUint(trustLevel.Uint64(2), 10)
+		if state.TrustingPeriod, err = cs.Duration(3); err != nil {
+			return nil, err
+		}
+		if state.UnbondingPeriod, err = cs.Duration(4); err != nil {
+			return nil, err
+		}
+		if state.MaxClockDrift, err = cs.Duration(5); err != nil {
+			return nil, err
+		}
+		frozenHeight, err := cs.Message(6)
+		if err != nil {
+			return nil, err
+		}
+		state.FrozenHeight.RevisionNumber = strconv.FormatUint(frozenHeight.Uint64(1), 10)
+		state.FrozenHeight.RevisionHeight = strconv.FormatUint(frozenHeight.Uint64(2), 10)
+		latestHeight, err := cs.Message(7)
+		if err != nil {
+			return nil, err
+		}
+		state.LatestHeight.RevisionNumber = strconv.FormatUint(latestHeight.Uint64(1), 10)
+		state.LatestHeight.RevisionHeight = strconv.FormatUint(latestHeight.Uint64(2), 10)
+		state.UpgradePath = cs.Strings(9)
+		state.AllowUpdateAfterExpiry = cs.Bool(10)
+		state.AllowUpdateAfterMisbehaviour = cs.Bool(11)
+	}
+
+	proofHeight, err := resp.Message(3)
+	if err != nil {
+		return nil, err
+	}
+	res.ProofHeight.RevisionNumber = strconv.FormatUint(proofHeight.Uint64(1), 10)
+	res.ProofHeight.RevisionHeight = strconv.FormatUint(proofHeight.Uint64(2), 10)
+	return &res, nil
+}
+
+func (s *protoSource) ClientConnections(ctx context.Context, clientId string) ([]string, error) {
+	resp, err := s.call(ctx, methodClientConnections, pbcodec.Encoder{}.String(1, clientId))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Strings(1), nil
+}
+
+func (s *protoSource) ConnectionChannels(ctx context.Context, connectionId string) ([]vo.LcdChannel, error) {
+	var res []vo.LcdChannel
+	req := pbcodec.Encoder{}.String(1, connectionId)
+	err := s.list(ctx, methodConnectionChannels, req, 2, func(resp pbcodec.Message) (pbcodec.Message, error) {
+		channels, err := decodeChannels(resp)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, channels...)
+		return resp.Message(2)
+	})
+	return res, err
+}
+
+func (s *protoSource) Supply(ctx context.Context) ([]vo.LcdCoin, error) {
+	var res []vo.LcdCoin
+	err := s.list(ctx, methodTotalSupply, nil, 1, func(resp pbcodec.Message) (pbcodec.Message, error) {
+		coins, err := decodeCoins(resp)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, coins...)
+		return resp.Message(2)
+	})
+	return res, err
+}
+
+func (s *protoSource) Balances(ctx context.Context, address string) ([]vo.LcdCoin, error) {
+	var res []vo.LcdCoin
+	req := pbcodec.Encoder{}.String(1, address)
+	err := s.list(ctx, methodAllBalances, req, 2, func(resp pbcodec.Message) (pbcodec.Message, error) {
+		coins, err := decodeCoins(resp)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, coins...)
+		return resp.Message(2)
+	})
+	return res, err
+}
+
+func (s *protoSource) StakingParams(ctx context.Context) (*vo.StakeParams, error) {
+	resp, err := s.call(ctx, methodStakingParams, nil)
+	if err != nil {
+		return nil, err
+	}
+	params, err := resp.Message(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var res vo.StakeParams
+	if res.Params.UnbondingTime, err = params.Duration(1); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// decodeChannels the repeated IdentifiedChannel of field 1
+func decodeChannels(resp pbcodec.Message) ([]vo.LcdChannel, error) {
+	channels, err := resp.Messages(1)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]vo.LcdChannel, 0, len(channels))
+	for _, v := range channels {
+		counterparty, err := v.Message(3)
+		if err != nil {
+			return nil, err
+		}
+		var channel vo.LcdChannel
+		channel.State = channelStates[v.Uint64(1)]
+		channel.Ordering = channelOrders[v.Uint64(2)]
+		channel.Counterparty.PortId = counterparty.String(1)
+		channel.Counterparty.ChannelId = counterparty.String(2)
+		channel.ConnectionHops = v.Strings(4)
+		channel.Version = v.String(5)
+		channel.PortId = v.String(6)
+		channel.ChannelId = v.String(7)
+		res = append(res, channel)
+	}
+	return res, nil
+}
+
+// decodeCoins the repeated Coin of field 1
+func decodeCoins(resp pbcodec.Message) ([]vo.LcdCoin, error) {
+	coins, err := resp.Messages(1)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]vo.LcdCoin, 0, len(coins))
+	for _, v := range coins {
+		res = append(res, vo.LcdCoin{Denom: v.String(1), Amount: v.String(2)})
+	}
+	return res, nil
+}
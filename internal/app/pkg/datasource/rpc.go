@@ -0,0 +1,141 @@
+package datasource
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+var httpClient = &http.Client{}
+
+// RpcClient the client of the tendermint rpc, the chain data is queried by the abci_query of the grpc methods
+type RpcClient struct {
+	protoSource
+	addr string
+}
+
+func NewRpcClient(chainId, addr string) *RpcClient {
+	c := &RpcClient{addr: strings.TrimRight(addr, "/")}
+	c.protoSource = protoSource{chainId: chainId, querier: c}
+	return c
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// call the rpc method by the uri over http, the string params must be quoted
+func (c *RpcClient) call(ctx context.Context, method string, params url.Values, res interface{}) error {
+	uri := fmt.Sprintf("%s/%s", c.addr, method)
+	if len(params) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, params.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	bz, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var rpcResp rpcResponse
+	if err = json.Unmarshal(bz, &rpcResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("StatusCode(%d %s) != 200, url: %s", resp.StatusCode, http.StatusText(resp.StatusCode), uri)
+		}
+		return err
+	}
+	if rpcResp.Error != nil {
+		// the invalid params are rejected, the internal errors may be transient
+		message := strings.TrimSpace(fmt.Sprintf("%s %s", rpcResp.Error.Message, rpcResp.Error.Data))
+		if rpcResp.Error.Code == -32602 || strings.Contains(rpcResp.Error.Data, "not found") {
+			return &QueryError{Method: method, Code: uint32(-rpcResp.Error.Code), Message: message}
+		}
+		return fmt.Errorf("rpc %s error, code: %d, message: %s", method, rpcResp.Error.Code, message)
+	}
+	return json.Unmarshal(rpcResp.Result, res)
+}
+
+func (c *RpcClient) query(ctx context.Context, method string, req []byte) ([]byte, error) {
+	params := url.Values{}
+	params.Set("path", strconv.Quote(method))
+	params.Set("data", "0x"+hex.EncodeToString(req))
+
+	var res vo.RpcAbciQueryResult
+	if err := c.call(ctx, "abci_query", params, &res); err != nil {
+		return nil, err
+	}
+	if res.Response.Code != 0 {
+		return nil, &QueryError{Method: method, Code: res.Response.Code, Message: res.Response.Log}
+	}
+	return res.Response.Value, nil
+}
+
+// Status the node info and the sync info of the node
+func (c *RpcClient) Status(ctx context.Context) (*vo.RpcStatusResult, error) {
+	var res vo.RpcStatusResult
+	err := withRetry(ctx, func(ctx context.Context) error {
+		return c.call(ctx, "status", nil, &res)
+	})
+	return &res, err
+}
+
+// Block the block of the height, the latest block if the height is 0
+func (c *RpcClient) Block(ctx context.Context, height int64) (*vo.RpcBlockResult, error) {
+	var res vo.RpcBlockResult
+	err := withRetry(ctx, func(ctx context.Context) error {
+		return c.call(ctx, "block", heightParams(height), &res)
+	})
+	return &res, err
+}
+
+// BlockResults the results of the txs and the begin/end block events of the height
+func (c *RpcClient) BlockResults(ctx context.Context, height int64) (*vo.RpcBlockResultsResult, error) {
+	var res vo.RpcBlockResultsResult
+	err := withRetry(ctx, func(ctx context.Context) error {
+		return c.call(ctx, "block_results", heightParams(height), &res)
+	})
+	return &res, err
+}
+
+// TxSearch search the txs by the event query, e.g. tx.height=100, the page starts from 1
+func (c *RpcClient) TxSearch(ctx context.Context, query string, page, perPage int) (*vo.RpcTxSearchResult, error) {
+	params := url.Values{}
+	params.Set("query", strconv.Quote(query))
+	params.Set("page", strconv.Itoa(page))
+	params.Set("per_page", strconv.Itoa(perPage))
+	params.Set("order_by", strconv.Quote("asc"))
+
+	var res vo.RpcTxSearchResult
+	err := withRetry(ctx, func(ctx context.Context) error {
+		return c.call(ctx, "tx_search", params, &res)
+	})
+	return &res, err
+}
+
+func heightParams(height int64) url.Values {
+	params := url.Values{}
+	if height > 0 {
+		params.Set("height", strconv.FormatInt(height, 10))
+	}
+	return params
+}
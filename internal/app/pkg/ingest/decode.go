@@ -0,0 +1,472 @@
+// Package ingest decodes the blocks and txs queried from the tendermint rpc into the sync_<chain>_block and
+// sync_<chain>_tx schema of the sync service, so the explorer can index a chain without deploying the sync service.
+// Only the txs containing the ibc msgs or the packet events are kept, and only the fields read by the explorer are
+// decoded.
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/pbcodec"
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	typeUrlTransfer           = "/ibc.applications.transfer.v1.MsgTransfer"
+	typeUrlNftTransfer        = "/ibc.applications.nft_transfer.v1.MsgTransfer"
+	typeUrlRecvPacket         = "/ibc.core.channel.v1.MsgRecvPacket"
+	typeUrlAcknowledgement    = "/ibc.core.channel.v1.MsgAcknowledgement"
+	typeUrlTimeout            = "/ibc.core.channel.v1.MsgTimeout"
+	typeUrlUpdateClient       = "/ibc.core.client.v1.MsgUpdateClient"
+	typeUrlChannelOpenConfirm = "/ibc.core.channel.v1.MsgChannelOpenConfirm"
+	typeUrlChannelOpenAck     = "/ibc.core.channel.v1.MsgChannelOpenAck"
+
+	eventSendPacket = "send_packet"
+	attrMsgIndex    = "msg_index"
+)
+
+// packetEvents the events of the packets, the txs of the msgs not decoded here are kept if they have them, e.g. the
+// MsgSendTx of ica and the MsgExecuteContract of wasm which send packets
+var packetEvents = map[string]struct{}{
+	eventSendPacket:           {},
+	"recv_packet":             {},
+	"write_acknowledgement":   {},
+	"acknowledge_packet":      {},
+	"timeout_packet":          {},
+	"timeout_on_close_packet": {},
+}
+
+// msgDecoder decode the msg into the msg doc of sync_<chain>_tx, the first address is the signer of the msg
+type msgDecoder struct {
+	msgType string
+	decode  func(msg pbcodec.Message) (doc bson.M, addrs []string, err error)
+}
+
+var msgDecoders = map[string]msgDecoder{
+	typeUrlTransfer:           {constant.MsgTypeTransfer, decodeTransfer},
+	typeUrlNftTransfer:        {constant.MsgTypeNftTransfer, decodeNftTransfer},
+	typeUrlRecvPacket:         {constant.MsgTypeRecvPacket, decodeRecvPacket},
+	typeUrlAcknowledgement:    {constant.MsgTypeAcknowledgement, decodeAcknowledgement},
+	typeUrlTimeout:            {constant.MsgTypeTimeoutPacket, decodeTimeout},
+	typeUrlUpdateClient:       {constant.MsgTypeUpdateClient, decodeUpdateClient},
+	typeUrlChannelOpenConfirm: {constant.MsgTypeChannelOpenConfirm, decodeChannelOpenConfirm},
+	typeUrlChannelOpenAck:     {constant.MsgTypeChannelOpenAck, decodeChannelOpenAck},
+}
+
+// ibcMsgTypes the types of the decoded ibc msgs
+var ibcMsgTypes = func() map[string]struct{} {
+	res := make(map[string]struct{}, len(msgDecoders))
+	for _, v := range msgDecoders {
+		res[v.msgType] = struct{}{}
+	}
+	return res
+}()
+
+// Block the sync block of the rpc block
+func Block(block *vo.RpcBlockResult) *entity.SyncBlock {
+	header := block.Block.Header
+	return &entity.SyncBlock{
+		Height:   header.Height,
+		Hash:     block.BlockId.Hash,
+		Txn:      int64(len(block.Block.Data.Txs)),
+		Time:     header.Time.Unix(),
+		Proposer: header.ProposerAddress,
+	}
+}
+
+// IbcTxs decode the txs of the block and keep the ones containing the ibc msgs or the packet events. The results of the
+// block are only queried if there are cosmos txs, the status, the log, the gas used and the events of the txs are
+// filled from them. The txs which are not cosmos txs, e.g. the raw evm txs, are skipped.
+func IbcTxs(block *vo.RpcBlockResult, results func() (*vo.RpcBlockResultsResult, error)) ([]*entity.Tx, error) {
+	header := block.Block.Header
+	var txs []*entity.Tx
+	var indexes []int
+	for i, bz := range block.Block.Data.Txs {
+		tx, err := DecodeTx(bz)
+		if err != nil || tx == nil {
+			continue
+		}
+		tx.Height = header.Height
+		tx.Time = header.Time.Unix()
+		tx.TxIndex = uint32(i)
+		txs = append(txs, tx)
+		indexes = append(indexes, i)
+	}
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	blockResults, err := results()
+	if err != nil {
+		return nil, err
+	}
+	if len(blockResults.TxsResults) != len(block.Block.Data.Txs) {
+		return nil, fmt.Errorf("block %d has %d txs but %d results", header.Height, len(block.Block.Data.Txs), len(blockResults.TxsResults))
+	}
+	var res []*entity.Tx
+	for i, tx := range txs {
+		SetResult(tx, blockResults.TxsResults[indexes[i]])
+		if isIbcTx(tx) {
+			res = append(res, tx)
+		}
+	}
+	return res, nil
+}
+
+// isIbcTx the tx contains an ibc msg, or a msg of it emits the packet events
+func isIbcTx(tx *entity.Tx) bool {
+	for _, v := range tx.Types {
+		if _, ok := ibcMsgTypes[v]; ok {
+			return true
+		}
+	}
+	for _, v := range tx.EventsNew {
+		for _, evt := range v.Events {
+			if _, ok := packetEvents[evt.Type]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DecodeTx decode the TxRaw. The msgs other than the ibc msgs keep their type urls as the types, so the msg index of
+// the events is not changed.
+func DecodeTx(bz []byte) (*entity.Tx, error) {
+	raw, err := pbcodec.Decode(bz)
+	if err != nil {
+		return nil, err
+	}
+	body, err := raw.Message(1)
+	if err != nil {
+		return nil, err
+	}
+	anys, err := body.Messages(1)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(bz)
+	tx := &entity.Tx{
+		TxHash: strings.ToUpper(fmt.Sprintf("%x", hash)),
+		Memo:   body.String(2),
+	}
+	for _, v := range anys {
+		typeUrl := v.String(1)
+		decoder, ok := msgDecoders[typeUrl]
+		if !ok {
+			tx.Types = append(tx.Types, typeUrl)
+			tx.DocTxMsgs = append(tx.DocTxMsgs, &model.TxMsg{Type: typeUrl, Msg: bson.M{}})
+			continue
+		}
+
+		msg, err := pbcodec.Decode(v.Bytes(2))
+		if err != nil {
+			return nil, err
+		}
+		doc, addrs, err := decoder.decode(msg)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s error, %v", typeUrl, err)
+		}
+		tx.Types = append(tx.Types, decoder.msgType)
+		tx.DocTxMsgs = append(tx.DocTxMsgs, &model.TxMsg{Type: decoder.msgType, Msg: doc})
+		if len(addrs) > 0 && addrs[0] != "" {
+			tx.Signers = appendUnique(tx.Signers, addrs[0])
+		}
+		for _, addr := range addrs {
+			if addr != "" {
+				tx.Addrs = appendUnique(tx.Addrs, addr)
+			}
+		}
+	}
+	if len(tx.Types) == 0 {
+		return nil, fmt.Errorf("tx %s has no msgs", tx.TxHash)
+	}
+	tx.Type = tx.Types[0]
+
+	authInfo, err := raw.Message(2)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := authInfo.Message(2)
+	if err != nil {
+		return nil, err
+	}
+	coins, err := fee.Messages(1)
+	if err != nil {
+		return nil, err
+	}
+	tx.Fee = &model.Fee{Gas: int64(fee.Uint64(2)), Amount: make([]*model.Coin, 0, len(coins))}
+	for _, v := range coins {
+		tx.Fee.Amount = append(tx.Fee.Amount, &model.Coin{Denom: v.String(1), Amount: v.String(2)})
+	}
+	return tx, nil
+}
+
+// SetResult fill the status, the log, the gas used and the events of the tx, and the packet ids of the transfer msgs
+// which are only known from the send_packet events
+func SetResult(tx *entity.Tx, result vo.RpcTxResult) {
+	tx.GasUsed = result.GasUsed
+	if result.Code != 0 {
+		tx.Status = entity.TxStatusFailed
+		tx.Log = result.Log
+		return
+	}
+
+	tx.Status = entity.TxStatusSuccess
+	tx.EventsNew = decodeEvents(result, len(tx.DocTxMsgs))
+	for i, msg := range tx.DocTxMsgs {
+		if msg.Type != constant.MsgTypeTransfer && msg.Type != constant.MsgTypeNftTransfer {
+			continue
+		}
+		for _, evt := range tx.EventsNew[i].Events {
+			if evt.Type != eventSendPacket {
+				continue
+			}
+			attrs := make(map[string]string, len(evt.Attributes))
+			for _, attr := range evt.Attributes {
+				attrs[attr.Key] = attr.Value
+			}
+			msg.Msg["packet_id"] = fmt.Sprintf("%s%s%s%s%s", attrs["packet_src_port"], attrs["packet_src_channel"],
+				attrs["packet_dst_port"], attrs["packet_dst_channel"], attrs["packet_sequence"])
+		}
+	}
+}
+
+// decodeEvents the events of every msg. Before cosmos-sdk v0.50 they are in the log of the tx, since v0.50 the log
+// is empty and the events of the msgs have the msg_index attribute.
+func decodeEvents(result vo.RpcTxResult, msgNum int) []entity.EventNew {
+	res := make([]entity.EventNew, msgNum)
+	for i := range res {
+		res[i].MsgIndex = uint32(i)
+	}
+
+	var logs []entity.EventNew
+	if err := json.Unmarshal([]byte(result.Log), &logs); err == nil && len(logs) > 0 {
+		for _, v := range logs {
+			if int(v.MsgIndex) < msgNum {
+				res[v.MsgIndex].Events = v.Events
+			}
+		}
+		return res
+	}
+
+	for _, evt := range result.Events {
+		event := entity.Event{Type: evt.Type}
+		index := -1
+		for _, attr := range evt.Attributes {
+			if attr.Key == attrMsgIndex {
+				_, _ = fmt.Sscanf(attr.Value, "%d", &index)
+				continue
+			}
+			event.Attributes = append(event.Attributes, entity.KvPair{Key: attr.Key, Value: attr.Value})
+		}
+		if index >= 0 && index < msgNum {
+			res[index].Events = append(res[index].Events, event)
+		}
+	}
+	return res
+}
+
+func decodeTransfer(msg pbcodec.Message) (bson.M, []string, error) {
+	token, err := msg.Message(3)
+	if err != nil {
+		return nil, nil, err
+	}
+	timeoutHeight, err := decodeHeight(msg, 6)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := bson.M{
+		"packet_id":         "",
+		"source_port":       msg.String(1),
+		"source_channel":    msg.String(2),
+		"token":             bson.M{"denom": token.String(1), "amount": token.String(2)},
+		"sender":            msg.String(4),
+		"receiver":          msg.String(5),
+		"timeout_height":    timeoutHeight,
+		"timeout_timestamp": msg.Int64(7),
+		"memo":              msg.String(8),
+	}
+	return doc, []string{msg.String(4), msg.String(5)}, nil
+}
+
+func decodeNftTransfer(msg pbcodec.Message) (bson.M, []string, error) {
+	timeoutHeight, err := decodeHeight(msg, 7)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := bson.M{
+		"packet_id":         "",
+		"source_port":       msg.String(1),
+		"source_channel":    msg.String(2),
+		"class_id":          msg.String(3),
+		"token_ids":         msg.Strings(4),
+		"sender":            msg.String(5),
+		"receiver":          msg.String(6),
+		"timeout_height":    timeoutHeight,
+		"timeout_timestamp": msg.Int64(8),
+		"memo":              msg.String(9),
+	}
+	return doc, []string{msg.String(5), msg.String(6)}, nil
+}
+
+func decodeRecvPacket(msg pbcodec.Message) (bson.M, []string, error) {
+	packet, packetId, err := decodePacket(msg, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	proofHeight, err := decodeHeight(msg, 3)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := bson.M{
+		"packet_id":        packetId,
+		"packet":           packet,
+		"proof_commitment": base64.StdEncoding.EncodeToString(msg.Bytes(2)),
+		"proof_height":     proofHeight,
+		"signer":           msg.String(4),
+	}
+	return doc, []string{msg.String(4)}, nil
+}
+
+func decodeAcknowledgement(msg pbcodec.Message) (bson.M, []string, error) {
+	packet, packetId, err := decodePacket(msg, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	proofHeight, err := decodeHeight(msg, 4)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := bson.M{
+		"packet_id":       packetId,
+		"packet":          packet,
+		"acknowledgement": string(msg.Bytes(2)),
+		"proof_acked":     base64.StdEncoding.EncodeToString(msg.Bytes(3)),
+		"proof_height":    proofHeight,
+		"signer":          msg.String(5),
+	}
+	return doc, []string{msg.String(5)}, nil
+}
+
+func decodeTimeout(msg pbcodec.Message) (bson.M, []string, error) {
+	packet, packetId, err := decodePacket(msg, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	proofHeight, err := decodeHeight(msg, 3)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := bson.M{
+		"packet_id":          packetId,
+		"packet":             packet,
+		"proof_unreceived":   base64.StdEncoding.EncodeToString(msg.Bytes(2)),
+		"proof_height":       proofHeight,
+		"next_sequence_recv": msg.Int64(4),
+		"signer":             msg.String(5),
+	}
+	return doc, []string{msg.String(5)}, nil
+}
+
+func decodeUpdateClient(msg pbcodec.Message) (bson.M, []string, error) {
+	doc := bson.M{
+		"client_id": msg.String(1),
+		"signer":    msg.String(3),
+	}
+	return doc, []string{msg.String(3)}, nil
+}
+
+func decodeChannelOpenConfirm(msg pbcodec.Message) (bson.M, []string, error) {
+	proofHeight, err := decodeHeight(msg, 4)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := bson.M{
+		"port_id":      msg.String(1),
+		"channel_id":   msg.String(2),
+		"proof_ack":    base64.StdEncoding.EncodeToString(msg.Bytes(3)),
+		"proof_height": proofHeight,
+		"signer":       msg.String(5),
+	}
+	return doc, []string{msg.String(5)}, nil
+}
+
+func decodeChannelOpenAck(msg pbcodec.Message) (bson.M, []string, error) {
+	proofHeight, err := decodeHeight(msg, 6)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc := bson.M{
+		"port_id":                 msg.String(1),
+		"channel_id":              msg.String(2),
+		"counterparty_channel_id": msg.String(3),
+		"counterparty_version":    msg.String(4),
+		"proof_try":               base64.StdEncoding.EncodeToString(msg.Bytes(5)),
+		"proof_height":            proofHeight,
+		"signer":                  msg.String(7),
+	}
+	return doc, []string{msg.String(7)}, nil
+}
+
+// decodePacket the packet of the field and the packet id, which is the concatenation of the ports, the channels and
+// the sequence
+func decodePacket(msg pbcodec.Message, num protowire.Number) (bson.M, string, error) {
+	packet, err := msg.Message(num)
+	if err != nil {
+		return nil, "", err
+	}
+	timeoutHeight, err := decodeHeight(packet, 7)
+	if err != nil {
+		return nil, "", err
+	}
+	doc := bson.M{
+		"sequence":            packet.Int64(1),
+		"source_port":         packet.String(2),
+		"source_channel":      packet.String(3),
+		"destination_port":    packet.String(4),
+		"destination_channel": packet.String(5),
+		"data":                decodePacketData(packet.Bytes(6)),
+		"timeout_height":      timeoutHeight,
+		"timeout_timestamp":   packet.Int64(8),
+	}
+	packetId := fmt.Sprintf("%s%s%s%s%d", packet.String(2), packet.String(3), packet.String(4), packet.String(5),
+		packet.Int64(1))
+	return doc, packetId, nil
+}
+
+// decodePacketData the json packet data is decoded as an object, the others are kept as the base64 string
+func decodePacketData(bz []byte) interface{} {
+	var data map[string]interface{}
+	if err := json.Unmarshal(bz, &data); err == nil {
+		return data
+	}
+	return base64.StdEncoding.EncodeToString(bz)
+}
+
+func decodeHeight(msg pbcodec.Message, num protowire.Number) (bson.M, error) {
+	height, err := msg.Message(num)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{"revision_number": height.Int64(1), "revision_height": height.Int64(2)}, nil
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, item := range list {
+		if item == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
@@ -0,0 +1,162 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/pbcodec"
+)
+
+func anyMsg(typeUrl string, msg pbcodec.Encoder) pbcodec.Encoder {
+	return pbcodec.Encoder{}.String(1, typeUrl).Bytes(2, msg)
+}
+
+func txRaw(memo string, msgs ...pbcodec.Encoder) []byte {
+	body := pbcodec.Encoder{}
+	for _, v := range msgs {
+		body = body.Message(1, v)
+	}
+	body = body.String(2, memo)
+	fee := pbcodec.Encoder{}.Message(1, pbcodec.Encoder{}.String(1, "uatom").String(2, "500")).Uint64(2, 200000)
+	authInfo := pbcodec.Encoder{}.Message(2, fee)
+	return pbcodec.Encoder{}.Message(1, body).Message(2, authInfo).Bytes(3, []byte("sig"))
+}
+
+func testTransfer() pbcodec.Encoder {
+	transfer := pbcodec.Encoder{}.String(1, "transfer").String(2, "channel-0").
+		Message(3, pbcodec.Encoder{}.String(1, "uatom").String(2, "100")).String(4, "cosmos1sender").
+		String(5, "osmo1receiver").Message(6, pbcodec.Encoder{}.Uint64(1, 1).Uint64(2, 1000)).Uint64(7, 1700000000000000000)
+	return anyMsg(typeUrlTransfer, transfer)
+}
+
+func testRecvPacket() pbcodec.Encoder {
+	packet := pbcodec.Encoder{}.Uint64(1, 7).String(2, "transfer").String(3, "channel-9").String(4, "transfer").
+		String(5, "channel-0").Bytes(6, []byte(`{"amount":"5","denom":"uosmo","receiver":"cosmos1receiver","sender":"osmo1sender"}`))
+	recv := pbcodec.Encoder{}.Message(1, packet).Bytes(2, []byte{1, 2}).
+		Message(3, pbcodec.Encoder{}.Uint64(1, 1).Uint64(2, 99)).String(4, "cosmos1relayer")
+	return anyMsg(typeUrlRecvPacket, recv)
+}
+
+func TestDecodeTx(t *testing.T) {
+	send := anyMsg("/cosmos.bank.v1beta1.MsgSend", pbcodec.Encoder{}.String(1, "cosmos1sender"))
+	tx, err := DecodeTx(txRaw("hello", send, testTransfer(), testRecvPacket()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type != "/cosmos.bank.v1beta1.MsgSend" || len(tx.DocTxMsgs) != 3 || tx.Memo != "hello" || len(tx.TxHash) != 64 {
+		t.Fatalf("unexpected tx %+v", tx)
+	}
+	if tx.Types[1] != constant.MsgTypeTransfer || tx.Types[2] != constant.MsgTypeRecvPacket {
+		t.Fatalf("unexpected types %v", tx.Types)
+	}
+	if len(tx.Signers) != 2 || tx.Signers[1] != "cosmos1relayer" || len(tx.Addrs) != 3 {
+		t.Fatalf("unexpected signers %v, addrs %v", tx.Signers, tx.Addrs)
+	}
+	if tx.Fee.Gas != 200000 || tx.Fee.Amount[0].Amount != "500" {
+		t.Fatalf("unexpected fee %+v", tx.Fee)
+	}
+
+	transfer := tx.DocTxMsgs[1].TransferMsg()
+	if transfer.SourceChannel != "channel-0" || transfer.Token.Amount != "100" || transfer.TimeoutHeight.RevisionHeight != 1000 ||
+		transfer.TimeoutTimestamp != 1700000000000000000 {
+		t.Fatalf("unexpected transfer msg %+v", transfer)
+	}
+	recv := tx.DocTxMsgs[2].RecvPacketMsg()
+	if recv.PacketId != "transferchannel-9transferchannel-07" || recv.Packet.Sequence != 7 || recv.Signer != "cosmos1relayer" ||
+		recv.ProofHeight.RevisionHeight != 99 || recv.Packet.Data.Receiver != "cosmos1receiver" {
+		t.Fatalf("unexpected recv packet msg %+v", recv)
+	}
+
+	if tx, err = DecodeTx(txRaw("", send)); err != nil || tx.Type != "/cosmos.bank.v1beta1.MsgSend" || isIbcTx(tx) {
+		t.Fatalf("the tx without ibc msgs is decoded but not an ibc tx, got %+v, %v", tx, err)
+	}
+}
+
+func TestSetResult(t *testing.T) {
+	sendPacket := `{"type":"send_packet","attributes":[{"key":"packet_src_port","value":"transfer"},` +
+		`{"key":"packet_src_channel","value":"channel-0"},{"key":"packet_dst_port","value":"transfer"},` +
+		`{"key":"packet_dst_channel","value":"channel-9"},{"key":"packet_sequence","value":"12"}]}`
+
+	// the events in the log before cosmos-sdk v0.50
+	tx, _ := DecodeTx(txRaw("", testTransfer()))
+	SetResult(tx, vo.RpcTxResult{GasUsed: 80000, Log: `[{"events":[` + sendPacket + `]}]`})
+	if tx.Status != entity.TxStatusSuccess || tx.GasUsed != 80000 || len(tx.EventsNew) != 1 ||
+		tx.DocTxMsgs[0].TransferMsg().PacketId != "transferchannel-0transferchannel-912" {
+		t.Fatalf("unexpected tx %+v", tx)
+	}
+
+	// the events with the msg_index attribute since cosmos-sdk v0.50
+	tx, _ = DecodeTx(txRaw("", testTransfer()))
+	var result vo.RpcTxResult
+	result.Events = make([]vo.RpcEvent, 2)
+	result.Events[0].Type = "tx"
+	result.Events[1].Type = eventSendPacket
+	for _, v := range [][2]string{{"packet_src_port", "transfer"}, {"packet_src_channel", "channel-0"},
+		{"packet_dst_port", "transfer"}, {"packet_dst_channel", "channel-9"}, {"packet_sequence", "13"}, {attrMsgIndex, "0"}} {
+		result.Events[1].Attributes = append(result.Events[1].Attributes, struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+			Index bool   `json:"index"`
+		}{Key: v[0], Value: v[1]})
+	}
+	SetResult(tx, result)
+	if len(tx.EventsNew[0].Events) != 1 || tx.DocTxMsgs[0].TransferMsg().PacketId != "transferchannel-0transferchannel-913" {
+		t.Fatalf("unexpected tx %+v", tx)
+	}
+
+	tx, _ = DecodeTx(txRaw("", testTransfer()))
+	SetResult(tx, vo.RpcTxResult{Code: 5, Log: "insufficient funds"})
+	if tx.Status != entity.TxStatusFailed || tx.Log != "insufficient funds" || tx.EventsNew != nil {
+		t.Fatalf("unexpected failed tx %+v", tx)
+	}
+}
+
+func TestIbcTxs(t *testing.T) {
+	var block vo.RpcBlockResult
+	block.BlockId.Hash = "ABCD"
+	block.Block.Header.Height = 100
+	block.Block.Header.Time = time.Unix(1700000000, 0)
+	block.Block.Data.Txs = [][]byte{[]byte("not a cosmos tx"), txRaw("", testRecvPacket())}
+
+	queried := false
+	txs, err := IbcTxs(&block, func() (*vo.RpcBlockResultsResult, error) {
+		queried = true
+		return &vo.RpcBlockResultsResult{TxsResults: []vo.RpcTxResult{{Code: 2}, {GasUsed: 10}}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !queried || len(txs) != 1 || txs[0].TxIndex != 1 || txs[0].Height != 100 || txs[0].Time != 1700000000 ||
+		txs[0].GasUsed != 10 || txs[0].Status != entity.TxStatusSuccess {
+		t.Fatalf("unexpected txs %+v", txs)
+	}
+	if sb := Block(&block); sb.Hash != "ABCD" || sb.Txn != 2 || sb.Time != 1700000000 {
+		t.Fatalf("unexpected block %+v", sb)
+	}
+
+	// the tx of a msg not decoded is kept by its packet events, e.g. the MsgSendTx of ica
+	sendTx := anyMsg("/ibc.applications.interchain_accounts.controller.v1.MsgSendTx", pbcodec.Encoder{}.String(1, "cosmos1owner"))
+	bankSend := anyMsg("/cosmos.bank.v1beta1.MsgSend", pbcodec.Encoder{}.String(1, "cosmos1sender"))
+	block.Block.Data.Txs = [][]byte{txRaw("", sendTx), txRaw("", bankSend)}
+	if txs, err = IbcTxs(&block, func() (*vo.RpcBlockResultsResult, error) {
+		return &vo.RpcBlockResultsResult{TxsResults: []vo.RpcTxResult{
+			{Log: `[{"events":[{"type":"send_packet","attributes":[{"key":"packet_sequence","value":"3"}]}]}]`},
+			{Log: `[{"events":[{"type":"transfer","attributes":[]}]}]`},
+		}}, nil
+	}); err != nil || len(txs) != 1 || txs[0].TxIndex != 0 || txs[0].Type != "/ibc.applications.interchain_accounts.controller.v1.MsgSendTx" {
+		t.Fatalf("unexpected txs %+v, err: %v", txs, err)
+	}
+
+	// the results are not queried without cosmos txs
+	block.Block.Data.Txs = [][]byte{[]byte("not a cosmos tx")}
+	queried = false
+	if txs, err = IbcTxs(&block, func() (*vo.RpcBlockResultsResult, error) {
+		queried = true
+		return nil, nil
+	}); err != nil || queried || len(txs) != 0 {
+		t.Fatalf("unexpected txs %+v, queried: %v, err: %v", txs, queried, err)
+	}
+}
@@ -0,0 +1,229 @@
+package lcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+const (
+	pathV1ChannelsProbe    = "/ibc/core/channel/v1/channels?pagination.limit=1"
+	pathChannels           = "/ibc/core/channel/%s/channels"
+	pathClientState        = "/ibc/core/channel/%s/channels/%s/ports/%s/client_state"
+	pathClientConnections  = "/ibc/core/connection/%s/client_connections/%s"
+	pathConnectionChannels = "/ibc/core/channel/%s/connections/%s/channels"
+	pathSupply             = "/cosmos/bank/v1beta1/supply"
+	pathBalances           = "/cosmos/bank/v1beta1/balances/" + entity.ApiBalancesPathPlaceholder
+	pathParams             = "/cosmos/" + entity.ParamsModulePathPlaceholder + "/v1beta1/params"
+	pathTx                 = "/cosmos/tx/v1beta1/txs/%s"
+	pathNodeInfo           = "/cosmos/base/tendermint/v1beta1/node_info"
+
+	channelsPageLimit = 1000
+	coinsPageLimit    = 500
+)
+
+// Channels all the channels of the chain
+func (c *Client) Channels(ctx context.Context) ([]vo.LcdChannel, error) {
+	path, err := c.channelsPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []vo.LcdChannel
+	err = c.list(ctx, path, channelsPageLimit, func(bz json.RawMessage) (*string, error) {
+		var resp vo.IbcChannelsResp
+		if err := json.Unmarshal(bz, &resp); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Channels...)
+		return resp.Pagination.NextKey, nil
+	})
+	return res, err
+}
+
+// channelsPath the channels path of the chain config, or the path of the negotiated version. The offset pagination in
+// the query of the configured path is dropped, the channels are listed by the key pagination.
+func (c *Client) channelsPath(ctx context.Context) (string, error) {
+	if c.apiPath.ChannelsPath == "" {
+		version, err := c.ApiVersion(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(pathChannels, version), nil
+	}
+
+	path := c.apiPath.ChannelsPath
+	i := strings.Index(path, "?")
+	if i < 0 {
+		return path, nil
+	}
+	var params []string
+	for _, v := range strings.Split(path[i+1:], "&") {
+		if v != "" && !strings.HasPrefix(v, "pagination.") {
+			params = append(params, v)
+		}
+	}
+	if len(params) == 0 {
+		return path[:i], nil
+	}
+	return path[:i] + "?" + strings.Join(params, "&"), nil
+}
+
+// ClientState the client state of the channel
+func (c *Client) ClientState(ctx context.Context, port, channel string) (*vo.ClientStateResp, error) {
+	var path string
+	if c.apiPath.ClientStatePath != "" {
+		path = strings.ReplaceAll(c.apiPath.ClientStatePath, entity.ApiChannelPathPlaceholder, channel)
+		path = strings.ReplaceAll(path, entity.ApiPortPathPlaceholder, port)
+	} else {
+		version, err := c.ApiVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		path = fmt.Sprintf(pathClientState, version, channel, port)
+	}
+
+	var resp vo.ClientStateResp
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ClientConnections the connection ids of the client
+func (c *Client) ClientConnections(ctx context.Context, clientId string) ([]string, error) {
+	version, err := c.ApiVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp vo.Connections
+	if err = c.get(ctx, fmt.Sprintf(pathClientConnections, version, clientId), &resp); err != nil {
+		return nil, err
+	}
+	return resp.ConnectionPaths, nil
+}
+
+// ConnectionChannels the channels of the connection
+func (c *Client) ConnectionChannels(ctx context.Context, connectionId string) ([]vo.LcdChannel, error) {
+	version, err := c.ApiVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []vo.LcdChannel
+	err = c.list(ctx, fmt.Sprintf(pathConnectionChannels, version, connectionId), channelsPageLimit, func(bz json.RawMessage) (*string, error) {
+		var resp vo.ConnectionChannels
+		if err := json.Unmarshal(bz, &resp); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Channels...)
+		return resp.Pagination.NextKey, nil
+	})
+	return res, err
+}
+
+// Supply the supply of all the denoms of the chain
+func (c *Client) Supply(ctx context.Context) ([]vo.LcdCoin, error) {
+	path := pathSupply
+	if c.apiPath.SupplyPath != "" {
+		path = c.apiPath.SupplyPath
+	}
+
+	var res []vo.LcdCoin
+	err := c.list(ctx, path, coinsPageLimit, func(bz json.RawMessage) (*string, error) {
+		var resp vo.SupplyResp
+		if err := json.Unmarshal(bz, &resp); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Supply...)
+		return resp.Pagination.NextKey, nil
+	})
+	return res, err
+}
+
+// Balances all the balances of the address
+func (c *Client) Balances(ctx context.Context, address string) ([]vo.LcdCoin, error) {
+	path := pathBalances
+	if c.apiPath.BalancesPath != "" {
+		path = c.apiPath.BalancesPath
+	}
+	path = strings.ReplaceAll(path, entity.ApiBalancesPathPlaceholder, address)
+
+	var res []vo.LcdCoin
+	err := c.list(ctx, path, coinsPageLimit, func(bz json.RawMessage) (*string, error) {
+		var resp vo.BalancesResp
+		if err := json.Unmarshal(bz, &resp); err != nil {
+			return nil, err
+		}
+		res = append(res, resp.Balances...)
+		return resp.Pagination.NextKey, nil
+	})
+	return res, err
+}
+
+// StakingParams the params of the staking module
+func (c *Client) StakingParams(ctx context.Context) (*vo.StakeParams, error) {
+	path := pathParams
+	if c.apiPath.ParamsPath != "" {
+		path = c.apiPath.ParamsPath
+	}
+
+	var resp vo.StakeParams
+	if err := c.get(ctx, strings.ReplaceAll(path, entity.ParamsModulePathPlaceholder, entity.StakeModule), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Tx the tx of the hash, a StatusError is returned if the tx is not found
+func (c *Client) Tx(ctx context.Context, hash string) (*vo.LcdTxData, error) {
+	var resp vo.LcdTxData
+	if err := c.get(ctx, fmt.Sprintf(pathTx, hash), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// NodeInfo the node info of the lcd
+func (c *Client) NodeInfo(ctx context.Context) (*vo.NodeInfoResp, error) {
+	var resp vo.NodeInfoResp
+	if err := c.get(ctx, pathNodeInfo, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// list query all the pages of the path, decode returns the next key of the page
+func (c *Client) list(ctx context.Context, basePath string, limit int, decode func(bz json.RawMessage) (*string, error)) error {
+	sep := "?"
+	if strings.Contains(basePath, "?") {
+		sep = "&"
+	}
+
+	key := ""
+	for {
+		path := fmt.Sprintf("%s%spagination.limit=%d", basePath, sep, limit)
+		if key != "" {
+			path = fmt.Sprintf("%s&pagination.key=%s", path, url.QueryEscape(key))
+		}
+
+		var bz json.RawMessage
+		if err := c.get(ctx, path, &bz); err != nil {
+			return err
+		}
+		nextKey, err := decode(bz)
+		if err != nil {
+			return err
+		}
+		if nextKey == nil || *nextKey == "" {
+			return nil
+		}
+		key = *nextKey
+	}
+}
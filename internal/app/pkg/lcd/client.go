@@ -0,0 +1,284 @@
+// Package lcd is the typed client of the cosmos lcd api. The requests are sent through a Transport, which is the lcd
+// endpoint pool of the chain in production and the recorded responses in tests. The requests are bounded by the
+// timeout, the failures are retried with jitter, and the version of the ibc api is negotiated per chain.
+package lcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcdpool"
+)
+
+const (
+	ApiVersionV1      = "v1"
+	ApiVersionV1beta1 = "v1beta1"
+
+	defaultTimeout       = 30 * time.Second
+	defaultRetries       = 2
+	defaultRetryInterval = 500 * time.Millisecond
+)
+
+// Transport send the request of the path to the lcd
+type Transport interface {
+	QueryContext(ctx context.Context, path string) (*lcdpool.Response, error)
+}
+
+// VersionCache cache the negotiated ibc api version of the chains
+type VersionCache interface {
+	GetApiVersion(chainId string) (string, error)
+	SetApiVersion(chainId, version string) error
+}
+
+// Options of the clients, the zero fields use the default values
+type Options struct {
+	Timeout       time.Duration // timeout of a call, including the retries
+	Retries       int           // max retries of a request
+	RetryInterval time.Duration // the interval before the nth retry is RetryInterval * 2^(n-1) plus a jitter
+}
+
+var (
+	options      = Options{}.withDefault()
+	versionCache VersionCache
+)
+
+func (o Options) withDefault() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+	if o.Retries <= 0 {
+		o.Retries = defaultRetries
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = defaultRetryInterval
+	}
+	return o
+}
+
+// SetOptions set the options of the clients, it should be called before the clients are used
+func SetOptions(opts Options) {
+	options = opts.withDefault()
+}
+
+// SetVersionCache set the cache of the negotiated versions shared by the clients
+func SetVersionCache(cache VersionCache) {
+	versionCache = cache
+}
+
+// StatusError the lcd responds a status code other than 200
+type StatusError struct {
+	StatusCode int
+	Path       string
+	Message    string // message of the error response of the lcd
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("StatusCode(%d %s) != 200, path: %s, message: %s", e.StatusCode, http.StatusText(e.StatusCode),
+		e.Path, e.Message)
+}
+
+// IsStatus whether the error is a StatusError with the status code
+func IsStatus(err error, statusCode int) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == statusCode
+}
+
+type Client struct {
+	chainId   string
+	transport Transport
+	apiPath   entity.ApiPath
+
+	mu      sync.Mutex
+	version string
+}
+
+// NewClient the supply, balances and params paths of the apiPath are used if they are not empty
+func NewClient(chainId string, transport Transport, apiPath entity.ApiPath) *Client {
+	return &Client{
+		chainId:   chainId,
+		transport: transport,
+		apiPath:   apiPath,
+	}
+}
+
+// ForChain the client of the lcd endpoint pool of the chain
+func ForChain(cf *entity.ChainConfig) *Client {
+	return NewClient(cf.ChainId, lcdpool.Of(cf.ChainId, cf.Lcd), cf.LcdApiPath)
+}
+
+// ForEndpoint the client of a single lcd endpoint of the chain, the results are still recorded into the pool
+func ForEndpoint(chainId, addr string) *Client {
+	return NewClient(chainId, &endpointTransport{pool: lcdpool.Of(chainId), addr: addr}, entity.ApiPath{})
+}
+
+// ForAddr the client of the lcd out of the pool, e.g. the lcd to be verified
+func ForAddr(chainId, addr string) *Client {
+	return NewClient(chainId, &httpTransport{addr: strings.TrimRight(addr, "/")}, entity.ApiPath{})
+}
+
+func (c *Client) ChainId() string {
+	return c.chainId
+}
+
+// ApiVersion the ibc api version of the chain, it's negotiated once and cached
+func (c *Client) ApiVersion(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	version := c.version
+	c.mu.Unlock()
+	if version != "" {
+		return version, nil
+	}
+
+	if versionCache != nil {
+		if version, err := versionCache.GetApiVersion(c.chainId); err == nil && isValidVersion(version) {
+			c.setVersion(version)
+			return version, nil
+		}
+	}
+	return c.RefreshApiVersion(ctx)
+}
+
+// RefreshApiVersion negotiate the ibc api version and update the cache
+func (c *Client) RefreshApiVersion(ctx context.Context) (string, error) {
+	version, err := c.NegotiateApiVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.setVersion(version)
+	if versionCache != nil {
+		_ = versionCache.SetApiVersion(c.chainId, version)
+	}
+	return version, nil
+}
+
+// NegotiateApiVersion the chain supports v1beta1 only if the v1 channels api is not implemented
+func (c *Client) NegotiateApiVersion(ctx context.Context) (string, error) {
+	var resp json.RawMessage
+	err := c.get(ctx, pathV1ChannelsProbe, &resp)
+	if err == nil {
+		return ApiVersionV1, nil
+	}
+	if IsStatus(err, http.StatusNotImplemented) {
+		return ApiVersionV1beta1, nil
+	}
+	return "", err
+}
+
+func (c *Client) setVersion(version string) {
+	c.mu.Lock()
+	c.version = version
+	c.mu.Unlock()
+}
+
+// get query the path and decode the response into res. The connection errors and 5xx(except 501) are retried.
+func (c *Client) get(ctx context.Context, path string, res interface{}) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	var err error
+	for i := 0; i <= options.Retries; i++ {
+		if i > 0 {
+			if err = sleep(ctx, retryInterval(i)); err != nil {
+				return err
+			}
+		}
+
+		var resp *lcdpool.Response
+		resp, err = c.transport.QueryContext(ctx, path)
+		if err != nil {
+			if errors.Is(err, lcdpool.ErrNoAvailableEndpoint) || ctx.Err() != nil {
+				return err
+			}
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			err = newStatusError(resp, path)
+			if resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented {
+				continue
+			}
+			return err
+		}
+		return json.Unmarshal(resp.Body, res)
+	}
+	return err
+}
+
+func newStatusError(resp *lcdpool.Response, path string) *StatusError {
+	statusErr := &StatusError{StatusCode: resp.StatusCode, Path: path}
+	var errResp struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(resp.Body, &errResp); err == nil {
+		statusErr.Message = errResp.Message
+		if statusErr.Message == "" {
+			statusErr.Message = errResp.Error
+		}
+	}
+	return statusErr
+}
+
+// retryInterval the interval before the nth retry, the jitter spreads the retries of the concurrent calls
+func retryInterval(n int) time.Duration {
+	interval := options.RetryInterval << uint(n-1)
+	return interval + time.Duration(rand.Int63n(int64(options.RetryInterval)))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func isValidVersion(version string) bool {
+	return version == ApiVersionV1 || version == ApiVersionV1beta1
+}
+
+type endpointTransport struct {
+	pool *lcdpool.Pool
+	addr string
+}
+
+func (t *endpointTransport) QueryContext(ctx context.Context, path string) (*lcdpool.Response, error) {
+	return t.pool.QueryEndpointContext(ctx, t.addr, path)
+}
+
+var httpClient = &http.Client{}
+
+type httpTransport struct {
+	addr string
+}
+
+func (t *httpTransport) QueryContext(ctx context.Context, path string) (*lcdpool.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.addr+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	bz, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &lcdpool.Response{Endpoint: t.addr, StatusCode: resp.StatusCode, Body: bz}, nil
+}
@@ -0,0 +1,183 @@
+package lcd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcdpool"
+)
+
+// the responses in testdata are recorded by Recorder, run the callers with a Recorder wrapping lcdpool.Of(chainId, lcd)
+// to record new ones
+func replayClient(chainId, dir string) *Client {
+	return NewClient(chainId, &Replayer{Dir: dir}, entity.ApiPath{})
+}
+
+type memVersionCache map[string]string
+
+func (m memVersionCache) GetApiVersion(chainId string) (string, error) {
+	return m[chainId], nil
+}
+
+func (m memVersionCache) SetApiVersion(chainId, version string) error {
+	m[chainId] = version
+	return nil
+}
+
+func TestClient_NegotiateApiVersion(t *testing.T) {
+	cache := memVersionCache{}
+	SetVersionCache(cache)
+	defer SetVersionCache(nil)
+
+	for dir, expect := range map[string]string{"testdata/v1": ApiVersionV1, "testdata/v1beta1": ApiVersionV1beta1} {
+		version, err := replayClient(dir, dir).ApiVersion(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != expect || cache[dir] != expect {
+			t.Fatalf("%s: expect %s, got %s, cached %s", dir, expect, version, cache[dir])
+		}
+	}
+
+	// the cached version is used without negotiation
+	cache["cached"] = ApiVersionV1beta1
+	if version, err := replayClient("cached", "testdata/none").ApiVersion(context.Background()); err != nil || version != ApiVersionV1beta1 {
+		t.Fatalf("expect the cached version, got %s, %v", version, err)
+	}
+}
+
+func TestClient_Channels(t *testing.T) {
+	channels, err := replayClient("chain_v1beta1", "testdata/v1beta1").Channels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 3 || channels[2].ChannelId != "channel-2" || channels[0].Counterparty.ChannelId != "channel-9" {
+		t.Fatalf("unexpected channels %+v", channels)
+	}
+}
+
+func TestClient_ClientState(t *testing.T) {
+	client := replayClient("chain_v1", "testdata/v1")
+	state, err := client.ClientState(context.Background(), "transfer", "channel-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.IdentifiedClientState.ClientState.ChainId != "osmosis-1" {
+		t.Fatalf("unexpected client state %+v", state)
+	}
+
+	connections, err := client.ClientConnections(context.Background(), state.IdentifiedClientState.ClientId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != 1 || connections[0] != "connection-0" {
+		t.Fatalf("unexpected connections %v", connections)
+	}
+}
+
+func TestClient_ApiPath(t *testing.T) {
+	// the configured paths are used without negotiating the api version, testdata/custom has no version probe
+	client := NewClient("chain_custom", &Replayer{Dir: "testdata/custom"}, entity.ApiPath{
+		ChannelsPath:    "/gateway/ibc/core/channel/v1/channels?pagination.offset=OFFSET&pagination.limit=LIMIT&pagination.count_total=true",
+		ClientStatePath: "/gateway/ibc/core/channel/v1/channels/CHANNEL/ports/PORT/client_state",
+	})
+	channels, err := client.Channels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(channels) != 1 || channels[0].ChannelId != "channel-2" {
+		t.Fatalf("unexpected channels %+v", channels)
+	}
+
+	state, err := client.ClientState(context.Background(), "transfer", "channel-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.IdentifiedClientState.ClientState.ChainId != "osmosis-1" {
+		t.Fatalf("unexpected client state %+v", state)
+	}
+}
+
+func TestClient_Bank(t *testing.T) {
+	supply, err := replayClient("chain_v1beta1", "testdata/v1beta1").Supply(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(supply) != 2 || supply[0].Denom != "uatom" || supply[0].Amount != "1000" {
+		t.Fatalf("unexpected supply %+v", supply)
+	}
+
+	balances, err := replayClient("chain_v1", "testdata/v1").Balances(context.Background(), "cosmos1escrow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 || balances[0].Amount != "7" {
+		t.Fatalf("unexpected balances %+v", balances)
+	}
+}
+
+func TestClient_StakingParams(t *testing.T) {
+	params, err := replayClient("chain_v1beta1", "testdata/v1beta1").StakingParams(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Params.UnbondingTime != "1814400s" {
+		t.Fatalf("unexpected params %+v", params)
+	}
+}
+
+func TestClient_TxNotFound(t *testing.T) {
+	_, err := replayClient("chain_v1beta1", "testdata/v1beta1").Tx(context.Background(), "ABCDEF")
+	if !IsStatus(err, http.StatusNotFound) {
+		t.Fatalf("expect 404, got %v", err)
+	}
+	if msg := err.(*StatusError).Message; msg != "tx not found: ABCDEF: key not found" {
+		t.Fatalf("unexpected message %s", msg)
+	}
+}
+
+type flakyTransport struct {
+	failures int
+	calls    int
+}
+
+func (f *flakyTransport) QueryContext(ctx context.Context, path string) (*lcdpool.Response, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return &lcdpool.Response{StatusCode: http.StatusBadGateway}, nil
+	}
+	return &lcdpool.Response{StatusCode: http.StatusOK, Body: []byte(`{"params":{"unbonding_time":"1s"}}`)}, nil
+}
+
+func TestClient_Retry(t *testing.T) {
+	SetOptions(Options{Retries: 2, RetryInterval: time.Millisecond})
+	defer SetOptions(Options{})
+
+	transport := &flakyTransport{failures: 2}
+	if _, err := NewClient("flaky", transport, entity.ApiPath{}).StakingParams(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if transport.calls != 3 {
+		t.Fatalf("expect 3 calls, got %d", transport.calls)
+	}
+
+	transport = &flakyTransport{failures: 3}
+	_, err := NewClient("flaky", transport, entity.ApiPath{}).StakingParams(context.Background())
+	if !IsStatus(err, http.StatusBadGateway) {
+		t.Fatalf("expect 502, got %v", err)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	SetOptions(Options{Timeout: 5 * time.Millisecond, RetryInterval: time.Second})
+	defer SetOptions(Options{})
+
+	start := time.Now()
+	_, err := NewClient("timeout", &flakyTransport{failures: 10}, entity.ApiPath{}).StakingParams(context.Background())
+	if err == nil || time.Since(start) > time.Second {
+		t.Fatalf("expect the call bounded by the timeout, got %v after %s", err, time.Since(start))
+	}
+}
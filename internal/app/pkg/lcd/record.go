@@ -0,0 +1,93 @@
+package lcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcdpool"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+)
+
+const recordFileNameMaxLen = 100
+
+var recordFileNameRegexp = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// recordedResponse the file content of a recorded response, the body is kept as json if it's valid json
+type recordedResponse struct {
+	Path       string          `json:"path"`
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	RawBody    string          `json:"raw_body,omitempty"`
+}
+
+// Replayer the transport replaying the responses recorded by Recorder in Dir, it's used to test the lcd callers offline
+type Replayer struct {
+	Dir string
+}
+
+func (r *Replayer) QueryContext(ctx context.Context, path string) (*lcdpool.Response, error) {
+	bz, err := ioutil.ReadFile(filepath.Join(r.Dir, RecordFileName(path)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no recorded response of %s", path)
+		}
+		return nil, err
+	}
+
+	var record recordedResponse
+	if err = json.Unmarshal(bz, &record); err != nil {
+		return nil, err
+	}
+	body := []byte(record.Body)
+	if record.RawBody != "" {
+		body = []byte(record.RawBody)
+	}
+	return &lcdpool.Response{Endpoint: r.Dir, StatusCode: record.StatusCode, Body: body}, nil
+}
+
+// Recorder the transport recording the responses of Transport into Dir
+type Recorder struct {
+	Transport Transport
+	Dir       string
+}
+
+func (r *Recorder) QueryContext(ctx context.Context, path string) (*lcdpool.Response, error) {
+	resp, err := r.Transport.QueryContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	record := recordedResponse{Path: path, StatusCode: resp.StatusCode}
+	if json.Valid(resp.Body) {
+		record.Body = resp.Body
+	} else {
+		record.RawBody = string(resp.Body)
+	}
+	bz, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(r.Dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(filepath.Join(r.Dir, RecordFileName(path)), bz, 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RecordFileName the file name of the recorded response of the path, e.g.
+// /cosmos/bank/v1beta1/supply?pagination.limit=500 => cosmos_bank_v1beta1_supply_pagination.limit_500_<md5 prefix>.json
+func RecordFileName(path string) string {
+	name := recordFileNameRegexp.ReplaceAllString(path, "_")
+	name = name[1:]
+	if len(name) > recordFileNameMaxLen {
+		name = name[:recordFileNameMaxLen]
+	}
+	return fmt.Sprintf("%s_%s.json", name, utils.Md5(path)[:8])
+}
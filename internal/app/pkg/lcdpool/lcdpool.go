@@ -0,0 +1,510 @@
+// Package lcdpool keeps a pool of lcd endpoints for each chain. The endpoints are scored by the latency, the error rate
+// and the freshness of the block height, the queries are balanced across the healthy endpoints and the endpoints
+// failing continuously are cut off by a circuit breaker.
+package lcdpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LatestBlockPath = "/cosmos/base/tendermint/v1beta1/blocks/latest"
+
+	defaultTimeout         = 30 * time.Second
+	defaultMaxAttempts     = 3
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 60 * time.Second
+	defaultMaxLagBlocks    = 50
+
+	ewmaAlpha    = 0.2
+	staleFactor  = 0.1
+	minScore     = 0.001
+	latencyScale = 1000 // ms
+)
+
+var ErrNoAvailableEndpoint = errors.New("no available lcd endpoint")
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// Options of the pools, the zero fields use the default values
+type Options struct {
+	Timeout         time.Duration // timeout of a request
+	MaxAttempts     int           // max endpoints tried by a query
+	BreakerFailures int           // consecutive failures to open the breaker
+	BreakerCooldown time.Duration // the breaker allows a trial request after the cooldown
+	MaxLagBlocks    int64         // the endpoint is stale if its height lags behind the highest one more than it
+}
+
+var (
+	options    = Options{}.withDefault()
+	httpClient = &http.Client{Timeout: options.Timeout}
+
+	poolsMu sync.Mutex
+	pools   = make(map[string]*Pool)
+	seed    func(chainId string) []string
+)
+
+func (o Options) withDefault() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = defaultTimeout
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = defaultMaxAttempts
+	}
+	if o.BreakerFailures <= 0 {
+		o.BreakerFailures = defaultBreakerFailures
+	}
+	if o.BreakerCooldown <= 0 {
+		o.BreakerCooldown = defaultBreakerCooldown
+	}
+	if o.MaxLagBlocks <= 0 {
+		o.MaxLagBlocks = defaultMaxLagBlocks
+	}
+	return o
+}
+
+// SetOptions set the options of the pools, it should be called before the pools are used
+func SetOptions(opts Options) {
+	options = opts.withDefault()
+	httpClient = &http.Client{Timeout: options.Timeout}
+}
+
+// SetSeed set the func which returns the endpoints of a chain known elsewhere, e.g. the lcds of the chain registry,
+// they are added into the pool when it's created, so that every process balances and fails over among them
+func SetSeed(fn func(chainId string) []string) {
+	poolsMu.Lock()
+	seed = fn
+	poolsMu.Unlock()
+}
+
+// Of return the pool of the chain, the addrs are added into the pool if they don't exist
+func Of(chainId string, addrs ...string) *Pool {
+	poolsMu.Lock()
+	pool, created := pools[chainId], false
+	if pool == nil {
+		pool, created = &Pool{chainId: chainId, endpoints: make(map[string]*endpoint)}, true
+		pools[chainId] = pool
+	}
+	seedFn := seed
+	poolsMu.Unlock()
+
+	pool.Add(addrs...)
+	if created && seedFn != nil {
+		pool.Add(seedFn(chainId)...)
+	}
+	return pool
+}
+
+// All return the pools of all the chains
+func All() []*Pool {
+	poolsMu.Lock()
+	defer poolsMu.Unlock()
+	res := make([]*Pool, 0, len(pools))
+	for _, v := range pools {
+		res = append(res, v)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].chainId < res[j].chainId
+	})
+	return res
+}
+
+type endpoint struct {
+	addr                string
+	latency             float64 // ewma of the latency, ms
+	successRate         float64 // ewma of the success rate
+	requests            int64
+	failures            int64
+	consecutiveFailures int
+	state               BreakerState
+	openTime            time.Time
+	height              int64
+}
+
+// Response the response of the endpoint
+type Response struct {
+	Endpoint   string
+	StatusCode int
+	Body       []byte
+}
+
+// EndpointScore the health of the endpoint
+type EndpointScore struct {
+	ChainId     string
+	Endpoint    string
+	Score       float64
+	Latency     float64
+	SuccessRate float64
+	Requests    int64
+	Failures    int64
+	Height      int64
+	Lag         int64
+	State       BreakerState
+}
+
+type Pool struct {
+	chainId   string
+	mu        sync.Mutex
+	endpoints map[string]*endpoint
+}
+
+func (p *Pool) ChainId() string {
+	return p.chainId
+}
+
+func (p *Pool) Add(addrs ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, addr := range addrs {
+		addr = formatAddr(addr)
+		if addr == "" {
+			continue
+		}
+		if _, ok := p.endpoints[addr]; !ok {
+			p.endpoints[addr] = &endpoint{addr: addr, successRate: 1, state: BreakerClosed}
+		}
+	}
+}
+
+// Available return the endpoints which can be requested, the best first
+func (p *Pool) Available() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	maxHeight := p.maxHeight()
+	var list []*endpoint
+	for _, v := range p.endpoints {
+		if p.allow(v, now) {
+			list = append(list, v)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		si, sj := p.score(list[i], maxHeight), p.score(list[j], maxHeight)
+		if si != sj {
+			return si > sj
+		}
+		return list[i].addr < list[j].addr
+	})
+
+	res := make([]string, 0, len(list))
+	for _, v := range list {
+		res = append(res, v.addr)
+	}
+	return res
+}
+
+// IsAvailable whether the endpoint can be requested
+func (p *Pool) IsAvailable(addr string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.endpoints[formatAddr(addr)]
+	return ok && p.allow(e, time.Now())
+}
+
+// IsHealthy whether the endpoint can be requested and its height is fresh
+func (p *Pool) IsHealthy(addr string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.endpoints[formatAddr(addr)]
+	return ok && p.allow(e, time.Now()) && !p.stale(e, p.maxHeight())
+}
+
+// Get query the path like utils.HttpGet, an error is returned if the status code isn't 200
+func (p *Pool) Get(path string) ([]byte, error) {
+	resp, err := p.Query(path)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("StatusCode(%d %s) != 200, url: %s%s", resp.StatusCode, http.StatusText(resp.StatusCode),
+			resp.Endpoint, path)
+	}
+	return resp.Body, nil
+}
+
+// Query query the path from the endpoints picked by their scores. The next endpoint is tried if the endpoint fails,
+// the response of other status codes is returned directly.
+func (p *Pool) Query(path string) (*Response, error) {
+	return p.QueryContext(context.Background(), path)
+}
+
+func (p *Pool) QueryContext(ctx context.Context, path string) (*Response, error) {
+	candidates := p.pick()
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("chain %s: %w", p.chainId, ErrNoAvailableEndpoint)
+	}
+	if len(candidates) > options.MaxAttempts {
+		candidates = candidates[:options.MaxAttempts]
+	}
+
+	var lastErr error
+	for _, addr := range candidates {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		resp, err := p.QueryEndpointContext(ctx, addr, path)
+		if err == nil && !isFailure(resp.StatusCode) {
+			return resp, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("StatusCode(%d %s) != 200, url: %s%s", resp.StatusCode, http.StatusText(resp.StatusCode), addr, path)
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// QueryEndpoint query the path from the endpoint, the result is recorded into the health of the endpoint
+func (p *Pool) QueryEndpoint(addr, path string) (*Response, error) {
+	return p.QueryEndpointContext(context.Background(), addr, path)
+}
+
+func (p *Pool) QueryEndpointContext(ctx context.Context, addr, path string) (*Response, error) {
+	addr = formatAddr(addr)
+	p.Add(addr)
+	p.halfOpen(addr)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		p.Report(addr, time.Since(start), err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	bz, err := ioutil.ReadAll(resp.Body)
+	if err == nil && isFailure(resp.StatusCode) {
+		p.Report(addr, time.Since(start), fmt.Errorf("StatusCode(%s)", resp.Status))
+	} else {
+		p.Report(addr, time.Since(start), err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{Endpoint: addr, StatusCode: resp.StatusCode, Body: bz}, nil
+}
+
+// Report record the result of a request of the endpoint
+func (p *Pool) Report(addr string, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.endpoints[formatAddr(addr)]
+	if !ok {
+		return
+	}
+
+	e.requests++
+	if err != nil {
+		e.failures++
+		e.consecutiveFailures++
+		e.successRate = (1 - ewmaAlpha) * e.successRate
+		// 半开状态试探失败, 或连续失败次数达到阈值时熔断
+		if e.state == BreakerHalfOpen || e.consecutiveFailures >= options.BreakerFailures {
+			e.state = BreakerOpen
+			e.openTime = time.Now()
+		}
+		return
+	}
+
+	ms := float64(latency) / float64(time.Millisecond)
+	if e.latency == 0 {
+		e.latency = ms
+	} else {
+		e.latency = (1-ewmaAlpha)*e.latency + ewmaAlpha*ms
+	}
+	e.successRate = (1-ewmaAlpha)*e.successRate + ewmaAlpha
+	e.consecutiveFailures = 0
+	e.state = BreakerClosed
+}
+
+// Probe query the latest block of all the endpoints, ignoring the breakers, to refresh their latency and height
+func (p *Pool) Probe() {
+	p.mu.Lock()
+	addrs := make([]string, 0, len(p.endpoints))
+	for addr := range p.endpoints {
+		addrs = append(addrs, addr)
+	}
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(len(addrs))
+	for _, addr := range addrs {
+		go func(addr string) {
+			defer wg.Done()
+			resp, err := p.QueryEndpoint(addr, LatestBlockPath)
+			if err != nil || resp.StatusCode != http.StatusOK {
+				return
+			}
+			var block latestBlockResp
+			if err = json.Unmarshal(resp.Body, &block); err != nil {
+				return
+			}
+			if height, err := strconv.ParseInt(block.Block.Header.Height, 10, 64); err == nil {
+				p.SetHeight(addr, height)
+			}
+		}(addr)
+	}
+	wg.Wait()
+}
+
+// SetHeight set the latest block height of the endpoint
+func (p *Pool) SetHeight(addr string, height int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.endpoints[formatAddr(addr)]; ok {
+		e.height = height
+	}
+}
+
+// Scores return the health of all the endpoints, the score of the endpoint whose breaker is open is 0
+func (p *Pool) Scores() []EndpointScore {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	maxHeight := p.maxHeight()
+	res := make([]EndpointScore, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		score := EndpointScore{
+			ChainId:     p.chainId,
+			Endpoint:    e.addr,
+			Latency:     e.latency,
+			SuccessRate: e.successRate,
+			Requests:    e.requests,
+			Failures:    e.failures,
+			Height:      e.height,
+			State:       e.state,
+		}
+		if e.height > 0 {
+			score.Lag = maxHeight - e.height
+		}
+		if e.state != BreakerOpen {
+			score.Score = p.score(e, maxHeight)
+		}
+		res = append(res, score)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Endpoint < res[j].Endpoint
+	})
+	return res
+}
+
+// pick order the available endpoints by weighted random of their scores
+func (p *Pool) pick() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	maxHeight := p.maxHeight()
+	var list []*endpoint
+	var weights []float64
+	for _, v := range p.endpoints {
+		if !p.allow(v, now) {
+			continue
+		}
+		list = append(list, v)
+		weights = append(weights, p.score(v, maxHeight))
+	}
+
+	res := make([]string, 0, len(list))
+	for len(list) > 0 {
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		r := rand.Float64() * total
+		i := 0
+		for ; i < len(weights)-1; i++ {
+			if r < weights[i] {
+				break
+			}
+			r -= weights[i]
+		}
+
+		res = append(res, list[i].addr)
+		list = append(list[:i], list[i+1:]...)
+		weights = append(weights[:i], weights[i+1:]...)
+	}
+	return res
+}
+
+// halfOpen the endpoint whose breaker has cooled down becomes half-open before the trial request
+func (p *Pool) halfOpen(addr string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.endpoints[addr]; ok && e.state == BreakerOpen && p.allow(e, time.Now()) {
+		e.state = BreakerHalfOpen
+	}
+}
+
+func (p *Pool) allow(e *endpoint, now time.Time) bool {
+	switch e.state {
+	case BreakerOpen:
+		return now.Sub(e.openTime) >= options.BreakerCooldown
+	case BreakerHalfOpen: // 试探请求结束前不再分配请求
+		return false
+	default:
+		return true
+	}
+}
+
+func (p *Pool) score(e *endpoint, maxHeight int64) float64 {
+	score := e.successRate * latencyScale / (latencyScale + e.latency)
+	if p.stale(e, maxHeight) {
+		score *= staleFactor
+	}
+	if score < minScore {
+		score = minScore
+	}
+	return score
+}
+
+func (p *Pool) stale(e *endpoint, maxHeight int64) bool {
+	return e.height > 0 && maxHeight-e.height > options.MaxLagBlocks
+}
+
+func (p *Pool) maxHeight() int64 {
+	var res int64
+	for _, v := range p.endpoints {
+		if v.height > res {
+			res = v.height
+		}
+	}
+	return res
+}
+
+// isFailure the connection errors and 5xx are counted as the failures of the endpoint, except 501 which means the api
+// isn't implemented by the node
+func isFailure(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError && statusCode != http.StatusNotImplemented
+}
+
+func formatAddr(addr string) string {
+	return strings.TrimRight(strings.TrimSpace(addr), "/")
+}
+
+type latestBlockResp struct {
+	Block struct {
+		Header struct {
+			Height string `json:"height"`
+		} `json:"header"`
+	} `json:"block"`
+}
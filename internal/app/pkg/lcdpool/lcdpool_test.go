@@ -0,0 +1,130 @@
+package lcdpool
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newServer(statusCode int, height int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+		if r.URL.Path == LatestBlockPath {
+			_, _ = fmt.Fprintf(w, `{"block":{"header":{"height":"%d"}}}`, height)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+}
+
+func TestPool_QueryFallback(t *testing.T) {
+	SetOptions(Options{MaxAttempts: 2, BreakerFailures: 2, BreakerCooldown: time.Hour})
+	bad := newServer(http.StatusBadGateway, 0)
+	defer bad.Close()
+	good := newServer(http.StatusOK, 0)
+	defer good.Close()
+
+	pool := Of("test_fallback", bad.URL, good.URL+"/")
+	for i := 0; i < 5; i++ {
+		resp, err := pool.Query("/cosmos/bank/v1beta1/supply")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Endpoint != good.URL {
+			t.Fatalf("expect %s, got %s", good.URL, resp.Endpoint)
+		}
+	}
+
+	// the bad endpoint is cut off after 2 consecutive failures
+	available := pool.Available()
+	if len(available) != 1 || available[0] != good.URL {
+		t.Fatalf("unexpected available endpoints %v", available)
+	}
+	for _, v := range pool.Scores() {
+		if v.Endpoint == bad.URL && (v.State != BreakerOpen || v.Score != 0) {
+			t.Fatalf("unexpected score of the bad endpoint %+v", v)
+		}
+	}
+}
+
+func TestPool_BreakerHalfOpen(t *testing.T) {
+	SetOptions(Options{BreakerFailures: 1, BreakerCooldown: time.Millisecond})
+	statusCode := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	pool := Of("test_half_open", server.URL)
+	if _, err := pool.Get("/"); err == nil {
+		t.Fatal("expect error")
+	}
+	if len(pool.Available()) != 0 {
+		t.Fatal("expect the breaker open")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	statusCode = http.StatusOK
+	if _, err := pool.Get("/"); err != nil {
+		t.Fatal(err)
+	}
+	if scores := pool.Scores(); scores[0].State != BreakerClosed {
+		t.Fatalf("expect the breaker closed, got %s", scores[0].State)
+	}
+}
+
+func TestPool_NotImplemented(t *testing.T) {
+	SetOptions(Options{BreakerFailures: 1})
+	server := newServer(http.StatusNotImplemented, 0)
+	defer server.Close()
+
+	pool := Of("test_not_implemented", server.URL)
+	_, err := pool.Get("/ibc/core/channel/v1/channels")
+	if err == nil || err.Error() != fmt.Sprintf("StatusCode(501 Not Implemented) != 200, url: %s/ibc/core/channel/v1/channels", server.URL) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(pool.Available()) != 1 {
+		t.Fatal("501 must not open the breaker")
+	}
+}
+
+func TestPool_StaleEndpoint(t *testing.T) {
+	SetOptions(Options{MaxLagBlocks: 10})
+	stale := newServer(http.StatusOK, 100)
+	defer stale.Close()
+	fresh := newServer(http.StatusOK, 200)
+	defer fresh.Close()
+
+	pool := Of("test_stale", stale.URL, fresh.URL)
+	pool.Probe()
+	available := pool.Available()
+	if len(available) != 2 || available[0] != fresh.URL {
+		t.Fatalf("expect the fresh endpoint first, got %v", available)
+	}
+	for _, v := range pool.Scores() {
+		if v.Endpoint == stale.URL && v.Lag != 100 {
+			t.Fatalf("expect lag 100, got %d", v.Lag)
+		}
+	}
+}
+
+func TestPool_NoAvailableEndpoint(t *testing.T) {
+	SetOptions(Options{})
+	if _, err := Of("test_empty").Get("/"); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestOf_Seed(t *testing.T) {
+	SetSeed(func(chainId string) []string {
+		return []string{"http://" + chainId + "-registry:1317"}
+	})
+	defer SetSeed(nil)
+
+	pool := Of("test_seed", "http://test_seed:1317")
+	if available := pool.Available(); len(available) != 2 {
+		t.Fatalf("expect the seed endpoints added, got %v", available)
+	}
+}
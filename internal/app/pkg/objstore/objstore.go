@@ -0,0 +1,182 @@
+// Package objstore the object storage of the archived files, a local directory or a bucket of a S3-compatible service
+// (AWS S3, MinIO, etc.)
+package objstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	schemeS3       = "s3://"
+	defaultRegion  = "us-east-1"
+	defaultTimeout = 10 * time.Minute
+)
+
+// ErrNotExist the object doesn't exist
+var ErrNotExist = errors.New("object doesn't exist")
+
+// Store the objects are addressed by the slash-separated keys
+type Store interface {
+	// Put write the object, the body is read more than once to checksum and retry the upload
+	Put(key string, body io.ReadSeeker, size int64) error
+	Get(key string) (io.ReadCloser, error)
+	// GetFrom the object from the offset to the end
+	GetFrom(key string, offset int64) (io.ReadCloser, error)
+	Stat(key string) (*ObjectInfo, error)
+	Delete(key string) error
+}
+
+// ObjectInfo the size and the hex sha256 of an object, the sha256 is empty if the object is not written by Put
+type ObjectInfo struct {
+	Size   int64
+	Sha256 string
+}
+
+// Options of the store. Path is a local directory, or s3://<bucket>/<prefix> of a S3-compatible service, Endpoint is
+// the url of the service, e.g. http://127.0.0.1:9000 for MinIO, the AWS endpoint of the region is used if it's empty
+type Options struct {
+	Path      string
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Timeout   time.Duration // timeout of a request
+}
+
+var options Options
+
+// SetOptions set the options of the default store
+func SetOptions(opts Options) {
+	options = opts
+}
+
+// Default the store of the options set by SetOptions
+func Default() (Store, error) {
+	return New(options)
+}
+
+func New(opts Options) (Store, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("the path of the object storage is not configured")
+	}
+	if !strings.HasPrefix(opts.Path, schemeS3) {
+		return &localStore{dir: opts.Path}, nil
+	}
+
+	bucket := strings.TrimPrefix(opts.Path, schemeS3)
+	var prefix string
+	if i := strings.Index(bucket, "/"); i >= 0 {
+		bucket, prefix = bucket[:i], strings.Trim(bucket[i+1:], "/")
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid path %s, the bucket is required", opts.Path)
+	}
+	if opts.Region == "" {
+		opts.Region = defaultRegion
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", opts.Region)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	return &s3Store{
+		endpoint:  strings.TrimRight(opts.Endpoint, "/"),
+		bucket:    bucket,
+		prefix:    prefix,
+		region:    opts.Region,
+		accessKey: opts.AccessKey,
+		secretKey: opts.SecretKey,
+		partSize:  s3PartSize,
+		client:    &http.Client{Timeout: opts.Timeout},
+	}, nil
+}
+
+// localStore the objects are the files under the directory
+type localStore struct {
+	dir string
+}
+
+func (s *localStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// Put write the object into a temp file then rename it, so that a partial object is never read
+func (s *localStore) Put(key string, body io.ReadSeeker, size int64) error {
+	file := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("put %s: %d bytes written, expect %d", key, n, size)
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func (s *localStore) Get(key string) (io.ReadCloser, error) {
+	return s.GetFrom(key, 0)
+}
+
+func (s *localStore) GetFrom(key string, offset int64) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// Stat the sha256 is calculated from the file
+func (s *localStore) Stat(key string) (*ObjectInfo, error) {
+	file, err := os.Open(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, file)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Size: n, Sha256: hex.EncodeToString(hasher.Sum(nil))}, nil
+}
+
+func (s *localStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
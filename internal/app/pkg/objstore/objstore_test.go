@@ -0,0 +1,209 @@
+package objstore
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func testStore(t *testing.T, store Store) {
+	data := []byte(`{"record_id":"1"}`)
+	key := "ex_ibc_tx/cosmoshub_4/2022-05/1.ndjson.gz"
+	if err := store.Put(key, bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := store.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expect %s, got %s", data, got)
+	}
+	info, err := store.Stat(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	if info.Size != int64(len(data)) || info.Sha256 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected object info %+v", info)
+	}
+
+	if err = store.Delete(key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Get(key); err != ErrNotExist {
+		t.Fatalf("expect ErrNotExist, got %v", err)
+	}
+	if err = store.Delete(key); err != nil {
+		t.Fatalf("deleting a missing object should succeed, got %v", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := New(Options{Path: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+// fakeS3 a MinIO stand-in keeping the objects in memory, the requests must be signed by the credential with the sha256
+// of the payload, the first request of a path in failOnce fails with 503
+func fakeS3(accessKey string, failOnce map[string]bool) *httptest.Server {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	metas := make(map[string]string)
+	uploads := make(map[string]map[int][]byte)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, s3Algorithm+" Credential="+accessKey+"/") || !strings.Contains(auth, "Signature=") ||
+			r.Header.Get("X-Amz-Date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		md5Sum := md5.Sum(body)
+		if md5Header := r.Header.Get("Content-MD5"); md5Header != "" && md5Header != base64.StdEncoding.EncodeToString(md5Sum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if failOnce[r.URL.Path] {
+			delete(failOnce, r.URL.Path)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		query := r.URL.Query()
+		_, initiate := query["uploads"]
+		switch {
+		case r.Method == http.MethodPost && initiate:
+			uploads[r.URL.Path] = make(map[int][]byte)
+			_, _ = w.Write([]byte("<InitiateMultipartUploadResult><UploadId>1</UploadId></InitiateMultipartUploadResult>"))
+			metas[r.URL.Path] = r.Header.Get(amzMetaSha256)
+		case r.Method == http.MethodPut && query.Get("uploadId") != "":
+			number, _ := strconv.Atoi(query.Get("partNumber"))
+			uploads[r.URL.Path][number] = body
+			w.Header().Set("ETag", fmt.Sprintf("%q", hex.EncodeToString(md5Sum[:])))
+		case r.Method == http.MethodPost && query.Get("uploadId") != "":
+			var data, md5s []byte
+			parts := uploads[r.URL.Path]
+			for i := 1; i <= len(parts); i++ {
+				data = append(data, parts[i]...)
+				partSum := md5.Sum(parts[i])
+				md5s = append(md5s, partSum[:]...)
+			}
+			objects[r.URL.Path] = data
+			etag := md5.Sum(md5s)
+			_, _ = fmt.Fprintf(w, "<CompleteMultipartUploadResult><ETag>\"%s-%d\"</ETag></CompleteMultipartUploadResult>",
+				hex.EncodeToString(etag[:]), len(parts))
+		case r.Method == http.MethodPut:
+			objects[r.URL.Path], metas[r.URL.Path] = body, r.Header.Get(amzMetaSha256)
+			w.Header().Set("ETag", fmt.Sprintf("%q", hex.EncodeToString(md5Sum[:])))
+		case r.Method == http.MethodGet || r.Method == http.MethodHead:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			var offset int
+			_, _ = fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &offset)
+			w.Header().Set(amzMetaSha256, metas[r.URL.Path])
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)-offset))
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(data[offset:])
+			}
+		case r.Method == http.MethodDelete:
+			if _, ok := objects[r.URL.Path]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestS3Store(t *testing.T) {
+	server := fakeS3("minio", map[string]bool{})
+	defer server.Close()
+
+	store, err := New(Options{Path: "s3://ibc-archive/explorer", Endpoint: server.URL, AccessKey: "minio", SecretKey: "minio123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := store.(*s3Store); s.bucket != "ibc-archive" || s.prefix != "explorer" || s.region != defaultRegion {
+		t.Fatalf("unexpected store %+v", s)
+	}
+	testStore(t, store)
+
+	denied, _ := New(Options{Path: "s3://ibc-archive", Endpoint: server.URL, AccessKey: "other"})
+	if err = denied.Put("a", strings.NewReader("a"), 1); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expect 403, got %v", err)
+	}
+	if _, err = New(Options{Path: "s3://"}); err == nil {
+		t.Fatal("expect error of empty bucket")
+	}
+}
+
+func TestS3StoreMultipart(t *testing.T) {
+	key := "ex_ibc_tx/cosmoshub_4/2022-05/2.ndjson.gz"
+	// the first part is retried
+	server := fakeS3("minio", map[string]bool{"/ibc-archive/" + key: true})
+	defer server.Close()
+
+	store, _ := New(Options{Path: "s3://ibc-archive", Endpoint: server.URL, AccessKey: "minio", SecretKey: "minio123"})
+	store.(*s3Store).partSize = 4
+	data := []byte("0123456789")
+	if err := store.Put(key, bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := store.Stat(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	if info.Size != int64(len(data)) || info.Sha256 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected object info %+v", info)
+	}
+
+	reader, err := store.GetFrom(key, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := ioutil.ReadAll(reader)
+	reader.Close()
+	if string(got) != "6789" {
+		t.Fatalf("expect 6789, got %s", got)
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	// the example of https://docs.aws.amazon.com/general/latest/gr/signature-v4-examples.html
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	if hex.EncodeToString(key) != "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d" {
+		t.Fatalf("unexpected signing key %x", key)
+	}
+	if got := escapePath("ex_ibc_tx/a b+c/1.gz"); got != "ex_ibc_tx/a%20b%2Bc/1.gz" {
+		t.Fatalf("unexpected escaped path %s", got)
+	}
+}
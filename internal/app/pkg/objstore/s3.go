@@ -0,0 +1,397 @@
+package objstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service     = "s3"
+	s3Algorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat = "20060102T150405Z"
+	amzMetaSha256 = "X-Amz-Meta-Sha256"
+
+	// s3PartSize the objects larger than it are uploaded in parts of it, a single PUT is limited to 5GB
+	s3PartSize   = 64 << 20
+	s3Retries    = 3
+	s3RetryDelay = time.Second
+)
+
+// emptySha256 the sha256 of an empty payload
+var emptySha256 = hex.EncodeToString(sha256.New().Sum(nil))
+
+// s3Store the objects of the bucket under the prefix, the requests are path-style and signed by AWS Signature
+// Version 4 with the sha256 of the payload, which are supported by AWS S3 and the S3-compatible services. The sha256 of
+// an object is kept in its metadata, so that Stat can verify it without reading the object.
+type s3Store struct {
+	endpoint  string
+	bucket    string
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+	partSize  int64
+	client    *http.Client
+}
+
+func (s *s3Store) url(key string, query url.Values) string {
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	res := fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, escapePath(key))
+	if len(query) > 0 {
+		res += "?" + canonicalQuery(query)
+	}
+	return res
+}
+
+// Put upload the object by a single PUT, or by the multipart upload if it's larger than the part size. The payloads
+// are signed and sent with Content-MD5, and the ETags returned are checked against the md5 of them.
+func (s *s3Store) Put(key string, body io.ReadSeeker, size int64) error {
+	object, err := newPayload(body, 0, size)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set(amzMetaSha256, object.sha256)
+	if size <= s.partSize {
+		resp, err := s.do(http.MethodPut, s.url(key, nil), header, object)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return checkETag(resp.Header, resp.Header.Get("ETag"), hex.EncodeToString(object.md5))
+	}
+	return s.putMultipart(key, header, body, size)
+}
+
+// putMultipart upload the object in parts, the upload is aborted if it fails
+func (s *s3Store) putMultipart(key string, header http.Header, body io.ReadSeeker, size int64) error {
+	resp, err := s.do(http.MethodPost, s.url(key, url.Values{"uploads": {""}}), header, nil)
+	if err != nil {
+		return err
+	}
+	var initiate struct {
+		UploadId string `xml:"UploadId"`
+	}
+	if err = decodeXML(resp, &initiate); err != nil {
+		return err
+	}
+	if initiate.UploadId == "" {
+		return fmt.Errorf("put %s: no upload id is returned", key)
+	}
+
+	if err = s.uploadParts(key, initiate.UploadId, body, size); err != nil {
+		if resp, abortErr := s.do(http.MethodDelete, s.url(key, url.Values{"uploadId": {initiate.UploadId}}), nil, nil); abortErr == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+type completePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+func (s *s3Store) uploadParts(key, uploadId string, body io.ReadSeeker, size int64) error {
+	var complete completeMultipartUpload
+	var md5s []byte
+	for offset, number := int64(0), 1; offset < size; offset, number = offset+s.partSize, number+1 {
+		n := s.partSize
+		if offset+n > size {
+			n = size - offset
+		}
+		part, err := newPayload(body, offset, n)
+		if err != nil {
+			return err
+		}
+		query := url.Values{"partNumber": {fmt.Sprint(number)}, "uploadId": {uploadId}}
+		resp, err := s.do(http.MethodPut, s.url(key, query), nil, part)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		etag := resp.Header.Get("ETag")
+		if err = checkETag(resp.Header, etag, hex.EncodeToString(part.md5)); err != nil {
+			return err
+		}
+		complete.Parts = append(complete.Parts, completePart{PartNumber: number, ETag: etag})
+		md5s = append(md5s, part.md5...)
+	}
+
+	data, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	payload, err := newPayload(bytes.NewReader(data), 0, int64(len(data)))
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(http.MethodPost, s.url(key, url.Values{"uploadId": {uploadId}}), nil, payload)
+	if err != nil {
+		return err
+	}
+	header := resp.Header
+	// an error of the completion may be returned with 200
+	var result struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+		ETag    string `xml:"ETag"`
+	}
+	if err = decodeXML(resp, &result); err != nil {
+		return err
+	}
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("complete %s: %s, %s", key, result.Code, result.Message)
+	}
+	sum := md5.Sum(md5s)
+	return checkETag(header, result.ETag, fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(complete.Parts)))
+}
+
+func (s *s3Store) Get(key string) (io.ReadCloser, error) {
+	return s.GetFrom(key, 0)
+}
+
+func (s *s3Store) GetFrom(key string, offset int64) (io.ReadCloser, error) {
+	var header http.Header
+	if offset > 0 {
+		header = http.Header{}
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := s.do(http.MethodGet, s.url(key, nil), header, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Stat the size and the sha256 of the object by HEAD, the sha256 is read from the metadata written by Put
+func (s *s3Store) Stat(key string) (*ObjectInfo, error) {
+	resp, err := s.do(http.MethodHead, s.url(key, nil), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return &ObjectInfo{Size: resp.ContentLength, Sha256: resp.Header.Get(amzMetaSha256)}, nil
+}
+
+func (s *s3Store) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, s.url(key, nil), nil, nil)
+	if err == ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sign and send the request, it's retried on the network errors, the 5xx and the 429 responses. The response of a
+// non-2xx status is returned as an error.
+func (s *s3Store) do(method, rawURL string, header http.Header, body *payload) (*http.Response, error) {
+	var lastErr error
+	for i := 0; i < s3Retries; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * s3RetryDelay)
+		}
+		req, err := http.NewRequest(method, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		payloadHash := emptySha256
+		if body != nil {
+			if err = body.attach(req); err != nil {
+				return nil, err
+			}
+			payloadHash = body.sha256
+		}
+		s.sign(req, payloadHash, time.Now().UTC())
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil, ErrNotExist
+		}
+		lastErr = responseError(req, resp)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, lastErr
+		}
+	}
+	return nil, lastErr
+}
+
+func responseError(req *http.Request, resp *http.Response) error {
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s %s: %s, %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+}
+
+func decodeXML(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	return xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// checkETag the ETag of a plain or SSE-S3 encrypted object is the md5 of it, or the md5 of the md5s of the parts with
+// the part count of a multipart upload, the ones of SSE-KMS or SSE-C are not checked
+func checkETag(header http.Header, etag, expect string) error {
+	if sse := header.Get("X-Amz-Server-Side-Encryption"); sse != "" && sse != "AES256" {
+		return nil
+	}
+	if header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != "" {
+		return nil
+	}
+	if strings.Trim(etag, `"`) != expect {
+		return fmt.Errorf("the etag %s doesn't match the md5 %s of the payload", etag, expect)
+	}
+	return nil
+}
+
+// payload the section of the body sent by a request, it's read again by the retries
+type payload struct {
+	body   io.ReadSeeker
+	offset int64
+	size   int64
+	sha256 string
+	md5    []byte
+}
+
+func newPayload(body io.ReadSeeker, offset, size int64) (*payload, error) {
+	if _, err := body.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	sha256Hasher, md5Hasher := sha256.New(), md5.New()
+	n, err := io.Copy(io.MultiWriter(sha256Hasher, md5Hasher), io.LimitReader(body, size))
+	if err != nil {
+		return nil, err
+	}
+	if n != size {
+		return nil, fmt.Errorf("%d bytes read, expect %d", n, size)
+	}
+	return &payload{body: body, offset: offset, size: size, sha256: hex.EncodeToString(sha256Hasher.Sum(nil)), md5: md5Hasher.Sum(nil)}, nil
+}
+
+// attach set the payload as the body of the request from its start
+func (p *payload) attach(req *http.Request) error {
+	if _, err := p.body.Seek(p.offset, io.SeekStart); err != nil {
+		return err
+	}
+	req.ContentLength = p.size
+	req.Body = http.NoBody
+	if p.size > 0 {
+		req.Body = ioutil.NopCloser(io.LimitReader(p.body, p.size))
+	}
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(p.md5))
+	return nil
+}
+
+// sign set the Authorization header of the request, the host, the Content-MD5 and the x-amz-* headers are signed
+func (s *s3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	names := []string{"host"}
+	for k := range req.Header {
+		if name := strings.ToLower(k); strings.HasPrefix(name, "x-amz-") || name == "content-md5" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		value := req.URL.Host
+		if name != "host" {
+			value = strings.TrimSpace(req.Header.Get(name))
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, value)
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonicalHeaders.String(),
+		signedHeaders, payloadHash}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, s.region, s3Service)
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(hash[:])}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(signingKey(s.secretKey, date, s.region, s3Service), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+func signingKey(secretKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery the query sorted by the names, the names and the values are escaped by the URI encoding of AWS
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for k, values := range query {
+		for _, v := range values {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// escapePath escape the key by the URI encoding of AWS, only the unreserved characters and the slashes are kept
+func escapePath(key string) string {
+	return uriEncode(key, false)
+}
+
+func uriEncode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~", c) >= 0 ||
+			c == '/' && !encodeSlash {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
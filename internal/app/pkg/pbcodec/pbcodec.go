@@ -0,0 +1,189 @@
+// Package pbcodec decodes and encodes the protobuf messages of the chains field by field, without the generated types
+// of the cosmos-sdk and ibc-go. Only the fields used by the explorer are read, the unknown fields are kept and ignored.
+package pbcodec
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Message the fields of a decoded message, the values of a repeated field keep their order
+type Message map[protowire.Number][]Value
+
+// Value the value of a field, Varint holds the varint and fixed values, Bytes holds the bytes, string and message values
+type Value struct {
+	Varint uint64
+	Bytes  []byte
+}
+
+// Decode decode the fields of the message
+func Decode(bz []byte) (Message, error) {
+	m := make(Message)
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		var v Value
+		switch typ {
+		case protowire.VarintType:
+			v.Varint, n = protowire.ConsumeVarint(bz)
+		case protowire.Fixed32Type:
+			var x uint32
+			x, n = protowire.ConsumeFixed32(bz)
+			v.Varint = uint64(x)
+		case protowire.Fixed64Type:
+			v.Varint, n = protowire.ConsumeFixed64(bz)
+		case protowire.BytesType:
+			v.Bytes, n = protowire.ConsumeBytes(bz)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, bz)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		bz = bz[n:]
+		m[num] = append(m[num], v)
+	}
+	return m, nil
+}
+
+// Has whether the field is present
+func (m Message) Has(num protowire.Number) bool {
+	return len(m[num]) > 0
+}
+
+// last the last value wins for the non-repeated fields
+func (m Message) last(num protowire.Number) Value {
+	values := m[num]
+	if len(values) == 0 {
+		return Value{}
+	}
+	return values[len(values)-1]
+}
+
+func (m Message) Uint64(num protowire.Number) uint64 {
+	return m.last(num).Varint
+}
+
+func (m Message) Int64(num protowire.Number) int64 {
+	return int64(m.last(num).Varint)
+}
+
+func (m Message) Bool(num protowire.Number) bool {
+	return m.last(num).Varint != 0
+}
+
+func (m Message) Bytes(num protowire.Number) []byte {
+	return m.last(num).Bytes
+}
+
+func (m Message) String(num protowire.Number) string {
+	return string(m.last(num).Bytes)
+}
+
+func (m Message) Strings(num protowire.Number) []string {
+	res := make([]string, 0, len(m[num]))
+	for _, v := range m[num] {
+		res = append(res, string(v.Bytes))
+	}
+	return res
+}
+
+// Message the sub message of the field, an empty message if the field is absent
+func (m Message) Message(num protowire.Number) (Message, error) {
+	return Decode(m.last(num).Bytes)
+}
+
+// Messages the sub messages of the repeated field
+func (m Message) Messages(num protowire.Number) ([]Message, error) {
+	res := make([]Message, 0, len(m[num]))
+	for _, v := range m[num] {
+		sub, err := Decode(v.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, sub)
+	}
+	return res, nil
+}
+
+// Any the type url and the value of the google.protobuf.Any field
+func (m Message) Any(num protowire.Number) (typeUrl string, value []byte, err error) {
+	msg, err := m.Message(num)
+	if err != nil {
+		return "", nil, err
+	}
+	return msg.String(1), msg.Bytes(2), nil
+}
+
+// Duration the google.protobuf.Duration field in the json format, e.g. 1814400s, 1.5s
+func (m Message) Duration(num protowire.Number) (string, error) {
+	d, err := m.Message(num)
+	if err != nil {
+		return "", err
+	}
+	seconds, nanos := d.Int64(1), int32(d.Uint64(2))
+	if nanos == 0 {
+		return fmt.Sprintf("%ds", seconds), nil
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%09d", abs(nanos)), "0")
+	if seconds == 0 && nanos < 0 {
+		return fmt.Sprintf("-0.%ss", frac), nil
+	}
+	return fmt.Sprintf("%d.%ss", seconds, frac), nil
+}
+
+// Time the google.protobuf.Timestamp field
+func (m Message) Time(num protowire.Number) (time.Time, error) {
+	ts, err := m.Message(num)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts.Int64(1), int64(int32(ts.Uint64(2)))).UTC(), nil
+}
+
+func abs(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Encoder append the fields of a message, the zero values are omitted as proto3 does
+type Encoder []byte
+
+func (e Encoder) Uint64(num protowire.Number, v uint64) Encoder {
+	if v == 0 {
+		return e
+	}
+	b := protowire.AppendTag(e, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func (e Encoder) Bool(num protowire.Number, v bool) Encoder {
+	return e.Uint64(num, protowire.EncodeBool(v))
+}
+
+func (e Encoder) Bytes(num protowire.Number, v []byte) Encoder {
+	if len(v) == 0 {
+		return e
+	}
+	b := protowire.AppendTag(e, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func (e Encoder) String(num protowire.Number, v string) Encoder {
+	return e.Bytes(num, []byte(v))
+}
+
+// Message append the sub message, it's appended even if it's empty to keep the presence
+func (e Encoder) Message(num protowire.Number, sub Encoder) Encoder {
+	b := protowire.AppendTag(e, num, protowire.BytesType)
+	return protowire.AppendBytes(b, sub)
+}
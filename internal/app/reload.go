@@ -0,0 +1,81 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/api"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task"
+	"github.com/sirupsen/logrus"
+)
+
+const configWatchInterval = 5 * time.Second
+
+// watchConfig reload the config when the config file is modified or SIGHUP is received. Only the safe settings
+// take effect, see conf.MergeReloadable.
+func watchConfig(cfgFilePath string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	modTime := configModTime(cfgFilePath)
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hup:
+			logrus.Infof("SIGHUP received, reload config %s", cfgFilePath)
+			modTime = configModTime(cfgFilePath)
+			reloadConfig(cfgFilePath)
+		case <-ticker.C:
+			t := configModTime(cfgFilePath)
+			if t.IsZero() || t.Equal(modTime) {
+				continue
+			}
+			modTime = t
+			logrus.Infof("config file %s modified, reload config", cfgFilePath)
+			reloadConfig(cfgFilePath)
+		}
+	}
+}
+
+func configModTime(cfgFilePath string) time.Time {
+	info, err := os.Stat(cfgFilePath)
+	if err != nil {
+		logrus.Errorf("stat config file %s error, %v", cfgFilePath, err)
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func reloadConfig(cfgFilePath string) {
+	data, err := ioutil.ReadFile(cfgFilePath)
+	if err != nil {
+		logrus.Errorf("reload config error, %v", err)
+		return
+	}
+	latest, err := conf.ReadConfig(data)
+	if err != nil {
+		logrus.Errorf("reload config error, keep the current config, %v", err)
+		return
+	}
+
+	cfg, restartRequired := conf.MergeReloadable(global.Config(), latest)
+	if len(restartRequired) > 0 {
+		logrus.Warnf("config %v changed, restart to take effect", restartRequired)
+	}
+
+	global.SetConfig(cfg)
+	task.LoadTaskConf(cfg.Task)
+	if cfg.App.ApiCacheAliveSeconds > 0 {
+		api.SetApiCacheAliveTime(cfg.App.ApiCacheAliveSeconds)
+	}
+	if level, err := logrus.ParseLevel(cfg.Log.LogLevel); err == nil {
+		logrus.SetLevel(level)
+	}
+	logrus.Infof("config reloaded")
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// seriesBucketExpr convert the unix time field to the bucket label, e.g. 2022-08-01
+func seriesBucketExpr(timeField string, cond *dto.AnalyticsSeriesCondDTO) bson.M {
+	return bson.M{
+		"$dateToString": bson.M{
+			"format":   cond.BucketFormat,
+			"timezone": cond.Timezone,
+			"date": bson.M{
+				"$toDate": bson.M{"$multiply": []interface{}{"$" + timeField, 1000}},
+			},
+		},
+	}
+}
+
+// seriesPipe group the documents by bucket and base denom
+func seriesPipe(match bson.M, bucket, baseDenom interface{}, count, successCount, refundedCount, amount interface{}) []bson.M {
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"bucket":              bucket,
+				"base_denom":          baseDenom,
+				"base_denom_chain_id": "$base_denom_chain_id",
+			},
+			"count":          bson.M{"$sum": count},
+			"success_count":  bson.M{"$sum": successCount},
+			"refunded_count": bson.M{"$sum": refundedCount},
+			"amount":         bson.M{"$sum": amount},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":                 0,
+			"bucket":              "$_id.bucket",
+			"base_denom":          "$_id.base_denom",
+			"base_denom_chain_id": "$_id.base_denom_chain_id",
+			"count":               "$count",
+			"success_count":       "$success_count",
+			"refunded_count":      "$refunded_count",
+			"amount":              "$amount",
+		},
+	}
+	sort := bson.M{
+		"$sort": bson.M{"bucket": 1},
+	}
+
+	return []bson.M{{"$match": match}, group, project, sort}
+}
+
+// statisticIdChainRegex match the channel id or relayer statistic id(chainA|channelA|chainB|channelB) which contains the chain
+func statisticIdChainRegex(chainId string) bson.M {
+	chain := regexp.QuoteMeta(chainId)
+	return bson.M{"$regex": fmt.Sprintf(`^%s\||^[^|]+\|[^|]+\|%s\|`, chain, chain)}
+}
+
+// statisticIdCond the condition of channel_id(ibc_channel_statistics) or statistic_id(ibc_relayer_statistics)
+func statisticIdCond(field string, cond *dto.AnalyticsSeriesCondDTO, relayer bool) []bson.M {
+	var res []bson.M
+	if cond.ChainId != "" {
+		res = append(res, bson.M{field: statisticIdChainRegex(cond.ChainId)})
+	}
+	if len(cond.ChannelIds) > 0 {
+		res = append(res, bson.M{field: bson.M{"$in": cond.ChannelIds}})
+	}
+	if relayer {
+		res = append(res, bson.M{field: bson.M{"$in": cond.StatisticIds}}, bson.M{"address": bson.M{"$in": cond.Addresses}})
+	}
+	return res
+}
@@ -43,3 +43,13 @@ func (repo *BaseDenomCacheRepo) FindBySymbol(symbol string) (entity.IBCBaseDenom
 	utils.UnmarshalJsonIgnoreErr([]byte(value), &data)
 	return data, nil
 }
+
+// DelCache delete the cache of all the base denoms and the base denoms of the symbols
+func (repo *BaseDenomCacheRepo) DelCache(symbols ...string) error {
+	keys := []string{baseDenom}
+	for _, v := range symbols {
+		keys = append(keys, fmt.Sprintf(baseDenomSymbol, v))
+	}
+	_, err := rc.Del(keys...)
+	return err
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	v8 "github.com/go-redis/redis/v8"
+)
+
+// ChainTaskCacheRepo the running locks of the tasks on a chain, e.g. the re-index job of a chain and the sync and relate
+// tasks working on it. The locks are short and refreshed while the task works on the chain.
+type ChainTaskCacheRepo struct {
+}
+
+func (repo *ChainTaskCacheRepo) Lock(chainId, taskName string, expiration time.Duration) error {
+	return rc.Lock(fmt.Sprintf(chainTaskRunning, chainId, taskName), time.Now().Unix(), expiration)
+}
+
+func (repo *ChainTaskCacheRepo) Refresh(chainId, taskName string, expiration time.Duration) bool {
+	return rc.Expire(fmt.Sprintf(chainTaskRunning, chainId, taskName), expiration)
+}
+
+func (repo *ChainTaskCacheRepo) Unlock(chainId, taskName string) error {
+	_, err := rc.Del(fmt.Sprintf(chainTaskRunning, chainId, taskName))
+	return err
+}
+
+func (repo *ChainTaskCacheRepo) IsLocked(chainId, taskName string) (bool, error) {
+	_, err := rc.Get(fmt.Sprintf(chainTaskRunning, chainId, taskName))
+	if err == v8.Nil {
+		return false, nil
+	}
+	return err == nil, err
+}
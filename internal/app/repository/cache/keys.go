@@ -37,4 +37,10 @@ const (
 	BaseDenomUnauth      = "base_denom_unauth"
 	baseDenomSymbol      = "base_denom:%s"
 	clientState          = "client_state:%s"
+	taskLastSuccess      = "task_last_success"
+	lcdApiVersion        = "lcd_api_version"
+	oneOffTaskRunning    = "one_off_task_running:%s"
+	oneOffTaskDone       = "one_off_task:%s"
+	chainTaskRunning     = "chain_task_running:%s:%s"
+	transferRoute        = "transfer_route:%s"
 )
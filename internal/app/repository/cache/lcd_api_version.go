@@ -0,0 +1,15 @@
+package cache
+
+// LcdApiVersionCacheRepo 缓存各链lcd协商的ibc api版本
+type LcdApiVersionCacheRepo struct {
+}
+
+func (repo *LcdApiVersionCacheRepo) SetApiVersion(chainId, version string) error {
+	_, err := rc.HSet(lcdApiVersion, chainId, version)
+	_ = rc.Expire(lcdApiVersion, oneDay)
+	return err
+}
+
+func (repo *LcdApiVersionCacheRepo) GetApiVersion(chainId string) (string, error) {
+	return rc.HGet(lcdApiVersion, chainId)
+}
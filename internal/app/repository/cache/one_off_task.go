@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	v8 "github.com/go-redis/redis/v8"
+)
+
+// OneOffTaskCacheRepo the locks of the one-off tasks. The running lock is short and refreshed while the task runs, so
+// that it's released soon if the process exits. The done marker keeps a finished task from running again.
+type OneOffTaskCacheRepo struct {
+}
+
+func (repo *OneOffTaskCacheRepo) LockRunning(taskName string, expiration time.Duration) error {
+	return rc.Lock(fmt.Sprintf(oneOffTaskRunning, taskName), time.Now().Unix(), expiration)
+}
+
+func (repo *OneOffTaskCacheRepo) RefreshRunning(taskName string, expiration time.Duration) bool {
+	return rc.Expire(fmt.Sprintf(oneOffTaskRunning, taskName), expiration)
+}
+
+func (repo *OneOffTaskCacheRepo) UnlockRunning(taskName string) error {
+	_, err := rc.Del(fmt.Sprintf(oneOffTaskRunning, taskName))
+	return err
+}
+
+func (repo *OneOffTaskCacheRepo) SetDone(taskName string, expiration time.Duration) error {
+	return rc.Set(fmt.Sprintf(oneOffTaskDone, taskName), time.Now().Unix(), expiration)
+}
+
+func (repo *OneOffTaskCacheRepo) IsDone(taskName string) (bool, error) {
+	_, err := rc.Get(fmt.Sprintf(oneOffTaskDone, taskName))
+	if err == v8.Nil {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Reset remove the running lock and the done marker of the task
+func (repo *OneOffTaskCacheRepo) Reset(taskName string) error {
+	// the keys are deleted one by one, they may be in different slots of the cluster
+	if _, err := rc.Del(fmt.Sprintf(oneOffTaskRunning, taskName)); err != nil {
+		return err
+	}
+	_, err := rc.Del(fmt.Sprintf(oneOffTaskDone, taskName))
+	return err
+}
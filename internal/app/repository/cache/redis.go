@@ -20,6 +20,11 @@ func RedisStatus() bool {
 	return rc.Ping() == nil
 }
 
+// RedisPing Redis `PING` command
+func RedisPing() error {
+	return rc.Ping()
+}
+
 // RedisDel Redis `DEL` command
 func RedisDel(keys ...string) (int64, error) {
 	result, err := rc.Del(keys...)
@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"strconv"
+)
+
+// TaskStatusCacheRepo the last successful run time of the cron tasks, shared by all the instances
+type TaskStatusCacheRepo struct {
+}
+
+func (repo *TaskStatusCacheRepo) SetLastSuccess(taskName string, runTime int64) error {
+	_, err := rc.HSet(taskLastSuccess, taskName, runTime)
+	return err
+}
+
+func (repo *TaskStatusCacheRepo) GetLastSuccess(taskName string) (int64, error) {
+	value, err := rc.HGet(taskLastSuccess, taskName)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
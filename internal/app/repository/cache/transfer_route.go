@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+// TransferRouteCacheRepo 缓存交易详情的转账路径
+type TransferRouteCacheRepo struct {
+}
+
+// Set the complete route is cached for a longer time, the hops of an incomplete route may be synced soon
+func (repo *TransferRouteCacheRepo) Set(recordId string, route *vo.TransferRoute) error {
+	expiration := oneMin
+	if route.Complete {
+		expiration = oneHour
+	}
+	return rc.MarshalSet(fmt.Sprintf(transferRoute, recordId), route, expiration)
+}
+
+func (repo *TransferRouteCacheRepo) Get(recordId string) (*vo.TransferRoute, error) {
+	var route vo.TransferRoute
+	if err := rc.UnmarshalGet(fmt.Sprintf(transferRoute, recordId), &route); err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
@@ -15,6 +15,7 @@ type IChainConfigRepo interface {
 	FindOne(chainId string) (*entity.ChainConfig, error)
 	UpdateIbcInfo(config *entity.ChainConfig) error
 	UpdateLcdApi(config *entity.ChainConfig) error
+	Save(config *entity.ChainConfig) error
 	Count() (int64, error)
 }
 
@@ -75,3 +76,9 @@ func (repo *ChainConfigRepo) UpdateLcdApi(config *entity.ChainConfig) error {
 			//"lcd_api_path.params_path":       config.LcdApiPath.ParamsPath,
 		}})
 }
+
+// Save insert or replace the chain config, the ibc info is synced by ibc_chain_config_task later
+func (repo *ChainConfigRepo) Save(config *entity.ChainConfig) error {
+	_, err := repo.coll().Upsert(context.Background(), bson.M{"chain_id": config.ChainId}, config)
+	return err
+}
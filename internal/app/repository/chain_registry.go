@@ -11,6 +11,7 @@ import (
 type IChainRegistryRepo interface {
 	FindAll() ([]*entity.ChainRegistry, error)
 	FindOne(chainId string) (*entity.ChainRegistry, error)
+	Save(registry *entity.ChainRegistry) error
 }
 
 var _ IChainRegistryRepo = new(ChainRegistryRepo)
@@ -33,3 +34,8 @@ func (repo *ChainRegistryRepo) FindOne(chainId string) (*entity.ChainRegistry, e
 	err := repo.coll().Find(context.Background(), bson.M{"chain_id": chainId}).One(&res)
 	return res, err
 }
+
+func (repo *ChainRegistryRepo) Save(registry *entity.ChainRegistry) error {
+	_, err := repo.coll().Upsert(context.Background(), bson.M{"chain_id": registry.ChainId}, registry)
+	return err
+}
@@ -90,6 +90,11 @@ func InitMgo(cfg conf.Mongo, ctx context.Context) {
 	//ensureDocsIndexes()
 }
 
+// MgoPing ping the mongo server within the timeout(second)
+func MgoPing(timeout int64) error {
+	return mgo.Ping(timeout)
+}
+
 func Close() {
 	if _ctx != nil {
 		err := mgo.Close(_ctx)
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IExIbcIcaTxRepo interface {
+	UpsertSendTx(icaTx *entity.ExIbcIcaTx) error
+	UpsertHostTx(icaTx *entity.ExIbcIcaTx) error
+	UpsertControllerTx(icaTx *entity.ExIbcIcaTx) error
+	UpdateIcaAddress(hostChainId, hostChannel, icaAddress string) error
+	CountIcaTxs(query dto.IbcIcaTxQuery) (int64, error)
+	FindIcaTxs(query dto.IbcIcaTxQuery, skip, limit int64) ([]*entity.ExIbcIcaTx, error)
+	AggrIcaActivity(hostChainId string, icaAddresses []string) ([]*dto.AggrIcaActivityDTO, error)
+}
+
+var _ IExIbcIcaTxRepo = new(ExIbcIcaTxRepo)
+
+type ExIbcIcaTxRepo struct {
+}
+
+func (repo *ExIbcIcaTxRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.ExIbcIcaTx{}.CollectionName())
+}
+
+// upsert the packet is seen on the host and the controller chain separately, each side only sets its own fields, and
+// the fields of setOnInsert if it's the first side seen
+func (repo *ExIbcIcaTxRepo) upsert(recordId string, set, setOnInsert bson.M) error {
+	now := time.Now().Unix()
+	set["update_at"] = now
+	setOnInsert["create_at"] = now
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": setOnInsert,
+	}
+	return repo.coll().UpdateOne(context.Background(), bson.M{"record_id": recordId}, update,
+		opts.UpdateOptions{UpdateOptions: options.Update().SetUpsert(true)})
+}
+
+// UpsertSendTx the packet is pending until it's received, acked or timed out, the tx time is the time it's sent
+func (repo *ExIbcIcaTxRepo) UpsertSendTx(icaTx *entity.ExIbcIcaTx) error {
+	set := bson.M{
+		"packet_id":           icaTx.PacketId,
+		"sequence":            icaTx.Sequence,
+		"controller_chain_id": icaTx.ControllerChainId,
+		"controller_port":     icaTx.ControllerPort,
+		"controller_channel":  icaTx.ControllerChannel,
+		"owner":               icaTx.Owner,
+		"host_chain_id":       icaTx.HostChainId,
+		"host_port":           icaTx.HostPort,
+		"host_channel":        icaTx.HostChannel,
+		"packet_type":         icaTx.PacketType,
+		"msg_types":           icaTx.MsgTypes,
+		"memo":                icaTx.Memo,
+		"send_tx_info":        icaTx.SendTxInfo,
+		"tx_time":             icaTx.TxTime,
+	}
+	if icaTx.IcaAddress != "" {
+		set["ica_address"] = icaTx.IcaAddress
+	}
+	return repo.upsert(icaTx.RecordId, set, bson.M{"status": entity.IcaTxStatusPending})
+}
+
+func (repo *ExIbcIcaTxRepo) UpsertHostTx(icaTx *entity.ExIbcIcaTx) error {
+	set := bson.M{
+		"packet_id":           icaTx.PacketId,
+		"sequence":            icaTx.Sequence,
+		"controller_chain_id": icaTx.ControllerChainId,
+		"controller_port":     icaTx.ControllerPort,
+		"controller_channel":  icaTx.ControllerChannel,
+		"owner":               icaTx.Owner,
+		"host_chain_id":       icaTx.HostChainId,
+		"host_port":           icaTx.HostPort,
+		"host_channel":        icaTx.HostChannel,
+		"status":              icaTx.Status,
+		"packet_type":         icaTx.PacketType,
+		"msg_types":           icaTx.MsgTypes,
+		"memo":                icaTx.Memo,
+		"error_log":           icaTx.ErrorLog,
+		"host_tx_info":        icaTx.HostTxInfo,
+	}
+	if icaTx.IcaAddress != "" {
+		set["ica_address"] = icaTx.IcaAddress
+	}
+	// the tx time is the time the packet is sent, or the time it's received if the send tx isn't seen yet
+	return repo.upsert(icaTx.RecordId, set, bson.M{"tx_time": icaTx.TxTime})
+}
+
+func (repo *ExIbcIcaTxRepo) UpsertControllerTx(icaTx *entity.ExIbcIcaTx) error {
+	set := bson.M{
+		"packet_id":           icaTx.PacketId,
+		"sequence":            icaTx.Sequence,
+		"controller_chain_id": icaTx.ControllerChainId,
+		"controller_port":     icaTx.ControllerPort,
+		"controller_channel":  icaTx.ControllerChannel,
+		"owner":               icaTx.Owner,
+		"host_chain_id":       icaTx.HostChainId,
+		"host_port":           icaTx.HostPort,
+		"host_channel":        icaTx.HostChannel,
+		"status":              icaTx.Status,
+	}
+	if icaTx.AckTxInfo != nil {
+		set["ack_tx_info"] = icaTx.AckTxInfo
+		set["error_log"] = icaTx.ErrorLog
+	}
+	setOnInsert := bson.M{}
+	if icaTx.TimeoutTxInfo != nil {
+		set["timeout_tx_info"] = icaTx.TimeoutTxInfo
+		setOnInsert["tx_time"] = icaTx.TxTime
+	}
+	if icaTx.IcaAddress != "" {
+		set["ica_address"] = icaTx.IcaAddress
+	}
+	return repo.upsert(icaTx.RecordId, set, setOnInsert)
+}
+
+func (repo *ExIbcIcaTxRepo) UpdateIcaAddress(hostChainId, hostChannel, icaAddress string) error {
+	query := bson.M{
+		"host_chain_id": hostChainId,
+		"host_channel":  hostChannel,
+		"ica_address":   bson.M{"$in": bson.A{"", nil}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"ica_address": icaAddress,
+			"update_at":   time.Now().Unix(),
+		},
+	}
+	_, err := repo.coll().UpdateAll(context.Background(), query, update)
+	return err
+}
+
+func parseIcaTxQuery(queryCond dto.IbcIcaTxQuery) bson.M {
+	query := bson.M{}
+	if queryCond.StartTime > 0 || queryCond.EndTime > 0 {
+		timeCond := bson.M{}
+		if queryCond.StartTime > 0 {
+			timeCond["$gte"] = queryCond.StartTime
+		}
+		if queryCond.EndTime > 0 {
+			timeCond["$lte"] = queryCond.EndTime
+		}
+		query["tx_time"] = timeCond
+	}
+	if queryCond.ChainId != "" {
+		query["$or"] = []bson.M{
+			{"controller_chain_id": queryCond.ChainId},
+			{"host_chain_id": queryCond.ChainId},
+		}
+	}
+	if queryCond.Owner != "" {
+		query["owner"] = queryCond.Owner
+	}
+	if queryCond.IcaAddress != "" {
+		query["ica_address"] = queryCond.IcaAddress
+	}
+	if len(queryCond.Status) > 0 {
+		query["status"] = bson.M{
+			"$in": queryCond.Status,
+		}
+	}
+	if queryCond.MsgType != "" {
+		query["msg_types"] = queryCond.MsgType
+	}
+	return query
+}
+
+func (repo *ExIbcIcaTxRepo) CountIcaTxs(query dto.IbcIcaTxQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseIcaTxQuery(query)).Count()
+}
+
+func (repo *ExIbcIcaTxRepo) FindIcaTxs(query dto.IbcIcaTxQuery, skip, limit int64) ([]*entity.ExIbcIcaTx, error) {
+	var res []*entity.ExIbcIcaTx
+	err := repo.coll().Find(context.Background(), parseIcaTxQuery(query)).Skip(skip).Limit(limit).Sort("-tx_time").All(&res)
+	return res, err
+}
+
+func (repo *ExIbcIcaTxRepo) AggrIcaActivity(hostChainId string, icaAddresses []string) ([]*dto.AggrIcaActivityDTO, error) {
+	match := bson.M{
+		"ica_address": bson.M{
+			"$in": icaAddresses,
+		},
+	}
+	if hostChainId != "" {
+		match["host_chain_id"] = hostChainId
+	}
+	countStatus := func(status entity.IcaTxStatus) bson.M {
+		return bson.M{
+			"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$status", status}}, 1, 0},
+			},
+		}
+	}
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"host_chain_id": "$host_chain_id",
+				"ica_address":   "$ica_address",
+			},
+			"txs": bson.M{
+				"$sum": 1,
+			},
+			"success_txs": countStatus(entity.IcaTxStatusSuccess),
+			"failed_txs":  countStatus(entity.IcaTxStatusFailed),
+			"timeout_txs": countStatus(entity.IcaTxStatusTimeout),
+			"pending_txs": countStatus(entity.IcaTxStatusPending),
+			"latest_tx_time": bson.M{
+				"$max": "$tx_time",
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":            0,
+			"host_chain_id":  "$_id.host_chain_id",
+			"ica_address":    "$_id.ica_address",
+			"txs":            "$txs",
+			"success_txs":    "$success_txs",
+			"failed_txs":     "$failed_txs",
+			"timeout_txs":    "$timeout_txs",
+			"pending_txs":    "$pending_txs",
+			"latest_tx_time": "$latest_tx_time",
+		},
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, bson.M{"$match": match}, group, project)
+	var res []*dto.AggrIcaActivityDTO
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
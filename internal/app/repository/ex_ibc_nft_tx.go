@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type IExIbcNftTxRepo interface {
+	InsertBatch(txs []*entity.ExIbcNftTx) error
+	FindProcessingTxs(chainId string, limit int64) ([]*entity.ExIbcNftTx, error)
+	UpdateNftTx(nftTx *entity.ExIbcNftTx) error
+	CountNftTxs(query dto.IbcNftTxQuery) (int64, error)
+	FindNftTxs(query dto.IbcNftTxQuery, skip, limit int64) ([]*entity.ExIbcNftTx, error)
+	TxDetail(hash string) ([]*entity.ExIbcNftTx, error)
+}
+
+var _ IExIbcNftTxRepo = new(ExIbcNftTxRepo)
+
+type ExIbcNftTxRepo struct {
+}
+
+func (repo *ExIbcNftTxRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.ExIbcNftTx{}.CollectionName())
+}
+
+func (repo *ExIbcNftTxRepo) InsertBatch(txs []*entity.ExIbcNftTx) error {
+	_, err := repo.coll().InsertMany(context.Background(), txs, insertIgnoreErrOpt)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+func (repo *ExIbcNftTxRepo) FindProcessingTxs(chainId string, limit int64) ([]*entity.ExIbcNftTx, error) {
+	var res []*entity.ExIbcNftTx
+	err := repo.coll().Find(context.Background(), bson.M{"sc_chain_id": chainId, "status": entity.IbcTxStatusProcessing}).Sort("next_try_time").Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *ExIbcNftTxRepo) UpdateNftTx(nftTx *entity.ExIbcNftTx) error {
+	return repo.coll().UpdateOne(context.Background(), bson.M{"record_id": nftTx.RecordId}, bson.M{
+		"$set": bson.M{
+			"status":                nftTx.Status,
+			"dc_connection_id":      nftTx.DcConnectionId,
+			"class_ids.dc_class_id": nftTx.ClassIds.DcClassId,
+			"dc_tx_info":            nftTx.DcTxInfo,
+			"refunded_tx_info":      nftTx.RefundedTxInfo,
+			"retry_times":           nftTx.RetryTimes,
+			"next_try_time":         nftTx.NextTryTime,
+			"process_info":          nftTx.ProcessInfo,
+			"update_at":             nftTx.UpdateAt,
+		},
+	})
+}
+
+func parseNftTxQuery(queryCond dto.IbcNftTxQuery) bson.M {
+	query := bson.M{}
+	if queryCond.StartTime > 0 || queryCond.EndTime > 0 {
+		timeCond := bson.M{}
+		if queryCond.StartTime > 0 {
+			timeCond["$gte"] = queryCond.StartTime
+		}
+		if queryCond.EndTime > 0 {
+			timeCond["$lte"] = queryCond.EndTime
+		}
+		query["tx_time"] = timeCond
+	}
+
+	var and []bson.M
+	if queryCond.ChainId != "" {
+		and = append(and, bson.M{"$or": []bson.M{
+			{"sc_chain_id": queryCond.ChainId},
+			{"dc_chain_id": queryCond.ChainId},
+		}})
+	}
+	if queryCond.ClassId != "" {
+		and = append(and, bson.M{"$or": []bson.M{
+			{"class_ids.sc_class_id": queryCond.ClassId},
+			{"class_ids.dc_class_id": queryCond.ClassId},
+		}})
+	}
+	if queryCond.Address != "" {
+		and = append(and, bson.M{"$or": []bson.M{
+			{"sc_addr": queryCond.Address},
+			{"dc_addr": queryCond.Address},
+		}})
+	}
+	if len(and) > 0 {
+		query["$and"] = and
+	}
+	if queryCond.BaseClassId != "" {
+		query["base_class_id"] = queryCond.BaseClassId
+		query["base_class_chain_id"] = queryCond.BaseClassChainId
+	}
+	if queryCond.TokenId != "" {
+		query["token_ids"] = queryCond.TokenId
+	}
+
+	if len(queryCond.Status) == 0 {
+		query["status"] = bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		}
+	} else {
+		query["status"] = bson.M{
+			"$in": queryCond.Status,
+		}
+	}
+	return query
+}
+
+func (repo *ExIbcNftTxRepo) CountNftTxs(query dto.IbcNftTxQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseNftTxQuery(query)).Count()
+}
+
+func (repo *ExIbcNftTxRepo) FindNftTxs(query dto.IbcNftTxQuery, skip, limit int64) ([]*entity.ExIbcNftTx, error) {
+	var res []*entity.ExIbcNftTx
+	err := repo.coll().Find(context.Background(), parseNftTxQuery(query)).Skip(skip).Limit(limit).Sort("-tx_time").All(&res)
+	return res, err
+}
+
+func (repo *ExIbcNftTxRepo) TxDetail(hash string) ([]*entity.ExIbcNftTx, error) {
+	var res []*entity.ExIbcNftTx
+	query := bson.M{
+		"status": bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		},
+		"$or": []bson.M{
+			{"sc_tx_info.hash": hash},
+			{"dc_tx_info.hash": hash},
+			{"refunded_tx_info.hash": hash},
+		},
+	}
+	err := repo.coll().Find(context.Background(), query).All(&res)
+	return res, err
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IExIbcPacketRepo interface {
+	UpsertSendPacket(packet *entity.ExIbcPacket) error
+	UpsertRecvPacket(packet *entity.ExIbcPacket) error
+	UpsertAckPacket(packet *entity.ExIbcPacket) error
+	UpsertTimeoutPacket(packet *entity.ExIbcPacket) error
+	CountPackets(query dto.IbcPacketQuery) (int64, error)
+	FindPackets(query dto.IbcPacketQuery, skip, limit int64) ([]*entity.ExIbcPacket, error)
+	FindPacket(scChainId, scPort, scChannel, sequence string) (*entity.ExIbcPacket, error)
+}
+
+var _ IExIbcPacketRepo = new(ExIbcPacketRepo)
+
+type ExIbcPacketRepo struct {
+}
+
+func (repo *ExIbcPacketRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.ExIbcPacket{}.CollectionName())
+}
+
+// upsert the packet is synced from the sc chain and the dc chain separately, each side only sets its own fields.
+// The status only moves forward and the tx_time is the earliest tx of the packet. The dc_chain_id is only set when
+// it's resolved, so that the send side doesn't wipe the one written by the recv side.
+func (repo *ExIbcPacketRepo) upsert(packet *entity.ExIbcPacket, set, setOnInsert bson.M) error {
+	now := time.Now().Unix()
+	set["packet_id"] = packet.PacketId
+	set["sc_chain_id"] = packet.ScChainId
+	set["sc_port"] = packet.ScPort
+	set["sc_channel"] = packet.ScChannel
+	if packet.DcChainId != "" {
+		set["dc_chain_id"] = packet.DcChainId
+	} else {
+		setOnInsert["dc_chain_id"] = ""
+	}
+	set["dc_port"] = packet.DcPort
+	set["dc_channel"] = packet.DcChannel
+	set["sequence"] = packet.Sequence
+	set["update_at"] = now
+	setOnInsert["create_at"] = now
+
+	update := bson.M{
+		"$set":         set,
+		"$setOnInsert": setOnInsert,
+		"$max":         bson.M{"status": packet.Status},
+		"$min":         bson.M{"tx_time": packet.TxTime},
+	}
+	return repo.coll().UpdateOne(context.Background(), bson.M{"record_id": packet.RecordId}, update,
+		opts.UpdateOptions{UpdateOptions: options.Update().SetUpsert(true)})
+}
+
+func (repo *ExIbcPacketRepo) UpsertSendPacket(packet *entity.ExIbcPacket) error {
+	set := bson.M{
+		"data":              packet.Data,
+		"data_hex":          packet.DataHex,
+		"timeout_height":    packet.TimeoutHeight,
+		"timeout_timestamp": packet.TimeoutTimestamp,
+		"send_tx_info":      packet.SendTxInfo,
+	}
+	return repo.upsert(packet, set, bson.M{})
+}
+
+func (repo *ExIbcPacketRepo) UpsertRecvPacket(packet *entity.ExIbcPacket) error {
+	set := bson.M{
+		"recv_tx_info": packet.RecvTxInfo,
+	}
+	if packet.AckResult != "" {
+		set["ack"] = packet.Ack
+		set["ack_result"] = packet.AckResult
+	}
+	return repo.upsert(packet, set, bson.M{"data": packet.Data})
+}
+
+func (repo *ExIbcPacketRepo) UpsertAckPacket(packet *entity.ExIbcPacket) error {
+	set := bson.M{
+		"ack":         packet.Ack,
+		"ack_result":  packet.AckResult,
+		"ack_tx_info": packet.AckTxInfo,
+	}
+	return repo.upsert(packet, set, bson.M{"data": packet.Data})
+}
+
+func (repo *ExIbcPacketRepo) UpsertTimeoutPacket(packet *entity.ExIbcPacket) error {
+	set := bson.M{
+		"timeout_tx_info": packet.TimeoutTxInfo,
+	}
+	return repo.upsert(packet, set, bson.M{"data": packet.Data})
+}
+
+func parsePacketQuery(queryCond dto.IbcPacketQuery) bson.M {
+	query := bson.M{}
+	if queryCond.StartTime > 0 || queryCond.EndTime > 0 {
+		timeCond := bson.M{}
+		if queryCond.StartTime > 0 {
+			timeCond["$gte"] = queryCond.StartTime
+		}
+		if queryCond.EndTime > 0 {
+			timeCond["$lte"] = queryCond.EndTime
+		}
+		query["tx_time"] = timeCond
+	}
+
+	var or []bson.M
+	sc, dc := bson.M{}, bson.M{}
+	if queryCond.ChainId != "" {
+		sc["sc_chain_id"], dc["dc_chain_id"] = queryCond.ChainId, queryCond.ChainId
+	}
+	if queryCond.Port != "" {
+		sc["sc_port"], dc["dc_port"] = queryCond.Port, queryCond.Port
+	}
+	if queryCond.Channel != "" {
+		sc["sc_channel"], dc["dc_channel"] = queryCond.Channel, queryCond.Channel
+	}
+	if len(sc) > 0 {
+		or = append(or, sc, dc)
+		query["$or"] = or
+	}
+
+	if len(queryCond.Status) > 0 {
+		query["status"] = bson.M{
+			"$in": queryCond.Status,
+		}
+	}
+	if queryCond.AckResult != "" {
+		query["ack_result"] = queryCond.AckResult
+	}
+	return query
+}
+
+func (repo *ExIbcPacketRepo) CountPackets(query dto.IbcPacketQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parsePacketQuery(query)).Count()
+}
+
+func (repo *ExIbcPacketRepo) FindPackets(query dto.IbcPacketQuery, skip, limit int64) ([]*entity.ExIbcPacket, error) {
+	var res []*entity.ExIbcPacket
+	err := repo.coll().Find(context.Background(), parsePacketQuery(query)).Skip(skip).Limit(limit).Sort("-tx_time").All(&res)
+	return res, err
+}
+
+func (repo *ExIbcPacketRepo) FindPacket(scChainId, scPort, scChannel, sequence string) (*entity.ExIbcPacket, error) {
+	var res entity.ExIbcPacket
+	query := bson.M{
+		"sc_chain_id": scChainId,
+		"sc_port":     scPort,
+		"sc_channel":  scChannel,
+		"sequence":    sequence,
+	}
+	err := repo.coll().Find(context.Background(), query).One(&res)
+	return &res, err
+}
@@ -3,24 +3,39 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type IExIbcTxRepo interface {
 	InsertBatch(txs []*entity.ExIbcTx) error
 	InsertBatchHistory(txs []*entity.ExIbcTx) error
 	DeleteByRecordIds(recordIds []string) error
+	DeleteHistoryByRecordIds(recordIds []string) error
+	FindByHeightRange(chainId string, startHeight, endHeight int64, history bool) ([]*entity.ExIbcTx, error)
+	FindByRecordIds(recordIds []string, history bool) ([]*entity.ExIbcTx, error)
+	ResetRelayed(recordIds []string, history bool) error
+	CreateReindexIndexes() error
+	MarkReindexing(recordIds []string, jobId string, history bool) error
+	ResetReindexing(recordIds []string, jobId string, history bool) error
+	ReplaceReindexing(tx *entity.ExIbcTx, history bool) error
+	DeleteReindexing(jobId string, history bool) (int64, error)
+	FindReindexProcessing(jobId, afterRecordId string, limit int64, history bool) ([]*entity.ExIbcTx, error)
 	FindAll(skip, limit int64) ([]*entity.ExIbcTx, error)
-	FindByRecordId(recordId string, targetHistory bool) (*entity.ExIbcTx, error)
 	FindByStatus(status []entity.IbcTxStatus, limit int64) ([]*entity.ExIbcTx, error)
+	FindByStatusBefore(status []entity.IbcTxStatus, txTime, limit int64) ([]*entity.ExIbcTx, error)
 	FindByTxTime(startTime, endTime, skip, limit int64) ([]*entity.ExIbcTx, error)
 	FindHistoryByTxTime(startTime, endTime, skip, limit int64) ([]*entity.ExIbcTx, error)
+	FindHistoryToArchive(chainId string, startTime, endTime, skip, limit int64) ([]*entity.ExIbcTx, error)
+	AggrArchivePartitions(txTime int64) ([]*dto.ArchivePartitionDTO, error)
 	FindByCreateAt(startTime, endTime, skip, limit int64, isTargetHistory bool) ([]*entity.ExIbcTx, error)
 	CountByStatus(status []entity.IbcTxStatus) (int64, error)
 	FindAllHistory(skip, limit int64) ([]*entity.ExIbcTx, error)
@@ -28,27 +43,22 @@ type IExIbcTxRepo interface {
 	FirstHistory() (*entity.ExIbcTx, error)
 	Latest() (*entity.ExIbcTx, error)
 	LatestHistory() (*entity.ExIbcTx, error)
+	CreateAtRange() (int64, int64, error)
 	FindProcessingTxs(chainId string, limit int64) ([]*entity.ExIbcTx, error)
-	FindProcessingHistoryTxs(chainId string, limit int64) ([]*entity.ExIbcTx, error)
 	UpdateIbcTx(ibcTx *entity.ExIbcTx, repaired bool) error
-	UpdateIbcHistoryTx(ibcTx *entity.ExIbcTx, repaired bool) error
 	CountBaseDenomTransferTxs(startTime, endTime int64) ([]*dto.CountBaseDenomTxsDTO, error)
-	CountBaseDenomHistoryTransferTxs(startTime, endTime int64) ([]*dto.CountBaseDenomTxsDTO, error)
 	CountIBCTokenRecvTxs(startTime, endTime int64) ([]*dto.CountIBCTokenRecvTxsDTO, error)
-	CountIBCTokenHistoryRecvTxs(startTime, endTime int64) ([]*dto.CountIBCTokenRecvTxsDTO, error)
 	GetRelayerInfo(startTime, endTime int64) ([]*dto.GetRelayerInfoDTO, error)
-	GetHistoryRelayerInfo(startTime, endTime int64) ([]*dto.GetRelayerInfoDTO, error)
 	GetLatestTxTime() (int64, error)
 	GetOneRelayerScTxPacketId(dto *dto.GetRelayerInfoDTO) (entity.ExIbcTx, error)
-	GetHistoryOneRelayerScTxPacketId(dto *dto.GetRelayerInfoDTO) (entity.ExIbcTx, error)
-	CountHistoryRelayerSuccessPacketTxs(startTime, endTime int64) ([]*dto.CountRelayerPacketTxsCntDTO, error)
 	CountRelayerSuccessPacketTxs(startTime, endTime int64) ([]*dto.CountRelayerPacketTxsCntDTO, error)
-	CountHistoryRelayerPacketAmount(startTime, endTime int64) ([]*dto.CountRelayerPacketAmountDTO, error)
 	CountRelayerPacketTxsAndAmount(startTime, endTime int64) ([]*dto.CountRelayerPacketAmountDTO, error)
 	AggrIBCChannelTxs(startTime, endTime int64) ([]*dto.AggrIBCChannelTxsDTO, error)
-	AggrIBCChannelHistoryTxs(startTime, endTime int64) ([]*dto.AggrIBCChannelTxsDTO, error)
 	Aggr24hActiveChannels(startTime int64) ([]*dto.Aggr24hActiveChannelsDTO, error)
 	Aggr24hActiveChains(startTime int64) ([]*dto.Aggr24hActiveChainsDTO, error)
+	AggrSeries(cond *dto.AnalyticsSeriesCondDTO) ([]*dto.AggrSeriesDTO, error)
+	AggrChainFlowTxs(startTime, endTime int64) ([]*dto.AggrChainFlowDTO, error)
+	AggrFailureCategory(startTime, endTime int64) ([]*dto.AggrFailureCategoryDTO, error)
 	Migrate(txs []*entity.ExIbcTx) error
 
 	// special method
@@ -69,13 +79,25 @@ type IExIbcTxRepo interface {
 	CountAll(stats []entity.IbcTxStatus) (int64, error)
 	CountTransferTxs(query dto.IbcTxQuery) (int64, error)
 	FindTransferTxs(query dto.IbcTxQuery, skip, limit int64) ([]*entity.ExIbcTx, error)
-	TxDetail(hash string, history bool) ([]*entity.ExIbcTx, error)
+	TxDetail(hash string) ([]*entity.ExIbcTx, error)
+	FindByScTxHash(scChainId, hash string) ([]*entity.ExIbcTx, error)
+	FindByDcTxHash(dcChainId, hash string) ([]*entity.ExIbcTx, error)
+	FindNextHopTx(scChainId, scAddr, scDenom string, startTime, endTime int64) (*entity.ExIbcTx, error)
+	FindPrevHopTx(dcChainId, dcAddr, dcDenom string, startTime, endTime int64) (*entity.ExIbcTx, error)
+
+	// tier specific, a tx is updated in the tier it's in. The processing txs are in the cold tier too if the tiering
+	// policy moves them, they are related by the ibc_tx_relate_history task and acknowledged by the history worker
+	FindByRecordId(recordId string, targetHistory bool) (*entity.ExIbcTx, error)
+	FindProcessingHistoryTxs(chainId string, limit int64) ([]*entity.ExIbcTx, error)
+	UpdateIbcHistoryTx(ibcTx *entity.ExIbcTx, repaired bool) error
 	GetNeedAcknowledgeTxs(history bool, startTime int64) ([]*entity.ExIbcTx, error)
 	GetNeedRecvPacketTxs(history bool) ([]*entity.ExIbcTx, error)
 	UpdateOne(recordId string, history bool, setData bson.M) error
 
 	// fix dc_chain_id
 	FindDcChainIdEmptyTxs(startTime, endTime, skip, limit int64, isTargetHistory bool) ([]*entity.ExIbcTx, error)
+	FindPacketMemoEmptyTxs(startTime, endTime, skip, limit int64, isTargetHistory bool) ([]*entity.ExIbcTx, error)
+	FindFailureCategoryEmptyTxs(startTime, endTime, skip, limit int64, isTargetHistory bool) ([]*entity.ExIbcTx, error)
 	FixDcChainId(recordId, dcChainId, dcChannel string, originStatus entity.IbcTxStatus, isTargetHistory bool) error
 	// fix base_denom_chain_id
 	FindByBaseDenom(startTime, endTime int64, baseDenom, baseDenomChainId string, isTargetHistory bool) ([]*entity.ExIbcTx, error)
@@ -127,6 +149,142 @@ func (repo *ExIbcTxRepo) DeleteByRecordIds(recordIds []string) error {
 	return err
 }
 
+func (repo *ExIbcTxRepo) DeleteHistoryByRecordIds(recordIds []string) error {
+	_, err := repo.collHistory().RemoveAll(context.Background(), bson.M{"record_id": bson.M{"$in": recordIds}})
+	return err
+}
+
+// FindByHeightRange the txs whose sc tx, dc tx or refunded tx is on the chain between the heights(inclusive), no upper
+// limit if endHeight is 0
+func (repo *ExIbcTxRepo) FindByHeightRange(chainId string, startHeight, endHeight int64, history bool) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	heightCond := bson.M{"$gte": startHeight}
+	if endHeight > 0 {
+		heightCond["$lte"] = endHeight
+	}
+	query := bson.M{
+		"$or": []bson.M{
+			{"sc_chain_id": chainId, "sc_tx_info.height": heightCond},
+			{"dc_chain_id": chainId, "dc_tx_info.height": heightCond},
+			{"sc_chain_id": chainId, "refunded_tx_info.height": heightCond},
+		},
+	}
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	err := coll.Find(context.Background(), query).All(&res)
+	return res, err
+}
+
+func (repo *ExIbcTxRepo) FindByRecordIds(recordIds []string, history bool) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	err := coll.Find(context.Background(), bson.M{"record_id": bson.M{"$in": recordIds}}).All(&res)
+	return res, err
+}
+
+// ResetRelayed clear the dc tx and the refunded tx of the txs and set them processing, they are related again by
+// the relate task
+func (repo *ExIbcTxRepo) ResetRelayed(recordIds []string, history bool) error {
+	return repo.resetRelayed(recordIds, bson.M{}, history)
+}
+
+func (repo *ExIbcTxRepo) resetRelayed(recordIds []string, set bson.M, history bool) error {
+	now := time.Now().Unix()
+	set["status"] = entity.IbcTxStatusProcessing
+	set["dc_tx_info"] = nil
+	set["refunded_tx_info"] = nil
+	set["retry_times"] = 0
+	set["next_try_time"] = now
+	set["update_at"] = now
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	_, err := coll.UpdateAll(context.Background(), bson.M{"record_id": bson.M{"$in": recordIds}}, bson.M{"$set": set})
+	return err
+}
+
+// CreateReindexIndexes the index of the txs marked by the re-index jobs in both tiers, the existing indexes are kept
+func (repo *ExIbcTxRepo) CreateReindexIndexes() error {
+	for _, coll := range []*qmgo.Collection{repo.coll(), repo.collHistory()} {
+		indexOpts := officialOpts.Index().SetName("reindex_job_id").
+			SetPartialFilterExpression(bson.M{"reindex_job_id": bson.M{"$exists": true}})
+		err := coll.CreateOneIndex(context.Background(), opts.IndexModel{Key: []string{"reindex_job_id", "record_id"}, IndexOptions: indexOpts})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkReindexing set the txs reindexing until they are replaced by ReplaceReindexing or removed by DeleteReindexing,
+// so the tier and the create_at of the txs are kept while the chain is re-indexed
+func (repo *ExIbcTxRepo) MarkReindexing(recordIds []string, jobId string, history bool) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":         entity.IbcTxStatusReindexing,
+			"reindex_job_id": jobId,
+			"update_at":      time.Now().Unix(),
+		},
+	}
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	_, err := coll.UpdateAll(context.Background(), bson.M{"record_id": bson.M{"$in": recordIds}}, update)
+	return err
+}
+
+// ResetReindexing reset the relayed txs as ResetRelayed, and mark them with the re-index job
+func (repo *ExIbcTxRepo) ResetReindexing(recordIds []string, jobId string, history bool) error {
+	return repo.resetRelayed(recordIds, bson.M{"reindex_job_id": jobId}, history)
+}
+
+// ReplaceReindexing replace the reindexing tx of the record id, qmgo.ErrNoSuchDocuments if it's not reindexing
+func (repo *ExIbcTxRepo) ReplaceReindexing(tx *entity.ExIbcTx, history bool) error {
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	query := bson.M{"record_id": tx.RecordId, "status": entity.IbcTxStatusReindexing}
+	return coll.ReplaceOne(context.Background(), query, tx)
+}
+
+// DeleteReindexing remove the txs of the job which are still reindexing, they are not found when the chain is
+// parsed again
+func (repo *ExIbcTxRepo) DeleteReindexing(jobId string, history bool) (int64, error) {
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	res, err := coll.RemoveAll(context.Background(), bson.M{"reindex_job_id": jobId, "status": entity.IbcTxStatusReindexing})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// FindReindexProcessing the processing txs of the job after the record id, in the order of the record id
+func (repo *ExIbcTxRepo) FindReindexProcessing(jobId, afterRecordId string, limit int64, history bool) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	coll := repo.coll()
+	if history {
+		coll = repo.collHistory()
+	}
+	query := bson.M{
+		"reindex_job_id": jobId,
+		"record_id":      bson.M{"$gt": afterRecordId},
+		"status":         entity.IbcTxStatusProcessing,
+	}
+	err := coll.Find(context.Background(), query).Sort("record_id").Limit(limit).All(&res)
+	return res, err
+}
+
 func (repo *ExIbcTxRepo) FindAll(skip, limit int64) ([]*entity.ExIbcTx, error) {
 	var res []*entity.ExIbcTx
 	err := repo.coll().Find(context.Background(), bson.M{}).Skip(skip).Limit(limit).All(&res)
@@ -157,6 +315,14 @@ func (repo *ExIbcTxRepo) FindByStatus(status []entity.IbcTxStatus, limit int64)
 	return res, err
 }
 
+// FindByStatusBefore the ibc txs of the status in the hot tier sent before the tx time, the oldest first
+func (repo *ExIbcTxRepo) FindByStatusBefore(status []entity.IbcTxStatus, txTime, limit int64) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	query := bson.M{"status": bson.M{"$in": status}, "tx_time": bson.M{"$lt": txTime}}
+	err := repo.coll().Find(context.Background(), query).Sort("tx_time").Limit(limit).All(&res)
+	return res, err
+}
+
 func (repo *ExIbcTxRepo) FindByTxTime(startTime, endTime, skip, limit int64) ([]*entity.ExIbcTx, error) {
 	var res []*entity.ExIbcTx
 	query := bson.M{
@@ -181,6 +347,65 @@ func (repo *ExIbcTxRepo) FindHistoryByTxTime(startTime, endTime, skip, limit int
 	return res, err
 }
 
+// archiveCond the txs of ex_ibc_tx which can be archived, the processing txs may be updated yet
+func (repo *ExIbcTxRepo) archiveCond(startTime, endTime int64) bson.M {
+	return bson.M{
+		"tx_time": bson.M{
+			"$gte": startTime,
+			"$lte": endTime,
+		},
+		"status": bson.M{
+			"$ne": entity.IbcTxStatusProcessing,
+		},
+	}
+}
+
+// FindHistoryToArchive the txs of ex_ibc_tx sent from the chain to be archived
+func (repo *ExIbcTxRepo) FindHistoryToArchive(chainId string, startTime, endTime, skip, limit int64) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	query := repo.archiveCond(startTime, endTime)
+	query["sc_chain_id"] = chainId
+	err := repo.collHistory().Find(context.Background(), query).Sort("tx_time", "_id").Skip(skip).Limit(limit).All(&res)
+	return res, err
+}
+
+// AggrArchivePartitions the sc chains and the months(UTC) of the txs of ex_ibc_tx to be archived, the tx time is before txTime
+func (repo *ExIbcTxRepo) AggrArchivePartitions(txTime int64) ([]*dto.ArchivePartitionDTO, error) {
+	match := bson.M{
+		"$match": repo.archiveCond(0, txTime-1),
+	}
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"chain_id": "$sc_chain_id",
+				"month": bson.M{
+					"$dateToString": bson.M{
+						"format": "%Y-%m",
+						"date":   bson.M{"$toDate": bson.M{"$multiply": []interface{}{"$tx_time", 1000}}},
+					},
+				},
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":      0,
+			"chain_id": "$_id.chain_id",
+			"month":    "$_id.month",
+		},
+	}
+	sort := bson.M{
+		"$sort": bson.M{
+			"month": 1,
+		},
+	}
+	var pipe []bson.M
+	pipe = append(pipe, match, group, project, sort)
+	var res []*dto.ArchivePartitionDTO
+	err := repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
+
 func (repo *ExIbcTxRepo) FindByCreateAt(startTime, endTime, skip, limit int64, isTargetHistory bool) ([]*entity.ExIbcTx, error) {
 	query := bson.M{
 		"create_at": bson.M{
@@ -251,6 +476,8 @@ func (repo *ExIbcTxRepo) parseUpdateIbcTxSql(ibcTx *entity.ExIbcTx, repaired boo
 		"retry_times":      ibcTx.RetryTimes,
 		"next_try_time":    ibcTx.NextTryTime,
 		"process_info":     ibcTx.ProcessInfo,
+		"failure_category": ibcTx.FailureCategory,
+		"failure_reason":   ibcTx.FailureReason,
 		"update_at":        ibcTx.UpdateAt,
 	}
 	if repaired {
@@ -340,14 +567,7 @@ func (repo *ExIbcTxRepo) countBaseDenomTransferTxsPipe(startTime, endTime int64)
 func (repo *ExIbcTxRepo) CountBaseDenomTransferTxs(startTime, endTime int64) ([]*dto.CountBaseDenomTxsDTO, error) {
 	pipe := repo.countBaseDenomTransferTxsPipe(startTime, endTime)
 	var res []*dto.CountBaseDenomTxsDTO
-	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
-	return res, err
-}
-
-func (repo *ExIbcTxRepo) CountBaseDenomHistoryTransferTxs(startTime, endTime int64) ([]*dto.CountBaseDenomTxsDTO, error) {
-	pipe := repo.countBaseDenomTransferTxsPipe(startTime, endTime)
-	var res []*dto.CountBaseDenomTxsDTO
-	err := repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
+	err := repo.aggregateTiers(pipe, &res)
 	return res, err
 }
 
@@ -391,28 +611,14 @@ func (repo *ExIbcTxRepo) countIBCTokenRecvTxsPipe(startTime, endTime int64) []bs
 func (repo *ExIbcTxRepo) CountIBCTokenRecvTxs(startTime, endTime int64) ([]*dto.CountIBCTokenRecvTxsDTO, error) {
 	pipe := repo.countIBCTokenRecvTxsPipe(startTime, endTime)
 	var res []*dto.CountIBCTokenRecvTxsDTO
-	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
-	return res, err
-}
-
-func (repo *ExIbcTxRepo) CountIBCTokenHistoryRecvTxs(startTime, endTime int64) ([]*dto.CountIBCTokenRecvTxsDTO, error) {
-	pipe := repo.countIBCTokenRecvTxsPipe(startTime, endTime)
-	var res []*dto.CountIBCTokenRecvTxsDTO
-	err := repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
+	err := repo.aggregateTiers(pipe, &res)
 	return res, err
 }
 
 func (repo *ExIbcTxRepo) GetRelayerInfo(startTime, endTime int64) ([]*dto.GetRelayerInfoDTO, error) {
 	pipe := repo.relayerInfoPipe(startTime, endTime)
 	var res []*dto.GetRelayerInfoDTO
-	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
-	return res, err
-}
-
-func (repo *ExIbcTxRepo) GetHistoryRelayerInfo(startTime, endTime int64) ([]*dto.GetRelayerInfoDTO, error) {
-	pipe := repo.relayerInfoPipe(startTime, endTime)
-	var res []*dto.GetRelayerInfoDTO
-	err := repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
+	err := repo.aggregateTiers(pipe, &res)
 	return res, err
 }
 
@@ -477,18 +683,13 @@ func (repo *ExIbcTxRepo) oneRelayerPacketCond(relayer *dto.GetRelayerInfoDTO) bs
 	}
 }
 
+// GetOneRelayerScTxPacketId the latest tx of the relayer in both tiers
 func (repo *ExIbcTxRepo) GetOneRelayerScTxPacketId(dto *dto.GetRelayerInfoDTO) (entity.ExIbcTx, error) {
-	var res entity.ExIbcTx
-	err := repo.coll().Find(context.Background(), repo.oneRelayerPacketCond(dto)).
-		Select(bson.M{"sc_tx_info.msg.msg.packet_id": 1}).Sort("-tx_time").Limit(1).One(&res)
-	return res, err
-}
-
-func (repo *ExIbcTxRepo) GetHistoryOneRelayerScTxPacketId(dto *dto.GetRelayerInfoDTO) (entity.ExIbcTx, error) {
-	var res entity.ExIbcTx
-	err := repo.collHistory().Find(context.Background(), repo.oneRelayerPacketCond(dto)).
-		Select(bson.M{"sc_tx_info.msg.msg.packet_id": 1}).Sort("-tx_time").Limit(1).One(&res)
-	return res, err
+	res, err := repo.findOneTiers(repo.oneRelayerPacketCond(dto), bson.D{{Key: "tx_time", Value: -1}})
+	if err != nil {
+		return entity.ExIbcTx{}, err
+	}
+	return *res, nil
 }
 
 func (repo *ExIbcTxRepo) relayerSuccessPacketCond(startTime, endTime int64) []bson.M {
@@ -586,31 +787,17 @@ func (repo *ExIbcTxRepo) relayerPacketAmountCond(startTime, endTime int64) []bso
 	return pipe
 }
 
-func (repo *ExIbcTxRepo) CountHistoryRelayerSuccessPacketTxs(startTime, endTime int64) ([]*dto.CountRelayerPacketTxsCntDTO, error) {
-	pipe := repo.relayerSuccessPacketCond(startTime, endTime)
-	var res []*dto.CountRelayerPacketTxsCntDTO
-	err := repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
-	return res, err
-}
-
 func (repo *ExIbcTxRepo) CountRelayerSuccessPacketTxs(startTime, endTime int64) ([]*dto.CountRelayerPacketTxsCntDTO, error) {
 	pipe := repo.relayerSuccessPacketCond(startTime, endTime)
 	var res []*dto.CountRelayerPacketTxsCntDTO
-	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	err := repo.aggregateTiers(pipe, &res)
 	return res, err
 }
 
 func (repo *ExIbcTxRepo) CountRelayerPacketTxsAndAmount(startTime, endTime int64) ([]*dto.CountRelayerPacketAmountDTO, error) {
 	pipe := repo.relayerPacketAmountCond(startTime, endTime)
 	var res []*dto.CountRelayerPacketAmountDTO
-	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
-	return res, err
-}
-
-func (repo *ExIbcTxRepo) CountHistoryRelayerPacketAmount(startTime, endTime int64) ([]*dto.CountRelayerPacketAmountDTO, error) {
-	pipe := repo.relayerPacketAmountCond(startTime, endTime)
-	var res []*dto.CountRelayerPacketAmountDTO
-	err := repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
+	err := repo.aggregateTiers(pipe, &res)
 	return res, err
 }
 
@@ -639,6 +826,12 @@ func (repo *ExIbcTxRepo) AggrIBCChannelTxsPipe(startTime, endTime int64) []bson.
 			"count": bson.M{
 				"$sum": 1,
 			},
+			"success_count": bson.M{
+				"$sum": statusCondExpr(entity.IbcTxStatusSuccess),
+			},
+			"refunded_count": bson.M{
+				"$sum": statusCondExpr(entity.IbcTxStatusRefunded),
+			},
 			"amount": bson.M{
 				"$sum": bson.M{
 					"$toDouble": "$sc_tx_info.msg_amount.amount",
@@ -656,6 +849,8 @@ func (repo *ExIbcTxRepo) AggrIBCChannelTxsPipe(startTime, endTime int64) []bson.
 			"sc_channel":          "$_id.sc_channel",
 			"dc_channel":          "$_id.dc_channel",
 			"count":               "$count",
+			"success_count":       "$success_count",
+			"refunded_count":      "$refunded_count",
 			"amount":              "$amount",
 		},
 	}
@@ -664,17 +859,163 @@ func (repo *ExIbcTxRepo) AggrIBCChannelTxsPipe(startTime, endTime int64) []bson.
 	return pipe
 }
 
-func (repo *ExIbcTxRepo) AggrIBCChannelTxs(startTime, endTime int64) ([]*dto.AggrIBCChannelTxsDTO, error) {
-	pipe := repo.AggrIBCChannelTxsPipe(startTime, endTime)
-	var res []*dto.AggrIBCChannelTxsDTO
+// statusCondExpr 1 if the status of ibc tx is the given status, otherwise 0
+func statusCondExpr(status entity.IbcTxStatus) bson.M {
+	return bson.M{
+		"$cond": []interface{}{bson.M{"$eq": []interface{}{"$status", status}}, 1, 0},
+	}
+}
+
+// aggrChainFlowTxsPipe 按方向统计成功的跨链交易，sc_chain_id -> dc_chain_id
+func (repo *ExIbcTxRepo) aggrChainFlowTxsPipe(startTime, endTime int64) []bson.M {
+	match := bson.M{
+		"$match": bson.M{
+			"create_at": bson.M{
+				"$gte": startTime,
+				"$lte": endTime,
+			},
+			"status": entity.IbcTxStatusSuccess,
+		},
+	}
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"sc_chain_id":         "$sc_chain_id",
+				"dc_chain_id":         "$dc_chain_id",
+				"base_denom":          "$base_denom",
+				"base_denom_chain_id": "$base_denom_chain_id",
+			},
+			"count": bson.M{
+				"$sum": 1,
+			},
+			"amount": bson.M{
+				"$sum": bson.M{
+					"$toDouble": "$sc_tx_info.msg_amount.amount",
+				},
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":                 0,
+			"sc_chain_id":         "$_id.sc_chain_id",
+			"dc_chain_id":         "$_id.dc_chain_id",
+			"base_denom":          "$_id.base_denom",
+			"base_denom_chain_id": "$_id.base_denom_chain_id",
+			"count":               "$count",
+			"amount":              "$amount",
+		},
+	}
+	var pipe []bson.M
+	pipe = append(pipe, match, group, project)
+	return pipe
+}
+
+func (repo *ExIbcTxRepo) AggrChainFlowTxs(startTime, endTime int64) ([]*dto.AggrChainFlowDTO, error) {
+	pipe := repo.aggrChainFlowTxsPipe(startTime, endTime)
+	var res []*dto.AggrChainFlowDTO
+	err := repo.aggregateTiers(pipe, &res)
+	return res, err
+}
+
+// aggrFailureCategoryPipe the failed and refunded txs by channel and failure category
+func (repo *ExIbcTxRepo) aggrFailureCategoryPipe(startTime, endTime int64) []bson.M {
+	match := bson.M{
+		"$match": bson.M{
+			"tx_time": bson.M{
+				"$gte": startTime,
+				"$lte": endTime,
+			},
+			"status": bson.M{
+				"$in": []entity.IbcTxStatus{entity.IbcTxStatusFailed, entity.IbcTxStatusRefunded},
+			},
+		},
+	}
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"sc_chain_id":      "$sc_chain_id",
+				"sc_channel":       "$sc_channel",
+				"dc_chain_id":      "$dc_chain_id",
+				"dc_channel":       "$dc_channel",
+				"failure_category": "$failure_category",
+			},
+			"count": bson.M{
+				"$sum": 1,
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":              0,
+			"sc_chain_id":      "$_id.sc_chain_id",
+			"sc_channel":       "$_id.sc_channel",
+			"dc_chain_id":      "$_id.dc_chain_id",
+			"dc_channel":       "$_id.dc_channel",
+			"failure_category": "$_id.failure_category",
+			"count":            "$count",
+		},
+	}
+	var pipe []bson.M
+	pipe = append(pipe, match, group, project)
+	return pipe
+}
+
+func (repo *ExIbcTxRepo) AggrFailureCategory(startTime, endTime int64) ([]*dto.AggrFailureCategoryDTO, error) {
+	pipe := repo.aggrFailureCategoryPipe(startTime, endTime)
+	var res []*dto.AggrFailureCategoryDTO
+	err := repo.aggregateTiers(pipe, &res)
+	return res, err
+}
+
+// AggrSeries aggregate the latest ibc txs by bucket, it's used for the buckets finer than the statistics segments
+func (repo *ExIbcTxRepo) AggrSeries(cond *dto.AnalyticsSeriesCondDTO) ([]*dto.AggrSeriesDTO, error) {
+	match := bson.M{
+		"create_at": bson.M{
+			"$gte": cond.StartTime,
+			"$lte": cond.EndTime,
+		},
+		"status": bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		},
+	}
+	var and []bson.M
+	if cond.ChainId != "" {
+		and = append(and, bson.M{"$or": []bson.M{{"sc_chain_id": cond.ChainId}, {"dc_chain_id": cond.ChainId}}})
+	}
+	if len(cond.ChannelIds) > 0 {
+		var or []bson.M
+		for _, v := range cond.ChannelIds {
+			split := strings.Split(v, "|")
+			if len(split) != 4 {
+				continue
+			}
+			or = append(or, bson.M{"sc_chain_id": split[0], "sc_channel": split[1], "dc_chain_id": split[2], "dc_channel": split[3]})
+		}
+		and = append(and, bson.M{"$or": or})
+	}
+	if len(and) > 0 {
+		match["$and"] = and
+	}
+	if cond.BaseDenom != "" {
+		match["base_denom"] = cond.BaseDenom
+	}
+	if cond.BaseDenomChainId != "" {
+		match["base_denom_chain_id"] = cond.BaseDenomChainId
+	}
+
+	pipe := seriesPipe(match, seriesBucketExpr("create_at", cond), "$base_denom", 1,
+		statusCondExpr(entity.IbcTxStatusSuccess), statusCondExpr(entity.IbcTxStatusRefunded),
+		bson.M{"$toDouble": "$sc_tx_info.msg_amount.amount"})
+	var res []*dto.AggrSeriesDTO
 	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
 	return res, err
 }
 
-func (repo *ExIbcTxRepo) AggrIBCChannelHistoryTxs(startTime, endTime int64) ([]*dto.AggrIBCChannelTxsDTO, error) {
+func (repo *ExIbcTxRepo) AggrIBCChannelTxs(startTime, endTime int64) ([]*dto.AggrIBCChannelTxsDTO, error) {
 	pipe := repo.AggrIBCChannelTxsPipe(startTime, endTime)
 	var res []*dto.AggrIBCChannelTxsDTO
-	err := repo.collHistory().Aggregate(context.Background(), pipe).All(&res)
+	err := repo.aggregateTiers(pipe, &res)
 	return res, err
 }
 
@@ -1051,6 +1392,20 @@ func parseQuery(queryCond dto.IbcTxQuery) bson.M {
 		}
 	}
 
+	//memo type
+	if len(queryCond.MemoType) > 0 {
+		query["packet_memo.type"] = bson.M{
+			"$in": queryCond.MemoType,
+		}
+	}
+
+	//failure category
+	if len(queryCond.FailureCategory) > 0 {
+		query["failure_category"] = bson.M{
+			"$in": queryCond.FailureCategory,
+		}
+	}
+
 	//status
 	if len(queryCond.Status) == 0 {
 		query["status"] = bson.M{
@@ -1064,18 +1419,17 @@ func parseQuery(queryCond dto.IbcTxQuery) bson.M {
 	return query
 }
 
+// CountTransferTxs the count of the transfer txs of both tiers
 func (repo *ExIbcTxRepo) CountTransferTxs(query dto.IbcTxQuery) (int64, error) {
-	return repo.coll().Find(context.Background(), parseQuery(query)).Count()
+	return repo.countTiers(parseQuery(query))
 }
 
+// FindTransferTxs the page of the transfer txs of both tiers, the latest first
 func (repo *ExIbcTxRepo) FindTransferTxs(query dto.IbcTxQuery, skip, limit int64) ([]*entity.ExIbcTx, error) {
-	var res []*entity.ExIbcTx
-	err := repo.coll().Find(context.Background(), parseQuery(query)).Skip(skip).Limit(limit).Sort("-tx_time").All(&res)
-	return res, err
+	return repo.findSortedTiers(parseQuery(query), bson.D{{Key: "tx_time", Value: -1}}, skip, limit)
 }
 
-func (repo *ExIbcTxRepo) TxDetail(hash string, history bool) ([]*entity.ExIbcTx, error) {
-	var res []*entity.ExIbcTx
+func (repo *ExIbcTxRepo) TxDetail(hash string) ([]*entity.ExIbcTx, error) {
 	query := bson.M{
 		"status": bson.M{
 			"$in": entity.IbcTxUsefulStatus,
@@ -1086,12 +1440,53 @@ func (repo *ExIbcTxRepo) TxDetail(hash string, history bool) ([]*entity.ExIbcTx,
 			{"refunded_tx_info.hash": hash},
 		},
 	}
-	if history {
-		err := repo.collHistory().Find(context.Background(), query).All(&res)
-		return res, err
+	return repo.findTiers(query)
+}
+
+// FindByScTxHash the ibc txs sent in the tx, e.g. the txs forwarded by packet-forward-middleware in a recv tx
+func (repo *ExIbcTxRepo) FindByScTxHash(scChainId, hash string) ([]*entity.ExIbcTx, error) {
+	query := bson.M{
+		"sc_chain_id":     scChainId,
+		"sc_tx_info.hash": hash,
+		"status": bson.M{
+			"$in": entity.IbcTxUsefulStatus,
+		},
 	}
-	err := repo.coll().Find(context.Background(), query).All(&res)
-	return res, err
+	return repo.findTiers(query)
+}
+
+// FindByDcTxHash the ibc txs received successfully in the tx
+func (repo *ExIbcTxRepo) FindByDcTxHash(dcChainId, hash string) ([]*entity.ExIbcTx, error) {
+	query := bson.M{
+		"dc_chain_id":     dcChainId,
+		"dc_tx_info.hash": hash,
+		"status":          entity.IbcTxStatusSuccess,
+	}
+	return repo.findTiers(query)
+}
+
+// FindNextHopTx the first ibc tx sending the denom from the address in the time range
+func (repo *ExIbcTxRepo) FindNextHopTx(scChainId, scAddr, scDenom string, startTime, endTime int64) (*entity.ExIbcTx, error) {
+	query := bson.M{
+		"sc_chain_id":     scChainId,
+		"sc_addr":         scAddr,
+		"denoms.sc_denom": scDenom,
+		"tx_time":         bson.M{"$gte": startTime, "$lte": endTime},
+		"status":          bson.M{"$in": entity.IbcTxUsefulStatus},
+	}
+	return repo.findOneTiers(query, bson.D{{Key: "tx_time", Value: 1}})
+}
+
+// FindPrevHopTx the last ibc tx received the denom by the address successfully in the time range
+func (repo *ExIbcTxRepo) FindPrevHopTx(dcChainId, dcAddr, dcDenom string, startTime, endTime int64) (*entity.ExIbcTx, error) {
+	query := bson.M{
+		"dc_chain_id":     dcChainId,
+		"dc_addr":         dcAddr,
+		"denoms.dc_denom": dcDenom,
+		"dc_tx_info.time": bson.M{"$gte": startTime, "$lte": endTime},
+		"status":          entity.IbcTxStatusSuccess,
+	}
+	return repo.findOneTiers(query, bson.D{{Key: "dc_tx_info.time", Value: -1}})
 }
 
 func (repo *ExIbcTxRepo) GetNeedAcknowledgeTxs(history bool, startTime int64) ([]*entity.ExIbcTx, error) {
@@ -1147,6 +1542,53 @@ func (repo *ExIbcTxRepo) FindDcChainIdEmptyTxs(startTime, endTime, skip, limit i
 	return txs, err
 }
 
+// FindPacketMemoEmptyTxs the txs with memo or legacy forward receiver, but the packet memo is not decoded
+func (repo *ExIbcTxRepo) FindPacketMemoEmptyTxs(startTime, endTime, skip, limit int64, isTargetHistory bool) ([]*entity.ExIbcTx, error) {
+	query := bson.M{
+		"create_at": bson.M{
+			"$gte": startTime,
+			"$lte": endTime,
+		},
+		"packet_memo": bson.M{"$exists": false},
+		"$or": []bson.M{
+			{"sc_tx_info.msg.msg.memo": bson.M{"$nin": []interface{}{"", nil}}},
+			{"dc_addr": bson.M{"$regex": `\|`}},
+		},
+	}
+
+	var txs []*entity.ExIbcTx
+	var err error
+	if isTargetHistory {
+		err = repo.collHistory().Find(context.Background(), query).Skip(skip).Sort("-create_at").Limit(limit).All(&txs)
+	} else {
+		err = repo.coll().Find(context.Background(), query).Skip(skip).Sort("-create_at").Limit(limit).All(&txs)
+	}
+	return txs, err
+}
+
+// FindFailureCategoryEmptyTxs the failed and refunded txs, whose failure category is not categorized
+func (repo *ExIbcTxRepo) FindFailureCategoryEmptyTxs(startTime, endTime, skip, limit int64, isTargetHistory bool) ([]*entity.ExIbcTx, error) {
+	query := bson.M{
+		"create_at": bson.M{
+			"$gte": startTime,
+			"$lte": endTime,
+		},
+		"status": bson.M{
+			"$in": []entity.IbcTxStatus{entity.IbcTxStatusFailed, entity.IbcTxStatusRefunded},
+		},
+		"failure_category": bson.M{"$in": []interface{}{"", nil}},
+	}
+
+	var txs []*entity.ExIbcTx
+	var err error
+	if isTargetHistory {
+		err = repo.collHistory().Find(context.Background(), query).Skip(skip).Sort("-create_at").Limit(limit).All(&txs)
+	} else {
+		err = repo.coll().Find(context.Background(), query).Skip(skip).Sort("-create_at").Limit(limit).All(&txs)
+	}
+	return txs, err
+}
+
 func (repo *ExIbcTxRepo) FixDcChainId(recordId, dcChainId, dcChannel string, originStatus entity.IbcTxStatus, isTargetHistory bool) error {
 	set := bson.M{}
 	if dcChainId == "" {
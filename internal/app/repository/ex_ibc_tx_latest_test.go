@@ -51,12 +51,6 @@ func TestExIbcTxRepo_GetOneRelayerScTxPacketId(t *testing.T) {
 
 func TestExIbcTxRepo_GetHistoryRelayerSuccessPacketTxs(t *testing.T) {
 	now := time.Now().Unix()
-	data, err := new(ExIbcTxRepo).CountHistoryRelayerSuccessPacketTxs(now-86400, now)
-	if err != nil {
-		t.Fatal(err.Error())
-	}
-	ret, _ := json.Marshal(data)
-	t.Log(string(ret))
 	data1, err1 := new(ExIbcTxRepo).CountRelayerSuccessPacketTxs(now-86400, now)
 	if err1 != nil {
 		t.Fatal(err1.Error())
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The ibc txs are stored in two tiers, the hot tier ex_ibc_tx_latest and the cold tier ex_ibc_tx. ibc_tx_migrate_task
+// moves the txs from the hot tier to the cold tier by the tiering policy, so a tx is in one of the tiers. The reading
+// methods below query both tiers by $unionWith(MongoDB 4.4+), the callers don't need to know which tier a tx is in.
+
+// unionTiersPipe the pipe on the hot tier followed by the cold tier. The first stage of the pipe must be $match, it's
+// applied to the cold tier before the union, so that the indexes of both tiers are used. The hot tier and the cold tier
+// are not read at the same point in time, a tx moved by ibc_tx_migrate_task between the two reads is seen in both
+// tiers, so the txs are deduplicated by the record id before the following stages, the one of the hot tier is kept.
+func unionTiersPipe(pipe []bson.M) []bson.M {
+	if len(pipe) == 0 {
+		return pipe
+	}
+	union := bson.M{
+		"$unionWith": bson.M{
+			"coll":     entity.ExIbcTx{}.CollectionName(true),
+			"pipeline": []bson.M{pipe[0]},
+		},
+	}
+	dedup := []bson.M{
+		{"$group": bson.M{"_id": "$record_id", "doc": bson.M{"$first": "$$ROOT"}}},
+		{"$replaceRoot": bson.M{"newRoot": "$doc"}},
+	}
+	res := make([]bson.M, 0, len(pipe)+3)
+	res = append(res, pipe[0], union)
+	res = append(res, dedup...)
+	return append(res, pipe[1:]...)
+}
+
+// aggregateTiers aggregate the ibc txs of both tiers, the deduplication may exceed the memory limit of a stage, so
+// the disk is allowed
+func (repo *ExIbcTxRepo) aggregateTiers(pipe []bson.M, result interface{}) error {
+	aggregateOpts := opts.AggregateOptions{AggregateOptions: officialOpts.Aggregate().SetAllowDiskUse(true)}
+	return repo.coll().Aggregate(context.Background(), unionTiersPipe(pipe), aggregateOpts).All(result)
+}
+
+// findTiers find the ibc txs of both tiers
+func (repo *ExIbcTxRepo) findTiers(query bson.M) ([]*entity.ExIbcTx, error) {
+	var res []*entity.ExIbcTx
+	err := repo.aggregateTiers([]bson.M{{"$match": query}}, &res)
+	return res, err
+}
+
+// findSortedTiers the page of the ibc txs of both tiers in the order of sort. Each tier is sorted and limited to
+// skip+limit by its indexes before the union, so that only the few txs left are deduplicated and sorted again.
+func (repo *ExIbcTxRepo) findSortedTiers(query bson.M, sort bson.D, skip, limit int64) ([]*entity.ExIbcTx, error) {
+	tierPipe := []bson.M{{"$match": query}, {"$sort": sort}, {"$limit": skip + limit}}
+	pipe := make([]bson.M, 0, len(tierPipe)+6)
+	pipe = append(pipe, tierPipe...)
+	pipe = append(pipe,
+		bson.M{"$unionWith": bson.M{"coll": entity.ExIbcTx{}.CollectionName(true), "pipeline": tierPipe}},
+		bson.M{"$group": bson.M{"_id": "$record_id", "doc": bson.M{"$first": "$$ROOT"}}},
+		bson.M{"$replaceRoot": bson.M{"newRoot": "$doc"}},
+		bson.M{"$sort": sort},
+	)
+	if skip > 0 {
+		pipe = append(pipe, bson.M{"$skip": skip})
+	}
+	pipe = append(pipe, bson.M{"$limit": limit})
+
+	var res []*entity.ExIbcTx
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
+
+// findOneTiers the first ibc tx of both tiers in the order of sort
+func (repo *ExIbcTxRepo) findOneTiers(query bson.M, sort bson.D) (*entity.ExIbcTx, error) {
+	res, err := repo.findSortedTiers(query, sort, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, qmgo.ErrNoSuchDocuments
+	}
+	return res[0], nil
+}
+
+// countTiers the count of the ibc txs of both tiers, a tx moved by ibc_tx_migrate_task between the two counts may be
+// counted twice
+func (repo *ExIbcTxRepo) countTiers(query bson.M) (int64, error) {
+	count, err := repo.coll().Find(context.Background(), query).Count()
+	if err != nil {
+		return 0, err
+	}
+	historyCount, err := repo.collHistory().Find(context.Background(), query).Count()
+	if err != nil {
+		return 0, err
+	}
+	return count + historyCount, nil
+}
+
+// CreateAtRange the min and the max create_at of the ibc txs of both tiers, it returns qmgo.ErrNoSuchDocuments if there is
+// no tx
+func (repo *ExIbcTxRepo) CreateAtRange() (int64, int64, error) {
+	var min, max int64
+	for _, v := range []func() (*entity.ExIbcTx, error){repo.First, repo.FirstHistory} {
+		tx, err := v()
+		if err != nil && err != qmgo.ErrNoSuchDocuments {
+			return 0, 0, err
+		}
+		if err == nil && (min == 0 || tx.CreateAt < min) {
+			min = tx.CreateAt
+		}
+	}
+	for _, v := range []func() (*entity.ExIbcTx, error){repo.Latest, repo.LatestHistory} {
+		tx, err := v()
+		if err != nil && err != qmgo.ErrNoSuchDocuments {
+			return 0, 0, err
+		}
+		if err == nil && tx.CreateAt > max {
+			max = tx.CreateAt
+		}
+	}
+	if min == 0 && max == 0 {
+		return 0, 0, qmgo.ErrNoSuchDocuments
+	}
+	return min, max, nil
+}
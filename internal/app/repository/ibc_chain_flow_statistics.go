@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IChainFlowStatisticsRepo interface {
+	CreateNew() error
+	SwitchColl() error
+	CopyToNew(endTime int64) error
+	BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCChainFlowStatistics) error
+	BatchInsertToNew(batch []*entity.IBCChainFlowStatistics) error
+	AggrFlows(startTime, endTime int64, baseDenom, baseDenomChainId string) ([]*dto.AggrChainFlowDTO, error)
+}
+
+var _ IChainFlowStatisticsRepo = new(ChainFlowStatisticsRepo)
+
+type ChainFlowStatisticsRepo struct {
+}
+
+func (repo *ChainFlowStatisticsRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCChainFlowStatisticsCollName)
+}
+
+func (repo *ChainFlowStatisticsRepo) collNew() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCChainFlowStatisticsNewCollName)
+}
+
+func (repo *ChainFlowStatisticsRepo) CreateNew() error {
+	indexOpts := officialOpts.Index().SetUnique(true).SetName("chain_flow_statistics_unique")
+	key := []string{"sc_chain_id", "dc_chain_id", "base_denom", "base_denom_chain_id", "-segment_start_time", "-segment_end_time"}
+	return repo.collNew().CreateOneIndex(context.Background(), opts.IndexModel{Key: key, IndexOptions: indexOpts})
+}
+
+// CopyToNew copy the rows of the segments ended before endTime into the new collection
+func (repo *ChainFlowStatisticsRepo) CopyToNew(endTime int64) error {
+	return copySegmentsToNew(repo.coll(), entity.IBCChainFlowStatisticsNewCollName, endTime)
+}
+
+func (repo *ChainFlowStatisticsRepo) SwitchColl() error {
+	command := bson.D{{Key: "renameCollection", Value: fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCChainFlowStatisticsNewCollName)},
+		{Key: "to", Value: fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCChainFlowStatisticsCollName)},
+		{Key: "dropTarget", Value: true}}
+	return mgo.Database(adminDatabase).RunCommand(context.Background(), command).Err()
+}
+
+func (repo *ChainFlowStatisticsRepo) BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCChainFlowStatistics) error {
+	callback := func(sessCtx context.Context) (interface{}, error) {
+		query := bson.M{
+			"segment_start_time": segmentStartTime,
+			"segment_end_time":   segmentEndTime,
+		}
+		if _, err := repo.coll().RemoveAll(sessCtx, query); err != nil {
+			return nil, err
+		}
+
+		if len(batch) == 0 {
+			return nil, nil
+		}
+
+		if _, err := repo.coll().InsertMany(sessCtx, batch); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+	_, err := mgo.DoTransaction(context.Background(), callback)
+	return err
+}
+
+func (repo *ChainFlowStatisticsRepo) BatchInsertToNew(batch []*entity.IBCChainFlowStatistics) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	_, err := repo.collNew().InsertMany(context.Background(), batch)
+	return err
+}
+
+func (repo *ChainFlowStatisticsRepo) AggrFlows(startTime, endTime int64, baseDenom, baseDenomChainId string) ([]*dto.AggrChainFlowDTO, error) {
+	query := bson.M{
+		"segment_start_time": bson.M{
+			"$gte": startTime,
+			"$lte": endTime,
+		},
+	}
+	if baseDenom != "" {
+		query["base_denom"] = baseDenom
+	}
+	if baseDenomChainId != "" {
+		query["base_denom_chain_id"] = baseDenomChainId
+	}
+
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"sc_chain_id":         "$sc_chain_id",
+				"dc_chain_id":         "$dc_chain_id",
+				"base_denom":          "$base_denom",
+				"base_denom_chain_id": "$base_denom_chain_id",
+			},
+			"count": bson.M{
+				"$sum": "$transfer_txs",
+			},
+			"amount": bson.M{
+				"$sum": bson.M{
+					"$toDouble": "$transfer_amount",
+				},
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":                 0,
+			"sc_chain_id":         "$_id.sc_chain_id",
+			"dc_chain_id":         "$_id.dc_chain_id",
+			"base_denom":          "$_id.base_denom",
+			"base_denom_chain_id": "$_id.base_denom_chain_id",
+			"count":               "$count",
+			"amount":              "$amount",
+		},
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, bson.M{"$match": query}, group, project)
+	var res []*dto.AggrChainFlowDTO
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IChainOnboardingRepo interface {
+	FindOne(chainId string) (*entity.IBCChainOnboarding, error)
+	Save(onboarding *entity.IBCChainOnboarding) error
+	Touch(chainId string) error
+}
+
+var _ IChainOnboardingRepo = new(ChainOnboardingRepo)
+
+type ChainOnboardingRepo struct {
+}
+
+func (repo *ChainOnboardingRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCChainOnboarding{}.CollectionName())
+}
+
+func (repo *ChainOnboardingRepo) FindOne(chainId string) (*entity.IBCChainOnboarding, error) {
+	var res *entity.IBCChainOnboarding
+	err := repo.coll().Find(context.Background(), bson.M{"chain_id": chainId}).One(&res)
+	return res, err
+}
+
+func (repo *ChainOnboardingRepo) Save(onboarding *entity.IBCChainOnboarding) error {
+	now := time.Now().Unix()
+	if onboarding.CreateAt == 0 {
+		onboarding.CreateAt = now
+	}
+	onboarding.UpdateAt = now
+	_, err := repo.coll().Upsert(context.Background(), bson.M{"chain_id": onboarding.ChainId}, onboarding)
+	return err
+}
+
+// Touch refresh the update_at of the running onboarding
+func (repo *ChainOnboardingRepo) Touch(chainId string) error {
+	return repo.coll().UpdateOne(context.Background(), bson.M{"chain_id": chainId}, bson.M{"$set": bson.M{"update_at": time.Now().Unix()}})
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IChainRegistryDiffRepo interface {
+	FindAll() ([]*entity.IBCChainRegistryDiff, error)
+	FindOne(diffId string) (*entity.IBCChainRegistryDiff, error)
+	Save(diff *entity.IBCChainRegistryDiff) error
+	Apply(diff *entity.IBCChainRegistryDiff) error
+	UpdateStatus(diffId string, status entity.ChainRegistryDiffStatus) error
+	List(query dto.ChainRegistryDiffQuery, skip, limit int64) ([]*entity.IBCChainRegistryDiff, error)
+	CountList(query dto.ChainRegistryDiffQuery) (int64, error)
+}
+
+var _ IChainRegistryDiffRepo = new(ChainRegistryDiffRepo)
+
+type ChainRegistryDiffRepo struct {
+}
+
+func (repo *ChainRegistryDiffRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCChainRegistryDiff{}.CollectionName())
+}
+
+func (repo *ChainRegistryDiffRepo) FindAll() ([]*entity.IBCChainRegistryDiff, error) {
+	var res []*entity.IBCChainRegistryDiff
+	err := repo.coll().Find(context.Background(), bson.M{}).All(&res)
+	return res, err
+}
+
+func (repo *ChainRegistryDiffRepo) FindOne(diffId string) (*entity.IBCChainRegistryDiff, error) {
+	var res *entity.IBCChainRegistryDiff
+	err := repo.coll().Find(context.Background(), bson.M{"diff_id": diffId}).One(&res)
+	return res, err
+}
+
+func (repo *ChainRegistryDiffRepo) Save(diff *entity.IBCChainRegistryDiff) error {
+	now := time.Now().Unix()
+	if diff.CreateAt == 0 {
+		diff.CreateAt = now
+	}
+	diff.UpdateAt = now
+	_, err := repo.coll().Upsert(context.Background(), bson.M{"diff_id": diff.DiffId}, diff)
+	return err
+}
+
+// Apply set the new value to the field of the target collection, then mark the diff as applied
+func (repo *ChainRegistryDiffRepo) Apply(diff *entity.IBCChainRegistryDiff) error {
+	query := bson.M{"chain_id": diff.ChainId}
+	switch diff.Coll {
+	case entity.ChainConfig{}.CollectionName(), entity.ChainRegistry{}.CollectionName():
+	case entity.IBCBaseDenom{}.CollectionName():
+		query["denom"] = diff.Denom
+	default:
+		return fmt.Errorf("unsupported collection %s", diff.Coll)
+	}
+
+	if err := mgo.Database(ibcDatabase).Collection(diff.Coll).UpdateOne(context.Background(), query, bson.M{
+		"$set": bson.M{
+			diff.Field: diff.NewValue,
+		},
+	}); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	diff.Status = entity.ChainRegistryDiffStatusApplied
+	diff.ApplyTime = now
+	return repo.coll().UpdateOne(context.Background(), bson.M{"diff_id": diff.DiffId}, bson.M{
+		"$set": bson.M{
+			"status":     diff.Status,
+			"apply_time": now,
+			"update_at":  now,
+		},
+	})
+}
+
+func (repo *ChainRegistryDiffRepo) UpdateStatus(diffId string, status entity.ChainRegistryDiffStatus) error {
+	return repo.coll().UpdateOne(context.Background(), bson.M{"diff_id": diffId}, bson.M{
+		"$set": bson.M{
+			"status":    status,
+			"update_at": time.Now().Unix(),
+		},
+	})
+}
+
+func parseChainRegistryDiffQuery(queryCond dto.ChainRegistryDiffQuery) bson.M {
+	query := bson.M{}
+	if queryCond.ChainId != "" {
+		query["chain_id"] = queryCond.ChainId
+	}
+	if queryCond.Coll != "" {
+		query["coll"] = queryCond.Coll
+	}
+	if queryCond.Status != "" {
+		query["status"] = queryCond.Status
+	}
+	return query
+}
+
+func (repo *ChainRegistryDiffRepo) List(query dto.ChainRegistryDiffQuery, skip, limit int64) ([]*entity.IBCChainRegistryDiff, error) {
+	var res []*entity.IBCChainRegistryDiff
+	err := repo.coll().Find(context.Background(), parseChainRegistryDiffQuery(query)).Skip(skip).Limit(limit).
+		Sort("-update_at", "chain_id").All(&res)
+	return res, err
+}
+
+func (repo *ChainRegistryDiffRepo) CountList(query dto.ChainRegistryDiffQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseChainRegistryDiffQuery(query)).Count()
+}
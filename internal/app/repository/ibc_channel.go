@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/qiniu/qmgo"
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,9 +17,10 @@ type IChannelRepo interface {
 	InsertBatch(batch []*entity.IBCChannel) error
 	DeleteByChannelIds(channelIds []string) error
 	UpdateChannel(channel *entity.IBCChannel) error
-	List(chainA, chainB string, status entity.ChannelStatus, skip, limit int64) (entity.IBCChannelList, error)
-	CountList(chainA, chainB string, status entity.ChannelStatus) (int64, error)
+	List(chainA, chainB string, status entity.ChannelStatus, channelType string, skip, limit int64) (entity.IBCChannelList, error)
+	CountList(chainA, chainB string, status entity.ChannelStatus, channelType string) (int64, error)
 	CountStatus(status entity.ChannelStatus) (int64, error)
+	AggrChannelType() ([]*dto.AggrChannelTypeDTO, error)
 }
 
 var _ IChannelRepo = new(ChannelRepo)
@@ -45,7 +47,7 @@ func (repo *ChannelRepo) UpdateOne(channelId string, updateTime, relayerCnt int6
 	})
 }
 
-func (repo *ChannelRepo) analyzeListParam(chainA, chainB string, status entity.ChannelStatus) map[string]interface{} {
+func (repo *ChannelRepo) analyzeListParam(chainA, chainB string, status entity.ChannelStatus, channelType string) map[string]interface{} {
 	chainCond := make(map[string]interface{}, 0)
 	if chainA == constant.AllChain && chainB == constant.AllChain {
 		// 无条件
@@ -65,6 +67,9 @@ func (repo *ChannelRepo) analyzeListParam(chainA, chainB string, status entity.C
 	if status != 0 {
 		statusCond["status"] = status
 	}
+	if channelType != "" {
+		statusCond["channel_type"] = channelType
+	}
 
 	if len(chainCond) == 0 && len(statusCond) == 0 {
 		return bson.M{}
@@ -77,15 +82,15 @@ func (repo *ChannelRepo) analyzeListParam(chainA, chainB string, status entity.C
 	}
 }
 
-func (repo *ChannelRepo) List(chainA, chainB string, status entity.ChannelStatus, skip, limit int64) (entity.IBCChannelList, error) {
-	param := repo.analyzeListParam(chainA, chainB, status)
+func (repo *ChannelRepo) List(chainA, chainB string, status entity.ChannelStatus, channelType string, skip, limit int64) (entity.IBCChannelList, error) {
+	param := repo.analyzeListParam(chainA, chainB, status, channelType)
 	var res entity.IBCChannelList
 	err := repo.coll().Find(context.Background(), param).Limit(limit).Skip(skip).Sort("-transfer_txs").All(&res)
 	return res, err
 }
 
-func (repo *ChannelRepo) CountList(chainA, chainB string, status entity.ChannelStatus) (int64, error) {
-	param := repo.analyzeListParam(chainA, chainB, status)
+func (repo *ChannelRepo) CountList(chainA, chainB string, status entity.ChannelStatus, channelType string) (int64, error) {
+	param := repo.analyzeListParam(chainA, chainB, status, channelType)
 	count, err := repo.coll().Find(context.Background(), param).Count()
 	return count, err
 }
@@ -147,8 +152,37 @@ func (repo *ChannelRepo) UpdateChannel(channel *entity.IBCChannel) error {
 			"latest_open_time":   channel.LatestOpenTime,
 			"transfer_txs":       channel.TransferTxs,
 			"transfer_txs_value": channel.TransferTxsValue,
+			"channel_type":       channel.ChannelType,
 			"update_at":          time.Now().Unix(),
 		},
 	}
 	return repo.coll().UpdateOne(context.Background(), query, update)
 }
+
+func (repo *ChannelRepo) AggrChannelType() ([]*dto.AggrChannelTypeDTO, error) {
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"channel_type": "$channel_type",
+				"status":       "$status",
+			},
+			"count": bson.M{
+				"$sum": 1,
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":          0,
+			"channel_type": "$_id.channel_type",
+			"status":       "$_id.status",
+			"count":        "$count",
+		},
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, group, project)
+	var res []*dto.AggrChannelTypeDTO
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
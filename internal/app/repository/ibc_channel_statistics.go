@@ -15,10 +15,12 @@ import (
 type IChannelStatisticsRepo interface {
 	CreateNew() error
 	SwitchColl() error
+	CopyToNew(endTime int64) error
 	BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCChannelStatistics) error
 	BatchInsert(batch []*entity.IBCChannelStatistics) error
 	BatchInsertToNew(batch []*entity.IBCChannelStatistics) error
 	Aggr() ([]*dto.ChannelStatisticsAggrDTO, error)
+	AggrSeries(cond *dto.AnalyticsSeriesCondDTO) ([]*dto.AggrSeriesDTO, error)
 }
 
 var _ IChannelStatisticsRepo = new(ChannelStatisticsRepo)
@@ -40,6 +42,11 @@ func (repo *ChannelStatisticsRepo) CreateNew() error {
 	return repo.collNew().CreateOneIndex(context.Background(), opts.IndexModel{Key: key, IndexOptions: indexOpts})
 }
 
+// CopyToNew copy the rows of the segments ended before endTime into the new collection
+func (repo *ChannelStatisticsRepo) CopyToNew(endTime int64) error {
+	return copySegmentsToNew(repo.coll(), entity.IBCChannelStatisticsNewCollName, endTime)
+}
+
 func (repo *ChannelStatisticsRepo) SwitchColl() error {
 	command := bson.D{{"renameCollection", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCChannelStatisticsNewCollName)},
 		{"to", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCChannelStatisticsCollName)},
@@ -124,3 +131,27 @@ func (repo *ChannelStatisticsRepo) Aggr() ([]*dto.ChannelStatisticsAggrDTO, erro
 	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
 	return res, err
 }
+
+func (repo *ChannelStatisticsRepo) AggrSeries(cond *dto.AnalyticsSeriesCondDTO) ([]*dto.AggrSeriesDTO, error) {
+	match := bson.M{
+		"segment_start_time": bson.M{
+			"$gte": cond.StartTime,
+			"$lte": cond.EndTime,
+		},
+	}
+	if idCond := statisticIdCond("channel_id", cond, false); len(idCond) > 0 {
+		match["$and"] = idCond
+	}
+	if cond.BaseDenom != "" {
+		match["base_denom"] = cond.BaseDenom
+	}
+	if cond.BaseDenomChainId != "" {
+		match["base_denom_chain_id"] = cond.BaseDenomChainId
+	}
+
+	pipe := seriesPipe(match, seriesBucketExpr("segment_start_time", cond), "$base_denom", "$transfer_txs",
+		"$success_txs", "$refunded_txs", bson.M{"$toDouble": "$transfer_amount"})
+	var res []*dto.AggrSeriesDTO
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
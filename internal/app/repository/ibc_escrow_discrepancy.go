@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IEscrowDiscrepancyRepo interface {
+	InsertMany(discrepancies []*entity.IBCEscrowDiscrepancy) error
+	List(query dto.EscrowDiscrepancyQuery, skip, limit int64) ([]*entity.IBCEscrowDiscrepancy, error)
+	CountList(query dto.EscrowDiscrepancyQuery) (int64, error)
+}
+
+var _ IEscrowDiscrepancyRepo = new(EscrowDiscrepancyRepo)
+
+type EscrowDiscrepancyRepo struct {
+}
+
+func (repo *EscrowDiscrepancyRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCEscrowDiscrepancy{}.CollectionName())
+}
+
+func (repo *EscrowDiscrepancyRepo) InsertMany(discrepancies []*entity.IBCEscrowDiscrepancy) error {
+	if len(discrepancies) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	for _, v := range discrepancies {
+		v.CreateAt = now
+	}
+	_, err := repo.coll().InsertMany(context.Background(), discrepancies)
+	return err
+}
+
+func parseEscrowDiscrepancyQuery(queryCond dto.EscrowDiscrepancyQuery) bson.M {
+	query := bson.M{}
+	if queryCond.StartTime > 0 && queryCond.EndTime > 0 {
+		query["check_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+			"$lte": queryCond.EndTime,
+		}
+	} else if queryCond.StartTime > 0 {
+		query["check_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+		}
+	} else if queryCond.EndTime > 0 {
+		query["check_time"] = bson.M{
+			"$lte": queryCond.EndTime,
+		}
+	}
+	if queryCond.ChainId != "" {
+		query["$or"] = []bson.M{
+			{"chain_id": queryCond.ChainId},
+			{"counterparty_chain_id": queryCond.ChainId},
+		}
+	}
+	if queryCond.ChannelId != "" {
+		query["channel_id"] = queryCond.ChannelId
+	}
+	if queryCond.Denom != "" {
+		query["denom"] = queryCond.Denom
+	}
+	return query
+}
+
+func (repo *EscrowDiscrepancyRepo) List(query dto.EscrowDiscrepancyQuery, skip, limit int64) ([]*entity.IBCEscrowDiscrepancy, error) {
+	var res []*entity.IBCEscrowDiscrepancy
+	err := repo.coll().Find(context.Background(), parseEscrowDiscrepancyQuery(query)).Skip(skip).Limit(limit).Sort("-check_time").All(&res)
+	return res, err
+}
+
+func (repo *EscrowDiscrepancyRepo) CountList(query dto.EscrowDiscrepancyQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseEscrowDiscrepancyQuery(query)).Count()
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IEscrowReconcileRepo interface {
+	FindAll() ([]*entity.IBCEscrowReconcile, error)
+	Save(reconcile *entity.IBCEscrowReconcile) error
+	List(query dto.EscrowReconcileQuery, skip, limit int64) ([]*entity.IBCEscrowReconcile, error)
+	CountList(query dto.EscrowReconcileQuery) (int64, error)
+}
+
+var _ IEscrowReconcileRepo = new(EscrowReconcileRepo)
+
+type EscrowReconcileRepo struct {
+}
+
+func (repo *EscrowReconcileRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCEscrowReconcile{}.CollectionName())
+}
+
+func (repo *EscrowReconcileRepo) FindAll() ([]*entity.IBCEscrowReconcile, error) {
+	var res []*entity.IBCEscrowReconcile
+	err := repo.coll().Find(context.Background(), bson.M{}).All(&res)
+	return res, err
+}
+
+// Save the reconciliation is identified by the chain, channel and the escrowed denom
+func (repo *EscrowReconcileRepo) Save(reconcile *entity.IBCEscrowReconcile) error {
+	now := time.Now().Unix()
+	if reconcile.CreateAt == 0 {
+		reconcile.CreateAt = now
+	}
+	reconcile.UpdateAt = now
+	query := bson.M{
+		"chain_id":   reconcile.ChainId,
+		"channel_id": reconcile.ChannelId,
+		"denom":      reconcile.Denom,
+	}
+	_, err := repo.coll().Upsert(context.Background(), query, reconcile)
+	return err
+}
+
+func parseEscrowReconcileQuery(queryCond dto.EscrowReconcileQuery) bson.M {
+	query := bson.M{}
+	if queryCond.ChainId != "" {
+		query["$or"] = []bson.M{
+			{"chain_id": queryCond.ChainId},
+			{"counterparty_chain_id": queryCond.ChainId},
+		}
+	}
+	if queryCond.ChannelId != "" {
+		query["channel_id"] = queryCond.ChannelId
+	}
+	if queryCond.Denom != "" {
+		query["denom"] = queryCond.Denom
+	}
+	if len(queryCond.Status) > 0 {
+		query["status"] = bson.M{
+			"$in": queryCond.Status,
+		}
+	}
+	if queryCond.Alerting != nil {
+		query["alerting"] = *queryCond.Alerting
+	}
+	return query
+}
+
+func (repo *EscrowReconcileRepo) List(query dto.EscrowReconcileQuery, skip, limit int64) ([]*entity.IBCEscrowReconcile, error) {
+	var res []*entity.IBCEscrowReconcile
+	err := repo.coll().Find(context.Background(), parseEscrowReconcileQuery(query)).Skip(skip).Limit(limit).
+		Sort("-status", "-mismatch_times", "chain_id", "channel_id").All(&res)
+	return res, err
+}
+
+func (repo *EscrowReconcileRepo) CountList(query dto.EscrowReconcileQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseEscrowReconcileQuery(query)).Count()
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IIcaAccountRepo interface {
+	Save(account *entity.IBCIcaAccount) error
+	FindByHostChannel(hostChainId, hostChannel string) (*entity.IBCIcaAccount, error)
+	List(query dto.IcaAccountQuery, skip, limit int64) ([]*entity.IBCIcaAccount, error)
+	CountList(query dto.IcaAccountQuery) (int64, error)
+}
+
+var _ IIcaAccountRepo = new(IcaAccountRepo)
+
+type IcaAccountRepo struct {
+}
+
+func (repo *IcaAccountRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCIcaAccount{}.CollectionName())
+}
+
+// Save the account is registered again with a new channel after the ordered channel is closed
+func (repo *IcaAccountRepo) Save(account *entity.IBCIcaAccount) error {
+	now := time.Now().Unix()
+	if account.CreateAt == 0 {
+		account.CreateAt = now
+	}
+	account.UpdateAt = now
+	query := bson.M{
+		"controller_chain_id":      account.ControllerChainId,
+		"controller_port":          account.ControllerPort,
+		"controller_connection_id": account.ControllerConnectionId,
+	}
+	_, err := repo.coll().Upsert(context.Background(), query, account)
+	return err
+}
+
+func (repo *IcaAccountRepo) FindByHostChannel(hostChainId, hostChannel string) (*entity.IBCIcaAccount, error) {
+	var res entity.IBCIcaAccount
+	err := repo.coll().Find(context.Background(), bson.M{"host_chain_id": hostChainId, "host_channel": hostChannel}).One(&res)
+	return &res, err
+}
+
+func parseIcaAccountQuery(queryCond dto.IcaAccountQuery) bson.M {
+	query := bson.M{}
+	if queryCond.ChainId != "" {
+		query["$or"] = []bson.M{
+			{"controller_chain_id": queryCond.ChainId},
+			{"host_chain_id": queryCond.ChainId},
+		}
+	}
+	if queryCond.Owner != "" {
+		query["owner"] = queryCond.Owner
+	}
+	if queryCond.IcaAddress != "" {
+		query["ica_address"] = queryCond.IcaAddress
+	}
+	return query
+}
+
+func (repo *IcaAccountRepo) List(query dto.IcaAccountQuery, skip, limit int64) ([]*entity.IBCIcaAccount, error) {
+	var res []*entity.IBCIcaAccount
+	err := repo.coll().Find(context.Background(), parseIcaAccountQuery(query)).Skip(skip).Limit(limit).Sort("-register_time").All(&res)
+	return res, err
+}
+
+func (repo *IcaAccountRepo) CountList(query dto.IcaAccountQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseIcaAccountQuery(query)).Count()
+}
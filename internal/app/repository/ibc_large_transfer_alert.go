@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type ILargeTransferAlertRepo interface {
+	Save(alert *entity.IBCLargeTransferAlert) error
+	List(query dto.LargeTransferAlertQuery, skip, limit int64) ([]*entity.IBCLargeTransferAlert, error)
+	CountList(query dto.LargeTransferAlertQuery) (int64, error)
+}
+
+var _ ILargeTransferAlertRepo = new(LargeTransferAlertRepo)
+
+type LargeTransferAlertRepo struct {
+}
+
+func (repo *LargeTransferAlertRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCLargeTransferAlert{}.CollectionName())
+}
+
+// Save the alert is identified by the record id of the transfer, so that the same transfer is alerted only once
+func (repo *LargeTransferAlertRepo) Save(alert *entity.IBCLargeTransferAlert) error {
+	alert.CreateAt = time.Now().Unix()
+	_, err := repo.coll().Upsert(context.Background(), bson.M{"record_id": alert.RecordId}, alert)
+	return err
+}
+
+func parseLargeTransferAlertQuery(queryCond dto.LargeTransferAlertQuery) bson.M {
+	query := bson.M{}
+	if queryCond.StartTime > 0 && queryCond.EndTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+			"$lte": queryCond.EndTime,
+		}
+	} else if queryCond.StartTime > 0 {
+		query["tx_time"] = bson.M{
+			"$gte": queryCond.StartTime,
+		}
+	} else if queryCond.EndTime > 0 {
+		query["tx_time"] = bson.M{
+			"$lte": queryCond.EndTime,
+		}
+	}
+	if queryCond.ChainId != "" {
+		query["$or"] = []bson.M{
+			{"sc_chain_id": queryCond.ChainId},
+			{"dc_chain_id": queryCond.ChainId},
+		}
+	}
+	if queryCond.BaseDenom != "" {
+		query["base_denom"] = queryCond.BaseDenom
+	}
+	if queryCond.Reason != "" {
+		query["reasons"] = queryCond.Reason
+	}
+	return query
+}
+
+func (repo *LargeTransferAlertRepo) List(query dto.LargeTransferAlertQuery, skip, limit int64) ([]*entity.IBCLargeTransferAlert, error) {
+	var res []*entity.IBCLargeTransferAlert
+	err := repo.coll().Find(context.Background(), parseLargeTransferAlertQuery(query)).Skip(skip).Limit(limit).Sort("-tx_time").All(&res)
+	return res, err
+}
+
+func (repo *LargeTransferAlertRepo) CountList(query dto.LargeTransferAlertQuery) (int64, error) {
+	return repo.coll().Find(context.Background(), parseLargeTransferAlertQuery(query)).Count()
+}
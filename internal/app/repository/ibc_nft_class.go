@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type INftClassRepo interface {
+	FindByChainId(chainId string) (entity.IBCNftClassList, error)
+	FindByClassIdChainId(classId, chainId string) (*entity.IBCNftClass, error)
+	InsertBatch(classes entity.IBCNftClassList) error
+}
+
+var _ INftClassRepo = new(NftClassRepo)
+
+type NftClassRepo struct {
+}
+
+func (repo *NftClassRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCNftClass{}.CollectionName())
+}
+
+func (repo *NftClassRepo) FindByChainId(chainId string) (entity.IBCNftClassList, error) {
+	var res entity.IBCNftClassList
+	err := repo.coll().Find(context.Background(), bson.M{"chain_id": chainId}).All(&res)
+	return res, err
+}
+
+func (repo *NftClassRepo) FindByClassIdChainId(classId, chainId string) (*entity.IBCNftClass, error) {
+	var res *entity.IBCNftClass
+	err := repo.coll().Find(context.Background(), bson.M{"class_id": classId, "chain_id": chainId}).One(&res)
+	return res, err
+}
+
+func (repo *NftClassRepo) InsertBatch(classes entity.IBCNftClassList) error {
+	_, err := repo.coll().InsertMany(context.Background(), classes, insertIgnoreErrOpt)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IReindexJobRepo interface {
+	CreateIndexes() error
+	Create(job *entity.IBCReindexJob) error
+	FindOne(jobId string) (*entity.IBCReindexJob, error)
+	FindRunning(chainId string) (*entity.IBCReindexJob, error)
+	FindByChain(chainId string, limit int64) ([]*entity.IBCReindexJob, error)
+	Save(job *entity.IBCReindexJob) error
+	Touch(jobId string) error
+}
+
+var _ IReindexJobRepo = new(ReindexJobRepo)
+
+type ReindexJobRepo struct {
+}
+
+func (repo *ReindexJobRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCReindexJob{}.CollectionName())
+}
+
+// CreateIndexes the unique index of the active job of a chain, the existing indexes are kept
+func (repo *ReindexJobRepo) CreateIndexes() error {
+	indexOpts := officialOpts.Index().SetUnique(true).SetName("reindex_job_active_unique").
+		SetPartialFilterExpression(bson.M{"active": true})
+	return repo.coll().CreateOneIndex(context.Background(), opts.IndexModel{Key: []string{"chain_id"}, IndexOptions: indexOpts})
+}
+
+// Create insert the job, a duplicate key error if the chain has an active job
+func (repo *ReindexJobRepo) Create(job *entity.IBCReindexJob) error {
+	now := time.Now().Unix()
+	job.CreateAt, job.UpdateAt = now, now
+	_, err := repo.coll().InsertOne(context.Background(), job)
+	return err
+}
+
+func (repo *ReindexJobRepo) FindOne(jobId string) (*entity.IBCReindexJob, error) {
+	var res *entity.IBCReindexJob
+	err := repo.coll().Find(context.Background(), bson.M{"job_id": jobId}).One(&res)
+	return res, err
+}
+
+// FindRunning the active job of the chain
+func (repo *ReindexJobRepo) FindRunning(chainId string) (*entity.IBCReindexJob, error) {
+	var res *entity.IBCReindexJob
+	query := bson.M{"chain_id": chainId, "active": true}
+	err := repo.coll().Find(context.Background(), query).One(&res)
+	return res, err
+}
+
+func (repo *ReindexJobRepo) FindByChain(chainId string, limit int64) ([]*entity.IBCReindexJob, error) {
+	var res []*entity.IBCReindexJob
+	err := repo.coll().Find(context.Background(), bson.M{"chain_id": chainId}).Sort("-create_at").Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *ReindexJobRepo) Save(job *entity.IBCReindexJob) error {
+	now := time.Now().Unix()
+	if job.CreateAt == 0 {
+		job.CreateAt = now
+	}
+	job.UpdateAt = now
+	_, err := repo.coll().Upsert(context.Background(), bson.M{"job_id": job.JobId}, job)
+	return err
+}
+
+// Touch refresh the update_at of the running job
+func (repo *ReindexJobRepo) Touch(jobId string) error {
+	return repo.coll().UpdateOne(context.Background(), bson.M{"job_id": jobId}, bson.M{"$set": bson.M{"update_at": time.Now().Unix()}})
+}
@@ -33,6 +33,7 @@ const (
 
 type IRelayerRepo interface {
 	FindLatestOne() (*entity.IBCRelayer, error)
+	FindOne(relayerId string) (*entity.IBCRelayer, error)
 	Insert(relayer []entity.IBCRelayer) error
 	UpdateStatusAndTime(relayerId string, status int, updateTime, timePeriod int64) error
 	UpdateTxsInfo(relayerId string, txs, txsSuccess int64, totalValue string) error
@@ -217,6 +218,12 @@ func (repo *IbcRelayerRepo) FindLatestOne() (*entity.IBCRelayer, error) {
 	return res, err
 }
 
+func (repo *IbcRelayerRepo) FindOne(relayerId string) (*entity.IBCRelayer, error) {
+	var res *entity.IBCRelayer
+	err := repo.coll().Find(context.Background(), bson.M{RelayerFieldelayerId: relayerId}).One(&res)
+	return res, err
+}
+
 func (repo *IbcRelayerRepo) CountChainRelayers(chainId string) (int64, error) {
 	return repo.coll().Find(context.Background(), bson.M{
 		RelayerFieldStatus: entity.RelayerRunning,
@@ -16,12 +16,14 @@ import (
 type IRelayerStatisticsRepo interface {
 	CreateNew() error
 	SwitchColl() error
+	CopyToNew(endTime int64) error
 	InserOrUpdate(data entity.IBCRelayerStatistics) error
 	CountRelayerBaseDenomAmt() ([]*dto.CountRelayerBaseDenomAmtDTO, error)
 	Insert(relayerStatistics []entity.IBCRelayerStatistics) error
 	InsertToNew(relayerStatistics []entity.IBCRelayerStatistics) error
 	AggregateRelayerTxs() ([]*dto.AggRelayerTxsDTO, error)
 	CreateStatisticId(scChain, dcChain, scChannel, dcChannel string) (string, string)
+	AggrSeries(cond *dto.AnalyticsSeriesCondDTO) ([]*dto.AggrSeriesDTO, error)
 }
 
 var _ IRelayerStatisticsRepo = new(RelayerStatisticsRepo)
@@ -60,6 +62,11 @@ func (repo *RelayerStatisticsRepo) CreateNew() error {
 	return repo.collNew().CreateOneIndex(context.Background(), opts.IndexModel{Key: key, IndexOptions: indexOpts})
 }
 
+// CopyToNew copy the rows of the segments ended before endTime into the new collection
+func (repo *RelayerStatisticsRepo) CopyToNew(endTime int64) error {
+	return copySegmentsToNew(repo.coll(), entity.IBCRelayerStatisticsNewCollName, endTime)
+}
+
 func (repo *RelayerStatisticsRepo) SwitchColl() error {
 	command := bson.D{{"renameCollection", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCRelayerStatisticsNewCollName)},
 		{"to", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCRelayerStatisticsCollName)},
@@ -178,3 +185,26 @@ func (repo *RelayerStatisticsRepo) AggregateRelayerTxs() ([]*dto.AggRelayerTxsDT
 	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
 	return res, err
 }
+
+// AggrSeries the relayer statistics don't record the refunded txs, refunded_count is always 0
+func (repo *RelayerStatisticsRepo) AggrSeries(cond *dto.AnalyticsSeriesCondDTO) ([]*dto.AggrSeriesDTO, error) {
+	match := bson.M{
+		"segment_start_time": bson.M{
+			"$gte": cond.StartTime,
+			"$lte": cond.EndTime,
+		},
+		"$and": statisticIdCond("statistic_id", cond, true),
+	}
+	if cond.BaseDenom != "" {
+		match["transfer_base_denom"] = cond.BaseDenom
+	}
+	if cond.BaseDenomChainId != "" {
+		match["base_denom_chain_id"] = cond.BaseDenomChainId
+	}
+
+	pipe := seriesPipe(match, seriesBucketExpr("segment_start_time", cond), "$transfer_base_denom", "$total_txs",
+		"$success_total_txs", 0, bson.M{"$toDouble": "$transfer_amount"})
+	var res []*dto.AggrSeriesDTO
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
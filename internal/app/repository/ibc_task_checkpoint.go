@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type ITaskCheckpointRepo interface {
+	FindOne(taskName, scope, worker string) (*entity.IbcTaskCheckpoint, error)
+	FindByTaskName(taskName string) ([]*entity.IbcTaskCheckpoint, error)
+	Save(checkpoint *entity.IbcTaskCheckpoint) error
+	DeleteByTaskName(taskName string) (int64, error)
+}
+
+var _ ITaskCheckpointRepo = new(TaskCheckpointRepo)
+
+type TaskCheckpointRepo struct {
+}
+
+func (repo *TaskCheckpointRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcTaskCheckpoint{}.CollectionName())
+}
+
+func (repo *TaskCheckpointRepo) FindOne(taskName, scope, worker string) (*entity.IbcTaskCheckpoint, error) {
+	var res entity.IbcTaskCheckpoint
+	err := repo.coll().Find(context.Background(), bson.M{"task_name": taskName, "scope": scope, "worker": worker}).One(&res)
+	return &res, err
+}
+
+func (repo *TaskCheckpointRepo) FindByTaskName(taskName string) ([]*entity.IbcTaskCheckpoint, error) {
+	var res []*entity.IbcTaskCheckpoint
+	err := repo.coll().Find(context.Background(), bson.M{"task_name": taskName}).Sort("scope", "worker").All(&res)
+	return res, err
+}
+
+func (repo *TaskCheckpointRepo) Save(checkpoint *entity.IbcTaskCheckpoint) error {
+	now := time.Now().Unix()
+	if checkpoint.CreateAt == 0 {
+		checkpoint.CreateAt = now
+	}
+	checkpoint.UpdateAt = now
+	query := bson.M{"task_name": checkpoint.TaskName, "scope": checkpoint.Scope, "worker": checkpoint.Worker}
+	_, err := repo.coll().Upsert(context.Background(), query, checkpoint)
+	return err
+}
+
+func (repo *TaskCheckpointRepo) DeleteByTaskName(taskName string) (int64, error) {
+	res, err := repo.coll().RemoveAll(context.Background(), bson.M{"task_name": taskName})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
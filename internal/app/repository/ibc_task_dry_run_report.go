@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type ITaskDryRunReportRepo interface {
+	Insert(report *entity.IbcTaskDryRunReport) error
+	FindByTaskName(taskName string, skip, limit int64) ([]*entity.IbcTaskDryRunReport, error)
+	CountByTaskName(taskName string) (int64, error)
+	AggrByField(taskName string) ([]*dto.AggrDryRunReportDTO, error)
+	DeleteByTaskName(taskName string) (int64, error)
+}
+
+var _ ITaskDryRunReportRepo = new(TaskDryRunReportRepo)
+
+type TaskDryRunReportRepo struct {
+}
+
+func (repo *TaskDryRunReportRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcTaskDryRunReport{}.CollectionName())
+}
+
+func (repo *TaskDryRunReportRepo) Insert(report *entity.IbcTaskDryRunReport) error {
+	_, err := repo.coll().InsertOne(context.Background(), report)
+	return err
+}
+
+func (repo *TaskDryRunReportRepo) FindByTaskName(taskName string, skip, limit int64) ([]*entity.IbcTaskDryRunReport, error) {
+	var res []*entity.IbcTaskDryRunReport
+	err := repo.coll().Find(context.Background(), bson.M{"task_name": taskName}).Sort("create_at").Skip(skip).Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *TaskDryRunReportRepo) CountByTaskName(taskName string) (int64, error) {
+	return repo.coll().Find(context.Background(), bson.M{"task_name": taskName}).Count()
+}
+
+func (repo *TaskDryRunReportRepo) AggrByField(taskName string) ([]*dto.AggrDryRunReportDTO, error) {
+	match := bson.M{
+		"$match": bson.M{
+			"task_name": taskName,
+		},
+	}
+	group := bson.M{
+		"$group": bson.M{
+			"_id": bson.M{
+				"coll":  "$coll",
+				"field": "$field",
+			},
+			"records": bson.M{
+				"$sum": 1,
+			},
+		},
+	}
+	project := bson.M{
+		"$project": bson.M{
+			"_id":     0,
+			"coll":    "$_id.coll",
+			"field":   "$_id.field",
+			"records": "$records",
+		},
+	}
+
+	var pipe []bson.M
+	pipe = append(pipe, match, group, project)
+	var res []*dto.AggrDryRunReportDTO
+	err := repo.coll().Aggregate(context.Background(), pipe).All(&res)
+	return res, err
+}
+
+func (repo *TaskDryRunReportRepo) DeleteByTaskName(taskName string) (int64, error) {
+	res, err := repo.coll().RemoveAll(context.Background(), bson.M{"task_name": taskName})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
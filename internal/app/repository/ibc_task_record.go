@@ -13,6 +13,7 @@ type ITaskRecordRepo interface {
 	FindByTaskName(taskName string) (*entity.IbcTaskRecord, error)
 	Insert(record *entity.IbcTaskRecord) error
 	UpdateHeight(taskName string, height int64) error
+	UpdateRollbackTime(taskName string, rollbackTime int64) error
 }
 
 var _ ITaskRecordRepo = new(TaskRecordRepo)
@@ -47,3 +48,12 @@ func (repo *TaskRecordRepo) UpdateHeight(taskName string, height int64) error {
 		},
 	})
 }
+
+func (repo *TaskRecordRepo) UpdateRollbackTime(taskName string, rollbackTime int64) error {
+	return repo.coll().UpdateOne(context.Background(), bson.M{"task_name": taskName}, bson.M{
+		"$set": bson.M{
+			"rollback_time": rollbackTime,
+			"update_at":     time.Now().Unix(),
+		},
+	})
+}
@@ -15,6 +15,7 @@ import (
 type ITokenStatisticsRepo interface {
 	CreateNew() error
 	SwitchColl() error
+	CopyToNew(endTime int64) error
 	BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCTokenStatistics) error
 	BatchInsert(batch []*entity.IBCTokenStatistics) error
 	BatchInsertToNew(batch []*entity.IBCTokenStatistics) error
@@ -41,6 +42,11 @@ func (repo *TokenStatisticsRepo) CreateNew() error {
 	return repo.collNew().CreateOneIndex(context.Background(), opts.IndexModel{Key: key, IndexOptions: indexOpts})
 }
 
+// CopyToNew copy the rows of the segments ended before endTime into the new collection
+func (repo *TokenStatisticsRepo) CopyToNew(endTime int64) error {
+	return copySegmentsToNew(repo.coll(), entity.IBCTokenStatisticsNewCollName, endTime)
+}
+
 func (repo *TokenStatisticsRepo) SwitchColl() error {
 	command := bson.D{{"renameCollection", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCTokenStatisticsNewCollName)},
 		{"to", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCTokenStatisticsCollName)},
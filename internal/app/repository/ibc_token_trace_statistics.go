@@ -15,6 +15,7 @@ import (
 type ITokenTraceStatisticsRepo interface {
 	CreateNew() error
 	SwitchColl() error
+	CopyToNew(endTime int64) error
 	BatchSwap(segmentStartTime, segmentEndTime int64, batch []*entity.IBCTokenTraceStatistics) error
 	BatchInsert(batch []*entity.IBCTokenTraceStatistics) error
 	BatchInsertToNew(batch []*entity.IBCTokenTraceStatistics) error
@@ -40,6 +41,11 @@ func (repo *TokenTraceStatisticsRepo) CreateNew() error {
 	return repo.collNew().CreateOneIndex(context.Background(), opts.IndexModel{Key: key, IndexOptions: indexOpts})
 }
 
+// CopyToNew copy the rows of the segments ended before endTime into the new collection
+func (repo *TokenTraceStatisticsRepo) CopyToNew(endTime int64) error {
+	return copySegmentsToNew(repo.coll(), entity.IBCTokenTraceStatisticsNewCollName, endTime)
+}
+
 func (repo *TokenTraceStatisticsRepo) SwitchColl() error {
 	command := bson.D{{"renameCollection", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCTokenTraceStatisticsNewCollName)},
 		{"to", fmt.Sprintf("%s.%s", ibcDatabase, entity.IBCTokenTraceStatisticsCollName)},
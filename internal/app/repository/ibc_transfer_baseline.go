@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type ITransferBaselineRepo interface {
+	FindAll() ([]*entity.IBCLargeTransferBaseline, error)
+	Save(baseline *entity.IBCLargeTransferBaseline) error
+}
+
+var _ ITransferBaselineRepo = new(TransferBaselineRepo)
+
+type TransferBaselineRepo struct {
+}
+
+func (repo *TransferBaselineRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCLargeTransferBaseline{}.CollectionName())
+}
+
+func (repo *TransferBaselineRepo) FindAll() ([]*entity.IBCLargeTransferBaseline, error) {
+	var res []*entity.IBCLargeTransferBaseline
+	err := repo.coll().Find(context.Background(), bson.M{}).All(&res)
+	return res, err
+}
+
+func (repo *TransferBaselineRepo) Save(baseline *entity.IBCLargeTransferBaseline) error {
+	now := time.Now().Unix()
+	if baseline.CreateAt == 0 {
+		baseline.CreateAt = now
+	}
+	baseline.UpdateAt = now
+	query := bson.M{
+		"base_denom":          baseline.BaseDenom,
+		"base_denom_chain_id": baseline.BaseDenomChainId,
+	}
+	_, err := repo.coll().Upsert(context.Background(), query, baseline)
+	return err
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IIbcTxArchiveRepo interface {
+	Insert(archive *entity.IBCTxArchive) error
+	Update(archive *entity.IBCTxArchive) error
+	FindByKey(key string) (*entity.IBCTxArchive, error)
+	FindByPartition(chainId, month string) ([]*entity.IBCTxArchive, error)
+	FindByChain(chainId string) ([]*entity.IBCTxArchive, error)
+	UpdateStatus(key string, status entity.ArchiveStatus, restoreAt int64) error
+	Delete(key string) error
+	MaxCreateAt() (int64, error)
+}
+
+var _ IIbcTxArchiveRepo = new(IbcTxArchiveRepo)
+
+type IbcTxArchiveRepo struct {
+}
+
+func (repo *IbcTxArchiveRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCTxArchive{}.CollectionName())
+}
+
+func (repo *IbcTxArchiveRepo) Insert(archive *entity.IBCTxArchive) error {
+	now := time.Now().Unix()
+	archive.CreateAt, archive.UpdateAt = now, now
+	_, err := repo.coll().InsertOne(context.Background(), archive)
+	return err
+}
+
+// Update the txs, the file and the status of the archive
+func (repo *IbcTxArchiveRepo) Update(archive *entity.IBCTxArchive) error {
+	archive.UpdateAt = time.Now().Unix()
+	set := bson.M{
+		"txs":           archive.Txs,
+		"min_tx_time":   archive.MinTxTime,
+		"max_tx_time":   archive.MaxTxTime,
+		"max_create_at": archive.MaxCreateAt,
+		"size":          archive.Size,
+		"sha256":        archive.Sha256,
+		"status":        archive.Status,
+		"update_at":     archive.UpdateAt,
+	}
+	return repo.coll().UpdateOne(context.Background(), bson.M{"key": archive.Key}, bson.M{"$set": set})
+}
+
+func (repo *IbcTxArchiveRepo) FindByKey(key string) (*entity.IBCTxArchive, error) {
+	var res *entity.IBCTxArchive
+	err := repo.coll().Find(context.Background(), bson.M{"key": key}).One(&res)
+	return res, err
+}
+
+func (repo *IbcTxArchiveRepo) FindByPartition(chainId, month string) ([]*entity.IBCTxArchive, error) {
+	var res []*entity.IBCTxArchive
+	err := repo.coll().Find(context.Background(), bson.M{"chain_id": chainId, "month": month}).Sort("create_at").All(&res)
+	return res, err
+}
+
+func (repo *IbcTxArchiveRepo) FindByChain(chainId string) ([]*entity.IBCTxArchive, error) {
+	var res []*entity.IBCTxArchive
+	err := repo.coll().Find(context.Background(), bson.M{"chain_id": chainId}).Sort("month", "create_at").All(&res)
+	return res, err
+}
+
+func (repo *IbcTxArchiveRepo) UpdateStatus(key string, status entity.ArchiveStatus, restoreAt int64) error {
+	set := bson.M{"status": status, "restore_at": restoreAt, "update_at": time.Now().Unix()}
+	return repo.coll().UpdateOne(context.Background(), bson.M{"key": key}, bson.M{"$set": set})
+}
+
+func (repo *IbcTxArchiveRepo) Delete(key string) error {
+	return repo.coll().Remove(context.Background(), bson.M{"key": key})
+}
+
+// MaxCreateAt the max create_at of the archived ibc txs, 0 if nothing is archived. The max tx time is used for the files
+// archived without max_create_at.
+func (repo *IbcTxArchiveRepo) MaxCreateAt() (int64, error) {
+	group := bson.M{
+		"$group": bson.M{
+			"_id": nil,
+			"max_create_at": bson.M{
+				"$max": bson.M{"$max": []string{"$max_create_at", "$max_tx_time"}},
+			},
+		},
+	}
+	var res []struct {
+		MaxCreateAt int64 `bson:"max_create_at"`
+	}
+	if err := repo.coll().Aggregate(context.Background(), []bson.M{group}).All(&res); err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+	return res[0].MaxCreateAt, nil
+}
+
+type IIbcTxArchiveHashRepo interface {
+	InsertBatch(hashes []*entity.IBCTxArchiveHash) error
+	FindByHash(hash string) ([]*entity.IBCTxArchiveHash, error)
+	DeleteByKey(key string) error
+}
+
+var _ IIbcTxArchiveHashRepo = new(IbcTxArchiveHashRepo)
+
+type IbcTxArchiveHashRepo struct {
+}
+
+func (repo *IbcTxArchiveHashRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IBCTxArchiveHash{}.CollectionName())
+}
+
+func (repo *IbcTxArchiveHashRepo) InsertBatch(hashes []*entity.IBCTxArchiveHash) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	_, err := repo.coll().InsertMany(context.Background(), hashes, insertIgnoreErrOpt)
+	return err
+}
+
+func (repo *IbcTxArchiveHashRepo) FindByHash(hash string) ([]*entity.IBCTxArchiveHash, error) {
+	var res []*entity.IBCTxArchiveHash
+	err := repo.coll().Find(context.Background(), bson.M{"hash": hash}).All(&res)
+	return res, err
+}
+
+func (repo *IbcTxArchiveHashRepo) DeleteByKey(key string) error {
+	_, err := repo.coll().RemoveAll(context.Background(), bson.M{"key": key})
+	return err
+}
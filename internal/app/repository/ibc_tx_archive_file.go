@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/objstore"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// ArchiveFileFormat a line of the file is an ibc tx of ex_ibc_tx in the relaxed extended json of mongo
+	ArchiveFileFormat = "ndjson.gz"
+	archiveManifest   = "manifest.json"
+	archiveMaxLine    = 16 << 20
+)
+
+// errArchiveTxFound stop the scan of a file once the tx is found
+var errArchiveTxFound = errors.New("archived tx found")
+
+// IIbcTxArchiveFileRepo the archived files in the object storage of archive.path
+type IIbcTxArchiveFileRepo interface {
+	Put(key string, file *ArchiveFileWriter) error
+	Verify(key string, size int64, sha256 string) error
+	Read(key, sha256 string, fn func(tx *entity.ExIbcTx) error) error
+	FindTx(key string, offset int64, recordId string) (*entity.ExIbcTx, error)
+	Delete(key string) error
+	PutManifest(manifest *entity.ArchiveManifest) error
+}
+
+var _ IIbcTxArchiveFileRepo = new(IbcTxArchiveFileRepo)
+
+type IbcTxArchiveFileRepo struct {
+}
+
+// ArchiveFileKey the key of a new file of the partition
+func ArchiveFileKey(chainId, month string) string {
+	return fmt.Sprintf("%s/%d.%s", entity.ArchivePartitionPath(chainId, month), time.Now().UnixNano(), ArchiveFileFormat)
+}
+
+func (repo *IbcTxArchiveFileRepo) Put(key string, file *ArchiveFileWriter) error {
+	store, err := objstore.Default()
+	if err != nil {
+		return err
+	}
+	if _, err = file.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return store.Put(key, file.file, file.Size())
+}
+
+// Verify the object of the key is the file of the size and the sha256
+func (repo *IbcTxArchiveFileRepo) Verify(key string, size int64, sha256Hex string) error {
+	store, err := objstore.Default()
+	if err != nil {
+		return err
+	}
+	info, err := store.Stat(key)
+	if err != nil {
+		return err
+	}
+	if info.Size != size || info.Sha256 != sha256Hex {
+		return fmt.Errorf("%s is %d bytes of sha256 %s, expect %d bytes of %s", key, info.Size, info.Sha256, size, sha256Hex)
+	}
+	return nil
+}
+
+// Read call fn with the ibc txs of the file in order, the file is rejected if its sha256 doesn't match
+func (repo *IbcTxArchiveFileRepo) Read(key, sha256Hex string, fn func(tx *entity.ExIbcTx) error) error {
+	store, err := objstore.Default()
+	if err != nil {
+		return err
+	}
+	reader, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	if err = scanArchive(key, io.TeeReader(reader, hasher), true, fn); err != nil {
+		return err
+	}
+
+	// read the rest of the file, so that the checksum covers all the bytes
+	if _, err = io.Copy(hasher, reader); err != nil {
+		return err
+	}
+	if sha256Hex != "" && hex.EncodeToString(hasher.Sum(nil)) != sha256Hex {
+		return fmt.Errorf("the sha256 of %s doesn't match the manifest", key)
+	}
+	return nil
+}
+
+// FindTx the ibc tx of the record id in the gzip member at the offset of the file, only the member is read, nil if the
+// tx is not in it
+func (repo *IbcTxArchiveFileRepo) FindTx(key string, offset int64, recordId string) (*entity.ExIbcTx, error) {
+	store, err := objstore.Default()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := store.GetFrom(key, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var res *entity.ExIbcTx
+	err = scanArchive(key, reader, false, func(tx *entity.ExIbcTx) error {
+		if tx.RecordId == recordId {
+			res = tx
+			return errArchiveTxFound
+		}
+		return nil
+	})
+	if err != nil && err != errArchiveTxFound {
+		return nil, err
+	}
+	return res, nil
+}
+
+// scanArchive call fn with the ibc txs of the gzipped ndjson in order, only the first gzip member is read if multistream
+// is false
+func scanArchive(key string, reader io.Reader, multistream bool, fn func(tx *entity.ExIbcTx) error) error {
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return err
+	}
+	gz.Multistream(multistream)
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), archiveMaxLine)
+	for scanner.Scan() {
+		var tx entity.ExIbcTx
+		if err = bson.UnmarshalExtJSON(scanner.Bytes(), false, &tx); err != nil {
+			return fmt.Errorf("decode %s error, %v", key, err)
+		}
+		if err = fn(&tx); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (repo *IbcTxArchiveFileRepo) Delete(key string) error {
+	store, err := objstore.Default()
+	if err != nil {
+		return err
+	}
+	return store.Delete(key)
+}
+
+func (repo *IbcTxArchiveFileRepo) PutManifest(manifest *entity.ArchiveManifest) error {
+	store, err := objstore.Default()
+	if err != nil {
+		return err
+	}
+	manifest.Format = ArchiveFileFormat
+	manifest.UpdateAt = time.Now().Unix()
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s/%s", entity.ArchivePartitionPath(manifest.ChainId, manifest.Month), archiveManifest)
+	return store.Put(key, bytes.NewReader(data), int64(len(data)))
+}
+
+// ArchiveFileWriter write the ibc txs into a gzipped ndjson temp file, which is removed by Close. The file is a series of
+// gzip members, a tx can be read from the offset of its member without the ones before it.
+type ArchiveFileWriter struct {
+	file   *os.File
+	hasher hash.Hash
+	out    *countWriter
+	gz     *gzip.Writer
+	lines  int
+	size   int64
+}
+
+// countWriter count the bytes written
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func NewArchiveFileWriter() (*ArchiveFileWriter, error) {
+	file, err := ioutil.TempFile("", "ibc_tx_archive_*."+ArchiveFileFormat)
+	if err != nil {
+		return nil, err
+	}
+	hasher := sha256.New()
+	out := &countWriter{w: io.MultiWriter(file, hasher)}
+	return &ArchiveFileWriter{file: file, hasher: hasher, out: out, gz: gzip.NewWriter(out)}, nil
+}
+
+// Offset end the current gzip member and return the offset of the next one
+func (w *ArchiveFileWriter) Offset() (int64, error) {
+	if w.lines > 0 {
+		if err := w.gz.Close(); err != nil {
+			return 0, err
+		}
+		w.gz.Reset(w.out)
+		w.lines = 0
+	}
+	return w.out.n, nil
+}
+
+func (w *ArchiveFileWriter) Write(tx *entity.ExIbcTx) error {
+	line, err := bson.MarshalExtJSON(tx, false, false)
+	if err != nil {
+		return err
+	}
+	if _, err = w.gz.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	w.lines++
+	return nil
+}
+
+// Finish flush the gzip stream, the file can't be written after it
+func (w *ArchiveFileWriter) Finish() error {
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	info, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	w.size = info.Size()
+	return nil
+}
+
+// Range call fn with the ibc txs written in order, it's called after Finish
+func (w *ArchiveFileWriter) Range(fn func(tx *entity.ExIbcTx) error) error {
+	file, err := os.Open(w.file.Name())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return scanArchive(w.file.Name(), file, true, fn)
+}
+
+func (w *ArchiveFileWriter) Size() int64 {
+	return w.size
+}
+
+func (w *ArchiveFileWriter) Sha256() string {
+	return hex.EncodeToString(w.hasher.Sum(nil))
+}
+
+func (w *ArchiveFileWriter) Close() error {
+	_ = w.file.Close()
+	return os.Remove(w.file.Name())
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type IVerifiedBlockRepo interface {
+	FindByHeightRange(chainId string, startHeight, endHeight int64) ([]*entity.IbcVerifiedBlock, error)
+	SaveByHeightRange(chainId string, startHeight, endHeight int64, blocks []*entity.IbcVerifiedBlock) error
+	DeleteFromHeight(chainId string, height int64) error
+	DeleteBeforeHeight(chainId string, height int64) error
+}
+
+var _ IVerifiedBlockRepo = new(VerifiedBlockRepo)
+
+type VerifiedBlockRepo struct {
+}
+
+func (repo *VerifiedBlockRepo) coll() *qmgo.Collection {
+	return mgo.Database(ibcDatabase).Collection(entity.IbcVerifiedBlock{}.CollectionName())
+}
+
+func (repo *VerifiedBlockRepo) FindByHeightRange(chainId string, startHeight, endHeight int64) ([]*entity.IbcVerifiedBlock, error) {
+	var res []*entity.IbcVerifiedBlock
+	query := bson.M{"chain_id": chainId, "height": bson.M{"$gte": startHeight, "$lte": endHeight}}
+	err := repo.coll().Find(context.Background(), query).Sort("height").All(&res)
+	return res, err
+}
+
+// SaveByHeightRange replace the blocks of the chain between the heights(inclusive)
+func (repo *VerifiedBlockRepo) SaveByHeightRange(chainId string, startHeight, endHeight int64, blocks []*entity.IbcVerifiedBlock) error {
+	query := bson.M{"chain_id": chainId, "height": bson.M{"$gte": startHeight, "$lte": endHeight}}
+	if _, err := repo.coll().RemoveAll(context.Background(), query); err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	_, err := repo.coll().InsertMany(context.Background(), blocks)
+	return err
+}
+
+func (repo *VerifiedBlockRepo) DeleteFromHeight(chainId string, height int64) error {
+	_, err := repo.coll().RemoveAll(context.Background(), bson.M{"chain_id": chainId, "height": bson.M{"$gte": height}})
+	return err
+}
+
+func (repo *VerifiedBlockRepo) DeleteBeforeHeight(chainId string, height int64) error {
+	_, err := repo.coll().RemoveAll(context.Background(), bson.M{"chain_id": chainId, "height": bson.M{"$lt": height}})
+	return err
+}
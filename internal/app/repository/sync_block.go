@@ -5,11 +5,17 @@ import (
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
 	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ISyncBlockRepo interface {
 	FindLatestBlock(chainId string) (*entity.SyncBlock, error)
+	FindByHeightRange(chainId string, startHeight, endHeight int64) ([]*entity.SyncBlock, error)
+	FindByTime(chainId string, blockTime int64, after bool) (*entity.SyncBlock, error)
+	InsertMissing(chainId string, startHeight, endHeight int64, blocks []*entity.SyncBlock) error
+	CreateIndexes(chainId string) error
 }
 
 var _ ISyncBlockRepo = new(SyncBlockRepo)
@@ -26,3 +32,54 @@ func (repo *SyncBlockRepo) FindLatestBlock(chainId string) (*entity.SyncBlock, e
 	err := repo.coll(chainId).Find(context.Background(), bson.M{}).Sort("-height").Limit(1).One(&res)
 	return &res, err
 }
+
+func (repo *SyncBlockRepo) FindByHeightRange(chainId string, startHeight, endHeight int64) ([]*entity.SyncBlock, error) {
+	var res []*entity.SyncBlock
+	query := bson.M{"height": bson.M{"$gte": startHeight, "$lte": endHeight}}
+	err := repo.coll(chainId).Find(context.Background(), query).Sort("height").All(&res)
+	return res, err
+}
+
+// FindByTime the first block at or after the time if after is true, otherwise the last block at or before the time
+func (repo *SyncBlockRepo) FindByTime(chainId string, blockTime int64, after bool) (*entity.SyncBlock, error) {
+	var res entity.SyncBlock
+	query, sort := bson.M{"time": bson.M{"$lte": blockTime}}, "-height"
+	if after {
+		query, sort = bson.M{"time": bson.M{"$gte": blockTime}}, "height"
+	}
+	err := repo.coll(chainId).Find(context.Background(), query).Sort(sort).Limit(1).One(&res)
+	return &res, err
+}
+
+// InsertMissing insert the blocks between the heights(inclusive) which don't exist, the existing blocks, which may be
+// written by the sync service, are kept
+func (repo *SyncBlockRepo) InsertMissing(chainId string, startHeight, endHeight int64, blocks []*entity.SyncBlock) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	existing, err := repo.FindByHeightRange(chainId, startHeight, endHeight)
+	if err != nil {
+		return err
+	}
+	existed := make(map[int64]struct{}, len(existing))
+	for _, v := range existing {
+		existed[v.Height] = struct{}{}
+	}
+
+	missing := make([]*entity.SyncBlock, 0, len(blocks))
+	for _, v := range blocks {
+		if _, ok := existed[v.Height]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	_, err = repo.coll(chainId).InsertMany(context.Background(), missing)
+	return err
+}
+
+func (repo *SyncBlockRepo) CreateIndexes(chainId string) error {
+	indexOpts := officialOpts.Index().SetUnique(true).SetName("ingest_height_unique")
+	return repo.coll(chainId).CreateOneIndex(context.Background(), opts.IndexModel{Key: []string{"-height"}, IndexOptions: indexOpts})
+}
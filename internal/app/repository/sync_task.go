@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/qiniu/qmgo"
@@ -10,6 +11,7 @@ import (
 
 type ISyncTaskRepo interface {
 	CheckFollowingStatus(chainId string) (bool, error)
+	SaveFollowing(chainId, workerId string, startHeight, currentHeight int64) error
 }
 
 var _ ISyncTaskRepo = new(SyncTaskRepo)
@@ -25,3 +27,17 @@ func (repo *SyncTaskRepo) CheckFollowingStatus(chainId string) (bool, error) {
 	count, err := repo.coll(chainId).Find(context.Background(), bson.M{"status": entity.SyncTaskStatusUnderway, "end_height": 0}).Count()
 	return count == 1, err
 }
+
+// SaveFollowing upsert the following task of the worker, whose end_height is 0
+func (repo *SyncTaskRepo) SaveFollowing(chainId, workerId string, startHeight, currentHeight int64) error {
+	query := bson.M{"worker_id": workerId, "end_height": 0}
+	task := entity.SyncTask{
+		Startheight:    startHeight,
+		CurrentHeight:  currentHeight,
+		Status:         entity.SyncTaskStatusUnderway,
+		WorkerId:       workerId,
+		LastUpdateTime: time.Now().Unix(),
+	}
+	_, err := repo.coll(chainId).Upsert(context.Background(), query, task)
+	return err
+}
@@ -2,11 +2,15 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/qiniu/qmgo"
+	opts "github.com/qiniu/qmgo/options"
 	"go.mongodb.org/mongo-driver/bson"
+	officialOpts "go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ITxRepo interface {
@@ -15,6 +19,7 @@ type ITxRepo interface {
 	GetLatestRecvPacketTime(chainId, address, channelId string, startTime int64) (int64, error)
 	GetChannelOpenConfirmTime(chainId, channelId string) (int64, error)
 	GetTransferTx(chainId string, height, limit int64) ([]*entity.Tx, error)
+	GetNftTransferTx(chainId string, height, limit int64) ([]*entity.Tx, error)
 	FindByTypeAndHeight(chainId, txType string, height int64) ([]*entity.Tx, error)
 	GetTxByHash(chainId string, hash string) (entity.Tx, error)
 	GetTxByHashes(chainId string, hashs []string) ([]*entity.Tx, error)
@@ -24,6 +29,12 @@ type ITxRepo interface {
 	FindAllAckTxs(chainId string, height int64) ([]*entity.Tx, error)
 	FindHeight(chainId string, min bool) (entity.Tx, error)
 	UpdateAckPacketId(chainId string, height int64, txHash string, msgs []interface{}) error
+	GetPortTx(chainId string, txTypes []string, portKey, portPrefix string, height, limit int64) ([]*entity.Tx, error)
+	FindPortTxByHeight(chainId string, txTypes []string, portKey, portPrefix string, height int64) ([]*entity.Tx, error)
+	GetEventTx(chainId, eventType string, height, limit int64) ([]*entity.Tx, error)
+	FindEventTxByHeight(chainId, eventType string, height int64) ([]*entity.Tx, error)
+	InsertMissing(chainId string, startHeight, endHeight int64, txs []*entity.Tx) error
+	CreateIndexes(chainId string) error
 }
 
 var _ ITxRepo = new(TxRepo)
@@ -134,6 +145,19 @@ func (repo *TxRepo) GetTransferTx(chainId string, height, limit int64) ([]*entit
 	return res, err
 }
 
+func (repo *TxRepo) GetNftTransferTx(chainId string, height, limit int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := bson.M{
+		"types": constant.MsgTypeNftTransfer,
+		"height": bson.M{
+			"$gt": height,
+		},
+	}
+
+	err := repo.coll(chainId).Find(context.Background(), query).Sort("height").Limit(limit).All(&res)
+	return res, err
+}
+
 func (repo *TxRepo) FindByTypeAndHeight(chainId, txType string, height int64) ([]*entity.Tx, error) {
 	var res []*entity.Tx
 	query := bson.M{
@@ -240,3 +264,104 @@ func (repo *TxRepo) UpdateAckPacketId(chainId string, height int64, txHash strin
 	err := repo.coll(chainId).UpdateOne(context.Background(), filter, update)
 	return err
 }
+
+func portTxQuery(txTypes []string, portKey, portPrefix string) bson.M {
+	return bson.M{
+		"types": bson.M{
+			"$in": txTypes,
+		},
+		"msgs.msg." + portKey: bson.M{
+			"$regex": "^" + portPrefix,
+		},
+	}
+}
+
+// GetPortTx the txs of the msg types, whose port of the msg matches the prefix. eg: recv_packet whose packet.destination_port is icahost
+func (repo *TxRepo) GetPortTx(chainId string, txTypes []string, portKey, portPrefix string, height, limit int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := portTxQuery(txTypes, portKey, portPrefix)
+	query["height"] = bson.M{
+		"$gt": height,
+	}
+
+	err := repo.coll(chainId).Find(context.Background(), query).Sort("height").Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *TxRepo) FindPortTxByHeight(chainId string, txTypes []string, portKey, portPrefix string, height int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := portTxQuery(txTypes, portKey, portPrefix)
+	query["height"] = height
+
+	err := repo.coll(chainId).Find(context.Background(), query).All(&res)
+	return res, err
+}
+
+// GetEventTx the txs emitted the event, eg: send_packet of the msgs of any ibc application
+func (repo *TxRepo) GetEventTx(chainId, eventType string, height, limit int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := bson.M{
+		"events_new.events.type": eventType,
+		"height": bson.M{
+			"$gt": height,
+		},
+	}
+
+	err := repo.coll(chainId).Find(context.Background(), query).Sort("height").Limit(limit).All(&res)
+	return res, err
+}
+
+func (repo *TxRepo) FindEventTxByHeight(chainId, eventType string, height int64) ([]*entity.Tx, error) {
+	var res []*entity.Tx
+	query := bson.M{
+		"events_new.events.type": eventType,
+		"height":                 height,
+	}
+
+	err := repo.coll(chainId).Find(context.Background(), query).All(&res)
+	return res, err
+}
+
+// InsertMissing insert the txs between the heights(inclusive) which don't exist by the height and the tx hash. The
+// existing txs, which may be written by the sync service or the last run before a restart, are kept.
+func (repo *TxRepo) InsertMissing(chainId string, startHeight, endHeight int64, txs []*entity.Tx) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	var existing []*entity.Tx
+	query := bson.M{"height": bson.M{"$gte": startHeight, "$lte": endHeight}}
+	if err := repo.coll(chainId).Find(context.Background(), query).Select(bson.M{"height": 1, "tx_hash": 1}).All(&existing); err != nil {
+		return err
+	}
+	existed := make(map[string]struct{}, len(existing))
+	for _, v := range existing {
+		existed[fmt.Sprintf("%d/%s", v.Height, v.TxHash)] = struct{}{}
+	}
+
+	missing := make([]*entity.Tx, 0, len(txs))
+	for _, v := range txs {
+		if _, ok := existed[fmt.Sprintf("%d/%s", v.Height, v.TxHash)]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	_, err := repo.coll(chainId).InsertMany(context.Background(), missing)
+	return err
+}
+
+// CreateIndexes the indexes of the queries of the tasks, the existing indexes are kept
+func (repo *TxRepo) CreateIndexes(chainId string) error {
+	indexes := []opts.IndexModel{
+		{Key: []string{"tx_hash"}},
+		{Key: []string{"height"}},
+		{Key: []string{"types", "height"}},
+		{Key: []string{"msgs.msg.packet_id"}},
+		{Key: []string{"events_new.events.type", "height"}},
+	}
+	for i := range indexes {
+		indexes[i].IndexOptions = officialOpts.Index().SetName(fmt.Sprintf("ingest_%s", strings.Join(indexes[i].Key, "_")))
+	}
+	return repo.coll(chainId).CreateIndexes(context.Background(), indexes)
+}
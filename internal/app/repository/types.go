@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"context"
+
+	"github.com/qiniu/qmgo"
 	qmgooptions "github.com/qiniu/qmgo/options"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -15,3 +19,14 @@ var (
 		},
 	}
 )
+
+// copySegmentsToNew copy the statistics rows of the segments ended before endTime from the collection into the new
+// collection of a full rebuild, the rows already in the new collection are kept
+func copySegmentsToNew(coll *qmgo.Collection, newCollName string, endTime int64) error {
+	pipe := []bson.M{
+		{"$match": bson.M{"segment_end_time": bson.M{"$lte": endTime}}},
+		{"$merge": bson.M{"into": newCollName, "whenMatched": "keepExisting", "whenNotMatched": "insert"}},
+	}
+	var res []bson.M
+	return coll.Aggregate(context.Background(), pipe).All(&res)
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+	"github.com/shopspring/decimal"
+)
+
+const seriesMaxBuckets = 1000
+
+// seriesInterval bucket definition of the series, the bucket label of go and mongo must be the same
+type seriesInterval struct {
+	mongoFormat string
+	label       func(t time.Time) string
+	truncate    func(t time.Time) time.Time
+	next        func(t time.Time) time.Time
+	defaultSpan func(end time.Time) time.Time
+}
+
+var seriesIntervals = map[string]seriesInterval{
+	vo.SeriesIntervalHour: {
+		mongoFormat: "%Y-%m-%d %H:00",
+		label:       func(t time.Time) string { return t.Format("2006-01-02 15:00") },
+		truncate: func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.Local)
+		},
+		next:        func(t time.Time) time.Time { return t.Add(time.Hour) },
+		defaultSpan: func(end time.Time) time.Time { return end.Add(-24 * time.Hour) },
+	},
+	vo.SeriesIntervalDay: {
+		mongoFormat: "%Y-%m-%d",
+		label:       func(t time.Time) string { return t.Format("2006-01-02") },
+		truncate: func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+		},
+		next:        func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+		defaultSpan: func(end time.Time) time.Time { return end.AddDate(0, 0, -30) },
+	},
+	vo.SeriesIntervalWeek: {
+		mongoFormat: "%G-W%V",
+		label: func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		},
+		truncate: func(t time.Time) time.Time {
+			offset := (int(t.Weekday()) + 6) % 7 // ISO week 从周一开始
+			return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.Local)
+		},
+		next:        func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+		defaultSpan: func(end time.Time) time.Time { return end.AddDate(0, 0, -7*26) },
+	},
+	vo.SeriesIntervalMonth: {
+		mongoFormat: "%Y-%m",
+		label:       func(t time.Time) string { return t.Format("2006-01") },
+		truncate: func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+		},
+		next:        func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+		defaultSpan: func(end time.Time) time.Time { return end.AddDate(-1, 0, 0) },
+	},
+}
+
+type IAnalyticsService interface {
+	Series(req *vo.AnalyticsSeriesReq) (*vo.AnalyticsSeriesResp, errors.Error)
+	Flows(req *vo.AnalyticsFlowsReq) (*vo.AnalyticsFlowsResp, errors.Error)
+	Failures(req *vo.AnalyticsFailuresReq) (*vo.AnalyticsFailuresResp, errors.Error)
+}
+
+var _ IAnalyticsService = new(AnalyticsService)
+
+type AnalyticsService struct {
+}
+
+// Series the transfer txs, value, success txs and refunded txs of each bucket. The day/week/month buckets are
+// aggregated from the daily statistics(ibc_channel_statistics, or ibc_relayer_statistics if relayer is specified),
+// the hour buckets are aggregated from ex_ibc_tx_latest. The value is calculated by the current token price.
+func (svc *AnalyticsService) Series(req *vo.AnalyticsSeriesReq) (*vo.AnalyticsSeriesResp, errors.Error) {
+	interval, ok := seriesIntervals[req.Interval]
+	if !ok {
+		return nil, errors.WrapBadRequest(fmt.Errorf("invalid interval %s, supported: hour, day, week, month", req.Interval))
+	}
+	if req.Interval == vo.SeriesIntervalHour && req.Relayer != "" {
+		return nil, errors.WrapBadRequest(fmt.Errorf("relayer series doesn't support hour interval"))
+	}
+
+	end := time.Now()
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	start := interval.defaultSpan(end)
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	if !start.Before(end) {
+		return nil, errors.WrapBadRequest(fmt.Errorf("start_time must be less than end_time"))
+	}
+
+	var buckets []time.Time
+	for t := interval.truncate(start); !t.After(end); t = interval.next(t) {
+		buckets = append(buckets, t)
+		if len(buckets) > seriesMaxBuckets {
+			return nil, errors.WrapBadRequest(fmt.Errorf("too many buckets, the max is %d", seriesMaxBuckets))
+		}
+	}
+
+	cond := &dto.AnalyticsSeriesCondDTO{
+		StartTime:        buckets[0].Unix(),
+		EndTime:          end.Unix(),
+		BucketFormat:     interval.mongoFormat,
+		Timezone:         end.Format("-07:00"),
+		ChainId:          req.Chain,
+		BaseDenom:        req.BaseDenom,
+		BaseDenomChainId: req.BaseDenomChainId,
+	}
+	if req.Channel != "" {
+		split := strings.Split(req.Channel, "|")
+		if len(split) != 4 {
+			return nil, errors.WrapBadRequest(fmt.Errorf("channel parameter format error, chainA|channelA|chainB|channelB"))
+		}
+		cond.ChannelIds = []string{req.Channel, fmt.Sprintf("%s|%s|%s|%s", split[2], split[3], split[0], split[1])}
+	}
+
+	var aggr []*dto.AggrSeriesDTO
+	var err error
+	switch {
+	case req.Relayer != "":
+		var relayer *entity.IBCRelayer
+		if relayer, err = relayerRepo.FindOne(req.Relayer); err != nil {
+			if err == qmgo.ErrNoSuchDocuments {
+				return nil, errors.WrapBadRequest(fmt.Errorf("relayer %s not found", req.Relayer))
+			}
+			return nil, errors.Wrap(err)
+		}
+		cond.StatisticIds, cond.Addresses = svc.relayerStatisticCond(relayer)
+		if len(cond.Addresses) > 0 {
+			aggr, err = relayerStatisticsRepo.AggrSeries(cond)
+		}
+	case req.Interval == vo.SeriesIntervalHour:
+		aggr, err = ibcTxRepo.AggrSeries(cond)
+	default:
+		aggr, err = channelStatisticsRepo.AggrSeries(cond)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	items, err := svc.buildItems(interval, buckets, aggr)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	return &vo.AnalyticsSeriesResp{
+		Interval: req.Interval,
+		Currency: constant.DefaultCurrency,
+		Items:    items,
+	}, nil
+}
+
+func (svc *AnalyticsService) relayerStatisticCond(relayer *entity.IBCRelayer) ([]string, []string) {
+	statisticId1, statisticId2 := relayerStatisticsRepo.CreateStatisticId(relayer.ChainA, relayer.ChainB, relayer.ChannelA, relayer.ChannelB)
+	var addrs []string
+	for _, v := range append([]string{relayer.ChainAAddress, relayer.ChainBAddress}, relayer.ChainAAllAddress...) {
+		if v != "" {
+			addrs = append(addrs, v)
+		}
+	}
+	return []string{statisticId1, statisticId2}, addrs
+}
+
+func (svc *AnalyticsService) buildItems(interval seriesInterval, buckets []time.Time, aggr []*dto.AggrSeriesDTO) ([]vo.AnalyticsSeriesItem, error) {
+	calculator, err := newDenomValueCalculator()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]vo.AnalyticsSeriesItem, 0, len(buckets))
+	indexMap := make(map[string]int, len(buckets))
+	values := make([]decimal.Decimal, len(buckets))
+	for i, v := range buckets {
+		label := interval.label(v)
+		indexMap[label] = i
+		items = append(items, vo.AnalyticsSeriesItem{
+			Bucket:    label,
+			StartTime: v.Unix(),
+		})
+	}
+
+	for _, v := range aggr {
+		i, ok := indexMap[v.Bucket]
+		if !ok {
+			continue
+		}
+		items[i].TransferTxs += v.Count
+		items[i].SuccessTxs += v.SuccessCount
+		items[i].RefundedTxs += v.RefundedCount
+		values[i] = values[i].Add(calculator.value(v.Amount, v.BaseDenom, v.BaseDenomChainId))
+	}
+
+	for i := range items {
+		items[i].TransferTxsValue = values[i].Round(constant.DefaultValuePrecision).String()
+	}
+	return items, nil
+}
+
+// denomValueCalculator calculate the value of base denom amount by the current token price
+type denomValueCalculator struct {
+	baseDenomMap entity.IBCBaseDenomMap
+	prices       map[string]float64
+}
+
+func newDenomValueCalculator() (*denomValueCalculator, error) {
+	baseDenoms, err := baseDenomRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	prices, err := tokenPriceRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return &denomValueCalculator{
+		baseDenomMap: baseDenoms.ConvertToMap(),
+		prices:       prices,
+	}, nil
+}
+
+func (c *denomValueCalculator) value(amount float64, baseDenom, baseDenomChainId string) decimal.Decimal {
+	denom, ok := c.baseDenomMap[fmt.Sprintf("%s%s", baseDenomChainId, baseDenom)]
+	if !ok || denom.CoinId == "" {
+		return decimal.Zero
+	}
+	price, ok := c.prices[denom.CoinId]
+	if !ok {
+		return decimal.Zero
+	}
+
+	return decimal.NewFromFloat(amount).Div(decimal.NewFromFloat(math.Pow10(denom.Scale))).Mul(decimal.NewFromFloat(price))
+}
+
+// Flows the directional flows between chains in the time window, aggregated from ibc_chain_flow_statistics
+func (svc *AnalyticsService) Flows(req *vo.AnalyticsFlowsReq) (*vo.AnalyticsFlowsResp, errors.Error) {
+	end := time.Now()
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	start := end.AddDate(0, 0, -30)
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	if !start.Before(end) {
+		return nil, errors.WrapBadRequest(fmt.Errorf("start_time must be less than end_time"))
+	}
+	// 统计数据按天分段
+	start = seriesIntervals[vo.SeriesIntervalDay].truncate(start)
+
+	flows, err := chainFlowStatisticsRepo.AggrFlows(start.Unix(), end.Unix(), req.BaseDenom, req.BaseDenomChainId)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	calculator, err := newDenomValueCalculator()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	type linkStat struct {
+		txs    int64
+		amount decimal.Decimal
+		value  decimal.Decimal
+	}
+	type nodeStat struct {
+		inflowTxs, outflowTxs     int64
+		inflowValue, outflowValue decimal.Decimal
+	}
+	linkMap := make(map[string]*linkStat)
+	nodeMap := make(map[string]*nodeStat)
+	getNode := func(chainId string) *nodeStat {
+		if _, ok := nodeMap[chainId]; !ok {
+			nodeMap[chainId] = &nodeStat{}
+		}
+		return nodeMap[chainId]
+	}
+	for _, v := range flows {
+		if req.Chain != "" && v.ScChainId != req.Chain && v.DcChainId != req.Chain {
+			continue
+		}
+
+		value := calculator.value(v.Amount, v.BaseDenom, v.BaseDenomChainId)
+		key := fmt.Sprintf("%s|%s", v.ScChainId, v.DcChainId)
+		link, ok := linkMap[key]
+		if !ok {
+			link = &linkStat{}
+			linkMap[key] = link
+		}
+		link.txs += v.Count
+		link.amount = link.amount.Add(decimal.NewFromFloat(v.Amount))
+		link.value = link.value.Add(value)
+
+		source, target := getNode(v.ScChainId), getNode(v.DcChainId)
+		source.outflowTxs += v.Count
+		source.outflowValue = source.outflowValue.Add(value)
+		target.inflowTxs += v.Count
+		target.inflowValue = target.inflowValue.Add(value)
+	}
+
+	links := make([]vo.AnalyticsFlowLink, 0, len(linkMap))
+	for k, v := range linkMap {
+		split := strings.Split(k, "|")
+		link := vo.AnalyticsFlowLink{
+			Source: split[0],
+			Target: split[1],
+			Txs:    v.txs,
+			Value:  v.value.Round(constant.DefaultValuePrecision).String(),
+		}
+		if req.BaseDenom != "" && req.BaseDenomChainId != "" {
+			link.Amount = v.amount.String()
+		}
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Txs != links[j].Txs {
+			return links[i].Txs > links[j].Txs
+		}
+		return links[i].Source+links[i].Target < links[j].Source+links[j].Target
+	})
+
+	nodes := make([]vo.AnalyticsFlowNode, 0, len(nodeMap))
+	for k, v := range nodeMap {
+		nodes = append(nodes, vo.AnalyticsFlowNode{
+			Name:         k,
+			InflowTxs:    v.inflowTxs,
+			OutflowTxs:   v.outflowTxs,
+			InflowValue:  v.inflowValue.Round(constant.DefaultValuePrecision).String(),
+			OutflowValue: v.outflowValue.Round(constant.DefaultValuePrecision).String(),
+			NetflowValue: v.inflowValue.Sub(v.outflowValue).Round(constant.DefaultValuePrecision).String(),
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	return &vo.AnalyticsFlowsResp{
+		StartTime: start.Unix(),
+		EndTime:   end.Unix(),
+		Currency:  constant.DefaultCurrency,
+		Nodes:     nodes,
+		Links:     links,
+	}, nil
+}
+
+// Failures the failed and refunded txs by failure category, grouped by the sc chain or the sc channel.
+// The txs of both ex_ibc_tx_latest and ex_ibc_tx in the time window are aggregated, the default window is the last 30 days.
+func (svc *AnalyticsService) Failures(req *vo.AnalyticsFailuresReq) (*vo.AnalyticsFailuresResp, errors.Error) {
+	groupBy := req.GroupBy
+	if groupBy == "" {
+		groupBy = vo.FailuresGroupByChain
+	}
+	if groupBy != vo.FailuresGroupByChain && groupBy != vo.FailuresGroupByChannel {
+		return nil, errors.WrapBadRequest(fmt.Errorf("invalid group_by %s, supported: chain, channel", req.GroupBy))
+	}
+
+	end := time.Now()
+	if req.EndTime > 0 {
+		end = time.Unix(req.EndTime, 0)
+	}
+	start := end.AddDate(0, 0, -30)
+	if req.StartTime > 0 {
+		start = time.Unix(req.StartTime, 0)
+	}
+	if !start.Before(end) {
+		return nil, errors.WrapBadRequest(fmt.Errorf("start_time must be less than end_time"))
+	}
+
+	failures, err := ibcTxRepo.AggrFailureCategory(start.Unix(), end.Unix())
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	type itemStat struct {
+		item       vo.AnalyticsFailureItem
+		categories map[string]int64
+	}
+	itemMap := make(map[string]*itemStat)
+	for _, v := range failures {
+		if req.Chain != "" && v.ScChainId != req.Chain && v.DcChainId != req.Chain {
+			continue
+		}
+
+		key := v.ScChainId
+		item := vo.AnalyticsFailureItem{ChainId: v.ScChainId}
+		if groupBy == vo.FailuresGroupByChannel {
+			key = fmt.Sprintf("%s|%s|%s|%s", v.ScChainId, v.ScChannel, v.DcChainId, v.DcChannel)
+			item.Channel = key
+		}
+		stat, ok := itemMap[key]
+		if !ok {
+			stat = &itemStat{item: item, categories: make(map[string]int64)}
+			itemMap[key] = stat
+		}
+
+		category := v.FailureCategory
+		if category == "" { // 未分类的交易, 需运行 fix_failure_category_task
+			category = model.FailureCategoryUnknown
+		}
+		stat.item.FailedTxs += v.Count
+		stat.categories[category] += v.Count
+	}
+
+	items := make([]vo.AnalyticsFailureItem, 0, len(itemMap))
+	for _, v := range itemMap {
+		v.item.Categories = make([]vo.AnalyticsFailureCategory, 0, len(v.categories))
+		for _, category := range model.FailureCategories {
+			if txs, ok := v.categories[category]; ok {
+				v.item.Categories = append(v.item.Categories, vo.AnalyticsFailureCategory{Category: category, Txs: txs})
+			}
+		}
+		items = append(items, v.item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].FailedTxs != items[j].FailedTxs {
+			return items[i].FailedTxs > items[j].FailedTxs
+		}
+		return items[i].ChainId+items[i].Channel < items[j].ChainId+items[j].Channel
+	})
+
+	return &vo.AnalyticsFailuresResp{
+		StartTime: start.Unix(),
+		EndTime:   end.Unix(),
+		GroupBy:   groupBy,
+		Items:     items,
+	}, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+func TestAnalyticsService_Series(t *testing.T) {
+	for _, interval := range []string{vo.SeriesIntervalHour, vo.SeriesIntervalDay, vo.SeriesIntervalWeek, vo.SeriesIntervalMonth} {
+		resp, err := new(AnalyticsService).Series(&vo.AnalyticsSeriesReq{
+			Interval: interval,
+			Chain:    "irishub_qa",
+		})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		t.Log(resp)
+	}
+}
+
+func TestSeriesIntervalWeek(t *testing.T) {
+	week := seriesIntervals[vo.SeriesIntervalWeek]
+	// 2022-01-01 is Saturday, belongs to the 52nd week of 2021
+	start := week.truncate(time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local))
+	if start.Weekday() != time.Monday || start.Day() != 27 {
+		t.Fatalf("unexpected week start %v", start)
+	}
+	if label := week.label(start); label != "2021-W52" {
+		t.Fatalf("unexpected week label %s", label)
+	}
+}
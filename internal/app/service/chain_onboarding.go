@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	lcdclient "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils/bech32"
+	"github.com/qiniu/qmgo"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	lcdApiChannels    = "/ibc/core/channel/%s/channels?pagination.offset=OFFSET&pagination.limit=LIMIT&pagination.count_total=true"
+	lcdApiClientState = "/ibc/core/channel/%s/channels/CHANNEL/ports/PORT/client_state"
+	lcdApiSupply      = "/cosmos/bank/v1beta1/supply"
+	lcdApiBalances    = "/cosmos/bank/v1beta1/balances/" + entity.ApiBalancesPathPlaceholder
+	lcdApiParams      = "/cosmos/" + entity.ParamsModulePathPlaceholder + "/v1beta1/params"
+)
+
+var addrPrefixRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+type IChainOnboardingService interface {
+	Prepare(req *vo.ChainOnboardReq) (*vo.ChainOnboardingResp, errors.Error)
+	Progress(chainId string) (*vo.ChainOnboardingResp, errors.Error)
+}
+
+var _ IChainOnboardingService = new(ChainOnboardingService)
+
+type ChainOnboardingService struct {
+	dto vo.ChainOnboardingResp
+}
+
+// Prepare validate the chain definition, then save the chain config and the chain registry. The remaining steps are
+// run by ChainOnboardingTask.
+func (svc *ChainOnboardingService) Prepare(req *vo.ChainOnboardReq) (*vo.ChainOnboardingResp, errors.Error) {
+	if req.ChainJsonUrl != "" {
+		if err := svc.loadChainRegistry(req); err != nil {
+			return nil, errors.WrapBadRequest(err)
+		}
+	}
+	if req.ChainId == "" {
+		return nil, errors.WrapBadRequest(fmt.Errorf("chain_id is required"))
+	}
+	chainId := strings.ReplaceAll(req.ChainId, "-", "_")
+
+	onboarding, err := chainOnboardingRepo.FindOne(chainId)
+	if err != nil && err != qmgo.ErrNoSuchDocuments {
+		return nil, errors.Wrap(err)
+	}
+	// 进程退出遗留的running状态超时后视为失败
+	stale := onboarding != nil && onboarding.IsStale(time.Now().Unix())
+	if onboarding != nil && onboarding.Status == entity.ChainOnboardingStatusRunning && !stale {
+		return nil, errors.WrapBadRequest(fmt.Errorf("chain %s is onboarding", chainId))
+	}
+	// 只有上次接入失败的链可以重新接入
+	if onboarding == nil || (onboarding.Status != entity.ChainOnboardingStatusFailed && !stale) {
+		_, err = chainCfgRepo.FindOne(chainId)
+		if err == nil {
+			return nil, errors.WrapBadRequest(fmt.Errorf("chain %s already exists", chainId))
+		}
+		if err != qmgo.ErrNoSuchDocuments {
+			return nil, errors.Wrap(err)
+		}
+	}
+
+	onboarding = entity.NewChainOnboarding(chainId, req.ChainJsonUrl)
+	onboarding.StartStep(entity.ChainOnboardingStepValidate)
+	chainConfig, err := svc.validate(req, chainId)
+	if err != nil {
+		return nil, errors.WrapBadRequest(err)
+	}
+	onboarding.FinishStep(entity.ChainOnboardingStepValidate, nil, fmt.Sprintf("lcd: %s", chainConfig.Lcd))
+
+	// the onboarding is saved as running before the chain config, so that a chain config left by a failed save is
+	// followed by a failed(or stale) onboarding, and the chain can be onboarded again
+	if err = chainOnboardingRepo.Save(onboarding); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	onboarding.StartStep(entity.ChainOnboardingStepSaveChainConfig)
+	if err = chainCfgRepo.Save(chainConfig); err != nil {
+		return nil, svc.fail(onboarding, entity.ChainOnboardingStepSaveChainConfig, err)
+	}
+	onboarding.FinishStep(entity.ChainOnboardingStepSaveChainConfig, nil, "")
+
+	onboarding.StartStep(entity.ChainOnboardingStepSaveRegistry)
+	if req.ChainJsonUrl != "" {
+		if err = chainRegistryRepo.Save(&entity.ChainRegistry{ChainId: chainId, ChainJsonUrl: req.ChainJsonUrl}); err != nil {
+			return nil, svc.fail(onboarding, entity.ChainOnboardingStepSaveRegistry, err)
+		}
+		onboarding.FinishStep(entity.ChainOnboardingStepSaveRegistry, nil, "")
+	} else {
+		onboarding.FinishStep(entity.ChainOnboardingStepSaveRegistry, nil, "skipped, chain_json_url is empty")
+	}
+
+	if err = chainOnboardingRepo.Save(onboarding); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	resp := svc.dto.LoadDto(onboarding)
+	return &resp, nil
+}
+
+// fail mark the step of the onboarding as failed, the failed onboarding can be retried. If the onboarding can't be
+// saved, it's left running and can be retried once it's stale.
+func (svc *ChainOnboardingService) fail(onboarding *entity.IBCChainOnboarding, step string, err error) errors.Error {
+	onboarding.FinishStep(step, err, "")
+	if saveErr := chainOnboardingRepo.Save(onboarding); saveErr != nil {
+		logrus.Errorf("chain %s save failed onboarding error, %v", onboarding.ChainId, saveErr)
+	}
+	return errors.Wrap(err)
+}
+
+func (svc *ChainOnboardingService) Progress(chainId string) (*vo.ChainOnboardingResp, errors.Error) {
+	onboarding, err := chainOnboardingRepo.FindOne(chainId)
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return nil, errors.WrapBadRequest(fmt.Errorf("chain %s has no onboarding", chainId))
+		}
+		return nil, errors.Wrap(err)
+	}
+	resp := svc.dto.LoadDto(onboarding)
+	return &resp, nil
+}
+
+// loadChainRegistry fill the empty fields of the chain definition from the chain.json
+func (svc *ChainOnboardingService) loadChainRegistry(req *vo.ChainOnboardReq) error {
+	bz, err := utils.HttpGet(req.ChainJsonUrl)
+	if err != nil {
+		return fmt.Errorf("get chain registry json error: %s", err.Error())
+	}
+	var chainRegisterResp vo.ChainRegisterResp
+	if err = json.Unmarshal(bz, &chainRegisterResp); err != nil {
+		return fmt.Errorf("unmarshal chain registry json error: %s", err.Error())
+	}
+	if chainRegisterResp.ChainId == "" {
+		return fmt.Errorf("chain_id of the chain registry json is empty")
+	}
+
+	if req.ChainId == "" {
+		req.ChainId = chainRegisterResp.ChainId
+	}
+	if req.ChainName == "" {
+		req.ChainName = chainRegisterResp.PrettyName
+	}
+	if req.AddrPrefix == "" {
+		req.AddrPrefix = chainRegisterResp.Bech32Prefix
+	}
+	if req.Lcd == "" {
+		lcds := make([]string, 0, len(chainRegisterResp.Apis.Rest))
+		for _, v := range chainRegisterResp.Apis.Rest {
+			lcds = append(lcds, v.Address)
+		}
+		req.Lcd = strings.Join(lcds, ",")
+	}
+	if req.Rpc == "" && req.DataSource == entity.DataSourceRpc && len(chainRegisterResp.Apis.Rpc) > 0 {
+		req.Rpc = chainRegisterResp.Apis.Rpc[0].Address
+	}
+	if req.Grpc == "" && req.DataSource == entity.DataSourceGrpc && len(chainRegisterResp.Apis.Grpc) > 0 {
+		req.Grpc = chainRegisterResp.Apis.Grpc[0].Address
+	}
+	return nil
+}
+
+// validate check the address prefix and find the first available lcd of the chain
+func (svc *ChainOnboardingService) validate(req *vo.ChainOnboardReq, chainId string) (*entity.ChainConfig, error) {
+	if !addrPrefixRegexp.MatchString(req.AddrPrefix) {
+		return nil, fmt.Errorf("invalid addr_prefix %s", req.AddrPrefix)
+	}
+	if _, err := bech32.ConvertAndEncode(req.AddrPrefix, make([]byte, 20)); err != nil {
+		return nil, fmt.Errorf("invalid addr_prefix %s, %v", req.AddrPrefix, err)
+	}
+	if req.Lcd == "" {
+		return nil, fmt.Errorf("lcd is required")
+	}
+	switch req.DataSource {
+	case "", entity.DataSourceLcd:
+	case entity.DataSourceRpc:
+		if req.Rpc == "" {
+			return nil, fmt.Errorf("rpc is required by data_source rpc")
+		}
+	case entity.DataSourceGrpc:
+		if req.Grpc == "" {
+			return nil, fmt.Errorf("grpc is required by data_source grpc")
+		}
+	default:
+		return nil, fmt.Errorf("invalid data_source %s", req.DataSource)
+	}
+
+	var lcdErrs []string
+	for _, lcd := range strings.Split(req.Lcd, ",") {
+		lcd = strings.TrimRight(strings.TrimSpace(lcd), "/")
+		if lcd == "" {
+			continue
+		}
+		version, err := checkOnboardingLcd(lcd, chainId)
+		if err != nil {
+			lcdErrs = append(lcdErrs, fmt.Sprintf("%s: %v", lcd, err))
+			continue
+		}
+
+		return &entity.ChainConfig{
+			ChainId:    chainId,
+			Icon:       req.Icon,
+			ChainName:  req.ChainName,
+			Lcd:        lcd,
+			AddrPrefix: req.AddrPrefix,
+			LcdApiPath: entity.ApiPath{
+				ChannelsPath:    fmt.Sprintf(lcdApiChannels, version),
+				ClientStatePath: fmt.Sprintf(lcdApiClientState, version),
+				SupplyPath:      lcdApiSupply,
+				BalancesPath:    lcdApiBalances,
+				ParamsPath:      lcdApiParams,
+			},
+			Status:     entity.ChainStatusOpen,
+			DataSource: req.DataSource,
+			Rpc:        strings.TrimRight(req.Rpc, "/"),
+			Grpc:       req.Grpc,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no available lcd, %s", strings.Join(lcdErrs, "; "))
+}
+
+// checkOnboardingLcd check the network of the lcd, and return the version of the ibc channel api
+func checkOnboardingLcd(lcd, chainId string) (string, error) {
+	client := lcdclient.ForAddr(chainId, lcd)
+	nodeInfo, err := client.NodeInfo(context.Background())
+	if err != nil {
+		return "", err
+	}
+	if strings.ReplaceAll(nodeInfo.DefaultNodeInfo.Network, "-", "_") != chainId {
+		return "", fmt.Errorf("network %s mismatch chain_id %s", nodeInfo.DefaultNodeInfo.Network, chainId)
+	}
+
+	return client.NegotiateApiVersion(context.Background())
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+)
+
+type IChainRegistryService interface {
+	DiffsCount(req *vo.ChainRegistryDiffsReq) (int64, errors.Error)
+	Diffs(req *vo.ChainRegistryDiffsReq) (vo.ChainRegistryDiffsResp, errors.Error)
+	Approve(diffId string) (*vo.ChainRegistryDiffDto, errors.Error)
+	Reject(diffId string) (*vo.ChainRegistryDiffDto, errors.Error)
+}
+
+var _ IChainRegistryService = new(ChainRegistryService)
+
+type ChainRegistryService struct {
+	dto vo.ChainRegistryDiffDto
+}
+
+func createChainRegistryDiffQuery(req *vo.ChainRegistryDiffsReq) dto.ChainRegistryDiffQuery {
+	return dto.ChainRegistryDiffQuery{
+		ChainId: req.ChainId,
+		Coll:    req.Coll,
+		Status:  req.Status,
+	}
+}
+
+func (svc ChainRegistryService) DiffsCount(req *vo.ChainRegistryDiffsReq) (int64, errors.Error) {
+	count, err := chainRegistryDiffRepo.CountList(createChainRegistryDiffQuery(req))
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	if count > constant.DisplayIbcRecordMax {
+		return constant.DisplayIbcRecordMax, nil
+	}
+	return count, nil
+}
+
+func (svc ChainRegistryService) Diffs(req *vo.ChainRegistryDiffsReq) (vo.ChainRegistryDiffsResp, errors.Error) {
+	var resp vo.ChainRegistryDiffsResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	res, err := chainRegistryDiffRepo.List(createChainRegistryDiffQuery(req), skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.ChainRegistryDiffDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.dto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+// Approve apply the pending diff to the local metadata
+func (svc ChainRegistryService) Approve(diffId string) (*vo.ChainRegistryDiffDto, errors.Error) {
+	diff, e := svc.findPendingDiff(diffId)
+	if e != nil {
+		return nil, e
+	}
+
+	var symbols []string
+	if diff.Coll == (entity.IBCBaseDenom{}).CollectionName() {
+		symbols, e = svc.baseDenomSymbols(diff)
+		if e != nil {
+			return nil, e
+		}
+	}
+	if err := chainRegistryDiffRepo.Apply(diff); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if len(symbols) > 0 {
+		if err := baseDenomRepo.DelCache(symbols...); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
+
+	resp := svc.dto.LoadDto(diff)
+	return &resp, nil
+}
+
+func (svc ChainRegistryService) Reject(diffId string) (*vo.ChainRegistryDiffDto, errors.Error) {
+	diff, e := svc.findPendingDiff(diffId)
+	if e != nil {
+		return nil, e
+	}
+	if err := chainRegistryDiffRepo.UpdateStatus(diffId, entity.ChainRegistryDiffStatusRejected); err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	diff.Status = entity.ChainRegistryDiffStatusRejected
+	resp := svc.dto.LoadDto(diff)
+	return &resp, nil
+}
+
+func (svc ChainRegistryService) findPendingDiff(diffId string) (*entity.IBCChainRegistryDiff, errors.Error) {
+	diff, err := chainRegistryDiffRepo.FindOne(diffId)
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return nil, errors.WrapBadRequest(fmt.Errorf("diff %s not found", diffId))
+		}
+		return nil, errors.Wrap(err)
+	}
+	if diff.Status != entity.ChainRegistryDiffStatusPending {
+		return nil, errors.WrapBadRequest(fmt.Errorf("diff %s is %s", diffId, diff.Status))
+	}
+	return diff, nil
+}
+
+// baseDenomSymbols the symbols whose cache should be deleted after the diff of the base denom is applied
+func (svc ChainRegistryService) baseDenomSymbols(diff *entity.IBCChainRegistryDiff) ([]string, errors.Error) {
+	baseDenoms, err := baseDenomRepo.FindAll()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	var symbols []string
+	for _, v := range baseDenoms {
+		if v.ChainId == diff.ChainId && v.Denom == diff.Denom {
+			symbols = append(symbols, v.Symbol)
+		}
+	}
+	if diff.Field == "symbol" {
+		symbols = append(symbols, fmt.Sprint(diff.NewValue))
+	}
+	return symbols, nil
+}
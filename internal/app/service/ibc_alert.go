@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+type IAlertService interface {
+	LargeTransfersCount(req *vo.LargeTransfersReq) (int64, errors.Error)
+	LargeTransfers(req *vo.LargeTransfersReq) (vo.LargeTransfersResp, errors.Error)
+}
+
+var _ IAlertService = new(AlertService)
+
+type AlertService struct {
+	dto vo.LargeTransferDto
+}
+
+func createLargeTransferQuery(req *vo.LargeTransfersReq) (dto.LargeTransferAlertQuery, error) {
+	query := dto.LargeTransferAlertQuery{
+		ChainId:   req.ChainId,
+		BaseDenom: req.BaseDenom,
+		Reason:    req.Reason,
+	}
+	if req.Reason != "" && req.Reason != entity.LargeTransferReasonUsdThreshold && req.Reason != entity.LargeTransferReasonStatistical {
+		return query, fmt.Errorf("invalid reason %s", req.Reason)
+	}
+	var err error
+	if req.DateRange != "" {
+		dateRange := strings.Split(req.DateRange, ",")
+		if len(dateRange) == 2 {
+			query.StartTime, err = strconv.ParseInt(dateRange[0], 10, 64)
+			if err != nil {
+				return query, err
+			}
+			query.EndTime, err = strconv.ParseInt(dateRange[1], 10, 64)
+			if err != nil {
+				return query, err
+			}
+		}
+	}
+	return query, nil
+}
+
+func (svc AlertService) LargeTransfersCount(req *vo.LargeTransfersReq) (int64, errors.Error) {
+	query, err := createLargeTransferQuery(req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	count, err := largeTransferAlertRepo.CountList(query)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	if count > constant.DisplayIbcRecordMax {
+		return constant.DisplayIbcRecordMax, nil
+	}
+	return count, nil
+}
+
+func (svc AlertService) LargeTransfers(req *vo.LargeTransfersReq) (vo.LargeTransfersResp, errors.Error) {
+	var resp vo.LargeTransfersResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	query, err := createLargeTransferQuery(req)
+	if err != nil {
+		return resp, errors.WrapBadRequest(err)
+	}
+	res, err := largeTransferAlertRepo.List(query, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.LargeTransferDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.dto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
@@ -3,15 +3,18 @@ package service
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 )
 
 type IChannelService interface {
 	List(req *vo.ChannelListReq) (*vo.ChannelListResp, errors.Error)
 	ListCount(req *vo.ChannelListReq) (int64, errors.Error)
+	ChannelTypes() (*vo.ChannelTypesResp, errors.Error)
 }
 
 var _ IChannelService = new(ChannelService)
@@ -26,7 +29,7 @@ func (svc *ChannelService) List(req *vo.ChannelListReq) (*vo.ChannelListResp, er
 	}
 
 	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
-	list, err := channelRepo.List(chainA, chainB, req.Status, skip, limit)
+	list, err := channelRepo.List(chainA, chainB, req.Status, req.ChannelType, skip, limit)
 	if err != nil {
 		return nil, errors.Wrap(err)
 	}
@@ -45,12 +48,13 @@ func (svc *ChannelService) List(req *vo.ChannelListReq) (*vo.ChannelListResp, er
 			IbcTransferTxs:      v.TransferTxs,
 			Currency:            constant.DefaultCurrency,
 			Status:              v.Status,
+			ChannelType:         v.ChannelType,
 		})
 	}
 
 	var totalItem int64
 	if req.UseCount {
-		totalItem, err = channelRepo.CountList(chainA, chainB, req.Status)
+		totalItem, err = channelRepo.CountList(chainA, chainB, req.Status, req.ChannelType)
 		if err != nil {
 			return nil, errors.Wrap(err)
 		}
@@ -82,10 +86,49 @@ func (svc *ChannelService) ListCount(req *vo.ChannelListReq) (int64, errors.Erro
 		return 0, errors.Wrap(err)
 	}
 
-	totalItem, err := channelRepo.CountList(chainA, chainB, req.Status)
+	totalItem, err := channelRepo.CountList(chainA, chainB, req.Status, req.ChannelType)
 	if err != nil {
 		return 0, errors.Wrap(err)
 	}
 
 	return totalItem, nil
 }
+
+// ChannelTypes the breakdown of the channels by the channel type
+func (svc *ChannelService) ChannelTypes() (*vo.ChannelTypesResp, errors.Error) {
+	aggrList, err := channelRepo.AggrChannelType()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	itemMap := make(map[string]*vo.ChannelTypeItem)
+	for _, v := range aggrList {
+		channelType := v.ChannelType
+		if channelType == "" { // channel_task 还未更新的channel
+			channelType = entity.ChannelTypeOther
+		}
+		item, ok := itemMap[channelType]
+		if !ok {
+			item = &vo.ChannelTypeItem{ChannelType: channelType}
+			itemMap[channelType] = item
+		}
+		item.Channels += v.Count
+		switch v.Status {
+		case entity.ChannelStatusOpened:
+			item.OpenedChannels += v.Count
+		case entity.ChannelStatusClosed:
+			item.ClosedChannels += v.Count
+		}
+	}
+
+	items := make([]vo.ChannelTypeItem, 0, len(itemMap))
+	for _, channelType := range []string{entity.ChannelTypeTransfer, entity.ChannelTypeIca, entity.ChannelTypeNft, entity.ChannelTypeOther} {
+		if item, ok := itemMap[channelType]; ok {
+			items = append(items, *item)
+		}
+	}
+	return &vo.ChannelTypesResp{
+		Items:     items,
+		TimeStamp: time.Now().Unix(),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+type IEscrowService interface {
+	ReconcilesCount(req *vo.EscrowReconcilesReq) (int64, errors.Error)
+	Reconciles(req *vo.EscrowReconcilesReq) (vo.EscrowReconcilesResp, errors.Error)
+	DiscrepanciesCount(req *vo.EscrowDiscrepanciesReq) (int64, errors.Error)
+	Discrepancies(req *vo.EscrowDiscrepanciesReq) (vo.EscrowDiscrepanciesResp, errors.Error)
+}
+
+var _ IEscrowService = new(EscrowService)
+
+type EscrowService struct {
+	reconcileDto   vo.EscrowReconcileDto
+	discrepancyDto vo.EscrowDiscrepancyDto
+}
+
+func createEscrowReconcileQuery(req *vo.EscrowReconcilesReq) (dto.EscrowReconcileQuery, error) {
+	query := dto.EscrowReconcileQuery{
+		ChainId:   req.ChainId,
+		ChannelId: req.ChannelId,
+		Denom:     req.Denom,
+	}
+	if req.Status != "" {
+		for _, val := range strings.Split(req.Status, ",") {
+			stat, err := strconv.Atoi(val)
+			if err != nil {
+				return query, err
+			}
+			query.Status = append(query.Status, stat)
+		}
+	}
+	if req.Alerting != "" {
+		alerting, err := strconv.ParseBool(req.Alerting)
+		if err != nil {
+			return query, err
+		}
+		query.Alerting = &alerting
+	}
+	return query, nil
+}
+
+func createEscrowDiscrepancyQuery(req *vo.EscrowDiscrepanciesReq) (dto.EscrowDiscrepancyQuery, error) {
+	query := dto.EscrowDiscrepancyQuery{
+		ChainId:   req.ChainId,
+		ChannelId: req.ChannelId,
+		Denom:     req.Denom,
+	}
+	var err error
+	if req.DateRange != "" {
+		dateRange := strings.Split(req.DateRange, ",")
+		if len(dateRange) == 2 {
+			query.StartTime, err = strconv.ParseInt(dateRange[0], 10, 64)
+			if err != nil {
+				return query, err
+			}
+			query.EndTime, err = strconv.ParseInt(dateRange[1], 10, 64)
+			if err != nil {
+				return query, err
+			}
+		}
+	}
+	return query, nil
+}
+
+func (svc EscrowService) ReconcilesCount(req *vo.EscrowReconcilesReq) (int64, errors.Error) {
+	query, err := createEscrowReconcileQuery(req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	count, err := escrowReconcileRepo.CountList(query)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc EscrowService) Reconciles(req *vo.EscrowReconcilesReq) (vo.EscrowReconcilesResp, errors.Error) {
+	var resp vo.EscrowReconcilesResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	query, err := createEscrowReconcileQuery(req)
+	if err != nil {
+		return resp, errors.WrapBadRequest(err)
+	}
+	res, err := escrowReconcileRepo.List(query, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.EscrowReconcileDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.reconcileDto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+func (svc EscrowService) DiscrepanciesCount(req *vo.EscrowDiscrepanciesReq) (int64, errors.Error) {
+	query, err := createEscrowDiscrepancyQuery(req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	count, err := escrowDiscrepancyRepo.CountList(query)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	if count > constant.DisplayIbcRecordMax {
+		return constant.DisplayIbcRecordMax, nil
+	}
+	return count, nil
+}
+
+func (svc EscrowService) Discrepancies(req *vo.EscrowDiscrepanciesReq) (vo.EscrowDiscrepanciesResp, errors.Error) {
+	var resp vo.EscrowDiscrepanciesResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	query, err := createEscrowDiscrepancyQuery(req)
+	if err != nil {
+		return resp, errors.WrapBadRequest(err)
+	}
+	res, err := escrowDiscrepancyRepo.List(query, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.EscrowDiscrepancyDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.discrepancyDto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+type IIcaService interface {
+	AccountsCount(req *vo.IcaAccountsReq) (int64, errors.Error)
+	Accounts(req *vo.IcaAccountsReq) (vo.IcaAccountsResp, errors.Error)
+	IcaTxsCount(req *vo.IcaTxsReq) (int64, errors.Error)
+	IcaTxs(req *vo.IcaTxsReq) (vo.IcaTxsResp, errors.Error)
+}
+
+var _ IIcaService = new(IcaService)
+
+type IcaService struct {
+	accountDto vo.IcaAccountDto
+	txDto      vo.IcaTxDto
+}
+
+func (svc IcaService) AccountsCount(req *vo.IcaAccountsReq) (int64, errors.Error) {
+	count, err := icaAccountRepo.CountList(dto.IcaAccountQuery{ChainId: req.ChainId, Owner: req.Owner, IcaAddress: req.IcaAddress})
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+// Accounts the interchain accounts with the activity of them
+func (svc IcaService) Accounts(req *vo.IcaAccountsReq) (vo.IcaAccountsResp, errors.Error) {
+	var resp vo.IcaAccountsResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	accounts, err := icaAccountRepo.List(dto.IcaAccountQuery{ChainId: req.ChainId, Owner: req.Owner, IcaAddress: req.IcaAddress}, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+
+	addresses := make([]string, 0, len(accounts))
+	for _, v := range accounts {
+		addresses = append(addresses, v.IcaAddress)
+	}
+	activityMap := make(map[string]*dto.AggrIcaActivityDTO)
+	if len(addresses) > 0 {
+		activityList, err := icaTxRepo.AggrIcaActivity("", addresses)
+		if err != nil {
+			return resp, errors.Wrap(err)
+		}
+		for _, v := range activityList {
+			activityMap[v.HostChainId+v.IcaAddress] = v
+		}
+	}
+
+	items := make([]vo.IcaAccountDto, 0, len(accounts))
+	for _, v := range accounts {
+		item := svc.accountDto.LoadDto(v)
+		if activity, ok := activityMap[v.HostChainId+v.IcaAddress]; ok {
+			item.Txs = activity.Txs
+			item.SuccessTxs = activity.SuccessTxs
+			item.FailedTxs = activity.FailedTxs
+			item.TimeoutTxs = activity.TimeoutTxs
+			item.PendingTxs = activity.PendingTxs
+			item.LatestTxTime = activity.LatestTxTime
+		}
+		items = append(items, item)
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+func createIcaTxQuery(req *vo.IcaTxsReq) (dto.IbcIcaTxQuery, error) {
+	query := dto.IbcIcaTxQuery{
+		ChainId:    req.ChainId,
+		Owner:      req.Owner,
+		IcaAddress: req.IcaAddress,
+		MsgType:    req.MsgType,
+	}
+	var err error
+	if req.DateRange != "" {
+		dateRange := strings.Split(req.DateRange, ",")
+		if len(dateRange) == 2 {
+			query.StartTime, err = strconv.ParseInt(dateRange[0], 10, 64)
+			if err != nil {
+				return query, err
+			}
+			query.EndTime, err = strconv.ParseInt(dateRange[1], 10, 64)
+			if err != nil {
+				return query, err
+			}
+		}
+	}
+	if req.Status != "" {
+		for _, val := range strings.Split(req.Status, ",") {
+			stat, err := strconv.Atoi(val)
+			if err != nil {
+				return query, err
+			}
+			query.Status = append(query.Status, stat)
+		}
+	}
+	return query, nil
+}
+
+func (svc IcaService) IcaTxsCount(req *vo.IcaTxsReq) (int64, errors.Error) {
+	query, err := createIcaTxQuery(req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	count, err := icaTxRepo.CountIcaTxs(query)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	return count, nil
+}
+
+func (svc IcaService) IcaTxs(req *vo.IcaTxsReq) (vo.IcaTxsResp, errors.Error) {
+	var resp vo.IcaTxsResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	query, err := createIcaTxQuery(req)
+	if err != nil {
+		return resp, errors.WrapBadRequest(err)
+	}
+	res, err := icaTxRepo.FindIcaTxs(query, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.IcaTxDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.txDto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+)
+
+type INftService interface {
+	NftTxsCount(req *vo.NftTxsReq) (int64, errors.Error)
+	NftTxs(req *vo.NftTxsReq) (vo.NftTxsResp, errors.Error)
+	NftTxDetail(hash string) (vo.NftTxDetailResp, errors.Error)
+}
+
+var _ INftService = new(NftService)
+
+type NftService struct {
+	dto       vo.NftTxDto
+	detailDto vo.NftTxDetailDto
+}
+
+func createNftTxQuery(req *vo.NftTxsReq) (dto.IbcNftTxQuery, error) {
+	query := dto.IbcNftTxQuery{
+		ChainId:          req.ChainId,
+		ClassId:          req.ClassId,
+		BaseClassId:      req.BaseClassId,
+		BaseClassChainId: req.BaseClassChainId,
+		TokenId:          req.TokenId,
+		Address:          req.Address,
+	}
+	var err error
+	if req.DateRange != "" {
+		dateRange := strings.Split(req.DateRange, ",")
+		if len(dateRange) == 2 {
+			query.StartTime, err = strconv.ParseInt(dateRange[0], 10, 64)
+			if err != nil {
+				return query, err
+			}
+			query.EndTime, err = strconv.ParseInt(dateRange[1], 10, 64)
+			if err != nil {
+				return query, err
+			}
+		}
+	}
+	if req.Status != "" {
+		for _, val := range strings.Split(req.Status, ",") {
+			stat, err := strconv.Atoi(val)
+			if err != nil {
+				return query, err
+			}
+			query.Status = append(query.Status, stat)
+		}
+	}
+	return query, nil
+}
+
+func (svc NftService) NftTxsCount(req *vo.NftTxsReq) (int64, errors.Error) {
+	query, err := createNftTxQuery(req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	count, err := nftTxRepo.CountNftTxs(query)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	if count > constant.DisplayIbcRecordMax {
+		return constant.DisplayIbcRecordMax, nil
+	}
+	return count, nil
+}
+
+func (svc NftService) NftTxs(req *vo.NftTxsReq) (vo.NftTxsResp, errors.Error) {
+	var resp vo.NftTxsResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	query, err := createNftTxQuery(req)
+	if err != nil {
+		return resp, errors.WrapBadRequest(err)
+	}
+	res, err := nftTxRepo.FindNftTxs(query, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.NftTxDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.dto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+func (svc NftService) NftTxDetail(hash string) (vo.NftTxDetailResp, errors.Error) {
+	var resp vo.NftTxDetailResp
+	nftTxs, err := nftTxRepo.TxDetail(hash)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+
+	items := make([]vo.NftTxDetailDto, 0, len(nftTxs))
+	for _, val := range nftTxs {
+		item := svc.detailDto.LoadDto(val)
+		item.ClassTrace, err = getNftClassTrace(val)
+		if err != nil {
+			return resp, errors.Wrap(err)
+		}
+		items = append(items, item)
+	}
+	resp.Items = items
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+// getNftClassTrace trace the class from the dc chain(sc chain if not received) back to the base class
+func getNftClassTrace(nftTx *entity.ExIbcNftTx) ([]vo.NftClassInfo, error) {
+	if nftTx.ClassIds == nil {
+		return nil, nil
+	}
+	chainId, classId := nftTx.ScChainId, nftTx.ClassIds.ScClassId
+	if nftTx.Status == entity.IbcTxStatusSuccess && nftTx.ClassIds.DcClassId != "" {
+		chainId, classId = nftTx.DcChainId, nftTx.ClassIds.DcClassId
+	}
+
+	var trace []vo.NftClassInfo
+	seen := make(map[string]struct{})
+	for chainId != "" && classId != "" {
+		key := chainId + "/" + classId
+		if _, ok := seen[key]; ok {
+			break
+		}
+		seen[key] = struct{}{}
+
+		class, err := nftClassRepo.FindByClassIdChainId(classId, chainId)
+		if err != nil {
+			if err == qmgo.ErrNoSuchDocuments {
+				break
+			}
+			return nil, err
+		}
+		trace = append(trace, vo.NftClassInfo{
+			ChainId:   class.ChainId,
+			ClassId:   class.ClassId,
+			ClassPath: class.ClassPath,
+		})
+		if class.IsBaseClass {
+			break
+		}
+		chainId, classId = class.PrevChainId, class.PrevClassId
+	}
+	return trace, nil
+}
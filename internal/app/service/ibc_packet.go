@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+)
+
+type IPacketService interface {
+	PacketsCount(req *vo.PacketsReq) (int64, errors.Error)
+	Packets(req *vo.PacketsReq) (vo.PacketsResp, errors.Error)
+	PacketDetail(scChainId, scPort, scChannel, sequence string) (vo.PacketDetailResp, errors.Error)
+}
+
+var _ IPacketService = new(PacketService)
+
+type PacketService struct {
+	dto       vo.PacketDto
+	detailDto vo.PacketDetailDto
+}
+
+func createPacketQuery(req *vo.PacketsReq) (dto.IbcPacketQuery, error) {
+	query := dto.IbcPacketQuery{
+		ChainId:   req.ChainId,
+		Port:      req.Port,
+		Channel:   req.Channel,
+		AckResult: req.AckResult,
+	}
+	var err error
+	if req.DateRange != "" {
+		dateRange := strings.Split(req.DateRange, ",")
+		if len(dateRange) == 2 {
+			query.StartTime, err = strconv.ParseInt(dateRange[0], 10, 64)
+			if err != nil {
+				return query, err
+			}
+			query.EndTime, err = strconv.ParseInt(dateRange[1], 10, 64)
+			if err != nil {
+				return query, err
+			}
+		}
+	}
+	if req.Status != "" {
+		for _, val := range strings.Split(req.Status, ",") {
+			stat, err := strconv.Atoi(val)
+			if err != nil {
+				return query, err
+			}
+			query.Status = append(query.Status, stat)
+		}
+	}
+	return query, nil
+}
+
+func (svc PacketService) PacketsCount(req *vo.PacketsReq) (int64, errors.Error) {
+	query, err := createPacketQuery(req)
+	if err != nil {
+		return 0, errors.WrapBadRequest(err)
+	}
+	count, err := packetRepo.CountPackets(query)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	if count > constant.DisplayIbcRecordMax {
+		return constant.DisplayIbcRecordMax, nil
+	}
+	return count, nil
+}
+
+func (svc PacketService) Packets(req *vo.PacketsReq) (vo.PacketsResp, errors.Error) {
+	var resp vo.PacketsResp
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	query, err := createPacketQuery(req)
+	if err != nil {
+		return resp, errors.WrapBadRequest(err)
+	}
+	res, err := packetRepo.FindPackets(query, skip, limit)
+	if err != nil {
+		return resp, errors.Wrap(err)
+	}
+	items := make([]vo.PacketDto, 0, len(res))
+	for _, val := range res {
+		items = append(items, svc.dto.LoadDto(val))
+	}
+	resp.Items = items
+	resp.PageInfo = vo.BuildPageInfo(int64(len(items)), req.PageNum, req.PageSize)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
+
+func (svc PacketService) PacketDetail(scChainId, scPort, scChannel, sequence string) (vo.PacketDetailResp, errors.Error) {
+	var resp vo.PacketDetailResp
+	packet, err := packetRepo.FindPacket(scChainId, scPort, scChannel, sequence)
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return resp, errors.WrapNotFound(fmt.Errorf("packet %s/%s/%s/%s not found", scChainId, scPort, scChannel, sequence))
+		}
+		return resp, errors.Wrap(err)
+	}
+	resp.PacketDetailDto = svc.detailDto.LoadDto(packet)
+	resp.TimeStamp = time.Now().Unix()
+	return resp, nil
+}
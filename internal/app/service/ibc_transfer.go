@@ -1,19 +1,21 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
+	stderrors "errors"
 	"fmt"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	lcdclient "github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcd"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/lcdpool"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/qiniu/qmgo"
 	"github.com/sirupsen/logrus"
-	"io/ioutil"
-	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -81,6 +83,24 @@ func createIbcTxQuery(req *vo.TranaferTxsReq) (dto.IbcTxQuery, error) {
 	} else if req.Denom != "" {
 		query.Denom = req.Denom
 	}
+
+	if req.MemoType != "" {
+		for _, val := range strings.Split(req.MemoType, ",") {
+			if val != model.PacketMemoTypeForward && val != model.PacketMemoTypeWasm {
+				return query, fmt.Errorf("invalid memo_type %s, only support forward,wasm", val)
+			}
+			query.MemoType = append(query.MemoType, val)
+		}
+	}
+
+	if req.FailureCategory != "" {
+		for _, val := range strings.Split(req.FailureCategory, ",") {
+			if !utils.InArray(model.FailureCategories, val) {
+				return query, fmt.Errorf("invalid failure_category %s, only support %s", val, strings.Join(model.FailureCategories, ","))
+			}
+			query.FailureCategory = append(query.FailureCategory, val)
+		}
+	}
 	return query, nil
 }
 func (t TransferService) TransferTxsCount(req *vo.TranaferTxsReq) (int64, errors.Error) {
@@ -99,7 +119,8 @@ func (t TransferService) TransferTxsCount(req *vo.TranaferTxsReq) (int64, errors
 		return count
 	}
 	//default cond
-	if len(query.ChainId) == 0 && len(query.Status) == 4 && query.StartTime == 0 && len(query.BaseDenom) == 0 && query.Denom == "" {
+	if len(query.ChainId) == 0 && len(query.Status) == 4 && query.StartTime == 0 && len(query.BaseDenom) == 0 && query.Denom == "" &&
+		len(query.MemoType) == 0 && len(query.FailureCategory) == 0 {
 		data, err := statisticRepo.FindOne(constant.TxLatestAllStatisticName)
 		if err != nil {
 			return 0, errors.Wrap(err)
@@ -141,16 +162,10 @@ func (t TransferService) TransferTxs(req *vo.TranaferTxsReq) (vo.TranaferTxsResp
 
 func (t TransferService) TransferTxDetail(hash string) (vo.TranaferTxDetailResp, errors.Error) {
 	var resp vo.TranaferTxDetailResp
-	ibcTxs, err := ibcTxRepo.TxDetail(hash, false)
+	ibcTxs, err := ibcTxRepo.TxDetail(hash)
 	if err != nil && err != qmgo.ErrNoSuchDocuments {
 		return resp, errors.Wrap(err)
 	}
-	if len(ibcTxs) == 0 {
-		ibcTxs, err = ibcTxRepo.TxDetail(hash, true)
-		if err != nil && err != qmgo.ErrNoSuchDocuments {
-			return resp, errors.Wrap(err)
-		}
-	}
 	setMap := make(map[string]struct{}, len(ibcTxs))
 	for _, val := range ibcTxs {
 		packetId := fmt.Sprintf("%s%s%s%s%s", val.ScPort, val.ScChannel, val.DcPort, val.DcChannel, val.Sequence)
@@ -249,16 +264,10 @@ func getConnectByRecvPacketEventsNews(eventNews []entity.EventNew, msgIndex int)
 
 func (t TransferService) TransferTxDetailNew(hash string) (*vo.TranaferTxDetailNewResp, errors.Error) {
 	var resp vo.TranaferTxDetailNewResp
-	ibcTxs, err := ibcTxRepo.TxDetail(hash, false)
+	ibcTxs, err := ibcTxRepo.TxDetail(hash)
 	if err != nil && err != qmgo.ErrNoSuchDocuments {
 		return nil, errors.Wrap(err)
 	}
-	if len(ibcTxs) == 0 {
-		ibcTxs, err = ibcTxRepo.TxDetail(hash, true)
-		if err != nil && err != qmgo.ErrNoSuchDocuments {
-			return nil, errors.Wrap(err)
-		}
-	}
 	if len(ibcTxs) == 0 {
 		return nil, nil
 	}
@@ -273,6 +282,11 @@ func (t TransferService) TransferTxDetailNew(hash string) (*vo.TranaferTxDetailN
 		if err != nil {
 			return nil, errors.Wrap(err)
 		}
+		resp.PacketMemo = getPacketMemo(ibcTxs[0])
+		resp.Route, err = getCachedTransferRoute(ibcTxs[0])
+		if err != nil {
+			logrus.Errorf("get transfer route err, record_id: %s, %s", ibcTxs[0].RecordId, err.Error())
+		}
 	} else if len(ibcTxs) > 1 {
 		resp.IsList = true
 		for _, val := range ibcTxs {
@@ -284,6 +298,18 @@ func (t TransferService) TransferTxDetailNew(hash string) (*vo.TranaferTxDetailN
 	return &resp, nil
 }
 
+// getPacketMemo the packet memo of the txs synced before the memo parsing is decoded on the fly
+func getPacketMemo(ibcTx *entity.ExIbcTx) *model.PacketMemo {
+	if ibcTx.PacketMemo != nil {
+		return ibcTx.PacketMemo
+	}
+	if ibcTx.ScTxInfo == nil || ibcTx.ScTxInfo.Msg == nil {
+		return nil
+	}
+	msg := ibcTx.ScTxInfo.Msg.TransferMsg()
+	return model.ParsePacketMemo(msg.Memo, msg.Receiver)
+}
+
 func getRelayerInfo(val *entity.ExIbcTx) (*vo.RelayerInfo, error) {
 	relayerCfgMap, err := getRelayerCfgMap()
 	if err != nil {
@@ -447,29 +473,37 @@ func getMsgAndTxData(msgType, chainId, hash string) (vo.TraceSourceResp, errors.
 func GetLcdTxData(chainId, hash string) (LcdTxData, errors.Error) {
 	lcdAddrs, _ := lcdAddrCache.Get(chainId)
 	if len(lcdAddrs) > 0 {
-		//全节点且支持交易查询
-		if lcdAddrs[0].FullNode && lcdAddrs[0].TxIndexEnable {
-			return GetTxDataFromChain(lcdAddrs[0].LcdAddr, hash)
-		}
-		//获取支持交易查询的lcd节点
+		//获取支持交易查询且未熔断的lcd节点
+		pool := lcdpool.Of(chainId)
 		var validNodes []cache.TraceSourceLcd
 		for _, val := range lcdAddrs {
-			if val.TxIndexEnable {
+			if !val.TxIndexEnable {
+				continue
+			}
+			pool.Add(val.LcdAddr)
+			if pool.IsAvailable(val.LcdAddr) {
 				validNodes = append(validNodes, val)
 			}
 		}
+		if len(validNodes) == 0 {
+			return LcdTxData{}, errors.WrapLcdNodeErr("no available lcd")
+		}
+		//全节点且支持交易查询
+		if validNodes[0].FullNode {
+			return GetTxDataFromChain(chainId, validNodes[0].LcdAddr, hash)
+		}
 		//并发处理
-		return doHandleTxData(2, validNodes, hash)
+		return doHandleTxData(chainId, 2, validNodes, hash)
 	} else {
 		cfg, err := chainCfgRepo.FindOne(chainId)
 		if err != nil {
 			return LcdTxData{}, errors.Wrap(fmt.Errorf("invalid chain id"))
 		}
-		return GetTxDataFromChain(cfg.Lcd, hash)
+		return wrapLcdTxData(lcdclient.ForChain(cfg).Tx(context.Background(), hash))
 	}
 }
 
-func doHandleTxData(workNum int, lcdAddrs []cache.TraceSourceLcd, hash string) (LcdTxData, errors.Error) {
+func doHandleTxData(chainId string, workNum int, lcdAddrs []cache.TraceSourceLcd, hash string) (LcdTxData, errors.Error) {
 	resData := make([]LcdTxData, len(lcdAddrs))
 	var wg sync.WaitGroup
 	wg.Add(workNum)
@@ -482,7 +516,7 @@ func doHandleTxData(workNum int, lcdAddrs []cache.TraceSourceLcd, hash string) (
 				if id%workNum != num {
 					continue
 				}
-				resData[id], err = GetTxDataFromChain(v.LcdAddr, hash)
+				resData[id], err = GetTxDataFromChain(chainId, v.LcdAddr, hash)
 				if err == nil {
 					break
 				} else {
@@ -500,34 +534,18 @@ func doHandleTxData(workNum int, lcdAddrs []cache.TraceSourceLcd, hash string) (
 	return LcdTxData{}, errors.WrapLcdNodeErr("no found")
 }
 
-func GetTxDataFromChain(lcdUri string, hash string) (LcdTxData, errors.Error) {
-	var txData LcdTxData
-	url := fmt.Sprintf("%s/cosmos/tx/v1beta1/txs/%s", lcdUri, hash)
-	resp, err := http.Get(url)
-	if err != nil {
-		return txData, errors.Wrap(err)
-	}
+// GetTxDataFromChain query the tx from the lcd, the result is recorded into the lcd pool of the chain
+func GetTxDataFromChain(chainId, lcdUri string, hash string) (LcdTxData, errors.Error) {
+	return wrapLcdTxData(lcdclient.ForEndpoint(chainId, lcdUri).Tx(context.Background(), hash))
+}
 
-	defer resp.Body.Close()
-	bz, err := ioutil.ReadAll(resp.Body)
+func wrapLcdTxData(txData *LcdTxData, err error) (LcdTxData, errors.Error) {
 	if err != nil {
-		return txData, errors.Wrap(err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		var errResp LcdErrRespond
-		if err := json.Unmarshal(bz, &errResp); err != nil {
-			return txData, errors.Wrap(err)
+		var statusErr *lcdclient.StatusError
+		if stderrors.As(err, &statusErr) {
+			return LcdTxData{}, errors.WrapLcdNodeErr(statusErr.Message)
 		}
-		return txData, errors.WrapLcdNodeErr(errResp.Message)
-	} else {
-		if err := json.Unmarshal(bz, &txData); err != nil {
-			return LcdTxData{}, errors.Wrap(err)
-		}
-	}
-
-	if err := json.Unmarshal(bz, &txData); err != nil {
 		return LcdTxData{}, errors.Wrap(err)
 	}
-	return txData, nil
+	return *txData, nil
 }
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+)
+
+const (
+	routeMaxHops = 4
+	// the queries of each direction of a route are limited, each hop takes one or two queries over both tiers
+	routeMaxQueries = 4
+	// the voucher sent by the receiver in the interval is regarded as the next hop
+	routeHopMaxInterval = 24 * 3600
+)
+
+// routeFinder count the queries of a direction of the route, the direction is truncated once routeMaxQueries is
+// reached
+type routeFinder struct {
+	queries   int
+	truncated bool
+}
+
+func (f *routeFinder) query() bool {
+	if f.queries >= routeMaxQueries {
+		f.truncated = true
+		return false
+	}
+	f.queries++
+	return true
+}
+
+// getCachedTransferRoute the route of the ibc tx is cached by its record id
+func getCachedTransferRoute(ibcTx *entity.ExIbcTx) (*vo.TransferRoute, error) {
+	if route, err := transferRouteCache.Get(ibcTx.RecordId); err == nil {
+		return route, nil
+	}
+	route, err := getTransferRoute(ibcTx)
+	if err != nil {
+		return nil, err
+	}
+	_ = transferRouteCache.Set(ibcTx.RecordId, route)
+	return route, nil
+}
+
+// getTransferRoute stitch the hops before and after the ibc tx into an end-to-end route
+func getTransferRoute(ibcTx *entity.ExIbcTx) (*vo.TransferRoute, error) {
+	// the previous hops and the next hops have their own budgets, so that a long history doesn't hide the next hops
+	var prevFinder, nextFinder routeFinder
+	seen := map[string]struct{}{ibcTx.RecordId: {}}
+
+	// 从当前交易向前追溯, prevHops 为倒序
+	var prevHops []vo.TransferRouteHop
+	cur := ibcTx
+	for i := 0; ; i++ {
+		if i == routeMaxHops {
+			prevFinder.truncated = true
+			break
+		}
+		prev, prevLinkType, err := prevFinder.findPrevHop(cur)
+		if err != nil {
+			return nil, err
+		}
+		if prev == nil {
+			break
+		}
+		if _, ok := seen[prev.RecordId]; ok {
+			break
+		}
+		seen[prev.RecordId] = struct{}{}
+		prevHops = append(prevHops, vo.LoadTransferRouteHop(cur, prevLinkType))
+		cur = prev
+	}
+	prevHops = append(prevHops, vo.LoadTransferRouteHop(cur, ""))
+
+	route := &vo.TransferRoute{
+		Hops:         make([]vo.TransferRouteHop, 0, len(prevHops)),
+		CurrentIndex: len(prevHops) - 1,
+	}
+	for i := len(prevHops) - 1; i >= 0; i-- {
+		route.Hops = append(route.Hops, prevHops[i])
+	}
+
+	cur = ibcTx
+	for i := 0; ; i++ {
+		if i == routeMaxHops {
+			nextFinder.truncated = true
+			break
+		}
+		next, nextLinkType, err := nextFinder.findNextHop(cur)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			break
+		}
+		if _, ok := seen[next.RecordId]; ok {
+			break
+		}
+		seen[next.RecordId] = struct{}{}
+		route.Hops = append(route.Hops, vo.LoadTransferRouteHop(next, nextLinkType))
+		cur = next
+	}
+
+	// the forward of the last hop is not found yet
+	if cur.Status == entity.IbcTxStatusSuccess {
+		route.NextForward = packetForward(cur)
+	}
+	route.Complete = route.NextForward == nil && !prevFinder.truncated && !nextFinder.truncated
+	for _, v := range route.Hops {
+		if v.Status != int(entity.IbcTxStatusSuccess) {
+			route.Complete = false
+		}
+	}
+	return route, nil
+}
+
+func packetForward(ibcTx *entity.ExIbcTx) *model.PacketForward {
+	if ibcTx.ScTxInfo == nil || ibcTx.ScTxInfo.Msg == nil {
+		return nil
+	}
+	msg := ibcTx.ScTxInfo.Msg.TransferMsg()
+	return model.ParsePacketForward(msg.Memo, msg.Receiver)
+}
+
+// findNextHop the tx forwarded in the recv tx, or the tx sending the received voucher by the receiver
+func (f *routeFinder) findNextHop(ibcTx *entity.ExIbcTx) (*entity.ExIbcTx, string, error) {
+	if ibcTx.Status != entity.IbcTxStatusSuccess || ibcTx.DcTxInfo == nil || ibcTx.Denoms == nil {
+		return nil, "", nil
+	}
+	if !f.query() {
+		return nil, "", nil
+	}
+
+	forwardTxs, err := ibcTxRepo.FindByScTxHash(ibcTx.DcChainId, ibcTx.DcTxInfo.Hash)
+	if err != nil {
+		return nil, "", err
+	}
+	forward := packetForward(ibcTx)
+	for _, v := range forwardTxs {
+		if v.Denoms == nil || v.Denoms.ScDenom != ibcTx.Denoms.DcDenom {
+			continue
+		}
+		if forward == nil || forward.Channel == v.ScChannel {
+			return v, vo.RouteLinkForward, nil
+		}
+	}
+
+	if !f.query() {
+		return nil, "", nil
+	}
+	next, err := ibcTxRepo.FindNextHopTx(ibcTx.DcChainId, ibcTx.DcAddr, ibcTx.Denoms.DcDenom, ibcTx.DcTxInfo.Time,
+		ibcTx.DcTxInfo.Time+routeHopMaxInterval)
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return next, vo.RouteLinkAddress, nil
+}
+
+// findPrevHop the tx whose recv tx forwarded the ibc tx, or the tx by which the sender received the voucher
+func (f *routeFinder) findPrevHop(ibcTx *entity.ExIbcTx) (*entity.ExIbcTx, string, error) {
+	if ibcTx.ScTxInfo == nil || ibcTx.Denoms == nil {
+		return nil, "", nil
+	}
+	if !f.query() {
+		return nil, "", nil
+	}
+
+	recvTxs, err := ibcTxRepo.FindByDcTxHash(ibcTx.ScChainId, ibcTx.ScTxInfo.Hash)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, v := range recvTxs {
+		if v.Denoms != nil && v.Denoms.DcDenom == ibcTx.Denoms.ScDenom {
+			return v, vo.RouteLinkForward, nil
+		}
+	}
+
+	if !f.query() {
+		return nil, "", nil
+	}
+	prev, err := ibcTxRepo.FindPrevHopTx(ibcTx.ScChainId, ibcTx.ScAddr, ibcTx.Denoms.ScDenom, ibcTx.TxTime-routeHopMaxInterval,
+		ibcTx.TxTime)
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return prev, vo.RouteLinkAddress, nil
+}
@@ -20,10 +20,24 @@ func TestTransferService_TraceSource(t *testing.T) {
 }
 
 func TestGetTxDataFromChain(t *testing.T) {
-	data, err := GetTxDataFromChain("https://mainnet.crescent.network:1317",
+	data, err := GetTxDataFromChain("crescent_1", "https://mainnet.crescent.network:1317",
 		"0E000429F0CCB543D0FE0CDA57DF3A470E8DE54498FF071E755736CDBECE1C72")
 	if err != nil {
 		t.Fatal(err.Error())
 	}
 	t.Log(string(utils.MarshalJsonIgnoreErr(data)))
 }
+
+func TestGetTransferRoute(t *testing.T) {
+	ibcTxs, err := ibcTxRepo.TxDetail("87DD9D44F64EC8E509508B99AD48554F9FCD3A79D775A400FE900CCA030290BE")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, v := range ibcTxs {
+		route, err := getTransferRoute(v)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		t.Log(string(utils.MarshalJsonIgnoreErr(route)))
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+)
+
+type IArchiveService interface {
+	Partitions(chainId string) ([]vo.ArchivePartitionResp, errors.Error)
+	CheckRestore(chainId, month string) errors.Error
+	Lookup(hash string) ([]vo.ArchivedTxResp, errors.Error)
+}
+
+var _ IArchiveService = new(ArchiveService)
+
+type ArchiveService struct {
+}
+
+func (svc *ArchiveService) Partitions(chainId string) ([]vo.ArchivePartitionResp, errors.Error) {
+	files, err := archiveRepo.FindByChain(chainId)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return vo.LoadArchivePartitions(files), nil
+}
+
+// CheckRestore the partition can be restored if it's archived and not being restored, the restore is run by
+// IbcTxArchiveTask
+func (svc *ArchiveService) CheckRestore(chainId, month string) errors.Error {
+	if _, _, err := entity.ArchiveMonthRange(month); err != nil {
+		return errors.WrapBadRequest(fmt.Errorf("invalid month %s, the format is yyyy-mm", month))
+	}
+	files, err := archiveRepo.FindByPartition(chainId, month)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if len(files) == 0 {
+		return errors.WrapBadRequest(fmt.Errorf("partition %s %s is not archived", chainId, month))
+	}
+	for _, v := range files {
+		if v.Status == entity.ArchiveStatusRestoring {
+			return errors.WrapBadRequest(fmt.Errorf("partition %s %s is being restored", chainId, month))
+		}
+		if v.Status == entity.ArchiveStatusPending {
+			return errors.WrapBadRequest(fmt.Errorf("partition %s %s is being archived", chainId, month))
+		}
+	}
+	return nil
+}
+
+// Lookup the archived ibc txs of the sc, dc or refunded tx hash, the txs are read from the gzip members of the archived
+// files which contain them
+func (svc *ArchiveService) Lookup(hash string) ([]vo.ArchivedTxResp, errors.Error) {
+	hashes, err := archiveHashRepo.FindByHash(hash)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	res := make([]vo.ArchivedTxResp, 0, len(hashes))
+	for _, v := range hashes {
+		file, err := archiveRepo.FindByKey(v.Key)
+		if err != nil {
+			if err == qmgo.ErrNoSuchDocuments {
+				continue
+			}
+			return nil, errors.Wrap(err)
+		}
+
+		item := vo.ArchivedTxResp{RecordId: v.RecordId, ChainId: v.ChainId, Month: v.Month, Key: v.Key, Status: string(file.Status)}
+		tx, err := archiveFileRepo.FindTx(v.Key, v.Offset, v.RecordId)
+		if err != nil {
+			// the file of a pending archive may be not written yet, its txs are still in mongo
+			if file.Status == entity.ArchiveStatusPending {
+				continue
+			}
+			return nil, errors.Wrap(err)
+		}
+		if tx != nil {
+			detail := vo.LoadTranaferTxDetail(tx)
+			item.Tx = &detail
+		}
+		res = append(res, item)
+	}
+	return res, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/qiniu/qmgo"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const reindexJobsLimit = 20
+
+type IReindexService interface {
+	Create(chainId string, req *vo.ReindexReq) (*vo.ReindexJobResp, errors.Error)
+	Resume(jobId string) (*vo.ReindexJobResp, errors.Error)
+	Progress(jobId string) (*vo.ReindexJobResp, errors.Error)
+	Jobs(chainId string) ([]vo.ReindexJobResp, errors.Error)
+}
+
+var _ IReindexService = new(ReindexService)
+
+type ReindexService struct {
+	dto vo.ReindexJobResp
+}
+
+// Create validate the range and save the job of the chain, the job is run by IbcReindexTask
+func (svc *ReindexService) Create(chainId string, req *vo.ReindexReq) (*vo.ReindexJobResp, errors.Error) {
+	job, err := entity.NewReindexJob(chainId, req.StartHeight, req.EndHeight, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, errors.WrapBadRequest(err)
+	}
+	if _, err = chainCfgRepo.FindOne(chainId); err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return nil, errors.WrapBadRequest(fmt.Errorf("chain %s doesn't exist", chainId))
+		}
+		return nil, errors.Wrap(err)
+	}
+	// 同一条链同时只能有一个重建任务，进程退出遗留的任务超时后置为失败
+	if err = reindexJobRepo.CreateIndexes(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	running, err := reindexJobRepo.FindRunning(chainId)
+	if err == nil {
+		if !running.IsStale(time.Now().Unix()) {
+			return nil, errors.WrapBadRequest(fmt.Errorf("chain %s is re-indexing by job %s", chainId, running.JobId))
+		}
+		running.Interrupt()
+		if err = reindexJobRepo.Save(running); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	} else if err != qmgo.ErrNoSuchDocuments {
+		return nil, errors.Wrap(err)
+	}
+
+	if err = reindexJobRepo.Create(job); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.WrapBadRequest(fmt.Errorf("chain %s is re-indexing", chainId))
+		}
+		return nil, errors.Wrap(err)
+	}
+	resp := svc.dto.LoadDto(job)
+	return &resp, nil
+}
+
+// Resume check the failed or stale job can be run again, the job is run by IbcReindexTask from its failed step
+func (svc *ReindexService) Resume(jobId string) (*vo.ReindexJobResp, errors.Error) {
+	job, err := reindexJobRepo.FindOne(jobId)
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return nil, errors.WrapBadRequest(fmt.Errorf("job %s doesn't exist", jobId))
+		}
+		return nil, errors.Wrap(err)
+	}
+	switch {
+	case job.Status == entity.ReindexJobStatusSuccess:
+		return nil, errors.WrapBadRequest(fmt.Errorf("job %s has succeeded", jobId))
+	case job.Active && !job.IsStale(time.Now().Unix()):
+		return nil, errors.WrapBadRequest(fmt.Errorf("job %s is running", jobId))
+	}
+	resp := svc.dto.LoadDto(job)
+	return &resp, nil
+}
+
+func (svc *ReindexService) Progress(jobId string) (*vo.ReindexJobResp, errors.Error) {
+	job, err := reindexJobRepo.FindOne(jobId)
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return nil, errors.WrapBadRequest(fmt.Errorf("job %s doesn't exist", jobId))
+		}
+		return nil, errors.Wrap(err)
+	}
+	resp := svc.dto.LoadDto(job)
+	return &resp, nil
+}
+
+// Jobs the latest jobs of the chain
+func (svc *ReindexService) Jobs(chainId string) ([]vo.ReindexJobResp, errors.Error) {
+	jobs, err := reindexJobRepo.FindByChain(chainId, reindexJobsLimit)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	resp := make([]vo.ReindexJobResp, 0, len(jobs))
+	for _, v := range jobs {
+		resp = append(resp, svc.dto.LoadDto(v))
+	}
+	return resp, nil
+}
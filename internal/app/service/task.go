@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/errors"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+)
+
+type ITaskService interface {
+	Progress(taskName string) (*vo.TaskProgressResp, errors.Error)
+	ResetCheckpoint(taskName string) (int64, errors.Error)
+	DryRunReport(taskName string, req *vo.TaskDryRunReportReq) (*vo.TaskDryRunReportResp, errors.Error)
+}
+
+var _ ITaskService = new(TaskService)
+
+type TaskService struct {
+	dto       vo.TaskCheckpointDto
+	reportDto vo.TaskDryRunReportDto
+}
+
+func (svc *TaskService) Progress(taskName string) (*vo.TaskProgressResp, errors.Error) {
+	checkpoints, err := taskCheckpointRepo.FindByTaskName(taskName)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if len(checkpoints) == 0 {
+		return nil, errors.WrapBadRequest(fmt.Errorf("task %s has no checkpoint", taskName))
+	}
+
+	resp := &vo.TaskProgressResp{
+		TaskName: taskName,
+		Status:   string(entity.TaskCheckpointStatusFinished),
+		Workers:  make([]vo.TaskCheckpointDto, 0, len(checkpoints)),
+	}
+	for _, v := range checkpoints {
+		resp.Total += v.Total
+		resp.Done += v.Done
+		if v.Status != entity.TaskCheckpointStatusFinished {
+			resp.Status = string(entity.TaskCheckpointStatusRunning)
+		}
+		resp.Workers = append(resp.Workers, svc.dto.LoadDto(v))
+	}
+	resp.Percent = vo.CalculatePercent(resp.Done, resp.Total)
+	return resp, nil
+}
+
+// ResetCheckpoint delete the checkpoints of the one-off task, and its running lock and done marker, so that it can run
+// from scratch again
+func (svc *TaskService) ResetCheckpoint(taskName string) (int64, errors.Error) {
+	num, err := taskCheckpointRepo.DeleteByTaskName(taskName)
+	if err != nil {
+		return 0, errors.Wrap(err)
+	}
+	if err = oneOffTaskCache.Reset(taskName); err != nil {
+		return num, errors.Wrap(err)
+	}
+	return num, nil
+}
+
+func (svc *TaskService) DryRunReport(taskName string, req *vo.TaskDryRunReportReq) (*vo.TaskDryRunReportResp, errors.Error) {
+	fields, err := taskDryRunReportRepo.AggrByField(taskName)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	total, err := taskDryRunReportRepo.CountByTaskName(taskName)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	skip, limit := vo.ParseParamPage(req.PageNum, req.PageSize)
+	reports, err := taskDryRunReportRepo.FindByTaskName(taskName, skip, limit)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	resp := &vo.TaskDryRunReportResp{
+		TaskName:     taskName,
+		TotalRecords: total,
+		Fields:       make([]vo.TaskDryRunFieldDto, 0, len(fields)),
+		Items:        make([]vo.TaskDryRunReportDto, 0, len(reports)),
+		PageInfo:     vo.BuildPageInfo(total, req.PageNum, req.PageSize),
+	}
+	for _, v := range fields {
+		resp.Fields = append(resp.Fields, vo.TaskDryRunFieldDto{
+			Coll:    v.Coll,
+			Field:   v.Field,
+			Records: v.Records,
+		})
+	}
+	for _, v := range reports {
+		resp.Items = append(resp.Items, svc.reportDto.LoadDto(v))
+	}
+	return resp, nil
+}
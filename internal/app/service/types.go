@@ -1,70 +1,55 @@
 package service
 
 import (
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
-	"time"
 )
 
 var (
-	tokenRepo           repository.ITokenRepo          = new(repository.TokenRepo)
-	tokenStatisticsRepo repository.ITokenTraceRepo     = new(repository.TokenTraceRepo)
-	channelRepo         repository.IChannelRepo        = new(repository.ChannelRepo)
-	denomRepo           repository.IDenomRepo          = new(repository.DenomRepo)
-	chainRepo           repository.IChainRepo          = new(repository.IbcChainRepo)
-	relayerRepo         repository.IRelayerRepo        = new(repository.IbcRelayerRepo)
-	statisticRepo       repository.IStatisticRepo      = new(repository.IbcStatisticRepo)
-	chainCfgRepo        repository.IChainConfigRepo    = new(repository.ChainConfigRepo)
-	ibcTxRepo           repository.IExIbcTxRepo        = new(repository.ExIbcTxRepo)
-	txRepo              repository.ITxRepo             = new(repository.TxRepo)
-	exSearchRecordRepo  repository.IExSearchRecordRepo = new(repository.ExSearchRecordRepo)
-	lcdTxDataCache      cache.LcdTxDataCacheRepo
-	lcdAddrCache        cache.LcdAddrCacheRepo
-	relayerCfgRepo      repository.IRelayerConfigRepo = new(cache.RelayerConfigCacheRepo)
-	baseDenomRepo       cache.BaseDenomCacheRepo
+	tokenRepo               repository.ITokenRepo               = new(repository.TokenRepo)
+	tokenStatisticsRepo     repository.ITokenTraceRepo          = new(repository.TokenTraceRepo)
+	channelRepo             repository.IChannelRepo             = new(repository.ChannelRepo)
+	denomRepo               repository.IDenomRepo               = new(repository.DenomRepo)
+	chainRepo               repository.IChainRepo               = new(repository.IbcChainRepo)
+	relayerRepo             repository.IRelayerRepo             = new(repository.IbcRelayerRepo)
+	statisticRepo           repository.IStatisticRepo           = new(repository.IbcStatisticRepo)
+	chainCfgRepo            repository.IChainConfigRepo         = new(repository.ChainConfigRepo)
+	ibcTxRepo               repository.IExIbcTxRepo             = new(repository.ExIbcTxRepo)
+	txRepo                  repository.ITxRepo                  = new(repository.TxRepo)
+	exSearchRecordRepo      repository.IExSearchRecordRepo      = new(repository.ExSearchRecordRepo)
+	taskCheckpointRepo      repository.ITaskCheckpointRepo      = new(repository.TaskCheckpointRepo)
+	taskDryRunReportRepo    repository.ITaskDryRunReportRepo    = new(repository.TaskDryRunReportRepo)
+	channelStatisticsRepo   repository.IChannelStatisticsRepo   = new(repository.ChannelStatisticsRepo)
+	relayerStatisticsRepo   repository.IRelayerStatisticsRepo   = new(repository.RelayerStatisticsRepo)
+	chainFlowStatisticsRepo repository.IChainFlowStatisticsRepo = new(repository.ChainFlowStatisticsRepo)
+	nftTxRepo               repository.IExIbcNftTxRepo          = new(repository.ExIbcNftTxRepo)
+	nftClassRepo            repository.INftClassRepo            = new(repository.NftClassRepo)
+	icaTxRepo               repository.IExIbcIcaTxRepo          = new(repository.ExIbcIcaTxRepo)
+	icaAccountRepo          repository.IIcaAccountRepo          = new(repository.IcaAccountRepo)
+	packetRepo              repository.IExIbcPacketRepo         = new(repository.ExIbcPacketRepo)
+	escrowReconcileRepo     repository.IEscrowReconcileRepo     = new(repository.EscrowReconcileRepo)
+	escrowDiscrepancyRepo   repository.IEscrowDiscrepancyRepo   = new(repository.EscrowDiscrepancyRepo)
+	largeTransferAlertRepo  repository.ILargeTransferAlertRepo  = new(repository.LargeTransferAlertRepo)
+	chainRegistryRepo       repository.IChainRegistryRepo       = new(repository.ChainRegistryRepo)
+	chainOnboardingRepo     repository.IChainOnboardingRepo     = new(repository.ChainOnboardingRepo)
+	chainRegistryDiffRepo   repository.IChainRegistryDiffRepo   = new(repository.ChainRegistryDiffRepo)
+	reindexJobRepo          repository.IReindexJobRepo          = new(repository.ReindexJobRepo)
+	archiveRepo             repository.IIbcTxArchiveRepo        = new(repository.IbcTxArchiveRepo)
+	archiveHashRepo         repository.IIbcTxArchiveHashRepo    = new(repository.IbcTxArchiveHashRepo)
+	archiveFileRepo         repository.IIbcTxArchiveFileRepo    = new(repository.IbcTxArchiveFileRepo)
+	lcdTxDataCache          cache.LcdTxDataCacheRepo
+	transferRouteCache      cache.TransferRouteCacheRepo
+	lcdAddrCache            cache.LcdAddrCacheRepo
+	oneOffTaskCache         cache.OneOffTaskCacheRepo
+	relayerCfgRepo          repository.IRelayerConfigRepo = new(cache.RelayerConfigCacheRepo)
+	baseDenomRepo           cache.BaseDenomCacheRepo
+	tokenPriceRepo          cache.TokenPriceCacheRepo
 )
 
 type (
-	LcdTxData struct {
-		TxResponse struct {
-			Logs []LogData `json:"logs"`
-			Tx   struct {
-				Body struct {
-					Messages []LcdMessage `json:"messages"`
-				} `json:"body"`
-			} `json:"tx"`
-			Timestamp time.Time `json:"timestamp"`
-		} `json:"tx_response"`
-	}
-	LogData struct {
-		MsgIndex int            `json:"msg_index"`
-		Log      string         `json:"log"`
-		Events   []entity.Event `json:"events"`
-	}
-	LcdMessage struct {
-		Type            string      `json:"@type"`
-		Packet          interface{} `json:"packet,omitempty"`
-		ProofCommitment string      `json:"proof_commitment,omitempty"`
-		ProofHeight     interface{} `json:"proof_height,omitempty"`
-		Signer          string      `json:"signer,omitempty"`
-
-		SourcePort       string      `json:"source_port,omitempty"`
-		SourceChannel    string      `json:"source_channel,omitempty"`
-		Token            interface{} `json:"token,omitempty"`
-		Sender           string      `json:"sender,omitempty"`
-		Receiver         string      `json:"receiver,omitempty"`
-		TimeoutHeight    interface{} `json:"timeout_height,omitempty"`
-		TimeoutTimestamp string      `json:"timeout_timestamp,omitempty"`
-
-		ProofUnreceived  string `json:"proof_unreceived,omitempty"`
-		NextSequenceRecv string `json:"next_sequence_recv,omitempty"`
-		Acknowledgement  string `json:"acknowledgement,omitempty"`
-		ProofAcked       string `json:"proof_acked,omitempty"`
-	}
-	LcdErrRespond struct {
-		Code    int           `json:"code"`
-		Message string        `json:"message"`
-		Details []interface{} `json:"details"`
-	}
+	LcdTxData     = vo.LcdTxData
+	LogData       = vo.LcdTxLog
+	LcdMessage    = vo.LcdMessage
+	LcdErrRespond = vo.LcdErrRespond
 )
@@ -21,11 +21,11 @@ func (t *AddChainTask) Name() string {
 }
 
 func (t *AddChainTask) Switch() bool {
-	return global.Config.Task.SwitchAddChainTask
+	return global.Config().Task.SwitchAddChainTask
 }
 
 func (t *AddChainTask) Run() int {
-	chainsStr := global.Config.ChainConfig.NewChains
+	chainsStr := global.Config().ChainConfig.NewChains
 	newChainIds := strings.Split(chainsStr, ",")
 	if len(newChainIds) == 0 {
 		logrus.Errorf("task %s don't have new chains", t.Name())
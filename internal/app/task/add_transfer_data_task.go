@@ -21,7 +21,7 @@ func (t *AddTransferDataTask) Switch() bool {
 
 func (t *AddTransferDataTask) Run() int {
 	return 1
-	//return t.handle(global.Config.ChainConfig.AddTransferChains)
+	//return t.handle(global.Config().ChainConfig.AddTransferChains)
 }
 
 func (t *AddTransferDataTask) RunWithParam(chainsStr string) int {
@@ -0,0 +1,172 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
+	"github.com/sirupsen/logrus"
+)
+
+// ChainOnboardingTask run the remaining steps of onboarding the chain after the chain config is saved: sync the ibc
+// info of the chain, update the ibc txs and denoms related to the chain, then sync the transfer txs of the chain.
+// The progress of each step is recorded in ibc_chain_onboarding. The tasks of the steps are run with their locks, so
+// they don't run together with the cron or one-off runs of the same tasks.
+type ChainOnboardingTask struct {
+}
+
+// onboardingLockWaitTime the time waiting for the running task of a step to release its lock
+const onboardingLockWaitTime = 10 * time.Minute
+
+func (t *ChainOnboardingTask) Name() string {
+	return "chain_onboarding_task"
+}
+
+func (t *ChainOnboardingTask) RunWithParam(chainId string) int {
+	onboarding, err := chainOnboardingRepo.FindOne(chainId)
+	if err != nil {
+		logrus.Errorf("task %s find chain %s onboarding error, %v", t.Name(), chainId, err)
+		return -1
+	}
+	if err = chainTaskCache.Lock(chainId, t.Name(), OneOffRunLockTime*time.Second); err != nil {
+		logrus.Errorf("task %s chain %s is onboarding, err:%v", t.Name(), chainId, err.Error())
+		return -1
+	}
+	stop := make(chan struct{})
+	go t.refresh(chainId, stop)
+	defer func() {
+		close(stop)
+		_ = chainTaskCache.Unlock(chainId, t.Name())
+	}()
+
+	steps := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{name: entity.ChainOnboardingStepSyncIbcInfo, run: func() (string, error) { return t.syncIbcInfo(chainId) }},
+		{name: entity.ChainOnboardingStepAddChain, run: func() (string, error) {
+			task := new(AddChainTask)
+			return "", runOneOffTaskLocked(task.Name(), func() int { return task.RunWithParam(chainId) })
+		}},
+		{name: entity.ChainOnboardingStepAddTransferData, run: func() (string, error) {
+			task := new(AddTransferDataTask)
+			return "", runOneOffTaskLocked(task.Name(), func() int { return task.RunWithParam(chainId) })
+		}},
+	}
+
+	for _, step := range steps {
+		logrus.Infof("task %s chain %s step %s start", t.Name(), chainId, step.name)
+		onboarding.StartStep(step.name)
+		t.save(onboarding)
+
+		message, err := step.run()
+		onboarding.FinishStep(step.name, err, message)
+		if err != nil {
+			logrus.Errorf("task %s chain %s step %s error, %v", t.Name(), chainId, step.name, err)
+			t.save(onboarding)
+			return -1
+		}
+		t.save(onboarding)
+	}
+
+	onboarding.Status = entity.ChainOnboardingStatusSuccess
+	t.save(onboarding)
+	return 1
+}
+
+func (t *ChainOnboardingTask) syncIbcInfo(chainId string) (string, error) {
+	if err := runCronTaskLocked(new(IbcChainConfigTask)); err != nil {
+		return "", err
+	}
+
+	chainConfig, err := chainConfigRepo.FindOne(chainId)
+	if err != nil {
+		return "", err
+	}
+	var channels int
+	for _, v := range chainConfig.IbcInfo {
+		channels += len(v.Paths)
+	}
+	if channels == 0 { // 新链可能还没有建立channel, 之后由ibc_chain_config_task同步
+		return "no ibc channel found", nil
+	}
+	return fmt.Sprintf("%d counterparty chains, %d channels", len(chainConfig.IbcInfo), channels), nil
+}
+
+// refresh keep the lock of the chain and the update_at of the onboarding until stop is closed
+func (t *ChainOnboardingTask) refresh(chainId string, stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !chainTaskCache.Refresh(chainId, t.Name(), OneOffRunLockTime*time.Second) {
+				logrus.Warnf("task %s chain %s refresh lock failed", t.Name(), chainId)
+			}
+			if err := chainOnboardingRepo.Touch(chainId); err != nil {
+				logrus.Warnf("task %s touch chain %s onboarding error, %v", t.Name(), chainId, err)
+			}
+		}
+	}
+}
+
+// runCronTaskLocked run the cron task with its redis lock as RunOnce does, after the running one releases the lock
+func runCronTaskLocked(task Task) error {
+	lockKey := fmt.Sprintf("%s:%s", "task", task.Name())
+	err := waitLock(func() error {
+		return cache.GetRedisClient().Lock(lockKey, time.Now().Unix(), redisLockExpiration())
+	})
+	if err != nil {
+		return fmt.Errorf("%s lock error, %v", task.Name(), err)
+	}
+	defer cache.GetRedisClient().Del(lockKey)
+
+	if res := task.Run(); res != 1 {
+		return fmt.Errorf("%s exec status: %d", task.Name(), res)
+	}
+	return nil
+}
+
+// runOneOffTaskLocked run the one-off task with its running lock as OneOffTaskRun does, after the running one releases
+// the lock. The done marker is not checked, the task is run for the chain.
+func runOneOffTaskLocked(taskName string, run func() int) error {
+	expiration := OneOffRunLockTime * time.Second
+	if err := waitLock(func() error { return oneOffTaskCache.LockRunning(taskName, expiration) }); err != nil {
+		return fmt.Errorf("%s lock error, %v", taskName, err)
+	}
+	stop := make(chan struct{})
+	go refreshOneOffTaskLock(taskName, expiration, stop)
+	defer func() {
+		close(stop)
+		_ = oneOffTaskCache.UnlockRunning(taskName)
+	}()
+
+	if res := run(); res != 1 {
+		return fmt.Errorf("%s exec status: %d", taskName, res)
+	}
+	return nil
+}
+
+// waitLock retry to take the lock until onboardingLockWaitTime
+func waitLock(lock func() error) error {
+	deadline := time.Now().Add(onboardingLockWaitTime)
+	for {
+		err := lock()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func (t *ChainOnboardingTask) save(onboarding *entity.IBCChainOnboarding) {
+	if err := chainOnboardingRepo.Save(onboarding); err != nil {
+		logrus.Errorf("task %s save chain %s onboarding error, %v", t.Name(), onboarding.ChainId, err)
+	}
+}
@@ -0,0 +1,7 @@
+package task
+
+import "testing"
+
+func Test_ChainOnboardingTask(t *testing.T) {
+	new(ChainOnboardingTask).RunWithParam("bigbang")
+}
@@ -0,0 +1,77 @@
+package task
+
+import (
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/qiniu/qmgo"
+	"github.com/sirupsen/logrus"
+)
+
+// checkpoint persists the progress of one one-off task worker, so that the task can resume from the last
+// processed segment(or height) instead of starting from scratch after the process restarts.
+type checkpoint struct {
+	record *entity.IbcTaskCheckpoint
+	dryRun bool // the checkpoint of dry-run is neither resumed nor persisted
+}
+
+// loadCheckpoint load the worker checkpoint. If the last run had finished, or the worker num or the beginning of
+// the workload is changed(the workload distribution is different), the task will start from scratch.
+func loadCheckpoint(taskName, scope, worker string, workerNum int, begin, total int64, dryRun bool) *checkpoint {
+	if dryRun {
+		return &checkpoint{
+			record: &entity.IbcTaskCheckpoint{TaskName: taskName, Scope: scope, Worker: worker, Total: total},
+			dryRun: true,
+		}
+	}
+
+	record, err := taskCheckpointRepo.FindOne(taskName, scope, worker)
+	if err != nil {
+		if err != qmgo.ErrNoSuchDocuments {
+			logrus.Errorf("task %s load checkpoint(%s, %s) error, %v", taskName, scope, worker, err)
+		}
+		record = nil
+	}
+
+	if record == nil || record.Status == entity.TaskCheckpointStatusFinished || record.WorkerNum != workerNum ||
+		record.Begin != begin {
+		record = &entity.IbcTaskCheckpoint{
+			TaskName:  taskName,
+			Scope:     scope,
+			Worker:    worker,
+			WorkerNum: workerNum,
+			Begin:     begin,
+		}
+	} else {
+		logrus.Infof("task %s worker %s resume from checkpoint, scope: %s, cursor: %d, done: %d", taskName, worker, scope, record.Cursor, record.Done)
+	}
+
+	record.Total = total
+	record.Status = entity.TaskCheckpointStatusRunning
+	return &checkpoint{record: record}
+}
+
+// resumed whether the workload at cursor has been done in the last run
+func (cp *checkpoint) resumed(cursor int64) bool {
+	return !cp.dryRun && cp.record.Done > 0 && cursor <= cp.record.Cursor
+}
+
+// advance mark the workload at cursor as done
+func (cp *checkpoint) advance(cursor int64) {
+	cp.record.Cursor = cursor
+	cp.record.Done++
+	cp.save()
+}
+
+func (cp *checkpoint) finish() {
+	cp.record.Done = cp.record.Total
+	cp.record.Status = entity.TaskCheckpointStatusFinished
+	cp.save()
+}
+
+func (cp *checkpoint) save() {
+	if cp.dryRun {
+		return
+	}
+	if err := taskCheckpointRepo.Save(cp.record); err != nil {
+		logrus.Errorf("task %s save checkpoint(%s, %s) error, %v", cp.record.TaskName, cp.record.Scope, cp.record.Worker, err)
+	}
+}
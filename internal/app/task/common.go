@@ -1,6 +1,8 @@
 package task
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -8,12 +10,17 @@ import (
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/datasource"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils/bech32"
+	"github.com/qiniu/qmgo"
 	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type segment struct {
@@ -69,6 +76,80 @@ func getSegment(step int64) ([]*segment, error) {
 	return segments, nil
 }
 
+// getTiersSegment the segments from the day of the first ibc tx of both tiers to today
+func getTiersSegment(step int64) ([]*segment, error) {
+	first, _, err := ibcTxRepo.CreateAtRange()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Unix(first, 0)
+	startUnix := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local).Unix()
+	end := time.Now()
+	endUnix := time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 59, time.Local).Unix()
+
+	var segments []*segment
+	for temp := startUnix; temp < endUnix; temp += step {
+		segments = append(segments, &segment{
+			StartTime: temp,
+			EndTime:   temp + step - 1,
+		})
+	}
+	return segments, nil
+}
+
+// archivedSegmentEnd the end of the last day which has archived ibc txs, 0 if nothing is archived. The statistics of the
+// days until it can't be recalculated from mongo, they are kept as they are.
+func archivedSegmentEnd() (int64, error) {
+	maxCreateAt, err := archiveRepo.MaxCreateAt()
+	if err != nil || maxCreateAt == 0 {
+		return 0, err
+	}
+	day := time.Unix(maxCreateAt, 0)
+	return time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 59, time.Local).Unix(), nil
+}
+
+// getRebuildSegments the segments of a full rebuild of the statistics from both tiers. The days which have archived
+// ibc txs are not rebuilt, keep copies their rows from the current collection into the new one.
+func getRebuildSegments(step int64, keep func(endTime int64) error) ([]*segment, error) {
+	archivedEnd, err := archivedSegmentEnd()
+	if err != nil {
+		return nil, err
+	}
+	if archivedEnd > 0 {
+		if err = keep(archivedEnd); err != nil {
+			return nil, err
+		}
+	}
+
+	segments, err := getTiersSegment(step)
+	if err == qmgo.ErrNoSuchDocuments && archivedEnd > 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range segments {
+		if v.StartTime > archivedEnd {
+			return segments[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+// recalculateSegments the days from startTime to today to recalculate the statistics, the days which have archived ibc
+// txs are skipped
+func recalculateSegments(startTime int64) ([]*segment, error) {
+	archivedEnd, err := archivedSegmentEnd()
+	if err != nil {
+		return nil, err
+	}
+	if startTime <= archivedEnd {
+		startTime = archivedEnd + 1
+	}
+	return daySegments(startTime), nil
+}
+
 // todayUnix 获取今日第一秒和最后一秒的时间戳
 func todayUnix() (int64, int64) {
 	now := time.Now()
@@ -241,41 +322,37 @@ func traceDenom(fullDenomPath, chainId string, allChainMap map[string]*entity.Ch
 // calculateNextDenomPath calculate full denom path of next hop.
 // return full denom path and cross back identification
 func calculateNextDenomPath(packet model.Packet) (string, bool) {
-	prefixSc := fmt.Sprintf("%s/%s/", packet.SourcePort, packet.SourceChannel)
-	prefixDc := fmt.Sprintf("%s/%s/", packet.DestinationPort, packet.DestinationChannel)
-	denomPath := packet.Data.Denom
-	if strings.HasPrefix(denomPath, prefixSc) { // transfer to prev chain
-		denomPath = strings.Replace(denomPath, prefixSc, "", 1)
-		return denomPath, true
-	} else {
-		denomPath = fmt.Sprintf("%s%s", prefixDc, denomPath)
-		return denomPath, false
+	return calculateNextPath(packet.SourcePort, packet.SourceChannel, packet.DestinationPort, packet.DestinationChannel, packet.Data.Denom)
+}
+
+// calculateNextPath calculate full path of denom or nft class of next hop.
+// return full path and cross back identification
+func calculateNextPath(scPort, scChannel, dcPort, dcChannel, fullPath string) (string, bool) {
+	prefixSc := fmt.Sprintf("%s/%s/", scPort, scChannel)
+	prefixDc := fmt.Sprintf("%s/%s/", dcPort, dcChannel)
+	if strings.HasPrefix(fullPath, prefixSc) { // transfer to prev chain
+		return strings.Replace(fullPath, prefixSc, "", 1), true
 	}
+	return fmt.Sprintf("%s%s", prefixDc, fullPath), false
 }
 
-// queryClientState 查询lcd client_state_path接口
-func queryClientState(lcd, apiPath, port, channel string) (*vo.ClientStateResp, error) {
-	apiPath = strings.ReplaceAll(apiPath, replaceHolderChannel, channel)
+// queryClientState 查询lcd client_state接口
+func queryClientState(chainConf *entity.ChainConfig, port, channel string) (*vo.ClientStateResp, error) {
+	apiPath := strings.ReplaceAll(chainConf.LcdApiPath.ClientStatePath, replaceHolderChannel, channel)
 	apiPath = strings.ReplaceAll(apiPath, replaceHolderPort, port)
-	url := fmt.Sprintf("%s%s", lcd, apiPath)
+	key := utils.Md5(fmt.Sprintf("%s%s", chainConf.Lcd, apiPath))
 
-	if state, err := lcdTxDataCacheRepo.GetClientState(utils.Md5(url)); err == nil {
+	if state, err := lcdTxDataCacheRepo.GetClientState(key); err == nil {
 		return state, nil
 	}
 
-	bz, err := utils.HttpGet(url)
-	if err != nil {
-		return nil, err
-	}
-
-	var resp vo.ClientStateResp
-	err = json.Unmarshal(bz, &resp)
+	resp, err := datasource.Of(chainConf).ClientState(context.Background(), port, channel)
 	if err != nil {
 		return nil, err
 	}
 
-	_ = lcdTxDataCacheRepo.SetClientState(utils.Md5(url), &resp)
-	return &resp, nil
+	_ = lcdTxDataCacheRepo.SetClientState(key, resp)
+	return resp, nil
 }
 
 // parseTransferTxEvents parse ibc info from events of transfer tx
@@ -350,16 +427,22 @@ func parseAckPacketTxEvents(msgIndex int, tx *entity.Tx) (existTransferEvent boo
 	return
 }
 
-//并发处理全量数据
-func doHandleSegments(taskName string, workNum int, segments []*segment, isTargetHistory bool, dowork WorkerExecHandler) {
+//并发处理全量数据. The checkpoint of a worker only advances after its segment succeeds, the worker stops at the
+// failed segment, so that the next run resumes from it. The first error of the workers is returned.
+func doHandleSegments(taskName string, workNum int, segments []*segment, isTargetHistory, dryRun bool, dowork WorkerExecHandler) error {
 	if workNum <= 0 {
-		return
+		return nil
 	}
 	st := time.Now().Unix()
 	logrus.Infof("task %s worker group start, target hirtoty: %t", taskName, isTargetHistory)
 	defer func() {
 		logrus.Infof("task %s worker group end, target hirtoty: %t, time use: %d(s)", taskName, isTargetHistory, time.Now().Unix()-st)
 	}()
+	scope := ibcTxTargetLatest
+	if isTargetHistory {
+		scope = ibcTxTargetHistory
+	}
+	errs := make([]error, workNum)
 	var wg sync.WaitGroup
 	wg.Add(workNum)
 	for i := 0; i < workNum; i++ {
@@ -367,16 +450,183 @@ func doHandleSegments(taskName string, workNum int, segments []*segment, isTarge
 		go func(num int) {
 			defer wg.Done()
 
+			var begin, total int64
+			for id, v := range segments {
+				if id%workNum == num {
+					if total == 0 {
+						begin = v.StartTime
+					}
+					total++
+				}
+			}
+			cp := loadCheckpoint(taskName, scope, fmt.Sprintf("worker-%d", num), workNum, begin, total, dryRun)
 			for id, v := range segments {
 				if id%workNum != num {
 					continue
 				}
+				if cp.resumed(v.StartTime) {
+					continue
+				}
 				logrus.Infof("task %s worker %d fix %d-%d, target history: %t", taskName, num, v.StartTime, v.EndTime, isTargetHistory)
-				dowork(v, isTargetHistory)
+				if err := dowork(v, isTargetHistory); err != nil {
+					logrus.Errorf("task %s worker %d fix %d-%d error, stop at the checkpoint, %v", taskName, num, v.StartTime, v.EndTime, err)
+					errs[num] = err
+					return
+				}
+				cp.advance(v.StartTime)
 			}
+			cp.finish()
 		}(num)
 	}
 	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type WorkerExecHandler func(seg *segment, isTargetHistory bool) error
+
+// checkTaskRecord get the task record of the sync task, it's created if not existed
+func checkTaskRecord(taskName string) (*entity.IbcTaskRecord, error) {
+	taskRecord, err := taskRecordRepo.FindByTaskName(taskName)
+	if err == nil {
+		return taskRecord, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	taskRecord = &entity.IbcTaskRecord{
+		TaskName: taskName,
+		Height:   0,
+		Status:   entity.TaskRecordStatusOpen,
+		CreateAt: time.Now().Unix(),
+		UpdateAt: time.Now().Unix(),
+	}
+	if err = taskRecordRepo.Insert(taskRecord); err != nil {
+		return nil, err
+	}
+	return taskRecord, nil
+}
+
+func newTxInfo(tx *entity.Tx, msg *model.TxMsg) *entity.TxInfo {
+	return &entity.TxInfo{
+		Hash:    tx.TxHash,
+		Status:  tx.Status,
+		Time:    tx.Time,
+		Height:  tx.Height,
+		Fee:     tx.Fee,
+		Msg:     msg,
+		Memo:    tx.Memo,
+		Signers: tx.Signers,
+		Log:     tx.Log,
+	}
+}
+
+// syncTxByHeight sync the txs from the height of the task record, the txs of the last height are all included in one batch
+func syncTxByHeight(taskName string, fetch func(height, limit int64) ([]*entity.Tx, error),
+	fetchByHeight func(height int64) ([]*entity.Tx, error), handle func(txList []*entity.Tx) error) error {
+	maxParseTx := global.Config().Task.SingleChainSyncTransferTxMax
+	if maxParseTx <= 0 {
+		maxParseTx = defaultMaxHandlerTx
+	}
+
+	taskRecord, err := checkTaskRecord(taskName)
+	if err != nil {
+		return err
+	}
+	if taskRecord.Status == entity.TaskRecordStatusClose {
+		return nil
+	}
+
+	totalParseTx := 0
+	for {
+		txList, err := fetch(taskRecord.Height, constant.DefaultLimit)
+		if err != nil {
+			return err
+		}
+		if len(txList) == 0 {
+			return nil
+		}
+
+		if len(txList) == constant.DefaultLimit {
+			maxHeight := txList[len(txList)-1].Height
+			heightTxList, err := fetchByHeight(maxHeight)
+			if err != nil {
+				return err
+			}
+			txHashMap := make(map[string]struct{}, len(txList))
+			for _, v := range txList {
+				txHashMap[v.TxHash] = struct{}{}
+			}
+			for _, v := range heightTxList {
+				if _, ok := txHashMap[v.TxHash]; !ok {
+					txList = append(txList, v)
+				}
+			}
+		}
+
+		if err = handle(txList); err != nil {
+			return err
+		}
+
+		taskRecord.Height = txList[len(txList)-1].Height
+		if err = taskRecordRepo.UpdateHeight(taskRecord.TaskName, taskRecord.Height); err != nil {
+			return err
+		}
+
+		totalParseTx += len(txList)
+		if len(txList) < constant.DefaultLimit || totalParseTx >= maxParseTx {
+			return nil
+		}
+	}
 }
 
-type WorkerExecHandler func(seg *segment, isTargetHistory bool)
+// loadFailureCategory categorize the failed or refunded ibc tx by the error log of the sc tx, or the ack error and the
+// error events of the dc tx
+func loadFailureCategory(ibcTx *entity.ExIbcTx) {
+	ibcTx.FailureCategory, ibcTx.FailureReason = "", ""
+	switch ibcTx.Status {
+	case entity.IbcTxStatusFailed:
+		if ibcTx.ScTxInfo == nil {
+			return
+		}
+		ibcTx.FailureReason = model.LogError(ibcTx.ScTxInfo.Log)
+		ibcTx.FailureCategory = model.ParseFailureCategory(ibcTx.FailureReason)
+	case entity.IbcTxStatusRefunded:
+		if ibcTx.RefundedTxInfo == nil || ibcTx.RefundedTxInfo.Msg == nil {
+			return
+		}
+		if ibcTx.RefundedTxInfo.Msg.Type == constant.MsgTypeTimeoutPacket {
+			ibcTx.FailureCategory = model.FailureCategoryTimeout
+			return
+		}
+
+		ackErr := model.AckError(ibcTx.RefundedTxInfo.Msg.AckPacketMsg().Acknowledgement)
+		var dcErr string
+		if ibcTx.DcTxInfo != nil {
+			dcErr = model.LogError(ibcTx.DcTxInfo.Log)
+		}
+		ibcTx.FailureReason = ackErr
+		if dcErr != "" { // ack 中的错误可能已被 ibc-go 脱敏
+			ibcTx.FailureReason = dcErr
+		}
+		ibcTx.FailureCategory = model.ParseFailureCategory(dcErr, ackErr)
+	}
+}
+
+// calculateEscrowAddress the ics-20 escrow address of the channel, the first 20 bytes of sha256("ics20-1\x00port/channel")
+func calculateEscrowAddress(portID, channelID, addrPrefix string) (string, error) {
+	contents := fmt.Sprintf("%s/%s", portID, channelID)
+	const version = "ics20-1"
+	preImage := []byte(version)
+	preImage = append(preImage, 0)
+	preImage = append(preImage, contents...)
+	hash := sha256.Sum256(preImage)
+
+	return bech32.ConvertAndEncode(addrPrefix, hash[:20])
+}
@@ -0,0 +1,266 @@
+package task
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DryRunOneOffTask one-off task which supports dry-run mode. In dry-run mode, the intended updates are recorded into
+// the dry-run report(ibc_task_dry_run_report) instead of being written to db.
+type DryRunOneOffTask interface {
+	OneOffTask
+	enableDryRun()
+}
+
+// NewDryRunTask clear the last dry-run report of the task and return a new instance of the task in dry-run mode. The
+// dry-run mode belongs to the run of the new instance, the shared task instance never leaves the normal mode, so that
+// an overlapping normal run keeps writing db. The running lock of the task should be held before.
+func NewDryRunTask(t OneOffTask) (OneOffTask, error) {
+	if _, ok := t.(DryRunOneOffTask); !ok {
+		return nil, fmt.Errorf("task %s doesn't support dry run", t.Name())
+	}
+	if _, err := taskDryRunReportRepo.DeleteByTaskName(t.Name()); err != nil {
+		return nil, err
+	}
+
+	dryRunTask := reflect.New(reflect.TypeOf(t).Elem()).Interface().(DryRunOneOffTask)
+	dryRunTask.enableDryRun()
+	return dryRunTask, nil
+}
+
+// dryRunTrait provides the db writers of the fix task, the writers only record the intended updates in dry-run mode.
+// The mode is only enabled on the instance created by NewDryRunTask.
+type dryRunTrait struct {
+	dryRun bool
+}
+
+func (trait *dryRunTrait) enableDryRun() {
+	trait.dryRun = true
+}
+
+func (trait *dryRunTrait) ibcTxWriter(taskName string) repository.IExIbcTxRepo {
+	if trait.dryRun {
+		return &dryRunIbcTxRepo{IExIbcTxRepo: ibcTxRepo, taskName: taskName}
+	}
+	return ibcTxRepo
+}
+
+func (trait *dryRunTrait) txWriter(taskName string) repository.ITxRepo {
+	if trait.dryRun {
+		return &dryRunTxRepo{ITxRepo: txRepo, taskName: taskName}
+	}
+	return txRepo
+}
+
+func (trait *dryRunTrait) denomWriter(taskName string) repository.IDenomRepo {
+	if trait.dryRun {
+		return &dryRunDenomRepo{IDenomRepo: denomRepo, taskName: taskName}
+	}
+	return denomRepo
+}
+
+// ================================================================================
+// ================================================================================
+// dry-run writers
+
+type dryRunIbcTxRepo struct {
+	repository.IExIbcTxRepo
+	taskName string
+}
+
+func (repo *dryRunIbcTxRepo) UpdateOne(recordId string, history bool, setData bson.M) error {
+	set, ok := setData["$set"].(bson.M)
+	if !ok {
+		return fmt.Errorf("dry-run only supports $set update")
+	}
+	return repo.record(recordId, history, set)
+}
+
+func (repo *dryRunIbcTxRepo) UpdateDenomTrace(originRecordId string, ibcTx *entity.ExIbcTx) error {
+	return repo.record(originRecordId, false, bson.M{
+		"base_denom_chain_id": ibcTx.BaseDenomChainId,
+		"base_denom":          ibcTx.BaseDenom,
+		"create_at":           ibcTx.CreateAt,
+		"update_at":           ibcTx.UpdateAt,
+	})
+}
+
+func (repo *dryRunIbcTxRepo) UpdateDenomTraceHistory(originRecordId string, ibcTx *entity.ExIbcTx) error {
+	return repo.record(originRecordId, true, bson.M{
+		"record_id":           ibcTx.RecordId,
+		"base_denom_chain_id": ibcTx.BaseDenomChainId,
+		"base_denom":          ibcTx.BaseDenom,
+		"create_at":           ibcTx.CreateAt,
+		"update_at":           ibcTx.UpdateAt,
+	})
+}
+
+func (repo *dryRunIbcTxRepo) FixDcChainId(recordId, dcChainId, dcChannel string, originStatus entity.IbcTxStatus, isTargetHistory bool) error {
+	set := bson.M{}
+	if dcChainId == "" {
+		if originStatus == entity.IbcTxStatusProcessing {
+			set["status"] = entity.IbcTxStatusSetting
+		} else {
+			return nil
+		}
+	} else {
+		set["dc_chain_id"] = dcChainId
+		set["dc_channel"] = dcChannel
+		if originStatus == entity.IbcTxStatusSetting {
+			set["status"] = entity.IbcTxStatusProcessing
+		}
+	}
+
+	return repo.record(recordId, isTargetHistory, set)
+}
+
+func (repo *dryRunIbcTxRepo) UpdateBaseDenom(recordId, baseDenom, baseDenomChainId string, isTargetHistory bool) error {
+	return repo.record(recordId, isTargetHistory, bson.M{
+		"base_denom":          baseDenom,
+		"base_denom_chain_id": baseDenomChainId,
+	})
+}
+
+func (repo *dryRunIbcTxRepo) FixIbxTx(ibcTx *entity.ExIbcTx, isTargetHistory bool) error {
+	return repo.record(ibcTx.RecordId, isTargetHistory, bson.M{
+		"sc_client_id":     ibcTx.ScClientId,
+		"sc_connection_id": ibcTx.ScConnectionId,
+		"dc_client_id":     ibcTx.DcClientId,
+		"dc_connection_id": ibcTx.DcConnectionId,
+		"sc_tx_info":       ibcTx.ScTxInfo,
+		"dc_tx_info":       ibcTx.DcTxInfo,
+		"refunded_tx_info": ibcTx.RefundedTxInfo,
+	})
+}
+
+func (repo *dryRunIbcTxRepo) record(recordId string, history bool, set bson.M) error {
+	origin, err := repo.FindByRecordId(recordId, history)
+	if err != nil {
+		return err
+	}
+
+	return recordDryRunChanges(repo.taskName, entity.ExIbcTx{}.CollectionName(history), recordId, origin, set)
+}
+
+type dryRunTxRepo struct {
+	repository.ITxRepo
+	taskName string
+}
+
+func (repo *dryRunTxRepo) UpdateAckPacketId(chainId string, height int64, txHash string, msgs []interface{}) error {
+	origin, err := repo.GetTxByHash(chainId, txHash)
+	if err != nil {
+		return err
+	}
+
+	return recordDryRunChanges(repo.taskName, entity.Tx{}.CollectionName(chainId), fmt.Sprintf("%d-%s", height, txHash), origin, bson.M{
+		"msgs": msgs,
+	})
+}
+
+type dryRunDenomRepo struct {
+	repository.IDenomRepo
+	taskName string
+}
+
+func (repo *dryRunDenomRepo) Insert(denom *entity.IBCDenom) error {
+	return recordDryRunInsert(repo.taskName, entity.IBCDenom{}.CollectionName(false), fmt.Sprintf("%s-%s", denom.ChainId, denom.Denom), denom)
+}
+
+func (repo *dryRunDenomRepo) InsertBatchToNew(denoms entity.IBCDenomList) error {
+	for _, v := range denoms {
+		if err := recordDryRunInsert(repo.taskName, entity.IBCDenom{}.CollectionName(true), fmt.Sprintf("%s-%s", v.ChainId, v.Denom), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *dryRunDenomRepo) UpdateDenom(denom *entity.IBCDenom) error {
+	origin, err := repo.FindByDenomChainId(denom.Denom, denom.ChainId)
+	if err != nil {
+		return err
+	}
+
+	return recordDryRunChanges(repo.taskName, entity.IBCDenom{}.CollectionName(false), fmt.Sprintf("%s-%s", denom.ChainId, denom.Denom), origin, bson.M{
+		"base_denom":          denom.BaseDenom,
+		"base_denom_chain_id": denom.BaseDenomChainId,
+		"prev_denom":          denom.PrevDenom,
+		"prev_chain_id":       denom.PrevChainId,
+		"is_base_denom":       denom.IsBaseDenom,
+	})
+}
+
+// recordDryRunChanges compare the fields to be set with the origin document, and record the changed fields
+func recordDryRunChanges(taskName, coll, recordId string, origin interface{}, set bson.M) error {
+	originDoc, err := toBsonDoc(origin)
+	if err != nil {
+		return err
+	}
+
+	newDoc, err := toBsonDoc(set)
+	if err != nil {
+		return err
+	}
+
+	fields := make([]string, 0, len(newDoc))
+	for k := range newDoc {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	now := time.Now().Unix()
+	for _, field := range fields {
+		oldValue, newValue := originDoc[field], newDoc[field]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		if err = taskDryRunReportRepo.Insert(&entity.IbcTaskDryRunReport{
+			TaskName: taskName,
+			Coll:     coll,
+			RecordId: recordId,
+			Field:    field,
+			OldValue: oldValue,
+			NewValue: newValue,
+			CreateAt: now,
+		}); err != nil {
+			logrus.Errorf("task %s record dry-run report error, %s %s, %v", taskName, coll, recordId, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func recordDryRunInsert(taskName, coll, recordId string, doc interface{}) error {
+	return taskDryRunReportRepo.Insert(&entity.IbcTaskDryRunReport{
+		TaskName: taskName,
+		Coll:     coll,
+		RecordId: recordId,
+		Field:    entity.DryRunFieldInsert,
+		NewValue: doc,
+		CreateAt: time.Now().Unix(),
+	})
+}
+
+// toBsonDoc convert the value to bson.M, so that the origin document and the update values have the same types
+func toBsonDoc(v interface{}) (bson.M, error) {
+	bz, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.M
+	if err = bson.Unmarshal(bz, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
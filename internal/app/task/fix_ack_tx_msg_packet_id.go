@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/sirupsen/logrus"
 	"strconv"
@@ -11,9 +12,10 @@ import (
 	"sync"
 )
 
-var _ OneOffTask = new(FixAckTxPacketIdTask)
+var _ DryRunOneOffTask = new(FixAckTxPacketIdTask)
 
 type FixAckTxPacketIdTask struct {
+	dryRunTrait
 }
 
 func (f FixAckTxPacketIdTask) Name() string {
@@ -29,7 +31,7 @@ func (f FixAckTxPacketIdTask) RunWithParam(chainsStr string, endHeightStr string
 }
 func (f FixAckTxPacketIdTask) Run() int {
 	return 1
-	//return f.handle(global.Config.ChainConfig.FixAckTxPacketIdChains)
+	//return f.handle(global.Config().ChainConfig.FixAckTxPacketIdChains)
 }
 
 func (f FixAckTxPacketIdTask) handle(chainsStr string, endHeightStr string) int {
@@ -92,7 +94,9 @@ func (f FixAckTxPacketIdTask) handle(chainsStr string, endHeightStr string) int
 		//}
 		logrus.Infof("task_name:%s fix ack_tx packet_id,start-end:%v-%v chain_id:%s",
 			f.Name(), minHTx.Height-1, maxEndHeight, chainId)
-		ret := NewfixAckTxTask(chainId, f.Name(), minHTx.Height-1, maxEndHeight).Run()
+		fixTask := NewfixAckTxTask(chainId, f.Name(), minHTx.Height-1, maxEndHeight)
+		fixTask.writer, fixTask.dryRun = f.txWriter(f.Name()), f.dryRun
+		ret := fixTask.Run()
 		if ret > 0 {
 			logrus.Infof("task_name:%s finish fix ack_tx packet_id,start-end:%v-%v chain_id:%s",
 				f.Name(), minHTx.Height-1, maxEndHeight, chainId)
@@ -132,6 +136,8 @@ type fixAckTxTask struct {
 	EndHeight   int64
 	ChainId     string
 	TaskName    string
+	writer      repository.ITxRepo
+	dryRun      bool
 }
 
 func NewfixAckTxTask(chainId, taskName string, startH, endH int64) *fixAckTxTask {
@@ -140,6 +146,7 @@ func NewfixAckTxTask(chainId, taskName string, startH, endH int64) *fixAckTxTask
 		EndHeight:   endH,
 		ChainId:     chainId,
 		TaskName:    taskName,
+		writer:      txRepo,
 	}
 }
 
@@ -150,6 +157,10 @@ func (t *fixAckTxTask) Run() int {
 	}
 	height := t.StartHeight
 	endTime := int64(1640966400) //修补数据的截止时间2022-01-01 00:00:00
+	cp := loadCheckpoint(t.TaskName, t.ChainId, "height", 1, t.StartHeight, (t.EndHeight-t.StartHeight)/constant.IncreHeight+1, t.dryRun)
+	for cp.resumed(height) {
+		height += constant.IncreHeight
+	}
 	for {
 		txs, err := txRepo.FindAllAckTxs(t.ChainId, height)
 		if err != nil {
@@ -168,6 +179,7 @@ func (t *fixAckTxTask) Run() int {
 			logrus.Infof("task_name:%s finish fix ack packet_id txs in height:%d-%d chain_id:%s",
 				t.TaskName, height, height+constant.IncreHeight, t.ChainId)
 		}
+		cp.advance(height)
 		height += constant.IncreHeight
 		logrus.Infof("task_name:%s finish scan %d-%d txs:%d chain_id:%s",
 			t.TaskName, height-constant.IncreHeight, height, len(txs), t.ChainId)
@@ -178,6 +190,7 @@ func (t *fixAckTxTask) Run() int {
 			break
 		}
 	}
+	cp.finish()
 	return 1
 }
 
@@ -204,7 +217,7 @@ func (t *fixAckTxTask) doTask(txs []*entity.Tx) error {
 			valMsgs = append(valMsgs, msg)
 		}
 		if msgsChange {
-			if err := txRepo.UpdateAckPacketId(t.ChainId, val.Height, val.TxHash, valMsgs); err != nil {
+			if err := t.writer.UpdateAckPacketId(t.ChainId, val.Height, val.TxHash, valMsgs); err != nil {
 				return err
 			}
 		}
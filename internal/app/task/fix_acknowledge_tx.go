@@ -10,9 +10,10 @@ import (
 )
 
 type FixAcknowledgeTxTask struct {
+	dryRunTrait
 }
 
-var _ OneOffTask = new(FixAcknowledgeTxTask)
+var _ DryRunOneOffTask = new(FixAcknowledgeTxTask)
 
 func (t *FixAcknowledgeTxTask) Name() string {
 	return "fix_acknowledge_tx_task"
@@ -35,21 +36,25 @@ func (t *FixAcknowledgeTxTask) Run() int {
 		return -1
 	}
 
+	var latestErr, historyErr error
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		err := t.fixAcknowledgeTxs(ibcTxTargetLatest, segments)
-		logrus.Infof("task %s fix latest end, %v", t.Name(), err)
+		latestErr = t.fixAcknowledgeTxs(ibcTxTargetLatest, segments)
+		logrus.Infof("task %s fix latest end, %v", t.Name(), latestErr)
 	}()
 
 	go func() {
 		defer wg.Done()
-		err := t.fixAcknowledgeTxs(ibcTxTargetHistory, historySegments)
-		logrus.Infof("task %s fix history end, %v", t.Name(), err)
+		historyErr = t.fixAcknowledgeTxs(ibcTxTargetHistory, historySegments)
+		logrus.Infof("task %s fix history end, %v", t.Name(), historyErr)
 	}()
 
 	wg.Wait()
+	if latestErr != nil || historyErr != nil {
+		return -1
+	}
 	return 1
 }
 
@@ -59,20 +64,20 @@ func (t *FixAcknowledgeTxTask) fixAcknowledgeTxs(target string, segments []*segm
 	if target == ibcTxTargetHistory {
 		isTargetHistory = true
 	}
-	doHandleSegments(t.Name(), 5, segments, isTargetHistory, func(seg *segment, isTargetHistory bool) {
+	return doHandleSegments(t.Name(), 5, segments, isTargetHistory, t.dryRun, func(seg *segment, isTargetHistory bool) error {
 		var skip int64 = 0
 		for {
 			txs, err := ibcTxRepo.FindAcknowledgeTxsEmptyTxs(seg.StartTime, seg.EndTime, skip, limit, isTargetHistory)
 			if err != nil {
 				logrus.Errorf("task %s FindAcknowledgeTxsEmptyTxs %s %d-%d err, %v", t.Name(), target, seg.StartTime, seg.EndTime, err)
-				return
+				return err
 			}
 
 			for _, val := range txs {
 				err := t.SaveAcknowledgeTx(val, isTargetHistory)
 				if err != nil && err != qmgo.ErrNoSuchDocuments {
 					logrus.Errorf("task %s saveAcknowledgeTx %s err, chain_id: %s, packet_id: %s, %v", t.Name(), target, val.ScChainId, val.ScTxInfo.Msg.CommonMsg().PacketId, err)
-					return
+					return err
 				}
 			}
 
@@ -81,9 +86,8 @@ func (t *FixAcknowledgeTxTask) fixAcknowledgeTxs(target string, segments []*segm
 			}
 			skip += limit
 		}
+		return nil
 	})
-
-	return nil
 }
 
 func (t *FixAcknowledgeTxTask) SaveAcknowledgeTx(ibcTx *entity.ExIbcTx, history bool) error {
@@ -107,7 +111,7 @@ func (t *FixAcknowledgeTxTask) SaveAcknowledgeTx(ibcTx *entity.ExIbcTx, history
 				MsgAmount: nil,
 				Msg:       getMsgByType(*ackTx, constant.MsgTypeAcknowledgement, packetId),
 			}
-			return ibcTxRepo.UpdateOne(ibcTx.RecordId, history, bson.M{
+			return t.ibcTxWriter(t.Name()).UpdateOne(ibcTx.RecordId, history, bson.M{
 				"$set": bson.M{
 					"refunded_tx_info": ibcTx.RefundedTxInfo,
 				},
@@ -12,18 +12,19 @@ import (
 )
 
 type FixBaseDenomChainIdTask struct {
+	dryRunTrait
 	chainMap map[string]*entity.ChainConfig
 	denomMap entity.IBCDenomMap
 }
 
-var _ OneOffTask = new(FixBaseDenomChainIdTask)
+var _ DryRunOneOffTask = new(FixBaseDenomChainIdTask)
 
 func (t *FixBaseDenomChainIdTask) Name() string {
 	return "fix_base_denom_chain_id_task"
 }
 
 func (t *FixBaseDenomChainIdTask) Switch() bool {
-	return global.Config.Task.SwitchFixBaseDenomChainIdTask
+	return global.Config().Task.SwitchFixBaseDenomChainIdTask
 }
 
 func (t *FixBaseDenomChainIdTask) Run() int {
@@ -112,7 +113,7 @@ func (t *FixBaseDenomChainIdTask) handleSegment(startTime, endTime int64, baseDe
 				_, _, denomFullPath, _, _ := parseTransferTxEvents(msgIndex, &tx)
 
 				ibcDenom := traceDenom(denomFullPath, ibcTx.ScChainId, t.chainMap)
-				if err = ibcTxRepo.UpdateBaseDenom(ibcTx.RecordId, ibcDenom.BaseDenom, ibcDenom.BaseDenomChainId, isTargetHistory); err != nil {
+				if err = t.ibcTxWriter(t.Name()).UpdateBaseDenom(ibcTx.RecordId, ibcDenom.BaseDenom, ibcDenom.BaseDenomChainId, isTargetHistory); err != nil {
 					logrus.Errorf("task %s UpdateBaseDenom(recordId: %s) error, %v", t.Name(), ibcTx.RecordId, err)
 				}
 
@@ -152,11 +153,11 @@ func (t *FixBaseDenomChainIdTask) handleSegment(startTime, endTime int64, baseDe
 func (t *FixBaseDenomChainIdTask) upsertDenom(ibcDenom *entity.IBCDenom) {
 	_, ok := t.denomMap[fmt.Sprintf("%s%s", ibcDenom.ChainId, ibcDenom.Denom)]
 	if !ok {
-		if err := denomRepo.Insert(ibcDenom); err != nil {
+		if err := t.denomWriter(t.Name()).Insert(ibcDenom); err != nil {
 			logrus.Errorf("task %s denomRepo.Insert error, chain_id: %s, denom: %s, %v", t.Name(), ibcDenom.ChainId, ibcDenom.Denom, err)
 		}
 	} else {
-		if err := denomRepo.UpdateDenom(ibcDenom); err != nil {
+		if err := t.denomWriter(t.Name()).UpdateDenom(ibcDenom); err != nil {
 			logrus.Errorf("task %s denomRepo.UpdateDenom error, chain_id: %s, denom: %s, %v", t.Name(), ibcDenom.ChainId, ibcDenom.Denom, err)
 		}
 	}
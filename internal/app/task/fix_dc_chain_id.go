@@ -10,17 +10,18 @@ import (
 )
 
 type FixDcChainIdTask struct {
+	dryRunTrait
 	chainMap map[string]*entity.ChainConfig
 }
 
-var _ OneOffTask = new(FixDcChainIdTask)
+var _ DryRunOneOffTask = new(FixDcChainIdTask)
 
 func (t *FixDcChainIdTask) Name() string {
 	return "fix_dc_chain_id_task"
 }
 
 func (t *FixDcChainIdTask) Switch() bool {
-	return global.Config.Task.SwitchFixDcChainIdTask
+	return global.Config().Task.SwitchFixDcChainIdTask
 }
 
 func (t *FixDcChainIdTask) Run() int {
@@ -96,7 +97,7 @@ func (t *FixDcChainIdTask) fixDcChainId(target string, segments []*segment) {
 		}
 
 		for _, tx := range toBeFixedTxs {
-			if err := ibcTxRepo.FixDcChainId(tx.RecordId, tx.DcChainId, tx.DcChannel, tx.Status, isTargetHistory); err != nil {
+			if err := t.ibcTxWriter(t.Name()).FixDcChainId(tx.RecordId, tx.DcChainId, tx.DcChannel, tx.Status, isTargetHistory); err != nil {
 				logrus.Errorf("task %s FixDcChainId(%s) %s err, dcChainId: %s, dcChannel: %s, %v", t.Name(), tx.RecordId, target, tx.DcChainId, tx.DcChannel, err)
 			}
 		}
@@ -5,3 +5,11 @@ import "testing"
 func Test_FixDcChainId(t *testing.T) {
 	new(FixDcChainIdTask).Run()
 }
+
+func Test_FixDcChainIdDryRun(t *testing.T) {
+	task, err := NewDryRunTask(new(FixDcChainIdTask))
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.Run()
+}
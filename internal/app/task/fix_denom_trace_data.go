@@ -6,6 +6,7 @@ import (
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -14,21 +15,21 @@ type FixDenomTraceDataTask struct {
 	fixDenomTraceDataTrait
 }
 
-var _ OneOffTask = new(FixDenomTraceDataTask)
+var _ DryRunOneOffTask = new(FixDenomTraceDataTask)
 
 func (t *FixDenomTraceDataTask) Name() string {
 	return "fix_denom_trace_data_task"
 }
 
 func (t *FixDenomTraceDataTask) Switch() bool {
-	return global.Config.Task.SwitchFixDenomTraceDataTask
+	return global.Config().Task.SwitchFixDenomTraceDataTask
 }
 
 func (t *FixDenomTraceDataTask) Run() int {
 	// init
 	t.target = ibcTxTargetLatest
-	t.startTime = global.Config.Task.FixDenomTraceDataStartTime
-	t.endTime = global.Config.Task.FixDenomTraceDataEndTime
+	t.startTime = global.Config().Task.FixDenomTraceDataStartTime
+	t.endTime = global.Config().Task.FixDenomTraceDataEndTime
 
 	if t.startTime < 0 || t.endTime < t.startTime {
 		logrus.Errorf("task %s start/end time config error, start time: %d, end time: %d", t.Name(), t.startTime, t.endTime)
@@ -69,21 +70,21 @@ type FixDenomTraceHistoryDataTask struct {
 	fixDenomTraceDataTrait
 }
 
-var _ OneOffTask = new(FixDenomTraceHistoryDataTask)
+var _ DryRunOneOffTask = new(FixDenomTraceHistoryDataTask)
 
 func (t *FixDenomTraceHistoryDataTask) Name() string {
 	return "fix_denom_trace_history_data_task"
 }
 
 func (t *FixDenomTraceHistoryDataTask) Switch() bool {
-	return global.Config.Task.SwitchFixDenomTraceHistoryDataTask
+	return global.Config().Task.SwitchFixDenomTraceHistoryDataTask
 }
 
 func (t *FixDenomTraceHistoryDataTask) Run() int {
 	// init
 	t.target = ibcTxTargetHistory
-	t.startTime = global.Config.Task.FixDenomTraceHistoryDataStartTime
-	t.endTime = global.Config.Task.FixDenomTraceHistoryDataEndTime
+	t.startTime = global.Config().Task.FixDenomTraceHistoryDataStartTime
+	t.endTime = global.Config().Task.FixDenomTraceHistoryDataEndTime
 
 	if t.startTime < 0 || t.endTime < t.startTime {
 		logrus.Errorf("task %s start/end time config error, start time: %d, end time: %d", t.Name(), t.startTime, t.endTime)
@@ -123,14 +124,15 @@ func (t *FixDenomTraceHistoryDataTask) RunWithParam(startTime, endTime int64) in
 // ================================================================================
 // trait
 type fixDenomTraceDataTrait struct {
+	dryRunTrait
 	target    string
 	startTime int64
 	endTime   int64
 }
 
 func (trait *fixDenomTraceDataTrait) workerNum() int {
-	if global.Config.Task.FixDenomTraceDataWorkerNum > 0 {
-		return global.Config.Task.FixDenomTraceDataWorkerNum
+	if global.Config().Task.FixDenomTraceDataWorkerNum > 0 {
+		return global.Config().Task.FixDenomTraceDataWorkerNum
 	}
 	return fixDenomTraceDataTaskWorkerNum
 }
@@ -158,7 +160,9 @@ func (trait *fixDenomTraceDataTrait) startWorker(taskName string) error {
 		workName := fmt.Sprintf("worker-%d", i)
 		workloadIndex := i
 		go func(wn string, wi int) {
-			newFixDenomTraceDataWorker(taskName, wn, trait.target, chainMap, denomMap, segments, wi).exec(workerNum)
+			w := newFixDenomTraceDataWorker(taskName, wn, trait.target, chainMap, denomMap, segments, wi)
+			w.ibcTxWriter, w.denomWriter, w.dryRun = trait.ibcTxWriter(taskName), trait.denomWriter(taskName), trait.dryRun
+			w.exec(workerNum)
 			waitGroup.Done()
 		}(workName, workloadIndex)
 	}
@@ -199,6 +203,8 @@ func newFixDenomTraceDataWorker(taskName string, workerName string, target strin
 		denomMap:         denomMap,
 		workloadSegments: workloadSegments,
 		workloadIndex:    workloadIndex,
+		ibcTxWriter:      ibcTxRepo,
+		denomWriter:      denomRepo,
 	}
 }
 
@@ -211,20 +217,39 @@ type fixDenomTraceDataWorker struct {
 	newDenomMap      map[string]*entity.IBCDenom
 	workloadSegments []segment
 	workloadIndex    int
+	ibcTxWriter      repository.IExIbcTxRepo
+	denomWriter      repository.IDenomRepo
+	dryRun           bool
 }
 
 func (w *fixDenomTraceDataWorker) exec(workerNum int) {
 	logrus.Infof("task %s worker %s start", w.taskName, w.workerName)
 	w.newDenomMap = make(map[string]*entity.IBCDenom)
+	var begin, total int64
+	for index, seg := range w.workloadSegments {
+		if index%workerNum == w.workloadIndex {
+			if total == 0 {
+				begin = seg.StartTime
+			}
+			total++
+		}
+	}
+
+	cp := loadCheckpoint(w.taskName, w.target, w.workerName, workerNum, begin, total, w.dryRun)
 	workloadAmount := 0
 	for index, seg := range w.workloadSegments {
 		if index%workerNum != w.workloadIndex {
 			continue
 		}
+		if cp.resumed(seg.StartTime) {
+			continue
+		}
 
 		workloadAmount++
 		w.fixData(seg.StartTime, seg.EndTime)
+		cp.advance(seg.StartTime)
 	}
+	cp.finish()
 
 	logrus.Infof("task %s worker %s end, workloadAmount: %d", w.taskName, w.workerName, workloadAmount)
 }
@@ -277,7 +302,7 @@ func (w *fixDenomTraceDataWorker) fixData(startTime, endTime int64) {
 	}
 
 	if len(newDenomList) > 0 {
-		if err := denomRepo.InsertBatchToNew(newDenomList); err != nil {
+		if err := w.denomWriter.InsertBatchToNew(newDenomList); err != nil {
 			logrus.Errorf("task %s worker %s insert new denoms error, %v", w.taskName, w.workerName, err)
 		}
 	}
@@ -293,10 +318,10 @@ func (w *fixDenomTraceDataWorker) getTxs(startTime, endTime, skip, limit int64)
 
 func (w *fixDenomTraceDataWorker) UpdateTx(originRecordId string, tx *entity.ExIbcTx) error {
 	if w.target == ibcTxTargetHistory {
-		return ibcTxRepo.UpdateDenomTraceHistory(originRecordId, tx)
+		return w.ibcTxWriter.UpdateDenomTraceHistory(originRecordId, tx)
 	}
 
-	return ibcTxRepo.UpdateDenomTrace(originRecordId, tx)
+	return w.ibcTxWriter.UpdateDenomTrace(originRecordId, tx)
 }
 
 func (w *fixDenomTraceDataWorker) parseDenom(tx *entity.ExIbcTx) (*entity.IBCDenom, *entity.IBCDenom) {
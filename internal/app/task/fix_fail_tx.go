@@ -11,9 +11,10 @@ import (
 )
 
 type FixFailTxTask struct {
+	dryRunTrait
 }
 
-var _ OneOffTask = new(FixFailTxTask)
+var _ DryRunOneOffTask = new(FixFailTxTask)
 
 func (t *FixFailTxTask) Name() string {
 	return "fix_fail_tx_task"
@@ -49,26 +50,32 @@ func (t *FixFailTxTask) Run() int {
 		return -1
 	}
 
+	var latestErr, historyErr error
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		err := t.fixFailTxs(ibcTxTargetLatest, segments)
-		logrus.Infof("task %s fix latest end, %v", t.Name(), err)
+		latestErr = t.fixFailTxs(ibcTxTargetLatest, segments)
+		logrus.Infof("task %s fix latest end, %v", t.Name(), latestErr)
 	}()
 
 	go func() {
 		defer wg.Done()
-		err := t.fixFailTxs(ibcTxTargetHistory, historySegments)
-		logrus.Infof("task %s fix history end, %v", t.Name(), err)
+		historyErr = t.fixFailTxs(ibcTxTargetHistory, historySegments)
+		logrus.Infof("task %s fix history end, %v", t.Name(), historyErr)
 	}()
 
 	wg.Wait()
+	if latestErr != nil || historyErr != nil {
+		return -1
+	}
 	return 1
 }
 
-func (t *FixFailTxTask) FixAcknowledgeTx(recordId string, ackTx *entity.Tx, history bool, status entity.IbcTxStatus, packetId string) error {
-	refundedTxInfo := &entity.TxInfo{
+// FixAcknowledgeTx set the ack tx and the status of the ibc tx, the failure category is recalculated by them
+func (t *FixFailTxTask) FixAcknowledgeTx(ibcTx *entity.ExIbcTx, ackTx *entity.Tx, history bool, status entity.IbcTxStatus, packetId string) error {
+	ibcTx.Status = status
+	ibcTx.RefundedTxInfo = &entity.TxInfo{
 		Hash:      ackTx.TxHash,
 		Height:    ackTx.Height,
 		Time:      ackTx.Time,
@@ -79,24 +86,30 @@ func (t *FixFailTxTask) FixAcknowledgeTx(recordId string, ackTx *entity.Tx, hist
 		MsgAmount: nil,
 		Msg:       getMsgByType(*ackTx, constant.MsgTypeAcknowledgement, packetId),
 	}
+	loadFailureCategory(ibcTx)
 	update := bson.M{
-		"refunded_tx_info": refundedTxInfo,
+		"refunded_tx_info": ibcTx.RefundedTxInfo,
 		"status":           status,
+		"failure_category": ibcTx.FailureCategory,
+		"failure_reason":   ibcTx.FailureReason,
 	}
-	return ibcTxRepo.UpdateOne(recordId, history, bson.M{
+	return t.ibcTxWriter(t.Name()).UpdateOne(ibcTx.RecordId, history, bson.M{
 		"$set": update,
 	})
 }
 
-func (t *FixFailTxTask) FixRecvPacketTxs(recordId string, recvTx, ackTx *entity.Tx, history bool, status entity.IbcTxStatus, packetId string) error {
+// FixRecvPacketTxs set the recv tx, the ack tx and the status of the ibc tx, the failure category is recalculated by
+// them
+func (t *FixFailTxTask) FixRecvPacketTxs(ibcTx *entity.ExIbcTx, recvTx, ackTx *entity.Tx, history bool, status entity.IbcTxStatus, packetId string) error {
 	if status <= 0 {
 		return nil
 	}
+	ibcTx.Status = status
 	update := bson.M{
 		"status": status,
 	}
 	if recvTx != nil {
-		dcTxInfo := &entity.TxInfo{
+		ibcTx.DcTxInfo = &entity.TxInfo{
 			Hash:      recvTx.TxHash,
 			Height:    recvTx.Height,
 			Time:      recvTx.Time,
@@ -104,17 +117,19 @@ func (t *FixFailTxTask) FixRecvPacketTxs(recordId string, recvTx, ackTx *entity.
 			Fee:       recvTx.Fee,
 			Memo:      recvTx.Memo,
 			Signers:   recvTx.Signers,
+			Log:       recvTx.Log,
 			MsgAmount: nil,
 			Msg:       getMsgByType(*recvTx, constant.MsgTypeRecvPacket, packetId),
 		}
-		update["dc_tx_info"] = dcTxInfo
+		update["dc_tx_info"] = ibcTx.DcTxInfo
 	} else if status == entity.IbcTxStatusProcessing {
 		//"处理中"将第二段数据清空
+		ibcTx.DcTxInfo = nil
 		update["dc_tx_info"] = bson.M{}
 	}
 
 	if ackTx != nil {
-		refundedTxInfo := &entity.TxInfo{
+		ibcTx.RefundedTxInfo = &entity.TxInfo{
 			Hash:      ackTx.TxHash,
 			Height:    ackTx.Height,
 			Time:      ackTx.Time,
@@ -125,10 +140,13 @@ func (t *FixFailTxTask) FixRecvPacketTxs(recordId string, recvTx, ackTx *entity.
 			MsgAmount: nil,
 			Msg:       getMsgByType(*ackTx, constant.MsgTypeAcknowledgement, packetId),
 		}
-		update["refunded_tx_info"] = refundedTxInfo
+		update["refunded_tx_info"] = ibcTx.RefundedTxInfo
 	}
+	loadFailureCategory(ibcTx)
+	update["failure_category"] = ibcTx.FailureCategory
+	update["failure_reason"] = ibcTx.FailureReason
 
-	return ibcTxRepo.UpdateOne(recordId, history, bson.M{
+	return t.ibcTxWriter(t.Name()).UpdateOne(ibcTx.RecordId, history, bson.M{
 		"$set": update,
 	})
 }
@@ -168,13 +186,13 @@ func (t *FixFailTxTask) fixFailTxs(target string, segments []*segment) error {
 	if target == ibcTxTargetHistory {
 		isTargetHistory = true
 	}
-	doHandleSegments(t.Name(), 3, segments, isTargetHistory, func(seg *segment, isTargetHistory bool) {
+	return doHandleSegments(t.Name(), 3, segments, isTargetHistory, t.dryRun, func(seg *segment, isTargetHistory bool) error {
 		var skip int64 = 0
 		for {
 			txs, err := ibcTxRepo.FindFailStatusTxs(seg.StartTime, seg.EndTime, skip, limit, isTargetHistory)
 			if err != nil {
 				logrus.Errorf("task %s FindFailToRefundStatusTxs %s %d-%d err, %v", t.Name(), target, seg.StartTime, seg.EndTime, err)
-				return
+				return err
 			}
 
 			for _, val := range txs {
@@ -191,7 +209,7 @@ func (t *FixFailTxTask) fixFailTxs(target string, segments []*segment) error {
 					if err != nil {
 						logrus.Errorf("task %s findAckTx %s err, chain_id: %s, packet_id: %s, %v",
 							t.Name(), target, val.ScChainId, packetId, err.Error())
-						return
+						return err
 					}
 					if ackTx != nil {
 						var status entity.IbcTxStatus
@@ -200,20 +218,20 @@ func (t *FixFailTxTask) fixFailTxs(target string, segments []*segment) error {
 						} else { //status: fail->refund
 							status = entity.IbcTxStatusRefunded
 						}
-						err = t.FixAcknowledgeTx(val.RecordId, ackTx, isTargetHistory, status, packetId)
+						err = t.FixAcknowledgeTx(val, ackTx, isTargetHistory, status, packetId)
 						if err != nil && err != qmgo.ErrNoSuchDocuments {
 							logrus.Errorf("task %s  %s err, chain_id: %s, packet_id: %s, %v", t.Name(), target, val.ScChainId, val.ScTxInfo.Msg.CommonMsg().PacketId, err)
-							return
+							return err
 						}
 					} else {
 						logrus.Debugf("status:%d recv_packet(chain_id:%s hash:%s) findWriteAck is ok,but no found acknowledge tx(chain_id:%s) tx",
 							val.Status, val.DcChainId, bindedTx.TxHash, val.ScChainId)
 						//status:fail->process
-						err = t.FixRecvPacketTxs(val.RecordId, nil, nil, isTargetHistory, entity.IbcTxStatusProcessing, packetId)
+						err = t.FixRecvPacketTxs(val, nil, nil, isTargetHistory, entity.IbcTxStatusProcessing, packetId)
 						if err != nil && err != qmgo.ErrNoSuchDocuments {
 							logrus.Errorf("task %s FixRecvPacketTxs %s err, chain_id: %s, packet_id: %s, %v",
 								t.Name(), target, val.ScChainId, val.ScTxInfo.Msg.CommonMsg().PacketId, err)
-							return
+							return err
 						}
 					}
 				} else {
@@ -221,7 +239,7 @@ func (t *FixFailTxTask) fixFailTxs(target string, segments []*segment) error {
 					recvTxs, err := txRepo.GetRecvPacketTxs(val.DcChainId, val.ScTxInfo.Msg.CommonMsg().PacketId)
 					if err != nil {
 						logrus.Errorf("task %s GetRecvPacketTxs %s err, chain_id: %s, packet_id: %s, %v", t.Name(), target, val.ScChainId, packetId, err)
-						return
+						return err
 					}
 
 					var (
@@ -243,7 +261,7 @@ func (t *FixFailTxTask) fixFailTxs(target string, segments []*segment) error {
 						ackTx, err = findAckTx(val, varAckRes, ackOk)
 						if err != nil {
 							logrus.Errorf("task %s findAckTx %s err, chain_id: %s, packet_id: %s, %v", t.Name(), target, val.ScChainId, packetId, err)
-							return
+							return err
 						}
 
 						if ackOk {
@@ -254,10 +272,10 @@ func (t *FixFailTxTask) fixFailTxs(target string, segments []*segment) error {
 					} else { //没有找到包含writeAck的recv_packet
 						status = entity.IbcTxStatusProcessing
 					}
-					err = t.FixRecvPacketTxs(val.RecordId, recvTx, ackTx, isTargetHistory, status, packetId)
+					err = t.FixRecvPacketTxs(val, recvTx, ackTx, isTargetHistory, status, packetId)
 					if err != nil && err != qmgo.ErrNoSuchDocuments {
 						logrus.Errorf("task %s FixRecvPacketTxs %s err, chain_id: %s, packet_id: %s, %v", t.Name(), target, val.ScChainId, packetId, err)
-						return
+						return err
 					}
 				}
 
@@ -268,9 +286,8 @@ func (t *FixFailTxTask) fixFailTxs(target string, segments []*segment) error {
 			}
 			skip += limit
 		}
+		return nil
 	})
-
-	return nil
 }
 
 func findAckTx(val *entity.ExIbcTx, ackRes string, ackOk bool) (*entity.Tx, error) {
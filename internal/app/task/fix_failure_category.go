@@ -0,0 +1,94 @@
+package task
+
+import (
+	"sync"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FixFailureCategoryTask categorize the failed and refunded txs synced before the failure categorization
+type FixFailureCategoryTask struct {
+	dryRunTrait
+}
+
+var _ DryRunOneOffTask = new(FixFailureCategoryTask)
+
+func (t *FixFailureCategoryTask) Name() string {
+	return "fix_failure_category_task"
+}
+
+func (t *FixFailureCategoryTask) Switch() bool {
+	return global.Config().Task.SwitchFixFailureCategoryTask
+}
+
+func (t *FixFailureCategoryTask) Run() int {
+	segments, err := getSegment(segmentStepLatest)
+	if err != nil {
+		logrus.Errorf("task %s getSegment error, %v", t.Name(), err)
+		return -1
+	}
+
+	historySegments, err := getHistorySegment(segmentStepHistory)
+	if err != nil {
+		logrus.Errorf("task %s getHistorySegment error, %v", t.Name(), err)
+		return -1
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		t.fixFailureCategory(ibcTxTargetLatest, segments)
+		logrus.Infof("task %s fix latest end", t.Name())
+	}()
+
+	go func() {
+		defer wg.Done()
+		t.fixFailureCategory(ibcTxTargetHistory, historySegments)
+		logrus.Infof("task %s fix history end", t.Name())
+	}()
+
+	wg.Wait()
+	return 1
+}
+
+func (t *FixFailureCategoryTask) fixFailureCategory(target string, segments []*segment) {
+	const limit int64 = 1000
+	isTargetHistory := false
+	if target == ibcTxTargetHistory {
+		isTargetHistory = true
+	}
+
+	for _, v := range segments {
+		logrus.Infof("task %s fix %s %d-%d", t.Name(), target, v.StartTime, v.EndTime)
+		var skip int64 = 0
+		toBeFixed := make(map[string]bson.M)
+		for {
+			txs, err := ibcTxRepo.FindFailureCategoryEmptyTxs(v.StartTime, v.EndTime, skip, limit, isTargetHistory)
+			if err != nil {
+				logrus.Errorf("task %s FindFailureCategoryEmptyTxs %s %d-%d err, %v", t.Name(), target, v.StartTime, v.EndTime, err)
+				break
+			}
+
+			for _, tx := range txs {
+				loadFailureCategory(tx)
+				if tx.FailureCategory != "" {
+					toBeFixed[tx.RecordId] = bson.M{"failure_category": tx.FailureCategory, "failure_reason": tx.FailureReason}
+				}
+			}
+
+			if int64(len(txs)) < limit {
+				break
+			}
+			skip += limit
+		}
+
+		for recordId, set := range toBeFixed {
+			if err := t.ibcTxWriter(t.Name()).UpdateOne(recordId, isTargetHistory, bson.M{"$set": set}); err != nil {
+				logrus.Errorf("task %s UpdateOne(%s) %s err, %v", t.Name(), recordId, target, err)
+			}
+		}
+	}
+}
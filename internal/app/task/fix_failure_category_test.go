@@ -0,0 +1,15 @@
+package task
+
+import "testing"
+
+func Test_FixFailureCategory(t *testing.T) {
+	new(FixFailureCategoryTask).Run()
+}
+
+func Test_FixFailureCategoryDryRun(t *testing.T) {
+	task, err := NewDryRunTask(new(FixFailureCategoryTask))
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.Run()
+}
@@ -11,11 +11,12 @@ import (
 )
 
 type FixIbxTxTask struct {
+	dryRunTrait
 	chainMap map[string]*entity.ChainConfig
 	domain   string // all, partly
 }
 
-var _ OneOffTask = new(FixIbxTxTask)
+var _ DryRunOneOffTask = new(FixIbxTxTask)
 
 const (
 	domainAll    = "all"
@@ -201,7 +202,7 @@ func (t *FixIbxTxTask) fixTxs(ibcTxs []*entity.ExIbcTx, isTargetHistory bool) {
 			v.DcClientId = cf.GetChannelClient(v.DcPort, v.DcChannel)
 		}
 
-		if err := ibcTxRepo.FixIbxTx(v, isTargetHistory); err != nil {
+		if err := t.ibcTxWriter(t.Name()).FixIbxTx(v, isTargetHistory); err != nil {
 			logrus.Errorf("task %s FixIbxTx(%s) err, %v", t.Name(), v.RecordId, err)
 		}
 	}
@@ -0,0 +1,98 @@
+package task
+
+import (
+	"sync"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FixPacketMemoTask decode the packet memo of the txs synced before the memo parsing
+type FixPacketMemoTask struct {
+	dryRunTrait
+}
+
+var _ DryRunOneOffTask = new(FixPacketMemoTask)
+
+func (t *FixPacketMemoTask) Name() string {
+	return "fix_packet_memo_task"
+}
+
+func (t *FixPacketMemoTask) Switch() bool {
+	return global.Config().Task.SwitchFixPacketMemoTask
+}
+
+func (t *FixPacketMemoTask) Run() int {
+	segments, err := getSegment(segmentStepLatest)
+	if err != nil {
+		logrus.Errorf("task %s getSegment error, %v", t.Name(), err)
+		return -1
+	}
+
+	historySegments, err := getHistorySegment(segmentStepHistory)
+	if err != nil {
+		logrus.Errorf("task %s getHistorySegment error, %v", t.Name(), err)
+		return -1
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		t.fixPacketMemo(ibcTxTargetLatest, segments)
+		logrus.Infof("task %s fix latest end", t.Name())
+	}()
+
+	go func() {
+		defer wg.Done()
+		t.fixPacketMemo(ibcTxTargetHistory, historySegments)
+		logrus.Infof("task %s fix history end", t.Name())
+	}()
+
+	wg.Wait()
+	return 1
+}
+
+func (t *FixPacketMemoTask) fixPacketMemo(target string, segments []*segment) {
+	const limit int64 = 1000
+	isTargetHistory := false
+	if target == ibcTxTargetHistory {
+		isTargetHistory = true
+	}
+
+	for _, v := range segments {
+		logrus.Infof("task %s fix %s %d-%d", t.Name(), target, v.StartTime, v.EndTime)
+		var skip int64 = 0
+		toBeFixedMemos := make(map[string]*model.PacketMemo)
+		for {
+			txs, err := ibcTxRepo.FindPacketMemoEmptyTxs(v.StartTime, v.EndTime, skip, limit, isTargetHistory)
+			if err != nil {
+				logrus.Errorf("task %s FindPacketMemoEmptyTxs %s %d-%d err, %v", t.Name(), target, v.StartTime, v.EndTime, err)
+				break
+			}
+
+			for _, tx := range txs {
+				if tx.ScTxInfo == nil || tx.ScTxInfo.Msg == nil {
+					continue
+				}
+				msg := tx.ScTxInfo.Msg.TransferMsg()
+				if memo := model.ParsePacketMemo(msg.Memo, msg.Receiver); memo != nil {
+					toBeFixedMemos[tx.RecordId] = memo
+				}
+			}
+
+			if int64(len(txs)) < limit {
+				break
+			}
+			skip += limit
+		}
+
+		for recordId, memo := range toBeFixedMemos {
+			if err := t.ibcTxWriter(t.Name()).UpdateOne(recordId, isTargetHistory, bson.M{"$set": bson.M{"packet_memo": memo}}); err != nil {
+				logrus.Errorf("task %s UpdateOne(%s) %s err, %v", t.Name(), recordId, target, err)
+			}
+		}
+	}
+}
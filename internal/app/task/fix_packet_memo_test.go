@@ -0,0 +1,15 @@
+package task
+
+import "testing"
+
+func Test_FixPacketMemo(t *testing.T) {
+	new(FixPacketMemoTask).Run()
+}
+
+func Test_FixPacketMemoDryRun(t *testing.T) {
+	task, err := NewDryRunTask(new(FixPacketMemoTask))
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.Run()
+}
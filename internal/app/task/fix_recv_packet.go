@@ -8,16 +8,17 @@ import (
 )
 
 type FixFailRecvPacketTask struct {
+	dryRunTrait
 }
 
-var _ OneOffTask = new(FixFailRecvPacketTask)
+var _ DryRunOneOffTask = new(FixFailRecvPacketTask)
 
 func (t *FixFailRecvPacketTask) Name() string {
 	return "fix_fail_recv_packet_task"
 }
 
 func (t *FixFailRecvPacketTask) Switch() bool {
-	return global.Config.Task.SwitchFixFailRecvPacketTask
+	return global.Config().Task.SwitchFixFailRecvPacketTask
 }
 
 func (t *FixFailRecvPacketTask) Run() int {
@@ -33,21 +34,25 @@ func (t *FixFailRecvPacketTask) Run() int {
 		return -1
 	}
 
+	var latestErr, historyErr error
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		err := t.fixFailRecvPacketTxs(ibcTxTargetLatest, segments)
-		logrus.Infof("task %s fix latest end, %v", t.Name(), err)
+		latestErr = t.fixFailRecvPacketTxs(ibcTxTargetLatest, segments)
+		logrus.Infof("task %s fix latest end, %v", t.Name(), latestErr)
 	}()
 
 	go func() {
 		defer wg.Done()
-		err := t.fixFailRecvPacketTxs(ibcTxTargetHistory, historySegments)
-		logrus.Infof("task %s fix history end, %v", t.Name(), err)
+		historyErr = t.fixFailRecvPacketTxs(ibcTxTargetHistory, historySegments)
+		logrus.Infof("task %s fix history end, %v", t.Name(), historyErr)
 	}()
 
 	wg.Wait()
+	if latestErr != nil || historyErr != nil {
+		return -1
+	}
 	return 1
 }
 
@@ -57,20 +62,20 @@ func (t *FixFailRecvPacketTask) fixFailRecvPacketTxs(target string, segments []*
 	if target == ibcTxTargetHistory {
 		isTargetHistory = true
 	}
-	doHandleSegments(t.Name(), 3, segments, isTargetHistory, func(seg *segment, isTargetHistory bool) {
+	return doHandleSegments(t.Name(), 3, segments, isTargetHistory, t.dryRun, func(seg *segment, isTargetHistory bool) error {
 		var skip int64 = 0
 		for {
 			txs, err := ibcTxRepo.FindRecvPacketTxsEmptyTxs(seg.StartTime, seg.EndTime, skip, limit, isTargetHistory)
 			if err != nil {
 				logrus.Errorf("task %s FindRecvPacketTxsEmptyTxs %s %d-%d err, %v", t.Name(), target, seg.StartTime, seg.EndTime, err)
-				return
+				return err
 			}
 
 			for _, val := range txs {
-				err := SaveRecvPacketTx(val, isTargetHistory)
+				err := SaveRecvPacketTx(t.ibcTxWriter(t.Name()), val, isTargetHistory)
 				if err != nil && err != qmgo.ErrNoSuchDocuments {
 					logrus.Errorf("task %s SaveRecvPacketTx %s err, chain_id: %s, packet_id: %s, %v", t.Name(), target, val.ScChainId, val.ScTxInfo.Msg.CommonMsg().PacketId, err)
-					return
+					return err
 				}
 			}
 
@@ -79,7 +84,6 @@ func (t *FixFailRecvPacketTask) fixFailRecvPacketTxs(target string, segments []*
 			}
 			skip += limit
 		}
+		return nil
 	})
-
-	return nil
 }
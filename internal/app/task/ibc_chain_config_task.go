@@ -1,15 +1,14 @@
 package task
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/datasource"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -27,8 +26,8 @@ func (t *IbcChainConfigTask) Name() string {
 	return "ibc_chain_config_task"
 }
 func (t *IbcChainConfigTask) Cron() int {
-	if taskConf.CronTimeChainConfigTask > 0 {
-		return taskConf.CronTimeChainConfigTask
+	if taskConf().CronTimeChainConfigTask > 0 {
+		return taskConf().CronTimeChainConfigTask
 	}
 	return EveryMinute
 }
@@ -48,7 +47,7 @@ func (t *IbcChainConfigTask) Run() int {
 		chain := v
 		go func() {
 			defer wg.Done()
-			channelPathList, err := t.getIbcChannels(chain.ChainId, chain.Lcd, chain.LcdApiPath.ChannelsPath)
+			channelPathList, err := t.getIbcChannels(chain)
 			if err != nil {
 				t.chainUpdateMap.Store(chain.ChainId, false) // 出错时，此链的信息将不会被更新
 			} else {
@@ -98,55 +97,35 @@ func (t *IbcChainConfigTask) getChainConf() ([]*entity.ChainConfig, error) {
 	return chainConfList, nil
 }
 
-// getIbcChannels 通过lcd channels_path 接口获取链上存在的所有channel信息
-func (t *IbcChainConfigTask) getIbcChannels(chainId, lcd, apiPath string) ([]*entity.ChannelPath, error) {
-	if lcd == "" {
-		logrus.Errorf("task %s %s getIbcChannels error, lcd error", t.Name(), chainId)
+// getIbcChannels 通过lcd channels 接口获取链上存在的所有channel信息
+func (t *IbcChainConfigTask) getIbcChannels(chain *entity.ChainConfig) ([]*entity.ChannelPath, error) {
+	if chain.Lcd == "" {
+		logrus.Errorf("task %s %s getIbcChannels error, lcd error", t.Name(), chain.ChainId)
 		return nil, fmt.Errorf("lcd error")
 	}
 
-	limit := 1000
-	offset := 0
-	var channelPathList []*entity.ChannelPath
-
-	for {
-		apiPath = strings.ReplaceAll(apiPath, replaceHolderOffset, strconv.Itoa(offset))
-		apiPath = strings.ReplaceAll(apiPath, replaceHolderLimit, strconv.Itoa(limit))
-		url := fmt.Sprintf("%s%s", lcd, apiPath)
-		bz, err := utils.HttpGet(url)
-		if err != nil {
-			logrus.Errorf("task %s %s getIbcChannels error, %v", t.Name(), chainId, err)
-			return nil, err
-		}
-
-		var resp vo.IbcChannelsResp
-		err = json.Unmarshal(bz, &resp)
-		if err != nil {
-			logrus.Errorf("task %s %s getIbcChannels error, %v", t.Name(), chainId, err)
-			return nil, err
-		}
-
-		for _, v := range resp.Channels {
-			channelPathList = append(channelPathList, &entity.ChannelPath{
-				State:     v.State,
-				PortId:    v.PortId,
-				ChannelId: v.ChannelId,
-				ChainId:   "",
-				ScChainId: chainId,
-				Counterparty: entity.CounterParty{
-					State:     "",
-					PortId:    v.Counterparty.PortId,
-					ChannelId: v.Counterparty.ChannelId,
-				},
-			})
-			k := fmt.Sprintf("%s%s%s%s%s", chainId, v.PortId, v.ChannelId, v.Counterparty.PortId, v.Counterparty.ChannelId)
-			t.channelStateMap.Store(k, v.State)
-		}
+	channels, err := datasource.Of(chain).Channels(context.Background())
+	if err != nil {
+		logrus.Errorf("task %s %s getIbcChannels error, %v", t.Name(), chain.ChainId, err)
+		return nil, err
+	}
 
-		if len(resp.Channels) < limit {
-			break
-		}
-		offset += limit
+	var channelPathList []*entity.ChannelPath
+	for _, v := range channels {
+		channelPathList = append(channelPathList, &entity.ChannelPath{
+			State:     v.State,
+			PortId:    v.PortId,
+			ChannelId: v.ChannelId,
+			ChainId:   "",
+			ScChainId: chain.ChainId,
+			Counterparty: entity.CounterParty{
+				State:     "",
+				PortId:    v.Counterparty.PortId,
+				ChannelId: v.Counterparty.ChannelId,
+			},
+		})
+		k := fmt.Sprintf("%s%s%s%s%s", chain.ChainId, v.PortId, v.ChannelId, v.Counterparty.PortId, v.Counterparty.ChannelId)
+		t.channelStateMap.Store(k, v.State)
 	}
 
 	return channelPathList, nil
@@ -177,7 +156,7 @@ func (t *IbcChainConfigTask) setChainIdAndCounterpartyState(chain *entity.ChainC
 			v.ClientId = existChannelState.ClientId
 		} else {
 			if !lcdConnectionErr { // 如果遇到lcd连接问题，则不再请求lcd.
-				stateResp, err := queryClientState(chain.Lcd, chain.LcdApiPath.ClientStatePath, v.PortId, v.ChannelId)
+				stateResp, err := queryClientState(chain, v.PortId, v.ChannelId)
 				if err != nil {
 					lcdConnectionErr = isConnectionErr(err)
 					logrus.Errorf("task %s %s queryClientState error, %v", t.Name(), chain.ChainId, err)
@@ -0,0 +1,101 @@
+package task
+
+import (
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+type ChainFlowStatisticsTask struct {
+}
+
+var chainFlowStatisticsTask ChainFlowStatisticsTask
+
+func (t *ChainFlowStatisticsTask) Name() string {
+	return "ibc_chain_flow_statistics_task"
+}
+
+func (t *ChainFlowStatisticsTask) Switch() bool {
+	return global.Config().Task.SwitchIbcChainFlowStatisticsTask
+}
+
+func (t *ChainFlowStatisticsTask) Run() int {
+	if err := chainFlowStatisticsRepo.CreateNew(); err != nil {
+		logrus.Errorf("task %s CreateNew err, %v", t.Name(), err)
+		return -1
+	}
+
+	segments, err := getRebuildSegments(segmentStepLatest, chainFlowStatisticsRepo.CopyToNew)
+	if err != nil {
+		logrus.Errorf("task %s getRebuildSegments err, %v", t.Name(), err)
+		return -1
+	}
+	logrus.Infof("task %s deal segment total: %d", t.Name(), len(segments))
+	if err = t.deal(segments, opInsert); err != nil {
+		logrus.Errorf("task %s deal err, %v", t.Name(), err)
+		return -1
+	}
+
+	if err = chainFlowStatisticsRepo.SwitchColl(); err != nil {
+		logrus.Errorf("task %s SwitchColl err, %v", t.Name(), err)
+		return -1
+	}
+
+	return 1
+}
+
+// deal 处理所有的记录，针对ex_ibc_tx_latest和ex_ibc_tx
+func (t *ChainFlowStatisticsTask) deal(segments []*segment, op int) error {
+	for _, v := range segments {
+		flows, err := ibcTxRepo.AggrChainFlowTxs(v.StartTime, v.EndTime)
+		if err != nil {
+			logrus.Errorf("task %s AggrChainFlowTxs err, %v", t.Name(), err)
+			return err
+		}
+
+		if len(flows) == 0 && op == opInsert {
+			continue
+		}
+
+		if err = t.saveData(flows, v.StartTime, v.EndTime, op); err != nil {
+			return err
+		}
+		logrus.Debugf("deal task %s scan ibc txs finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
+	}
+	return nil
+}
+
+func (t *ChainFlowStatisticsTask) saveData(flows []*dto.AggrChainFlowDTO, segmentStart, segmentEnd int64, op int) error {
+	var statistics = make([]*entity.IBCChainFlowStatistics, 0, len(flows))
+	for _, v := range flows {
+		statistics = append(statistics, &entity.IBCChainFlowStatistics{
+			ScChainId:        v.ScChainId,
+			DcChainId:        v.DcChainId,
+			BaseDenom:        v.BaseDenom,
+			BaseDenomChainId: v.BaseDenomChainId,
+			TransferTxs:      v.Count,
+			TransferAmount:   decimal.NewFromFloat(v.Amount).String(),
+			SegmentStartTime: segmentStart,
+			SegmentEndTime:   segmentEnd,
+			CreateAt:         time.Now().Unix(),
+			UpdateAt:         time.Now().Unix(),
+		})
+	}
+
+	var err error
+	if op == opInsert {
+		if err = chainFlowStatisticsRepo.BatchInsertToNew(statistics); err != nil {
+			logrus.Errorf("task %s chainFlowStatisticsRepo.BatchInsertToNew err, %v", t.Name(), err)
+		}
+	} else {
+		if err = chainFlowStatisticsRepo.BatchSwap(segmentStart, segmentEnd, statistics); err != nil {
+			logrus.Errorf("task %s chainFlowStatisticsRepo.BatchSwap err, %v", t.Name(), err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,74 @@
+package task
+
+import (
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/sirupsen/logrus"
+)
+
+// IbcChainFlowTask 更新今日和昨日的 ibc_chain_flow_statistics, 全量数据由 ChainFlowStatisticsTask 初始化
+type IbcChainFlowTask struct {
+}
+
+var _ Task = new(IbcChainFlowTask)
+
+func (t *IbcChainFlowTask) Name() string {
+	return "ibc_chain_flow_task"
+}
+
+func (t *IbcChainFlowTask) Cron() int {
+	if taskConf().CronTimeChainFlowTask > 0 {
+		return taskConf().CronTimeChainFlowTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcChainFlowTask) Run() int {
+	if err := t.todayStatistics(); err != nil {
+		return -1
+	}
+
+	_ = t.yesterdayStatistics()
+	return 1
+}
+
+func (t *IbcChainFlowTask) todayStatistics() error {
+	startTime, endTime := todayUnix()
+	segments := []*segment{
+		{
+			StartTime: startTime,
+			EndTime:   endTime,
+		},
+	}
+	if err := chainFlowStatisticsTask.deal(segments, opUpdate); err != nil {
+		logrus.Errorf("task %s todayStatistics error, %v", t.Name(), err)
+		return err
+	}
+
+	return nil
+}
+
+func (t *IbcChainFlowTask) yesterdayStatistics() error {
+	mmdd := time.Now().Format(constant.TimeFormatMMDD)
+	incr, _ := statisticsCheckRepo.GetIncr(t.Name(), mmdd)
+	if incr > statisticsCheckTimes {
+		return nil
+	}
+
+	logrus.Infof("task %s check yeaterday statistics, time: %d", t.Name(), incr)
+	startTime, endTime := yesterdayUnix()
+	segments := []*segment{
+		{
+			StartTime: startTime,
+			EndTime:   endTime,
+		},
+	}
+	if err := chainFlowStatisticsTask.deal(segments, opUpdate); err != nil {
+		logrus.Errorf("task %s yesterdayStatistics error, %v", t.Name(), err)
+		return err
+	}
+
+	_ = statisticsCheckRepo.Incr(t.Name(), mmdd)
+	return nil
+}
@@ -0,0 +1,223 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	chainRegistryChainJson = "chain.json"
+	chainRegistryAssetList = "assetlist.json"
+)
+
+// IbcChainRegistrySyncTask pull the chain.json and assetlist.json of the configured chains from the chain-registry, and
+// record the differences of the endpoints, the address prefix, the icons and the base denom metadata in
+// ibc_chain_registry_diff. The differences are applied directly if chain_registry_auto_apply is enabled, otherwise
+// they wait for approval.
+type IbcChainRegistrySyncTask struct {
+	diffMap       map[string]*entity.IBCChainRegistryDiff
+	changedSymbol []string
+}
+
+var _ Task = new(IbcChainRegistrySyncTask)
+
+func (t *IbcChainRegistrySyncTask) Name() string {
+	return "ibc_chain_registry_sync_task"
+}
+
+func (t *IbcChainRegistrySyncTask) Cron() int {
+	if taskConf().CronTimeChainRegistrySyncTask > 0 {
+		return taskConf().CronTimeChainRegistrySyncTask
+	}
+	return OneDay
+}
+
+func (t *IbcChainRegistrySyncTask) Run() int {
+	registries, err := chainRegistryRepo.FindAll()
+	if err != nil {
+		logrus.Errorf("task %s chainRegistryRepo.FindAll error, %v", t.Name(), err)
+		return -1
+	}
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+	baseDenoms, err := baseDenomRepo.FindAll()
+	if err != nil {
+		logrus.Errorf("task %s baseDenomRepo.FindAll error, %v", t.Name(), err)
+		return -1
+	}
+	diffs, err := chainRegistryDiffRepo.FindAll()
+	if err != nil {
+		logrus.Errorf("task %s chainRegistryDiffRepo.FindAll error, %v", t.Name(), err)
+		return -1
+	}
+
+	t.changedSymbol = nil
+	t.diffMap = make(map[string]*entity.IBCChainRegistryDiff, len(diffs))
+	for _, v := range diffs {
+		t.diffMap[v.DiffId] = v
+	}
+	chainBaseDenomMap := make(map[string][]*entity.IBCBaseDenom)
+	for _, v := range baseDenoms {
+		chainBaseDenomMap[v.ChainId] = append(chainBaseDenomMap[v.ChainId], v)
+	}
+
+	for _, registry := range registries {
+		cf, ok := chainMap[registry.ChainId]
+		if !ok || registry.ChainJsonUrl == "" {
+			continue
+		}
+		if err = t.syncChain(registry, cf); err != nil {
+			logrus.Errorf("task %s chain %s sync chain.json error, %v", t.Name(), registry.ChainId, err)
+			continue
+		}
+		if err = t.syncAssets(registry, chainBaseDenomMap[registry.ChainId]); err != nil {
+			logrus.Errorf("task %s chain %s sync assetlist.json error, %v", t.Name(), registry.ChainId, err)
+		}
+	}
+
+	if len(t.changedSymbol) > 0 {
+		if err = baseDenomCache.DelCache(t.changedSymbol...); err != nil {
+			logrus.Errorf("task %s delete base denom cache error, %v", t.Name(), err)
+		}
+	}
+	return 1
+}
+
+func (t *IbcChainRegistrySyncTask) syncChain(registry *entity.ChainRegistry, cf *entity.ChainConfig) error {
+	bz, err := utils.HttpGet(registry.ChainJsonUrl)
+	if err != nil {
+		return err
+	}
+	var chainRegisterResp vo.ChainRegisterResp
+	if err = json.Unmarshal(bz, &chainRegisterResp); err != nil {
+		return err
+	}
+
+	lcds := make([]string, 0, len(chainRegisterResp.Apis.Rest))
+	for _, v := range chainRegisterResp.Apis.Rest {
+		lcds = append(lcds, v.Address)
+	}
+	rpcs := make([]string, 0, len(chainRegisterResp.Apis.Rpc))
+	for _, v := range chainRegisterResp.Apis.Rpc {
+		rpcs = append(rpcs, v.Address)
+	}
+	icon := chainRegisterResp.LogoURIs.Png
+	if icon == "" {
+		icon = chainRegisterResp.LogoURIs.Svg
+	}
+
+	chainConfigColl := entity.ChainConfig{}.CollectionName()
+	chainRegistryColl := entity.ChainRegistry{}.CollectionName()
+	t.compare(registry.ChainId, chainConfigColl, "", "addr_prefix", cf.AddrPrefix, chainRegisterResp.Bech32Prefix)
+	t.compare(registry.ChainId, chainConfigColl, "", "icon", cf.Icon, icon)
+	t.compare(registry.ChainId, chainRegistryColl, "", "lcds", registry.Lcds, lcds)
+	t.compare(registry.ChainId, chainRegistryColl, "", "rpcs", registry.Rpcs, rpcs)
+	return nil
+}
+
+func (t *IbcChainRegistrySyncTask) syncAssets(registry *entity.ChainRegistry, baseDenoms []*entity.IBCBaseDenom) error {
+	if len(baseDenoms) == 0 || !strings.HasSuffix(registry.ChainJsonUrl, chainRegistryChainJson) {
+		return nil
+	}
+	assetListUrl := strings.TrimSuffix(registry.ChainJsonUrl, chainRegistryChainJson) + chainRegistryAssetList
+	bz, err := utils.HttpGet(assetListUrl)
+	if err != nil {
+		return err
+	}
+	var assetListResp vo.AssetListResp
+	if err = json.Unmarshal(bz, &assetListResp); err != nil {
+		return err
+	}
+
+	baseDenomMap := make(map[string]*entity.IBCBaseDenom, len(baseDenoms))
+	for _, v := range baseDenoms {
+		baseDenomMap[v.Denom] = v
+	}
+	baseDenomColl := entity.IBCBaseDenom{}.CollectionName()
+	for _, asset := range assetListResp.Assets {
+		baseDenom, ok := baseDenomMap[asset.Base]
+		if !ok {
+			continue
+		}
+
+		icon := asset.LogoURIs.Png
+		if icon == "" {
+			icon = asset.LogoURIs.Svg
+		}
+		changed := t.compare(registry.ChainId, baseDenomColl, baseDenom.Denom, "symbol", baseDenom.Symbol, asset.Symbol)
+		changed = t.compare(registry.ChainId, baseDenomColl, baseDenom.Denom, "coin_id", baseDenom.CoinId, asset.CoingeckoId) || changed
+		changed = t.compare(registry.ChainId, baseDenomColl, baseDenom.Denom, "icon", baseDenom.Icon, icon) || changed
+		for _, unit := range asset.DenomUnits {
+			if unit.Denom == asset.Display {
+				changed = t.compare(registry.ChainId, baseDenomColl, baseDenom.Denom, "scale", baseDenom.Scale, unit.Exponent) || changed
+				break
+			}
+		}
+		if changed {
+			t.changedSymbol = append(t.changedSymbol, baseDenom.Symbol, asset.Symbol)
+		}
+	}
+	return nil
+}
+
+// compare record the difference of the field, return true if the difference is applied
+func (t *IbcChainRegistrySyncTask) compare(chainId, coll, denom, field string, localValue, upstreamValue interface{}) bool {
+	upstream := fmt.Sprint(upstreamValue)
+	if upstream == "" || upstream == "[]" { // 上游没有配置的字段不做同步
+		return false
+	}
+
+	diffId := utils.Md5(fmt.Sprintf("%s|%s|%s|%s", chainId, coll, denom, field))
+	existing, ok := t.diffMap[diffId]
+	if fmt.Sprint(localValue) == upstream {
+		if ok && existing.Status == entity.ChainRegistryDiffStatusPending {
+			if err := chainRegistryDiffRepo.UpdateStatus(diffId, entity.ChainRegistryDiffStatusOutdated); err != nil {
+				logrus.Errorf("task %s update diff %s status error, %v", t.Name(), diffId, err)
+			}
+		}
+		return false
+	}
+	// 同样的差异已经待审核或被拒绝
+	if ok && fmt.Sprint(existing.NewValue) == upstream && (existing.Status == entity.ChainRegistryDiffStatusPending ||
+		existing.Status == entity.ChainRegistryDiffStatusRejected) {
+		return false
+	}
+
+	diff := &entity.IBCChainRegistryDiff{
+		DiffId:   diffId,
+		ChainId:  chainId,
+		Coll:     coll,
+		Denom:    denom,
+		Field:    field,
+		OldValue: localValue,
+		NewValue: upstreamValue,
+		Status:   entity.ChainRegistryDiffStatusPending,
+	}
+	if ok {
+		diff.CreateAt = existing.CreateAt
+	}
+	if err := chainRegistryDiffRepo.Save(diff); err != nil {
+		logrus.Errorf("task %s save diff %s error, %v", t.Name(), diffId, err)
+		return false
+	}
+	t.diffMap[diffId] = diff
+	logrus.Infof("task %s chain %s %s %s %s: %v => %v", t.Name(), chainId, coll, denom, field, localValue, upstreamValue)
+
+	if !taskConf().ChainRegistryAutoApply {
+		return false
+	}
+	if err := chainRegistryDiffRepo.Apply(diff); err != nil {
+		logrus.Errorf("task %s apply diff %s error, %v", t.Name(), diffId, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,7 @@
+package task
+
+import "testing"
+
+func Test_ChainRegistrySyncTask(t *testing.T) {
+	new(IbcChainRegistrySyncTask).Run()
+}
@@ -13,8 +13,8 @@ func (t *IbcChainCronTask) Name() string {
 	return "ibc_chain_task"
 }
 func (t *IbcChainCronTask) Cron() int {
-	if taskConf.CronTimeChainTask > 0 {
-		return taskConf.CronTimeChainTask
+	if taskConf().CronTimeChainTask > 0 {
+		return taskConf().CronTimeChainTask
 	}
 	return EveryMinute
 }
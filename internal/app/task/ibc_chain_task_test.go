@@ -32,7 +32,7 @@ func TestMain(m *testing.M) {
 	}, context.Background())
 
 	time.Local = time.UTC
-	global.Config = &conf.Config{
+	global.SetConfig(&conf.Config{
 		Task: conf.Task{
 			SingleChainSyncTransferTxMax:      1000,
 			SingleChainIbcTxRelateMax:         1000,
@@ -42,7 +42,7 @@ func TestMain(m *testing.M) {
 			FixDenomTraceHistoryDataEndTime:   1658830692,
 		},
 		ChainConfig: conf.ChainConfig{
-			NewChains: "qa_iris_snapshot"}}
+			NewChains: "qa_iris_snapshot"}})
 	m.Run()
 }
 
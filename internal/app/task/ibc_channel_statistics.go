@@ -20,7 +20,7 @@ func (t *ChannelStatisticsTask) Name() string {
 }
 
 func (t *ChannelStatisticsTask) Switch() bool {
-	return global.Config.Task.SwitchIbcChannelStatisticsTask
+	return global.Config().Task.SwitchIbcChannelStatisticsTask
 }
 
 func (t *ChannelStatisticsTask) Run() int {
@@ -29,20 +29,9 @@ func (t *ChannelStatisticsTask) Run() int {
 		return -1
 	}
 
-	historySegments, err := getHistorySegment(segmentStepHistory)
+	segments, err := getRebuildSegments(segmentStepLatest, channelStatisticsRepo.CopyToNew)
 	if err != nil {
-		logrus.Errorf("task %s getHistorySegment err, %v", t.Name(), err)
-		return -1
-	}
-	logrus.Infof("task %s deal history segment total: %d", t.Name(), len(historySegments))
-	if err = t.dealHistory(historySegments); err != nil {
-		logrus.Errorf("task %s dealHistory err, %v", t.Name(), err)
-		return -1
-	}
-
-	segments, err := getSegment(segmentStepLatest)
-	if err != nil {
-		logrus.Errorf("task %s getSegment err, %v", t.Name(), err)
+		logrus.Errorf("task %s getRebuildSegments err, %v", t.Name(), err)
 		return -1
 	}
 	logrus.Infof("task %s deal segment total: %d", t.Name(), len(segments))
@@ -59,29 +48,7 @@ func (t *ChannelStatisticsTask) Run() int {
 	return 1
 }
 
-// dealHistory 处理历史记录，针对ex_ibc_tx
-func (t *ChannelStatisticsTask) dealHistory(segments []*segment) error {
-	for _, v := range segments {
-		txs, err := ibcTxRepo.AggrIBCChannelHistoryTxs(v.StartTime, v.EndTime)
-		if err != nil {
-			logrus.Errorf("task %s AggrIBCChannelHistoryTxs err, %v", t.Name(), err)
-			return err
-		}
-
-		if len(txs) == 0 {
-			continue
-		}
-
-		aggr := t.aggr(txs)
-		if err = t.saveData(aggr, v.StartTime, v.EndTime, opInsert); err != nil {
-			return err
-		}
-		logrus.Debugf("dealHistory task %s scan ex_ibc_tx finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
-	}
-	return nil
-}
-
-// deal 处理最新的记录，针对ex_ibc_tx_latest
+// deal 处理所有的记录，针对ex_ibc_tx_latest和ex_ibc_tx
 func (t *ChannelStatisticsTask) deal(segments []*segment, op int) error {
 	for _, v := range segments {
 		txs, err := ibcTxRepo.AggrIBCChannelTxs(v.StartTime, v.EndTime)
@@ -98,7 +65,7 @@ func (t *ChannelStatisticsTask) deal(segments []*segment, op int) error {
 		if err = t.saveData(aggr, v.StartTime, v.EndTime, op); err != nil {
 			return err
 		}
-		logrus.Debugf("deal task %s scan ex_ibc_tx_latest finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
+		logrus.Debugf("deal task %s scan ibc txs finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
 	}
 	return nil
 }
@@ -111,6 +78,8 @@ func (t *ChannelStatisticsTask) aggr(txs []*dto.AggrIBCChannelTxsDTO) []*dto.Cha
 		for _, c := range cl {
 			if c.ChannelId == ChannelId && v.BaseDenom == c.BaseDenom && v.BaseDenomChainId == c.BaseDenomChainId { // 同一个channel
 				c.TxsCount += v.Count
+				c.SuccessCount += v.SuccessCount
+				c.RefundedCount += v.RefundedCount
 				c.TxsAmount = c.TxsAmount.Add(decimal.NewFromFloat(v.Amount))
 				isExisted = true
 				break
@@ -123,6 +92,8 @@ func (t *ChannelStatisticsTask) aggr(txs []*dto.AggrIBCChannelTxsDTO) []*dto.Cha
 				BaseDenom:        v.BaseDenom,
 				BaseDenomChainId: v.BaseDenomChainId,
 				TxsCount:         v.Count,
+				SuccessCount:     v.SuccessCount,
+				RefundedCount:    v.RefundedCount,
 				TxsAmount:        decimal.NewFromFloat(v.Amount),
 			})
 		}
@@ -140,6 +111,8 @@ func (t *ChannelStatisticsTask) saveData(dtoList []*dto.ChannelStatisticsDTO, se
 			BaseDenomChainId: v.BaseDenomChainId,
 			TransferTxs:      v.TxsCount,
 			TransferAmount:   v.TxsAmount.String(),
+			SuccessTxs:       v.SuccessCount,
+			RefundedTxs:      v.RefundedCount,
 			SegmentStartTime: segmentStart,
 			SegmentEndTime:   segmentEnd,
 			CreateAt:         time.Now().Unix(),
@@ -18,6 +18,7 @@ import (
 type ChannelTask struct {
 	allChannelIds    []string
 	channelStatusMap map[string]entity.ChannelStatus
+	channelTypeMap   map[string]string
 	baseDenomMap     entity.IBCBaseDenomMap // 所有的base denom
 	chainTxsMap      map[string]int64
 	chainTxsValueMap map[string]decimal.Decimal
@@ -28,8 +29,8 @@ func (t *ChannelTask) Name() string {
 }
 
 func (t *ChannelTask) Cron() int {
-	if taskConf.CronTimeChannelTask > 0 {
-		return taskConf.CronTimeChannelTask
+	if taskConf().CronTimeChannelTask > 0 {
+		return taskConf().CronTimeChannelTask
 	}
 	return ThreeMinute
 }
@@ -108,6 +109,7 @@ func (t *ChannelTask) analyzeChainConfig() error {
 
 	var channelIds []string
 	channelStatusMap := make(map[string]entity.ChannelStatus)
+	channelTypeMap := make(map[string]string)
 
 	var chainA, channelA, chainB, channelB string
 	for _, v := range confList {
@@ -124,6 +126,7 @@ func (t *ChannelTask) analyzeChainConfig() error {
 				}
 
 				channelIds = append(channelIds, channelId)
+				channelTypeMap[channelId] = entity.GetChannelType(p.PortId, p.Counterparty.PortId)
 				if p.State == constant.ChannelStateOpen && p.Counterparty.State == constant.ChannelStateOpen {
 					channelStatusMap[channelId] = entity.ChannelStatusOpened
 				} else {
@@ -135,6 +138,7 @@ func (t *ChannelTask) analyzeChainConfig() error {
 
 	t.allChannelIds = channelIds
 	t.channelStatusMap = channelStatusMap
+	t.channelTypeMap = channelTypeMap
 	return nil
 }
 
@@ -184,6 +188,7 @@ func (t *ChannelTask) getAllChannel() (entity.IBCChannelList, entity.IBCChannelL
 		isExist := false
 		for _, e := range existedChannelList {
 			if v == e.ChannelId {
+				e.ChannelType = t.channelTypeMap[v]
 				stillExistChannelList = append(stillExistChannelList, e)
 				isExist = true
 				break
@@ -212,6 +217,7 @@ func (t *ChannelTask) getAllChannel() (entity.IBCChannelList, entity.IBCChannelL
 			Relayers:         0,
 			TransferTxs:      0,
 			TransferTxsValue: "",
+			ChannelType:      t.channelTypeMap[v],
 			CreateAt:         time.Now().Unix(),
 			UpdateAt:         time.Now().Unix(),
 		})
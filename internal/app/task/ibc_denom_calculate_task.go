@@ -21,8 +21,8 @@ func (t *IbcDenomCalculateTask) Name() string {
 }
 
 func (t *IbcDenomCalculateTask) Cron() int {
-	if taskConf.CronTimeDenomCalculateTask > 0 {
-		return taskConf.CronTimeDenomCalculateTask
+	if taskConf().CronTimeDenomCalculateTask > 0 {
+		return taskConf().CronTimeDenomCalculateTask
 	}
 	return EveryMinute
 }
@@ -16,8 +16,8 @@ func (t *IbcDenomUpdateTask) Name() string {
 }
 
 func (t *IbcDenomUpdateTask) Cron() int {
-	if taskConf.CronTimeDenomUpdateTask > 0 {
-		return taskConf.CronTimeDenomUpdateTask
+	if taskConf().CronTimeDenomUpdateTask > 0 {
+		return taskConf().CronTimeDenomUpdateTask
 	}
 	return ThreeMinute
 }
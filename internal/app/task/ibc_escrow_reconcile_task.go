@@ -0,0 +1,277 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/datasource"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultEscrowMismatchAlertTimes = 3
+
+// IbcEscrowReconcileTask reconcile the escrow of the ics-20 channels. For each denom escrowed in the escrow address of
+// the channel, the escrowed amount must be equal to the supply of the voucher minted on the counterparty chain.
+// The packets in flight cause transient divergence, so the alert is raised only if the divergence lasts for
+// escrow_mismatch_alert_times checks.
+type IbcEscrowReconcileTask struct {
+	chainMap     map[string]*entity.ChainConfig
+	supplyMap    map[string]map[string]decimal.Decimal // chain id => denom => supply
+	denomPathMap map[string]map[string]string          // chain id => ibc denom => full path
+	reconcileMap map[string]*entity.IBCEscrowReconcile
+}
+
+var _ Task = new(IbcEscrowReconcileTask)
+
+func (t *IbcEscrowReconcileTask) Name() string {
+	return "ibc_escrow_reconcile_task"
+}
+
+func (t *IbcEscrowReconcileTask) Cron() int {
+	if taskConf().CronTimeEscrowReconcileTask > 0 {
+		return taskConf().CronTimeEscrowReconcileTask
+	}
+	return EveryHour
+}
+
+func (t *IbcEscrowReconcileTask) Run() int {
+	if err := t.init(); err != nil {
+		logrus.Errorf("task %s init error, %v", t.Name(), err)
+		return -1
+	}
+
+	checkTime := time.Now().Unix()
+	for chainId, cf := range t.chainMap {
+		if cf.Status == entity.ChainStatusClosed {
+			continue
+		}
+		for _, info := range cf.IbcInfo {
+			for _, path := range info.Paths {
+				if path.PortId != constant.PortTransfer {
+					continue
+				}
+				if err := t.reconcileChannel(cf, info.ChainId, path, checkTime); err != nil {
+					logrus.Errorf("task %s chain %s channel %s reconcile error, %v", t.Name(), chainId, path.ChannelId, err)
+				}
+			}
+		}
+	}
+
+	return 1
+}
+
+func (t *IbcEscrowReconcileTask) init() error {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		return err
+	}
+	reconciles, err := escrowReconcileRepo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	t.chainMap = chainMap
+	t.supplyMap = make(map[string]map[string]decimal.Decimal)
+	t.denomPathMap = make(map[string]map[string]string)
+	t.reconcileMap = make(map[string]*entity.IBCEscrowReconcile, len(reconciles))
+	for _, v := range reconciles {
+		t.reconcileMap[escrowReconcileKey(v.ChainId, v.ChannelId, v.Denom)] = v
+	}
+	return nil
+}
+
+func (t *IbcEscrowReconcileTask) reconcileChannel(cf *entity.ChainConfig, counterpartyChainId string, path *entity.ChannelPath, checkTime int64) error {
+	counterparty, ok := t.chainMap[counterpartyChainId]
+	if !ok || counterparty.Status == entity.ChainStatusClosed {
+		return nil
+	}
+
+	escrowAddress, err := calculateEscrowAddress(path.PortId, path.ChannelId, cf.AddrPrefix)
+	if err != nil {
+		return err
+	}
+	balances, err := getBalancesFromLcd(cf, escrowAddress)
+	if err != nil {
+		return err
+	}
+	supplies, err := t.getSupply(counterparty)
+	if err != nil {
+		return err
+	}
+
+	// 之前托管过但已全部取出的denom, 托管数量按0核对
+	for _, v := range t.reconcileMap {
+		if v.ChainId == cf.ChainId && v.ChannelId == path.ChannelId {
+			if _, ok := balances[v.Denom]; !ok {
+				balances[v.Denom] = decimal.Zero
+			}
+		}
+	}
+
+	var discrepancies []*entity.IBCEscrowDiscrepancy
+	for denom, escrowAmount := range balances {
+		fullPath, err := t.getDenomFullPath(cf.ChainId, denom)
+		if err != nil {
+			return err
+		}
+		if fullPath == "" {
+			logrus.Warnf("task %s chain %s denom %s no found denom trace", t.Name(), cf.ChainId, denom)
+			continue
+		}
+		voucherPath, isCrossBack := calculateNextPath(path.PortId, path.ChannelId, path.Counterparty.PortId, path.Counterparty.ChannelId, fullPath)
+		if isCrossBack { // 从对手链转入的voucher不会被托管
+			continue
+		}
+		voucherDenom := calculateIbcHash(voucherPath)
+		voucherSupply := supplies[voucherDenom]
+
+		reconcile := t.reconcile(cf.ChainId, path, counterpartyChainId, escrowAddress, denom, fullPath, voucherDenom,
+			escrowAmount, voucherSupply, checkTime)
+		if err = escrowReconcileRepo.Save(reconcile); err != nil {
+			return err
+		}
+		alerting := float64(0)
+		if reconcile.Alerting {
+			alerting = 1
+		}
+		monitor.SetEscrowMismatchMetricValue(cf.ChainId, path.ChannelId, denom, alerting)
+
+		if reconcile.Status == entity.EscrowReconcileStatusMismatched {
+			discrepancies = append(discrepancies, &entity.IBCEscrowDiscrepancy{
+				ChainId:             reconcile.ChainId,
+				ChannelId:           reconcile.ChannelId,
+				Denom:               reconcile.Denom,
+				CounterpartyChainId: reconcile.CounterpartyChainId,
+				VoucherDenom:        reconcile.VoucherDenom,
+				EscrowAmount:        reconcile.EscrowAmount,
+				VoucherSupply:       reconcile.VoucherSupply,
+				Diff:                reconcile.Diff,
+				MismatchTimes:       reconcile.MismatchTimes,
+				CheckTime:           checkTime,
+			})
+		}
+	}
+
+	return escrowDiscrepancyRepo.InsertMany(discrepancies)
+}
+
+// reconcile compare the escrowed amount with the voucher supply, the consecutive mismatch times are accumulated
+func (t *IbcEscrowReconcileTask) reconcile(chainId string, path *entity.ChannelPath, counterpartyChainId, escrowAddress, denom,
+	fullPath, voucherDenom string, escrowAmount, voucherSupply decimal.Decimal, checkTime int64) *entity.IBCEscrowReconcile {
+	key := escrowReconcileKey(chainId, path.ChannelId, denom)
+	reconcile, ok := t.reconcileMap[key]
+	if !ok {
+		reconcile = &entity.IBCEscrowReconcile{}
+		t.reconcileMap[key] = reconcile
+	}
+
+	reconcile.ChainId = chainId
+	reconcile.PortId = path.PortId
+	reconcile.ChannelId = path.ChannelId
+	reconcile.EscrowAddress = escrowAddress
+	reconcile.Denom = denom
+	reconcile.DenomFullPath = fullPath
+	reconcile.CounterpartyChainId = counterpartyChainId
+	reconcile.CounterpartyChannelId = path.Counterparty.ChannelId
+	reconcile.VoucherDenom = voucherDenom
+	reconcile.EscrowAmount = escrowAmount.String()
+	reconcile.VoucherSupply = voucherSupply.String()
+	diff := escrowAmount.Sub(voucherSupply)
+	reconcile.Diff = diff.String()
+	reconcile.CheckTime = checkTime
+
+	if diff.IsZero() {
+		reconcile.Status = entity.EscrowReconcileStatusMatched
+		reconcile.MismatchTimes = 0
+		reconcile.FirstMismatchTime = 0
+	} else {
+		if reconcile.Status != entity.EscrowReconcileStatusMismatched {
+			reconcile.FirstMismatchTime = checkTime
+		}
+		reconcile.Status = entity.EscrowReconcileStatusMismatched
+		reconcile.MismatchTimes++
+	}
+
+	alertTimes := taskConf().EscrowMismatchAlertTimes
+	if alertTimes <= 0 {
+		alertTimes = defaultEscrowMismatchAlertTimes
+	}
+	reconcile.Alerting = reconcile.MismatchTimes >= alertTimes
+	return reconcile
+}
+
+func (t *IbcEscrowReconcileTask) getSupply(cf *entity.ChainConfig) (map[string]decimal.Decimal, error) {
+	if supply, ok := t.supplyMap[cf.ChainId]; ok {
+		return supply, nil
+	}
+	supply, err := getSupplyFromLcd(cf)
+	if err != nil {
+		return nil, err
+	}
+	t.supplyMap[cf.ChainId] = supply
+	return supply, nil
+}
+
+// getDenomFullPath the full path of the ibc denom is traced from ibc_denom, the native denom is the full path itself
+func (t *IbcEscrowReconcileTask) getDenomFullPath(chainId, denom string) (string, error) {
+	if !strings.HasPrefix(denom, constant.IBCTokenPrefix) {
+		return denom, nil
+	}
+
+	pathMap, ok := t.denomPathMap[chainId]
+	if !ok {
+		denomList, err := denomRepo.FindByChainId(chainId)
+		if err != nil {
+			return "", err
+		}
+		pathMap = make(map[string]string, len(denomList))
+		for _, v := range denomList {
+			if v.DenomPath != "" {
+				pathMap[v.Denom] = fmt.Sprintf("%s/%s", v.DenomPath, v.RootDenom)
+			}
+		}
+		t.denomPathMap[chainId] = pathMap
+	}
+	return pathMap[denom], nil
+}
+
+func escrowReconcileKey(chainId, channelId, denom string) string {
+	return fmt.Sprintf("%s|%s|%s", chainId, channelId, denom)
+}
+
+// getBalancesFromLcd query all the balances of the address
+func getBalancesFromLcd(cf *entity.ChainConfig, address string) (map[string]decimal.Decimal, error) {
+	balances, err := datasource.Of(cf).Balances(context.Background(), address)
+	if err != nil {
+		return nil, err
+	}
+	return sumLcdCoins(balances)
+}
+
+// getSupplyFromLcd query the supply of all the denoms on the chain
+func getSupplyFromLcd(cf *entity.ChainConfig) (map[string]decimal.Decimal, error) {
+	supply, err := datasource.Of(cf).Supply(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return sumLcdCoins(supply)
+}
+
+func sumLcdCoins(coins []vo.LcdCoin) (map[string]decimal.Decimal, error) {
+	res := make(map[string]decimal.Decimal, len(coins))
+	for _, v := range coins {
+		amount, err := decimal.NewFromString(v.Amount)
+		if err != nil {
+			return nil, err
+		}
+		res[v.Denom] = res[v.Denom].Add(amount)
+	}
+	return res, nil
+}
@@ -0,0 +1,7 @@
+package task
+
+import "testing"
+
+func Test_EscrowReconcileTask(t *testing.T) {
+	new(IbcEscrowReconcileTask).Run()
+}
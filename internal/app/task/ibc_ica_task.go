@@ -0,0 +1,341 @@
+package task
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/qiniu/qmgo"
+	"github.com/sirupsen/logrus"
+)
+
+// IbcIcaTask index the ics-27 interchain accounts and their packets.
+//   - accounts: channel_open_ack of the controller chain, the ica address is in the counterparty version
+//   - send: send_packet of MsgSendTx on the controller chain, the packet is pending until it's received or timed out
+//   - host: recv_packet of the host chain, with the execution result in the ack
+//   - controller: acknowledge_packet and timeout_packet of the controller chain
+type IbcIcaTask struct {
+	chainMap map[string]*entity.ChainConfig
+}
+
+var _ Task = new(IbcIcaTask)
+
+func (t *IbcIcaTask) Name() string {
+	return "ibc_ica_task"
+}
+
+func (t *IbcIcaTask) Cron() int {
+	if taskConf().CronTimeIcaTask > 0 {
+		return taskConf().CronTimeIcaTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcIcaTask) Run() int {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+	t.chainMap = chainMap
+
+	for chainId, cf := range chainMap {
+		if cf.Status == entity.ChainStatusClosed {
+			continue
+		}
+
+		if err = t.syncPortTx(chainId, entity.IcaAccountTaskNameFmt, []string{constant.MsgTypeChannelOpenAck},
+			"port_id", constant.PortIcaController, t.handleAccountTx); err != nil {
+			logrus.Errorf("task %s sync chain %s ica account error, %v", t.Name(), chainId, err)
+		}
+		if err = syncTxByHeight(fmt.Sprintf(entity.IcaSendTaskNameFmt, chainId),
+			func(height, limit int64) ([]*entity.Tx, error) {
+				return txRepo.GetEventTx(chainId, "send_packet", height, limit)
+			},
+			func(height int64) ([]*entity.Tx, error) {
+				return txRepo.FindEventTxByHeight(chainId, "send_packet", height)
+			},
+			func(txList []*entity.Tx) error {
+				return t.handleSendTx(chainId, txList)
+			}); err != nil {
+			logrus.Errorf("task %s sync chain %s ica send tx error, %v", t.Name(), chainId, err)
+		}
+		if err = t.syncPortTx(chainId, entity.IcaHostTaskNameFmt, []string{constant.MsgTypeRecvPacket},
+			"packet.destination_port", constant.PortIcaHost, t.handleHostTx); err != nil {
+			logrus.Errorf("task %s sync chain %s ica host tx error, %v", t.Name(), chainId, err)
+		}
+		if err = t.syncPortTx(chainId, entity.IcaControllerTaskNameFmt, []string{constant.MsgTypeAcknowledgement, constant.MsgTypeTimeoutPacket},
+			"packet.source_port", constant.PortIcaController, t.handleControllerTx); err != nil {
+			logrus.Errorf("task %s sync chain %s ica controller tx error, %v", t.Name(), chainId, err)
+		}
+	}
+
+	return 1
+}
+
+// syncPortTx sync the txs of the msg types whose port matches the prefix, from the height of the task record
+func (t *IbcIcaTask) syncPortTx(chainId, taskNameFmt string, txTypes []string, portKey, portPrefix string,
+	handle func(chainId string, txList []*entity.Tx) error) error {
+	return syncTxByHeight(fmt.Sprintf(taskNameFmt, chainId),
+		func(height, limit int64) ([]*entity.Tx, error) {
+			return txRepo.GetPortTx(chainId, txTypes, portKey, portPrefix, height, limit)
+		},
+		func(height int64) ([]*entity.Tx, error) {
+			return txRepo.FindPortTxByHeight(chainId, txTypes, portKey, portPrefix, height)
+		},
+		func(txList []*entity.Tx) error {
+			return handle(chainId, txList)
+		})
+}
+
+func (t *IbcIcaTask) handleAccountTx(chainId string, txList []*entity.Tx) error {
+	for _, tx := range txList {
+		if tx.Status != entity.TxStatusSuccess {
+			continue
+		}
+		for _, msg := range tx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeChannelOpenAck {
+				continue
+			}
+			ackMsg := msg.ChannelOpenAckMsg()
+			if !model.IsIcaPort(ackMsg.PortId) {
+				continue
+			}
+			metadata := model.ParseIcaMetadata(ackMsg.CounterpartyVersion)
+			if metadata == nil {
+				continue
+			}
+
+			hostChainId, hostPort, _ := matchDcInfo(chainId, ackMsg.PortId, ackMsg.ChannelId, t.chainMap)
+			if hostPort == "" {
+				hostPort = constant.PortIcaHost
+			}
+			account := &entity.IBCIcaAccount{
+				ControllerChainId:      chainId,
+				ControllerPort:         ackMsg.PortId,
+				ControllerChannel:      ackMsg.ChannelId,
+				ControllerConnectionId: metadata.ControllerConnectionId,
+				Owner:                  model.IcaOwner(ackMsg.PortId),
+				HostChainId:            hostChainId,
+				HostPort:               hostPort,
+				HostChannel:            ackMsg.CounterpartyChannelId,
+				HostConnectionId:       metadata.HostConnectionId,
+				IcaAddress:             metadata.Address,
+				RegisterTxHash:         tx.TxHash,
+				RegisterTime:           tx.Time,
+			}
+			if err := icaAccountRepo.Save(account); err != nil {
+				return err
+			}
+			if hostChainId != "" {
+				if err := icaTxRepo.UpdateIcaAddress(hostChainId, account.HostChannel, account.IcaAddress); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// handleSendTx the send_packet events of the controller ports, the msg of the event is usually MsgSendTx of ics-27, or
+// the msg of the contract or the module which sends the packet by the interchain account
+func (t *IbcIcaTask) handleSendTx(chainId string, txList []*entity.Tx) error {
+	icaAddressMap := make(map[string]string)
+	for _, tx := range txList {
+		if tx.Status != entity.TxStatusSuccess {
+			continue
+		}
+		for _, evts := range tx.EventsNew {
+			for _, evt := range evts.Events {
+				if evt.Type != "send_packet" {
+					continue
+				}
+				packet := parseSendPacketEvent(evt)
+				if !strings.HasPrefix(packet.ScPort, constant.PortIcaController) || packet.ScChannel == "" || packet.Sequence == "" {
+					continue
+				}
+				hostChainId, _, _ := matchDcInfo(chainId, packet.ScPort, packet.ScChannel, t.chainMap)
+				if hostChainId == "" {
+					logrus.Warnf("task %s chain %s ica packet %s/%s/%s no found host chain", t.Name(), chainId, packet.ScPort,
+						packet.ScChannel, packet.Sequence)
+					continue
+				}
+				icaAddress, err := t.getIcaAddress(icaAddressMap, hostChainId, packet.DcChannel)
+				if err != nil {
+					return err
+				}
+
+				var msg *model.TxMsg
+				if int(evts.MsgIndex) < len(tx.DocTxMsgs) {
+					msg = tx.DocTxMsgs[evts.MsgIndex]
+				}
+				packetId := fmt.Sprintf("%s%s%s%s%s", packet.ScPort, packet.ScChannel, packet.DcPort, packet.DcChannel, packet.Sequence)
+				data := model.ParseIcaPacketData(sendPacketData(packet))
+				icaTx := &entity.ExIbcIcaTx{
+					RecordId:          icaRecordId(hostChainId, packetId),
+					PacketId:          packetId,
+					Sequence:          packet.Sequence,
+					ControllerChainId: chainId,
+					ControllerPort:    packet.ScPort,
+					ControllerChannel: packet.ScChannel,
+					Owner:             model.IcaOwner(packet.ScPort),
+					HostChainId:       hostChainId,
+					HostPort:          packet.DcPort,
+					HostChannel:       packet.DcChannel,
+					IcaAddress:        icaAddress,
+					PacketType:        data.Type,
+					MsgTypes:          data.MsgTypes,
+					Memo:              data.Memo,
+					SendTxInfo:        newTxInfo(tx, msg),
+					TxTime:            tx.Time,
+				}
+				if err = icaTxRepo.UpsertSendTx(icaTx); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sendPacketData the packet data of the send_packet event, packet_data_hex is used if packet_data is absent
+func sendPacketData(packet *entity.ExIbcPacket) []byte {
+	if packet.Data != "" {
+		return []byte(packet.Data)
+	}
+	bz, _ := hex.DecodeString(packet.DataHex)
+	return bz
+}
+
+func (t *IbcIcaTask) handleHostTx(chainId string, txList []*entity.Tx) error {
+	icaAddressMap := make(map[string]string)
+	for _, tx := range txList {
+		if tx.Status != entity.TxStatusSuccess {
+			continue
+		}
+		for msgIndex, msg := range tx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeRecvPacket {
+				continue
+			}
+			packet := msg.IcaPacket()
+			if packet.DestinationPort != constant.PortIcaHost {
+				continue
+			}
+			_, packetAck, existPacketAck := parseRecvPacketTxEvents(msgIndex, tx)
+			if !existPacketAck { // 重复relay的recv_packet
+				continue
+			}
+
+			icaAddress, err := t.getIcaAddress(icaAddressMap, chainId, packet.DestinationChannel)
+			if err != nil {
+				return err
+			}
+			controllerChainId, _, _ := matchDcInfo(chainId, packet.DestinationPort, packet.DestinationChannel, t.chainMap)
+			packetId := msg.CommonMsg().PacketId
+			data := packet.PacketData()
+			icaTx := &entity.ExIbcIcaTx{
+				RecordId:          icaRecordId(chainId, packetId),
+				PacketId:          packetId,
+				Sequence:          strconv.FormatInt(packet.Sequence, 10),
+				ControllerChainId: controllerChainId,
+				ControllerPort:    packet.SourcePort,
+				ControllerChannel: packet.SourceChannel,
+				Owner:             model.IcaOwner(packet.SourcePort),
+				HostChainId:       chainId,
+				HostPort:          packet.DestinationPort,
+				HostChannel:       packet.DestinationChannel,
+				IcaAddress:        icaAddress,
+				Status:            entity.IcaTxStatusSuccess,
+				PacketType:        data.Type,
+				MsgTypes:          data.MsgTypes,
+				Memo:              data.Memo,
+				ErrorLog:          model.AckError(packetAck),
+				HostTxInfo:        newTxInfo(tx, msg),
+				TxTime:            tx.Time,
+			}
+			if icaTx.ErrorLog != "" {
+				icaTx.Status = entity.IcaTxStatusFailed
+			}
+			if err = icaTxRepo.UpsertHostTx(icaTx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *IbcIcaTask) handleControllerTx(chainId string, txList []*entity.Tx) error {
+	for _, tx := range txList {
+		if tx.Status != entity.TxStatusSuccess {
+			continue
+		}
+		for _, msg := range tx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeAcknowledgement && msg.Type != constant.MsgTypeTimeoutPacket {
+				continue
+			}
+			packet := msg.IcaPacket()
+			if !model.IsIcaPort(packet.SourcePort) {
+				continue
+			}
+			hostChainId, _, _ := matchDcInfo(chainId, packet.SourcePort, packet.SourceChannel, t.chainMap)
+			if hostChainId == "" {
+				logrus.Warnf("task %s chain %s ica packet %s/%s/%d no found host chain", t.Name(), chainId, packet.SourcePort,
+					packet.SourceChannel, packet.Sequence)
+				continue
+			}
+
+			packetId := msg.CommonMsg().PacketId
+			icaTx := &entity.ExIbcIcaTx{
+				RecordId:          icaRecordId(hostChainId, packetId),
+				PacketId:          packetId,
+				Sequence:          strconv.FormatInt(packet.Sequence, 10),
+				ControllerChainId: chainId,
+				ControllerPort:    packet.SourcePort,
+				ControllerChannel: packet.SourceChannel,
+				Owner:             model.IcaOwner(packet.SourcePort),
+				HostChainId:       hostChainId,
+				HostPort:          packet.DestinationPort,
+				HostChannel:       packet.DestinationChannel,
+			}
+			if msg.Type == constant.MsgTypeTimeoutPacket {
+				icaTx.Status = entity.IcaTxStatusTimeout
+				icaTx.TimeoutTxInfo = newTxInfo(tx, msg)
+				icaTx.TxTime = tx.Time
+			} else {
+				icaTx.Status = entity.IcaTxStatusSuccess
+				icaTx.ErrorLog = model.AckError(msg.AckPacketMsg().Acknowledgement)
+				if icaTx.ErrorLog != "" {
+					icaTx.Status = entity.IcaTxStatusFailed
+				}
+				icaTx.AckTxInfo = newTxInfo(tx, msg)
+			}
+			if err := icaTxRepo.UpsertControllerTx(icaTx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *IbcIcaTask) getIcaAddress(icaAddressMap map[string]string, hostChainId, hostChannel string) (string, error) {
+	if address, ok := icaAddressMap[hostChannel]; ok {
+		return address, nil
+	}
+
+	account, err := icaAccountRepo.FindByHostChannel(hostChainId, hostChannel)
+	if err != nil && err != qmgo.ErrNoSuchDocuments {
+		return "", err
+	}
+	icaAddressMap[hostChannel] = account.IcaAddress
+	return account.IcaAddress, nil
+}
+
+// icaRecordId the packet is identified by the packet id on the host chain
+func icaRecordId(hostChainId, packetId string) string {
+	return utils.Md5(fmt.Sprintf("%s%s", hostChainId, packetId))
+}
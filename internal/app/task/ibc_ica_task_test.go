@@ -0,0 +1,7 @@
+package task
+
+import "testing"
+
+func Test_IcaTask(t *testing.T) {
+	new(IbcIcaTask).Run()
+}
@@ -0,0 +1,208 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/datasource"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/ingest"
+	"github.com/qiniu/qmgo"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ingestWorkerId         = "ibc_ingest_task"
+	ingestDefaultMaxBlocks = 300
+	ingestBatchBlocks      = 20 // the blocks saved together, the checkpoint is advanced after every batch
+)
+
+// IbcIngestTask pull the blocks and the ibc txs of the ingest_chains from the rpc of the chains into
+// sync_<chain>_block and sync_<chain>_tx, so the explorer can run without the external sync service. The last
+// ingested height of a chain is kept in ibc_task_checkpoint, and a following task is saved in sync_<chain>_task as the
+// sync service does, which is checked by the transfer tx task.
+type IbcIngestTask struct {
+}
+
+var _ Task = new(IbcIngestTask)
+
+func (t *IbcIngestTask) Name() string {
+	return "ibc_ingest_task"
+}
+
+func (t *IbcIngestTask) Cron() int {
+	if taskConf().CronTimeIngestTask > 0 {
+		return taskConf().CronTimeIngestTask
+	}
+	return EveryMinute
+}
+
+func (t *IbcIngestTask) Run() int {
+	chainIds := t.chainIds()
+	if len(chainIds) == 0 {
+		return 1
+	}
+
+	var failed bool
+	var mu sync.Mutex
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(len(chainIds))
+	for _, v := range chainIds {
+		go func(chainId string) {
+			defer waitGroup.Done()
+			if err := t.ingestChain(chainId); err != nil {
+				logrus.Errorf("task %s chain %s ingest error, %v", t.Name(), chainId, err)
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(v)
+	}
+	waitGroup.Wait()
+
+	if failed {
+		return -1
+	}
+	return 1
+}
+
+func (t *IbcIngestTask) chainIds() []string {
+	var res []string
+	for _, v := range strings.Split(taskConf().IngestChains, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func (t *IbcIngestTask) maxBlocks() int64 {
+	if taskConf().IngestMaxBlocks > 0 {
+		return int64(taskConf().IngestMaxBlocks)
+	}
+	return ingestDefaultMaxBlocks
+}
+
+// ingestChain ingest at most ingest_max_blocks blocks after the checkpoint of the chain
+func (t *IbcIngestTask) ingestChain(chainId string) error {
+	cf, err := chainConfigRepo.FindOne(chainId)
+	if err != nil {
+		return fmt.Errorf("find chain config error, %v", err)
+	}
+	if cf.Rpc == "" {
+		return fmt.Errorf("rpc of the chain is not configured")
+	}
+	client := datasource.NewRpcClient(chainId, cf.Rpc)
+	status, err := client.Status(context.Background())
+	if err != nil {
+		return fmt.Errorf("query rpc status error, %v", err)
+	}
+
+	cp, err := t.loadCheckpoint(chainId, status)
+	if err != nil {
+		return err
+	}
+	latest := status.SyncInfo.LatestBlockHeight
+	end := cp.Cursor + t.maxBlocks()
+	if end > latest {
+		end = latest
+	}
+
+	for start := cp.Cursor + 1; start <= end; start += ingestBatchBlocks {
+		batchEnd := start + ingestBatchBlocks - 1
+		if batchEnd > end {
+			batchEnd = end
+		}
+		blocks, txs, err := t.pull(client, start, batchEnd)
+		if err != nil {
+			return err
+		}
+
+		// the txs are saved before the blocks, so the txs of a saved block are always complete
+		if err = txRepo.InsertMissing(chainId, start, batchEnd, txs); err != nil {
+			return fmt.Errorf("save txs of %d-%d error, %v", start, batchEnd, err)
+		}
+		if err = syncBlockRepo.InsertMissing(chainId, start, batchEnd, blocks); err != nil {
+			return fmt.Errorf("save blocks of %d-%d error, %v", start, batchEnd, err)
+		}
+
+		cp.Cursor = batchEnd
+		cp.Done += int64(len(blocks))
+		cp.Total = latest - cp.Begin + 1
+		if err = taskCheckpointRepo.Save(cp); err != nil {
+			return fmt.Errorf("save checkpoint error, %v", err)
+		}
+		if err = syncTaskRepo.SaveFollowing(chainId, ingestWorkerId, cp.Begin, batchEnd); err != nil {
+			return fmt.Errorf("save following task error, %v", err)
+		}
+	}
+
+	logrus.Infof("task %s chain %s ingested to %d, latest height: %d", t.Name(), chainId, cp.Cursor, latest)
+	return nil
+}
+
+// loadCheckpoint the checkpoint of the chain. The first run starts from ingest_start_height, or the next height of
+// the blocks synced by the sync service, or the latest height of the chain.
+func (t *IbcIngestTask) loadCheckpoint(chainId string, status *vo.RpcStatusResult) (*entity.IbcTaskCheckpoint, error) {
+	cp, err := taskCheckpointRepo.FindOne(t.Name(), chainId, ingestWorkerId)
+	if err == nil {
+		return cp, nil
+	}
+	if err != qmgo.ErrNoSuchDocuments {
+		return nil, fmt.Errorf("load checkpoint error, %v", err)
+	}
+
+	start := status.SyncInfo.LatestBlockHeight
+	if taskConf().IngestStartHeight > 0 {
+		start = taskConf().IngestStartHeight
+	} else if block, err := syncBlockRepo.FindLatestBlock(chainId); err == nil {
+		start = block.Height + 1
+	} else if err != qmgo.ErrNoSuchDocuments {
+		return nil, fmt.Errorf("find latest block error, %v", err)
+	}
+	if start < status.SyncInfo.EarliestBlockHeight {
+		start = status.SyncInfo.EarliestBlockHeight
+	}
+
+	if err = txRepo.CreateIndexes(chainId); err != nil {
+		logrus.Warningf("task %s chain %s create tx indexes error, %v", t.Name(), chainId, err)
+	}
+	if err = syncBlockRepo.CreateIndexes(chainId); err != nil {
+		logrus.Warningf("task %s chain %s create block indexes error, %v", t.Name(), chainId, err)
+	}
+	logrus.Infof("task %s chain %s start ingesting from %d", t.Name(), chainId, start)
+	return &entity.IbcTaskCheckpoint{
+		TaskName:  t.Name(),
+		Scope:     chainId,
+		Worker:    ingestWorkerId,
+		WorkerNum: 1,
+		Begin:     start,
+		Cursor:    start - 1,
+		Status:    entity.TaskCheckpointStatusRunning,
+	}, nil
+}
+
+// pull the blocks and the ibc txs between the heights(inclusive)
+func (t *IbcIngestTask) pull(client *datasource.RpcClient, start, end int64) ([]*entity.SyncBlock, []*entity.Tx, error) {
+	ctx := context.Background()
+	var blocks []*entity.SyncBlock
+	var txs []*entity.Tx
+	for height := start; height <= end; height++ {
+		block, err := client.Block(ctx, height)
+		if err != nil {
+			return nil, nil, fmt.Errorf("query block %d error, %v", height, err)
+		}
+		blockTxs, err := ingest.IbcTxs(block, func() (*vo.RpcBlockResultsResult, error) {
+			return client.BlockResults(ctx, height)
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("ingest block %d error, %v", height, err)
+		}
+		blocks = append(blocks, ingest.Block(block))
+		txs = append(txs, blockTxs...)
+	}
+	return blocks, txs, nil
+}
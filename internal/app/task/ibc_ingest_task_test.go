@@ -0,0 +1,7 @@
+package task
+
+import "testing"
+
+func Test_IngestTask(t *testing.T) {
+	new(IbcIngestTask).Run()
+}
@@ -0,0 +1,246 @@
+package task
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/monitor"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLargeTransferUsdThreshold = 100000
+	defaultLargeTransferMinUsd       = 10000
+	defaultLargeTransferStddevTimes  = 5
+	defaultLargeTransferMinSamples   = 100
+
+	// largeTransferDelay the ibc txs created in the last seconds may be inserting
+	largeTransferDelay = 10
+)
+
+// IbcLargeTransferTask evaluate the new transfers of ex_ibc_tx_latest, the transfer is alerted if its value exceeds
+// large_transfer_usd_threshold, or its amount exceeds mean + large_transfer_stddev_times * stddev of the base denom.
+// The progress is recorded as the create_at of the ibc txs in ibc_task_record.
+type IbcLargeTransferTask struct {
+	baseDenomMap entity.IBCBaseDenomMap
+	prices       map[string]float64
+	baselineMap  map[string]*entity.IBCLargeTransferBaseline
+}
+
+var _ Task = new(IbcLargeTransferTask)
+
+func (t *IbcLargeTransferTask) Name() string {
+	return "ibc_large_transfer_task"
+}
+
+func (t *IbcLargeTransferTask) Cron() int {
+	if taskConf().CronTimeLargeTransferTask > 0 {
+		return taskConf().CronTimeLargeTransferTask
+	}
+	return EveryMinute
+}
+
+func (t *IbcLargeTransferTask) Run() int {
+	if err := t.init(); err != nil {
+		logrus.Errorf("task %s init error, %v", t.Name(), err)
+		return -1
+	}
+
+	taskRecord, err := checkTaskRecord(entity.LargeTransferTaskName)
+	if err != nil {
+		logrus.Errorf("task %s checkTaskRecord error, %v", t.Name(), err)
+		return -1
+	}
+	if taskRecord.Status == entity.TaskRecordStatusClose {
+		return 1
+	}
+
+	endTime := time.Now().Unix() - largeTransferDelay
+	startTime := taskRecord.Height
+	if startTime == 0 { // 首次运行只检测新的交易
+		startTime = endTime - int64(t.Cron())
+	}
+	if startTime >= endTime {
+		return 1
+	}
+
+	changed := make(map[string]*entity.IBCLargeTransferBaseline)
+	var skip int64
+	for {
+		txs, err := ibcTxRepo.FindByCreateAt(startTime+1, endTime, skip, constant.DefaultLimit, false)
+		if err != nil {
+			logrus.Errorf("task %s FindByCreateAt error, %v", t.Name(), err)
+			return -1
+		}
+
+		for _, tx := range txs {
+			baseline, err := t.evaluate(tx)
+			if err != nil {
+				logrus.Errorf("task %s evaluate tx %s error, %v", t.Name(), tx.RecordId, err)
+				return -1
+			}
+			if baseline != nil {
+				changed[largeTransferBaselineKey(baseline.BaseDenom, baseline.BaseDenomChainId)] = baseline
+			}
+		}
+
+		if len(txs) < constant.DefaultLimit {
+			break
+		}
+		skip += constant.DefaultLimit
+	}
+
+	for _, v := range changed {
+		if err = transferBaselineRepo.Save(v); err != nil {
+			logrus.Errorf("task %s save baseline error, %v", t.Name(), err)
+			return -1
+		}
+	}
+	if err = taskRecordRepo.UpdateHeight(entity.LargeTransferTaskName, endTime); err != nil {
+		logrus.Errorf("task %s update task record error, %v", t.Name(), err)
+		return -1
+	}
+
+	return 1
+}
+
+func (t *IbcLargeTransferTask) init() error {
+	baseDenoms, err := baseDenomCache.FindAll()
+	if err != nil {
+		return err
+	}
+	prices, err := tokenPriceRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	baselines, err := transferBaselineRepo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	t.baseDenomMap = baseDenoms.ConvertToMap()
+	t.prices = prices
+	t.baselineMap = make(map[string]*entity.IBCLargeTransferBaseline, len(baselines))
+	for _, v := range baselines {
+		t.baselineMap[largeTransferBaselineKey(v.BaseDenom, v.BaseDenomChainId)] = v
+	}
+	return nil
+}
+
+// evaluate alert the transfer against the thresholds, then add the amount into the baseline of the base denom
+func (t *IbcLargeTransferTask) evaluate(tx *entity.ExIbcTx) (*entity.IBCLargeTransferBaseline, error) {
+	if tx.Status == entity.IbcTxStatusFailed || tx.ScTxInfo == nil || tx.ScTxInfo.MsgAmount == nil || tx.BaseDenom == "" {
+		return nil, nil
+	}
+	amount, err := decimal.NewFromString(tx.ScTxInfo.MsgAmount.Amount)
+	if err != nil || !amount.IsPositive() {
+		return nil, nil
+	}
+
+	key := largeTransferBaselineKey(tx.BaseDenom, tx.BaseDenomChainId)
+	baseline, ok := t.baselineMap[key]
+	if !ok {
+		baseline = &entity.IBCLargeTransferBaseline{BaseDenom: tx.BaseDenom, BaseDenomChainId: tx.BaseDenomChainId}
+		t.baselineMap[key] = baseline
+	}
+
+	value, priced := t.value(amount, tx.BaseDenom, tx.BaseDenomChainId)
+	amountFloat, _ := amount.Float64()
+	mean, stddev := baseline.Mean, baseline.Stddev()
+
+	var reasons []string
+	if priced && value.GreaterThanOrEqual(decimal.NewFromFloat(largeTransferUsdThreshold())) {
+		reasons = append(reasons, entity.LargeTransferReasonUsdThreshold)
+	}
+	if baseline.Count >= largeTransferMinSamples() && stddev > 0 && amountFloat > mean+largeTransferStddevTimes()*stddev &&
+		(!priced || value.GreaterThanOrEqual(decimal.NewFromFloat(largeTransferMinUsd()))) {
+		reasons = append(reasons, entity.LargeTransferReasonStatistical)
+	}
+	baseline.Add(amountFloat)
+
+	if len(reasons) == 0 {
+		return baseline, nil
+	}
+
+	alert := &entity.IBCLargeTransferAlert{
+		RecordId:         tx.RecordId,
+		ScChainId:        tx.ScChainId,
+		ScChannel:        tx.ScChannel,
+		ScAddr:           tx.ScAddr,
+		DcChainId:        tx.DcChainId,
+		DcChannel:        tx.DcChannel,
+		DcAddr:           tx.DcAddr,
+		ScTxHash:         tx.ScTxInfo.Hash,
+		BaseDenom:        tx.BaseDenom,
+		BaseDenomChainId: tx.BaseDenomChainId,
+		Amount:           amount.String(),
+		Reasons:          reasons,
+		BaselineMean:     mean,
+		BaselineStddev:   stddev,
+		Status:           tx.Status,
+		TxTime:           tx.TxTime,
+	}
+	if tx.Denoms != nil {
+		alert.Denom = tx.Denoms.ScDenom
+	}
+	if priced {
+		alert.Value = value.Round(constant.DefaultValuePrecision).String()
+	}
+	if err = largeTransferAlertRepo.Save(alert); err != nil {
+		return nil, err
+	}
+	for _, reason := range reasons {
+		monitor.AddLargeTransferMetricValue(tx.ScChainId, tx.BaseDenom, reason)
+	}
+	return baseline, nil
+}
+
+// value the usd value of the amount, false if the price of the base denom is unknown
+func (t *IbcLargeTransferTask) value(amount decimal.Decimal, baseDenom, baseDenomChainId string) (decimal.Decimal, bool) {
+	denom, ok := t.baseDenomMap[fmt.Sprintf("%s%s", baseDenomChainId, baseDenom)]
+	if !ok || denom.CoinId == "" {
+		return decimal.Zero, false
+	}
+	price, ok := t.prices[denom.CoinId]
+	if !ok || price == 0 || price == constant.UnknownTokenPrice {
+		return decimal.Zero, false
+	}
+
+	return amount.Div(decimal.NewFromFloat(math.Pow10(denom.Scale))).Mul(decimal.NewFromFloat(price)), true
+}
+
+func largeTransferBaselineKey(baseDenom, baseDenomChainId string) string {
+	return fmt.Sprintf("%s%s", baseDenomChainId, baseDenom)
+}
+
+func largeTransferUsdThreshold() float64 {
+	if taskConf().LargeTransferUsdThreshold > 0 {
+		return taskConf().LargeTransferUsdThreshold
+	}
+	return defaultLargeTransferUsdThreshold
+}
+
+func largeTransferMinUsd() float64 {
+	if taskConf().LargeTransferMinUsd > 0 {
+		return taskConf().LargeTransferMinUsd
+	}
+	return defaultLargeTransferMinUsd
+}
+
+func largeTransferStddevTimes() float64 {
+	if taskConf().LargeTransferStddevTimes > 0 {
+		return taskConf().LargeTransferStddevTimes
+	}
+	return defaultLargeTransferStddevTimes
+}
+
+func largeTransferMinSamples() int64 {
+	if taskConf().LargeTransferMinSamples > 0 {
+		return taskConf().LargeTransferMinSamples
+	}
+	return defaultLargeTransferMinSamples
+}
@@ -0,0 +1,7 @@
+package task
+
+import "testing"
+
+func Test_LargeTransferTask(t *testing.T) {
+	new(IbcLargeTransferTask).Run()
+}
@@ -0,0 +1,436 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// IbcNftTransferTask sync the ics-721 nft transfers of the chains, and relate the recv, ack and timeout packets of them
+type IbcNftTransferTask struct {
+	chainMap map[string]*entity.ChainConfig
+}
+
+var _ Task = new(IbcNftTransferTask)
+
+func (t *IbcNftTransferTask) Name() string {
+	return "ibc_nft_transfer_task"
+}
+
+func (t *IbcNftTransferTask) Cron() int {
+	if taskConf().CronTimeNftTransferTask > 0 {
+		return taskConf().CronTimeNftTransferTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcNftTransferTask) Run() int {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+	t.chainMap = chainMap
+
+	for chainId, cf := range chainMap {
+		if cf.Status == entity.ChainStatusClosed {
+			continue
+		}
+
+		if err = t.syncChainNftTx(chainId); err != nil {
+			logrus.Errorf("task %s sync chain %s nft tx error, %v", t.Name(), chainId, err)
+		}
+		if err = t.relateChainNftTx(chainId); err != nil {
+			logrus.Errorf("task %s relate chain %s nft tx error, %v", t.Name(), chainId, err)
+		}
+	}
+
+	return 1
+}
+
+// ================================================================================
+// ================================================================================
+// sync nft transfer
+
+func (t *IbcNftTransferTask) syncChainNftTx(chainId string) error {
+	maxParseTx := global.Config().Task.SingleChainSyncTransferTxMax
+	if maxParseTx <= 0 {
+		maxParseTx = defaultMaxHandlerTx
+	}
+
+	taskRecord, err := checkTaskRecord(fmt.Sprintf(entity.NftTaskNameFmt, chainId))
+	if err != nil {
+		return err
+	}
+	if taskRecord.Status == entity.TaskRecordStatusClose {
+		return nil
+	}
+
+	classMap, err := t.getChainClassMap(chainId)
+	if err != nil {
+		return err
+	}
+
+	totalParseTx := 0
+	for {
+		txList, err := t.getTxList(chainId, taskRecord.Height, int64(constant.DefaultLimit))
+		if err != nil {
+			return err
+		}
+		if len(txList) == 0 {
+			return nil
+		}
+
+		nftTxList, classList := t.handleSourceTx(chainId, txList, classMap)
+		if len(classList) > 0 {
+			if err = nftClassRepo.InsertBatch(classList); err != nil {
+				return err
+			}
+		}
+		if len(nftTxList) > 0 {
+			if err = nftTxRepo.InsertBatch(nftTxList); err != nil {
+				return err
+			}
+		}
+
+		taskRecord.Height = txList[len(txList)-1].Height
+		if err = taskRecordRepo.UpdateHeight(taskRecord.TaskName, taskRecord.Height); err != nil {
+			return err
+		}
+
+		totalParseTx += len(txList)
+		if len(txList) < constant.DefaultLimit || totalParseTx >= maxParseTx {
+			return nil
+		}
+	}
+}
+
+func (t *IbcNftTransferTask) handleSourceTx(chainId string, txList []*entity.Tx, classMap map[string]*entity.IBCNftClass) ([]*entity.ExIbcNftTx, entity.IBCNftClassList) {
+	var nftTxList []*entity.ExIbcNftTx
+	var classList entity.IBCNftClassList
+	for _, tx := range txList {
+		for msgIndex, msg := range tx.DocTxMsgs {
+			if msg.Type != constant.MsgTypeNftTransfer {
+				continue
+			}
+
+			var status entity.IbcTxStatus
+			switch tx.Status {
+			case entity.TxStatusSuccess:
+				status = entity.IbcTxStatusProcessing
+			case entity.TxStatusFailed:
+				status = entity.IbcTxStatusFailed
+			}
+
+			transferMsg := msg.NftTransferMsg()
+			scPort, scChannel, scClassId := transferMsg.SourcePort, transferMsg.SourceChannel, transferMsg.ClassId
+			dcChainId, dcPort, dcChannel := matchDcInfo(chainId, scPort, scChannel, t.chainMap)
+
+			var classFullPath, sequence, scConnection string
+			class, isExisted := classMap[scClassId]
+			if status != entity.IbcTxStatusFailed {
+				dcPort, dcChannel, classFullPath, sequence, scConnection = parseNftTransferTxEvents(msgIndex, tx)
+				if !isExisted && classFullPath != "" {
+					if class = traceNftClass(classFullPath, chainId, t.chainMap); class != nil {
+						classMap[class.ClassId] = class
+						classList = append(classList, class)
+					}
+				}
+			}
+
+			if dcChainId == "" && status != entity.IbcTxStatusFailed {
+				status = entity.IbcTxStatusSetting
+			}
+
+			var baseClassId, baseClassChainId string
+			if class != nil {
+				baseClassId, baseClassChainId = class.BaseClassId, class.BaseClassChainId
+			}
+			recordId := utils.Md5(fmt.Sprintf("%s%s%s%s%s%s%s%d", scPort, scChannel, dcPort, dcChannel, sequence, chainId, tx.TxHash, msgIndex))
+			nowUnix := time.Now().Unix()
+			nftTxList = append(nftTxList, &entity.ExIbcNftTx{
+				RecordId:       recordId,
+				TxTime:         tx.Time,
+				ScAddr:         transferMsg.Sender,
+				DcAddr:         transferMsg.Receiver,
+				ScPort:         scPort,
+				ScChannel:      scChannel,
+				ScConnectionId: scConnection,
+				ScChainId:      chainId,
+				DcPort:         dcPort,
+				DcChannel:      dcChannel,
+				DcChainId:      dcChainId,
+				Sequence:       sequence,
+				Status:         status,
+				ScTxInfo:       newTxInfo(tx, msg),
+				ClassIds: &entity.ClassIds{
+					ScClassId: scClassId,
+				},
+				TokenIds:         transferMsg.TokenIds,
+				BaseClassId:      baseClassId,
+				BaseClassChainId: baseClassChainId,
+				NextTryTime:      nowUnix,
+				CreateAt:         nowUnix,
+				UpdateAt:         nowUnix,
+			})
+		}
+	}
+	return nftTxList, classList
+}
+
+func (t *IbcNftTransferTask) getChainClassMap(chainId string) (map[string]*entity.IBCNftClass, error) {
+	classList, err := nftClassRepo.FindByChainId(chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	classMap := make(map[string]*entity.IBCNftClass, len(classList))
+	for _, v := range classList {
+		classMap[v.ClassId] = v
+	}
+	return classMap, nil
+}
+
+// getTxList the txs of the last height are all included
+func (t *IbcNftTransferTask) getTxList(chainId string, height, limit int64) ([]*entity.Tx, error) {
+	txList, err := txRepo.GetNftTransferTx(chainId, height, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(txList) < int(limit) {
+		return txList, nil
+	}
+
+	maxHeight := txList[len(txList)-1].Height
+	txHashMap := make(map[string]struct{})
+	for _, v := range txList {
+		if v.Height == maxHeight {
+			txHashMap[v.TxHash] = struct{}{}
+		}
+	}
+
+	heightTxList, err := txRepo.FindByTypeAndHeight(chainId, constant.MsgTypeNftTransfer, maxHeight)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range heightTxList {
+		if _, ok := txHashMap[v.TxHash]; !ok {
+			txList = append(txList, v)
+		}
+	}
+	return txList, nil
+}
+
+// ================================================================================
+// ================================================================================
+// relate recv, ack and timeout packet
+
+func (t *IbcNftTransferTask) relateChainNftTx(chainId string) error {
+	nftTxList, err := nftTxRepo.FindProcessingTxs(chainId, constant.DefaultLimit)
+	if err != nil {
+		return err
+	}
+	if len(nftTxList) == 0 {
+		return nil
+	}
+
+	dcPacketIds := make(map[string][]string)
+	var scPacketIds []string
+	for _, v := range nftTxList {
+		if v.DcChainId == "" || v.ScTxInfo == nil || v.ScTxInfo.Msg == nil {
+			continue
+		}
+		packetId := v.ScTxInfo.Msg.CommonMsg().PacketId
+		dcPacketIds[v.DcChainId] = append(dcPacketIds[v.DcChainId], packetId)
+		scPacketIds = append(scPacketIds, packetId)
+	}
+
+	status := entity.TxStatusSuccess
+	recvTxMap := make(map[string]*entity.Tx)
+	for dcChainId, packetIds := range dcPacketIds {
+		recvTxs, err := txRepo.FindByPacketIds(dcChainId, constant.MsgTypeRecvPacket, packetIds, &status)
+		if err != nil {
+			return err
+		}
+		t.putPacketTxMap(recvTxMap, dcChainId, constant.MsgTypeRecvPacket, recvTxs)
+	}
+	ackTxs, err := txRepo.FindByPacketIds(chainId, constant.MsgTypeAcknowledgement, scPacketIds, &status)
+	if err != nil {
+		return err
+	}
+	ackTxMap := make(map[string]*entity.Tx)
+	t.putPacketTxMap(ackTxMap, chainId, constant.MsgTypeAcknowledgement, ackTxs)
+	timeoutTxs, err := txRepo.FindByPacketIds(chainId, constant.MsgTypeTimeoutPacket, scPacketIds, &status)
+	if err != nil {
+		return err
+	}
+	timeoutTxMap := make(map[string]*entity.Tx)
+	t.putPacketTxMap(timeoutTxMap, chainId, constant.MsgTypeTimeoutPacket, timeoutTxs)
+
+	var classList entity.IBCNftClassList
+	for _, v := range nftTxList {
+		if v.DcChainId != "" && v.ScTxInfo != nil && v.ScTxInfo.Msg != nil {
+			packetId := v.ScTxInfo.Msg.CommonMsg().PacketId
+			if recvTx, ok := recvTxMap[packetKey(v.DcChainId, packetId)]; ok {
+				if class := t.loadRecvPacketTx(v, recvTx, ackTxMap[packetKey(chainId, packetId)]); class != nil {
+					classList = append(classList, class)
+				}
+			} else if timeoutTx, ok := timeoutTxMap[packetKey(chainId, packetId)]; ok {
+				v.Status = entity.IbcTxStatusRefunded
+				v.RefundedTxInfo = newTxInfo(timeoutTx, findPacketMsg(timeoutTx, constant.MsgTypeTimeoutPacket, packetId))
+			}
+		}
+
+		if v.Status == entity.IbcTxStatusProcessing {
+			v.RetryTimes += 1
+			v.NextTryTime = time.Now().Unix() + (v.RetryTimes * 2)
+			v.ProcessInfo = constant.NoFoundSuccessRecvPacket
+		} else {
+			v.ProcessInfo = ""
+		}
+		v.UpdateAt = time.Now().Unix()
+		if err = nftTxRepo.UpdateNftTx(v); err != nil {
+			logrus.Errorf("task %s update nft tx %s error, %v", t.Name(), v.RecordId, err)
+		}
+	}
+
+	if len(classList) > 0 {
+		return nftClassRepo.InsertBatch(classList)
+	}
+	return nil
+}
+
+// loadRecvPacketTx set the status by the ack of the recv packet, the class traced on the dc chain is returned if it's new
+func (t *IbcNftTransferTask) loadRecvPacketTx(nftTx *entity.ExIbcNftTx, recvTx, ackTx *entity.Tx) *entity.IBCNftClass {
+	packetId := nftTx.ScTxInfo.Msg.CommonMsg().PacketId
+	for msgIndex, msg := range recvTx.DocTxMsgs {
+		if msg.Type != constant.MsgTypeRecvPacket || msg.CommonMsg().PacketId != packetId {
+			continue
+		}
+		dcConnection, packetAck, existPacketAck := parseRecvPacketTxEvents(msgIndex, recvTx)
+		if !existPacketAck {
+			return nil
+		}
+
+		if strings.Contains(packetAck, "error") {
+			if ackTx == nil { // 改为refunded状态时，必须要ack_packet交易
+				return nil
+			}
+			nftTx.Status = entity.IbcTxStatusRefunded
+		} else {
+			nftTx.Status = entity.IbcTxStatusSuccess
+		}
+		nftTx.DcConnectionId = dcConnection
+		nftTx.DcTxInfo = newTxInfo(recvTx, msg)
+		if ackTx != nil {
+			nftTx.RefundedTxInfo = newTxInfo(ackTx, findPacketMsg(ackTx, constant.MsgTypeAcknowledgement, packetId))
+		}
+
+		packet := msg.NftPacket()
+		dcClassPath, isCrossBack := calculateNextPath(packet.SourcePort, packet.SourceChannel, packet.DestinationPort,
+			packet.DestinationChannel, packet.PacketData().ClassId)
+		nftTx.ClassIds.DcClassId = calculateIbcHash(dcClassPath)
+		if nftTx.Status != entity.IbcTxStatusSuccess || isCrossBack {
+			return nil
+		}
+
+		classPath, rootClassId := splitFullPath(dcClassPath)
+		return &entity.IBCNftClass{
+			ChainId:          nftTx.DcChainId,
+			ClassId:          nftTx.ClassIds.DcClassId,
+			PrevClassId:      nftTx.ClassIds.ScClassId,
+			PrevChainId:      nftTx.ScChainId,
+			BaseClassId:      nftTx.BaseClassId,
+			BaseClassChainId: nftTx.BaseClassChainId,
+			ClassPath:        classPath,
+			RootClassId:      rootClassId,
+			IsBaseClass:      false,
+			CreateAt:         time.Now().Unix(),
+			UpdateAt:         time.Now().Unix(),
+		}
+	}
+	return nil
+}
+
+func (t *IbcNftTransferTask) putPacketTxMap(txMap map[string]*entity.Tx, chainId, msgType string, txs []*entity.Tx) {
+	for _, tx := range txs {
+		for _, msg := range tx.DocTxMsgs {
+			if msg.Type != msgType {
+				continue
+			}
+			key := packetKey(chainId, msg.CommonMsg().PacketId)
+			if exist, ok := txMap[key]; !ok || tx.Time > exist.Time {
+				txMap[key] = tx
+			}
+		}
+	}
+}
+
+func packetKey(chainId, packetId string) string {
+	return fmt.Sprintf("%s_%s", chainId, packetId)
+}
+
+func findPacketMsg(tx *entity.Tx, msgType, packetId string) *model.TxMsg {
+	for _, msg := range tx.DocTxMsgs {
+		if msg.Type == msgType && msg.CommonMsg().PacketId == packetId {
+			return msg
+		}
+	}
+	return nil
+}
+
+// parseNftTransferTxEvents parse ibc info from events of nft transfer tx
+func parseNftTransferTxEvents(msgIndex int, tx *entity.Tx) (dcPort, dcChannel, classFullPath, sequence, scConnection string) {
+	if len(tx.EventsNew) > msgIndex {
+		for _, evt := range tx.EventsNew[msgIndex].Events {
+			if evt.Type == "send_packet" {
+				for _, attr := range evt.Attributes {
+					switch attr.Key {
+					case "packet_dst_port":
+						dcPort = attr.Value
+					case "packet_dst_channel":
+						dcChannel = attr.Value
+					case "packet_sequence":
+						sequence = attr.Value
+					case "packet_data":
+						classFullPath = model.ParseNftPacketData(json.RawMessage(attr.Value)).ClassId
+					case "packet_connection":
+						scConnection = attr.Value
+					default:
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// traceNftClass trace the nft class like the denom, the class id of ics-721 is calculated in the same way as ics-20
+func traceNftClass(classFullPath, chainId string, allChainMap map[string]*entity.ChainConfig) *entity.IBCNftClass {
+	denom := traceDenom(classFullPath, chainId, allChainMap)
+	if denom == nil {
+		return nil
+	}
+	return &entity.IBCNftClass{
+		ChainId:          denom.ChainId,
+		ClassId:          denom.Denom,
+		PrevClassId:      denom.PrevDenom,
+		PrevChainId:      denom.PrevChainId,
+		BaseClassId:      denom.BaseDenom,
+		BaseClassChainId: denom.BaseDenomChainId,
+		ClassPath:        denom.DenomPath,
+		RootClassId:      denom.RootDenom,
+		IsBaseClass:      denom.IsBaseDenom,
+		CreateAt:         denom.CreateAt,
+		UpdateAt:         denom.UpdateAt,
+	}
+}
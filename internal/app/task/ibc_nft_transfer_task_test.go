@@ -0,0 +1,17 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+)
+
+func Test_NftTransferTask(t *testing.T) {
+	new(IbcNftTransferTask).Run()
+}
+
+func Test_TraceNftClass(t *testing.T) {
+	chainMap, _ := getAllChainMap()
+	class := traceNftClass("nft-transfer/channel-1/kitty", "irishub_qa", chainMap)
+	t.Log(utils.MustMarshalJsonToStr(class))
+}
@@ -0,0 +1,256 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/sirupsen/logrus"
+)
+
+var packetRelayMsgTypes = []string{constant.MsgTypeRecvPacket, constant.MsgTypeAcknowledgement, constant.MsgTypeTimeoutPacket}
+
+// IbcPacketTask index the packets of all the ibc applications, not only the ics-20 transfers.
+// The send txs are found by the send_packet events, the recv, ack and timeout txs by the relay msgs.
+type IbcPacketTask struct {
+	chainMap map[string]*entity.ChainConfig
+}
+
+var _ Task = new(IbcPacketTask)
+
+func (t *IbcPacketTask) Name() string {
+	return "ibc_packet_task"
+}
+
+func (t *IbcPacketTask) Cron() int {
+	if taskConf().CronTimePacketTask > 0 {
+		return taskConf().CronTimePacketTask
+	}
+	return ThreeMinute
+}
+
+func (t *IbcPacketTask) Run() int {
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+	t.chainMap = chainMap
+
+	for chainId, cf := range chainMap {
+		if cf.Status == entity.ChainStatusClosed {
+			continue
+		}
+
+		if err = syncTxByHeight(fmt.Sprintf(entity.PacketSendTaskNameFmt, chainId),
+			func(height, limit int64) ([]*entity.Tx, error) {
+				return txRepo.GetEventTx(chainId, "send_packet", height, limit)
+			},
+			func(height int64) ([]*entity.Tx, error) {
+				return txRepo.FindEventTxByHeight(chainId, "send_packet", height)
+			},
+			func(txList []*entity.Tx) error {
+				return t.handleSendTx(chainId, txList)
+			}); err != nil {
+			logrus.Errorf("task %s sync chain %s send packet error, %v", t.Name(), chainId, err)
+		}
+
+		if err = syncTxByHeight(fmt.Sprintf(entity.PacketRelayTaskNameFmt, chainId),
+			func(height, limit int64) ([]*entity.Tx, error) {
+				return txRepo.GetPortTx(chainId, packetRelayMsgTypes, "packet.source_port", "", height, limit)
+			},
+			func(height int64) ([]*entity.Tx, error) {
+				return txRepo.FindPortTxByHeight(chainId, packetRelayMsgTypes, "packet.source_port", "", height)
+			},
+			func(txList []*entity.Tx) error {
+				return t.handleRelayTx(chainId, txList)
+			}); err != nil {
+			logrus.Errorf("task %s sync chain %s relay packet error, %v", t.Name(), chainId, err)
+		}
+	}
+
+	return 1
+}
+
+func (t *IbcPacketTask) handleSendTx(chainId string, txList []*entity.Tx) error {
+	for _, tx := range txList {
+		if tx.Status != entity.TxStatusSuccess {
+			continue
+		}
+		for _, evts := range tx.EventsNew {
+			for _, evt := range evts.Events {
+				if evt.Type != "send_packet" {
+					continue
+				}
+
+				packet := parseSendPacketEvent(evt)
+				if packet.ScPort == "" || packet.ScChannel == "" || packet.Sequence == "" {
+					continue
+				}
+				packet.ScChainId = chainId
+				packet.DcChainId, _, _ = matchDcInfo(chainId, packet.ScPort, packet.ScChannel, t.chainMap)
+				packet.RecordId = packetRecordId(chainId, packet.ScPort, packet.ScChannel, packet.Sequence)
+				packet.PacketId = fmt.Sprintf("%s%s%s%s%s", packet.ScPort, packet.ScChannel, packet.DcPort, packet.DcChannel, packet.Sequence)
+				packet.Status = entity.PacketStatusSent
+				var msg *model.TxMsg
+				if int(evts.MsgIndex) < len(tx.DocTxMsgs) {
+					msg = tx.DocTxMsgs[evts.MsgIndex]
+				}
+				packet.SendTxInfo = newTxInfo(tx, msg)
+				packet.TxTime = tx.Time
+				if err := packetRepo.UpsertSendPacket(packet); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (t *IbcPacketTask) handleRelayTx(chainId string, txList []*entity.Tx) error {
+	for _, tx := range txList {
+		if tx.Status != entity.TxStatusSuccess {
+			continue
+		}
+		for msgIndex, msg := range tx.DocTxMsgs {
+			if !utils.InArray(packetRelayMsgTypes, msg.Type) {
+				continue
+			}
+
+			rawPacket := parseRawPacket(msg)
+			packet := &entity.ExIbcPacket{
+				PacketId:  msg.CommonMsg().PacketId,
+				ScPort:    rawPacket.SourcePort,
+				ScChannel: rawPacket.SourceChannel,
+				DcPort:    rawPacket.DestinationPort,
+				DcChannel: rawPacket.DestinationChannel,
+				Sequence:  strconv.FormatInt(rawPacket.Sequence, 10),
+				Data:      rawPacket.Data,
+				TxTime:    tx.Time,
+			}
+
+			var err error
+			switch msg.Type {
+			case constant.MsgTypeRecvPacket:
+				_, packetAck, existPacketAck := parseRecvPacketTxEvents(msgIndex, tx)
+				if !existPacketAck { // 重复relay的recv_packet
+					continue
+				}
+				packet.DcChainId = chainId
+				packet.ScChainId, _, _ = matchDcInfo(chainId, packet.DcPort, packet.DcChannel, t.chainMap)
+				packet.Status = entity.PacketStatusReceived
+				packet.RecvTxInfo = newTxInfo(tx, msg)
+				packet.Ack, packet.AckResult = packetAck, packetAckResult(packetAck)
+			case constant.MsgTypeAcknowledgement:
+				packet.ScChainId = chainId
+				packet.DcChainId, _, _ = matchDcInfo(chainId, packet.ScPort, packet.ScChannel, t.chainMap)
+				packet.Status = entity.PacketStatusAcknowledged
+				packet.AckTxInfo = newTxInfo(tx, msg)
+				ack := msg.AckPacketMsg().Acknowledgement
+				packet.Ack, packet.AckResult = ack, packetAckResult(ack)
+			case constant.MsgTypeTimeoutPacket:
+				packet.ScChainId = chainId
+				packet.DcChainId, _, _ = matchDcInfo(chainId, packet.ScPort, packet.ScChannel, t.chainMap)
+				packet.Status = entity.PacketStatusTimeout
+				packet.TimeoutTxInfo = newTxInfo(tx, msg)
+			}
+			if packet.ScChainId == "" {
+				logrus.Warnf("task %s chain %s packet %s/%s/%s no found sc chain", t.Name(), chainId, packet.ScPort,
+					packet.ScChannel, packet.Sequence)
+				continue
+			}
+			packet.RecordId = packetRecordId(packet.ScChainId, packet.ScPort, packet.ScChannel, packet.Sequence)
+
+			switch msg.Type {
+			case constant.MsgTypeRecvPacket:
+				err = packetRepo.UpsertRecvPacket(packet)
+			case constant.MsgTypeAcknowledgement:
+				err = packetRepo.UpsertAckPacket(packet)
+			case constant.MsgTypeTimeoutPacket:
+				err = packetRepo.UpsertTimeoutPacket(packet)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type rawPacket struct {
+	Sequence           int64  `json:"sequence"`
+	SourcePort         string `json:"source_port"`
+	SourceChannel      string `json:"source_channel"`
+	DestinationPort    string `json:"destination_port"`
+	DestinationChannel string `json:"destination_channel"`
+	Data               string `json:"-"`
+}
+
+// parseRawPacket parse the packet of the relay msg, the data is kept as the raw json of any application
+func parseRawPacket(msg *model.TxMsg) rawPacket {
+	var res struct {
+		Packet struct {
+			rawPacket
+			Data json.RawMessage `json:"data"`
+		} `json:"packet"`
+	}
+	bz, _ := json.Marshal(msg.Msg)
+	_ = json.Unmarshal(bz, &res)
+
+	packet := res.Packet.rawPacket
+	var data string
+	if err := json.Unmarshal(res.Packet.Data, &data); err == nil {
+		packet.Data = data
+	} else {
+		packet.Data = string(res.Packet.Data)
+	}
+	return packet
+}
+
+// parseSendPacketEvent parse the packet from the attributes of the send_packet event
+func parseSendPacketEvent(evt entity.Event) *entity.ExIbcPacket {
+	var packet entity.ExIbcPacket
+	for _, attr := range evt.Attributes {
+		switch attr.Key {
+		case "packet_src_port":
+			packet.ScPort = attr.Value
+		case "packet_src_channel":
+			packet.ScChannel = attr.Value
+		case "packet_dst_port":
+			packet.DcPort = attr.Value
+		case "packet_dst_channel":
+			packet.DcChannel = attr.Value
+		case "packet_sequence":
+			packet.Sequence = attr.Value
+		case "packet_data":
+			packet.Data = attr.Value
+		case "packet_data_hex":
+			packet.DataHex = attr.Value
+		case "packet_timeout_height":
+			packet.TimeoutHeight = attr.Value
+		case "packet_timeout_timestamp":
+			packet.TimeoutTimestamp = attr.Value
+		default:
+		}
+	}
+	return &packet
+}
+
+func packetAckResult(ack string) string {
+	if ack == "" {
+		return ""
+	}
+	if strings.Contains(ack, "error") {
+		return entity.PacketAckResultError
+	}
+	return entity.PacketAckResultSuccess
+}
+
+func packetRecordId(scChainId, scPort, scChannel, sequence string) string {
+	return utils.Md5(fmt.Sprintf("%s%s%s%s", scChainId, scPort, scChannel, sequence))
+}
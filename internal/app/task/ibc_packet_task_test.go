@@ -0,0 +1,7 @@
+package task
+
+import "testing"
+
+func Test_PacketTask(t *testing.T) {
+	new(IbcPacketTask).Run()
+}
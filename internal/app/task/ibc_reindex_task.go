@@ -0,0 +1,494 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
+	"github.com/qiniu/qmgo"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IbcReindexTask re-index the ibc txs of a chain between the heights or the times of the job: the ibc txs sent from
+// the chain in the range are set reindexing and parsed again from sync_<chain>_tx as ibc_sync_transfer_tx_task does,
+// the ibc txs received, acknowledged or refunded on the chain in the range are related again, then the statistics of
+// the affected days are recalculated. The progress of each step is recorded in ibc_reindex_job, the affected ibc txs
+// are marked with the job, so a failed or interrupted job is resumed from its failed step.
+type IbcReindexTask struct {
+}
+
+// reindexLockTime the lock of the chain held by the running job, refreshed every minute
+const reindexLockTime = 300 * time.Second
+
+func (t *IbcReindexTask) Name() string {
+	return "ibc_reindex_task"
+}
+
+// RunWithRange create the job of the range and run it
+func (t *IbcReindexTask) RunWithRange(chainId string, startHeight, endHeight, startTime, endTime int64) int {
+	job, err := entity.NewReindexJob(chainId, startHeight, endHeight, startTime, endTime)
+	if err != nil {
+		logrus.Errorf("task %s chain %s new job error, %v", t.Name(), chainId, err)
+		return -1
+	}
+	if _, err = chainConfigRepo.FindOne(chainId); err != nil {
+		logrus.Errorf("task %s find chain %s config error, %v", t.Name(), chainId, err)
+		return -1
+	}
+	if err = createReindexJob(job); err != nil {
+		logrus.Errorf("task %s create job %s error, %v", t.Name(), job.JobId, err)
+		return -1
+	}
+	logrus.Infof("task %s chain %s job %s created", t.Name(), chainId, job.JobId)
+	return t.RunWithParam(job.JobId)
+}
+
+// createReindexJob save the job unless the chain has an active job, the stale one is failed first
+func createReindexJob(job *entity.IBCReindexJob) error {
+	if err := reindexJobRepo.CreateIndexes(); err != nil {
+		return err
+	}
+	running, err := reindexJobRepo.FindRunning(job.ChainId)
+	if err == nil {
+		if !running.IsStale(time.Now().Unix()) {
+			return fmt.Errorf("chain %s is re-indexing by job %s", job.ChainId, running.JobId)
+		}
+		running.Interrupt()
+		if err = reindexJobRepo.Save(running); err != nil {
+			return err
+		}
+	} else if err != qmgo.ErrNoSuchDocuments {
+		return err
+	}
+
+	if err = reindexJobRepo.Create(job); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("chain %s is re-indexing", job.ChainId)
+		}
+		return err
+	}
+	return nil
+}
+
+// RunWithParam run the job, the succeeded steps of a failed or interrupted job are skipped
+func (t *IbcReindexTask) RunWithParam(jobId string) int {
+	job, err := reindexJobRepo.FindOne(jobId)
+	if err != nil {
+		logrus.Errorf("task %s find job %s error, %v", t.Name(), jobId, err)
+		return -1
+	}
+	if job.Status == entity.ReindexJobStatusSuccess {
+		logrus.Infof("task %s job %s has succeeded", t.Name(), jobId)
+		return 1
+	}
+
+	if err = chainTaskCache.Lock(job.ChainId, t.Name(), reindexLockTime); err != nil {
+		logrus.Errorf("task %s chain %s is re-indexing, err:%v", t.Name(), job.ChainId, err.Error())
+		return -1
+	}
+	stop := make(chan struct{})
+	go refreshReindexLock(job.ChainId, job.JobId, stop)
+	defer func() {
+		close(stop)
+		_ = chainTaskCache.Unlock(job.ChainId, t.Name())
+	}()
+	if err = waitChainTasks(job.ChainId); err != nil {
+		logrus.Errorf("task %s job %s wait for the tasks of chain %s error, %v", t.Name(), jobId, job.ChainId, err)
+		return -1
+	}
+	if err = ibcTxRepo.CreateReindexIndexes(); err != nil {
+		logrus.Errorf("task %s create the indexes of ibc txs error, %v", t.Name(), err)
+		return -1
+	}
+	chainMap, err := getAllChainMap()
+	if err != nil {
+		logrus.Errorf("task %s getAllChainMap error, %v", t.Name(), err)
+		return -1
+	}
+
+	// the lock of the chain is held, so the running job is taken over from the exited process
+	job.Status, job.Active = entity.ReindexJobStatusRunning, true
+	if err = reindexJobRepo.Save(job); err != nil {
+		logrus.Errorf("task %s save job %s error, the chain may have another active job, %v", t.Name(), jobId, err)
+		return -1
+	}
+
+	r := &reindexer{
+		taskName: t.Name(),
+		job:      job,
+		chainMap: chainMap,
+	}
+	steps := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{name: entity.ReindexStepResolveRange, run: r.resolveRange},
+		{name: entity.ReindexStepResetTxs, run: r.resetTxs},
+		{name: entity.ReindexStepReplayTxs, run: r.replayTxs},
+		{name: entity.ReindexStepRelateTxs, run: r.relateTxs},
+		{name: entity.ReindexStepStatistics, run: r.statistics},
+	}
+
+	for _, step := range steps {
+		if job.Step(step.name).Status == entity.ReindexJobStatusSuccess {
+			logrus.Infof("task %s job %s step %s has succeeded, skip", t.Name(), jobId, step.name)
+			continue
+		}
+		logrus.Infof("task %s job %s step %s start", t.Name(), jobId, step.name)
+		job.StartStep(step.name)
+		r.save()
+
+		message, err := step.run()
+		job.FinishStep(step.name, err, message)
+		if err != nil {
+			logrus.Errorf("task %s job %s step %s error, %v", t.Name(), jobId, step.name, err)
+			r.save()
+			return -1
+		}
+		r.save()
+	}
+
+	job.Status, job.Active = entity.ReindexJobStatusSuccess, false
+	r.save()
+	return 1
+}
+
+// refreshReindexLock keep the lock of the chain and the update_at of the job until stop is closed
+func refreshReindexLock(chainId, jobId string, stop <-chan struct{}) {
+	taskName := new(IbcReindexTask).Name()
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !chainTaskCache.Refresh(chainId, taskName, reindexLockTime) {
+				logrus.Warnf("task %s chain %s refresh lock failed", taskName, chainId)
+			}
+			if err := reindexJobRepo.Touch(jobId); err != nil {
+				logrus.Warnf("task %s touch job %s error, %v", taskName, jobId, err)
+			}
+		}
+	}
+}
+
+// waitChainTasks wait for the sync and relate tasks working on the chain, they skip the chain once it's locked by the
+// re-index job
+func waitChainTasks(chainId string) error {
+	deadline := time.Now().Add(redisLockExpiration())
+	for _, taskName := range []string{new(IbcSyncTransferTxTask).Name(), new(IbcTxRelateTask).Name(), new(IbcTxRelateHistoryTask).Name()} {
+		for {
+			locked, err := chainTaskCache.IsLocked(chainId, taskName)
+			if err != nil {
+				return err
+			}
+			if !locked {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("task %s is still working on the chain", taskName)
+			}
+			time.Sleep(5 * time.Second)
+		}
+	}
+	return nil
+}
+
+// lockChainTask lock the chain for the sync or relate task, false if the chain is re-indexing. The task takes its lock
+// before checking the one of the re-index job, and the job does the reverse, so they never work on the chain together.
+func lockChainTask(chainId, taskName string) bool {
+	if err := chainTaskCache.Lock(chainId, taskName, redisLockExpiration()); err != nil {
+		logrus.Errorf("task %s lock chain %s failed, err:%v", taskName, chainId, err.Error())
+		return false
+	}
+	reindexing, err := chainTaskCache.IsLocked(chainId, new(IbcReindexTask).Name())
+	if err != nil || reindexing {
+		logrus.Infof("task %s chain %s is re-indexing, skip, %v", taskName, chainId, err)
+		unlockChainTask(chainId, taskName)
+		return false
+	}
+	return true
+}
+
+func unlockChainTask(chainId, taskName string) {
+	if err := chainTaskCache.Unlock(chainId, taskName); err != nil {
+		logrus.Errorf("task %s unlock chain %s error, %v", taskName, chainId, err)
+	}
+}
+
+// reindexer run the steps of a re-index job, the affected ibc txs are marked with the job id
+type reindexer struct {
+	taskName string
+	job      *entity.IBCReindexJob
+	chainMap map[string]*entity.ChainConfig
+}
+
+// resolveRange resolve the times to the heights by sync_<chain>_block. The heights after the parsed height of
+// ibc_sync_transfer_tx_task are left to it.
+func (r *reindexer) resolveRange() (string, error) {
+	job := r.job
+	if job.StartTime > 0 {
+		startBlock, err := syncBlockRepo.FindByTime(job.ChainId, job.StartTime, true)
+		if err != nil {
+			return "", fmt.Errorf("find the block at start time error, %v", err)
+		}
+		endBlock, err := syncBlockRepo.FindByTime(job.ChainId, job.EndTime, false)
+		if err != nil {
+			return "", fmt.Errorf("find the block at end time error, %v", err)
+		}
+		job.StartHeight, job.EndHeight = startBlock.Height, endBlock.Height
+	}
+
+	taskRecord, err := taskRecordRepo.FindByTaskName(fmt.Sprintf(entity.TaskNameFmt, job.ChainId))
+	if err != nil {
+		if err == qmgo.ErrNoSuchDocuments {
+			return "", fmt.Errorf("the transfer txs of the chain are not parsed yet")
+		}
+		return "", err
+	}
+	var message string
+	if job.EndHeight > taskRecord.Height {
+		job.EndHeight = taskRecord.Height
+		message = fmt.Sprintf(", end height is limited to the parsed height %d", taskRecord.Height)
+	}
+	if job.StartHeight > job.EndHeight {
+		return "", fmt.Errorf("no parsed height in the range %d-%d", job.StartHeight, job.EndHeight)
+	}
+	return fmt.Sprintf("heights %d-%d%s", job.StartHeight, job.EndHeight, message), nil
+}
+
+// resetTxs set reindexing the ibc txs sent from the chain in the range, and set processing the ibc txs received,
+// acknowledged or refunded on the chain in the range. The reindexing txs keep their collection and create_at.
+func (r *reindexer) resetTxs() (string, error) {
+	chainId, jobId := r.job.ChainId, r.job.JobId
+	var reindexingNum, resetNum int
+	for _, history := range []bool{false, true} {
+		txs, err := ibcTxRepo.FindByHeightRange(chainId, r.job.StartHeight, r.job.EndHeight, history)
+		if err != nil {
+			return "", err
+		}
+
+		var reindexingIds, resetIds []string
+		for _, v := range txs {
+			if v.ScChainId == chainId && v.ScTxInfo != nil && v.ScTxInfo.Height >= r.job.StartHeight && v.ScTxInfo.Height <= r.job.EndHeight {
+				reindexingIds = append(reindexingIds, v.RecordId)
+			} else {
+				resetIds = append(resetIds, v.RecordId)
+			}
+			r.job.AffectCreateAt(v.CreateAt)
+		}
+
+		if len(reindexingIds) > 0 {
+			if err = ibcTxRepo.MarkReindexing(reindexingIds, jobId, history); err != nil {
+				return "", err
+			}
+		}
+		if len(resetIds) > 0 {
+			if err = ibcTxRepo.ResetReindexing(resetIds, jobId, history); err != nil {
+				return "", err
+			}
+		}
+		reindexingNum += len(reindexingIds)
+		resetNum += len(resetIds)
+	}
+	return fmt.Sprintf("reindexing: %d, reset: %d", reindexingNum, resetNum), nil
+}
+
+// replayTxs parse the transfer txs of the chain in the range again. The parsed ibc txs replace the reindexing ones and
+// keep their collection and create_at, so they are counted in the same day of the statistics. The reindexing txs not
+// parsed again are removed at last.
+func (r *reindexer) replayTxs() (string, error) {
+	chainId := r.job.ChainId
+	step := r.job.Step(entity.ReindexStepReplayTxs)
+	step.Total = r.job.EndHeight - r.job.StartHeight + 1
+
+	worker := newSyncTransferTxWorker(r.taskName, r.job.JobId, r.chainMap)
+	denomMap, err := worker.getChainDenomMap(chainId)
+	if err != nil {
+		return "", err
+	}
+
+	var txNum, ibcTxNum int
+	height := r.job.StartHeight - 1
+	for height < r.job.EndHeight {
+		txList, err := worker.getTxList(chainId, height, int64(constant.DefaultLimit))
+		if err != nil {
+			return "", err
+		}
+		var inRange []*entity.Tx
+		for _, v := range txList {
+			if v.Height <= r.job.EndHeight {
+				inRange = append(inRange, v)
+			}
+		}
+		if len(inRange) == 0 {
+			break
+		}
+
+		ibcTxList, ibcDenomList := worker.handleSourceTx(chainId, inRange, denomMap)
+		if len(ibcDenomList) > 0 {
+			if err = denomRepo.InsertBatch(ibcDenomList); err != nil {
+				return "", err
+			}
+		}
+		if len(ibcTxList) > 0 {
+			if err = r.saveReplayed(ibcTxList); err != nil {
+				return "", err
+			}
+		}
+
+		txNum += len(inRange)
+		ibcTxNum += len(ibcTxList)
+		height = inRange[len(inRange)-1].Height
+		step.Done = height - r.job.StartHeight + 1
+		r.save()
+		if len(txList) < constant.DefaultLimit || len(inRange) < len(txList) {
+			break
+		}
+	}
+
+	var removed int64
+	for _, history := range []bool{false, true} {
+		num, err := ibcTxRepo.DeleteReindexing(r.job.JobId, history)
+		if err != nil {
+			return "", err
+		}
+		removed += num
+	}
+	step.Done = step.Total
+	return fmt.Sprintf("txs: %d, ibc txs: %d, removed: %d", txNum, ibcTxNum, removed), nil
+}
+
+// saveReplayed replace the reindexing txs by the parsed ones and insert the new ones into the hot tier. The txs
+// replaced by the interrupted run of the job are skipped.
+func (r *reindexer) saveReplayed(ibcTxList []*entity.ExIbcTx) error {
+	recordIds := make([]string, 0, len(ibcTxList))
+	for _, v := range ibcTxList {
+		recordIds = append(recordIds, v.RecordId)
+	}
+	type originTx struct {
+		tx      *entity.ExIbcTx
+		history bool
+	}
+	origins := make(map[string]originTx, len(ibcTxList))
+	for _, history := range []bool{false, true} {
+		txs, err := ibcTxRepo.FindByRecordIds(recordIds, history)
+		if err != nil {
+			return err
+		}
+		for _, v := range txs {
+			origins[v.RecordId] = originTx{tx: v, history: history}
+		}
+	}
+
+	var newTxs []*entity.ExIbcTx
+	for _, v := range ibcTxList {
+		v.ReindexJobId = r.job.JobId
+		origin, ok := origins[v.RecordId]
+		if !ok {
+			newTxs = append(newTxs, v)
+			r.job.AffectCreateAt(v.CreateAt)
+			continue
+		}
+		if origin.tx.Status != entity.IbcTxStatusReindexing {
+			continue
+		}
+		v.CreateAt, v.UpdateAt = origin.tx.CreateAt, origin.tx.CreateAt
+		if err := ibcTxRepo.ReplaceReindexing(v, origin.history); err != nil && err != qmgo.ErrNoSuchDocuments {
+			return err
+		}
+	}
+	if len(newTxs) > 0 {
+		return ibcTxRepo.InsertBatch(newTxs)
+	}
+	return nil
+}
+
+// relateTxs relate the processing ibc txs of the job as the relate tasks do, the ones whose dc txs are not synced yet
+// are left to the relate tasks
+func (r *reindexer) relateTxs() (string, error) {
+	step := r.job.Step(entity.ReindexStepRelateTxs)
+	denomMaps := make(map[string]map[string]*entity.IBCDenom)
+	var related int
+	for _, history := range []bool{false, true} {
+		target := ibcTxTargetLatest
+		if history {
+			target = ibcTxTargetHistory
+		}
+		worker := newIbcTxRelateWorker(r.taskName, r.job.JobId, target, r.chainMap)
+
+		var afterRecordId string
+		for {
+			txs, err := ibcTxRepo.FindReindexProcessing(r.job.JobId, afterRecordId, int64(constant.DefaultLimit), history)
+			if err != nil {
+				return "", err
+			}
+			if len(txs) == 0 {
+				break
+			}
+
+			scChainTxs := make(map[string][]*entity.ExIbcTx)
+			for _, v := range txs {
+				scChainTxs[v.ScChainId] = append(scChainTxs[v.ScChainId], v)
+			}
+			for scChainId, list := range scChainTxs {
+				denomMap, ok := denomMaps[scChainId]
+				if !ok {
+					if denomMap, err = worker.getChainDenomMap(scChainId); err != nil {
+						return "", err
+					}
+					denomMaps[scChainId] = denomMap
+				}
+				worker.handlerIbcTxs(scChainId, list, denomMap)
+			}
+
+			related += len(txs)
+			step.Done, step.Total = int64(related), int64(related)
+			r.save()
+			if len(txs) < constant.DefaultLimit {
+				break
+			}
+			afterRecordId = txs[len(txs)-1].RecordId
+		}
+	}
+	return fmt.Sprintf("related: %d", related), nil
+}
+
+// statistics recalculate the channel, relayer, token and chain flow statistics from the earliest affected day, the days
+// which have archived ibc txs are kept
+func (r *reindexer) statistics() (string, error) {
+	if r.job.MinCreateAt == 0 {
+		return "skipped, no ibc tx is affected", nil
+	}
+
+	segments, err := recalculateSegments(r.job.MinCreateAt)
+	if err != nil {
+		return "", err
+	}
+	if len(segments) == 0 {
+		return "skipped, the affected days are archived", nil
+	}
+	if err = channelStatisticsTask.deal(segments, opUpdate); err != nil {
+		return "", fmt.Errorf("channel statistics error, %v", err)
+	}
+	if err = relayerStatisticsTask.deal(segments, opUpdate); err != nil {
+		return "", fmt.Errorf("relayer statistics error, %v", err)
+	}
+	if err = tokenStatisticsTask.deal(segments, opUpdate); err != nil {
+		return "", fmt.Errorf("token statistics error, %v", err)
+	}
+	if err = chainFlowStatisticsTask.deal(segments, opUpdate); err != nil {
+		return "", fmt.Errorf("chain flow statistics error, %v", err)
+	}
+	return fmt.Sprintf("%d days from %s", len(segments), time.Unix(segments[0].StartTime, 0).Format(utils.DateFmtYYYYMMDD)), nil
+}
+
+func (r *reindexer) save() {
+	if err := reindexJobRepo.Save(r.job); err != nil {
+		logrus.Errorf("task %s save job %s error, %v", r.taskName, r.job.JobId, err)
+	}
+}
@@ -0,0 +1,7 @@
+package task
+
+import "testing"
+
+func Test_IbcReindexTask(t *testing.T) {
+	new(IbcReindexTask).RunWithRange("bigbang", 1000, 2000, 0, 0)
+}
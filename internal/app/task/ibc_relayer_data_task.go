@@ -22,26 +22,18 @@ func (t *RelayerDataTask) Name() string {
 }
 
 func (t *RelayerDataTask) Switch() bool {
-	return global.Config.Task.SwitchOnlyInitRelayerData
+	return global.Config().Task.SwitchOnlyInitRelayerData
 }
 
 func (t *RelayerDataTask) Run() int {
 	startTime := time.Now().Unix()
-	historySegments, err := getHistorySegment(segmentStepHistory)
+	segments, err := getTiersSegment(segmentStepLatest)
 	if err != nil {
-		logrus.Errorf("task %s getHistorySegment err, %v", t.Name(), err)
+		logrus.Errorf("task %s getTiersSegment err, %v", t.Name(), err)
 		return -1
 	}
 	//insert relayer data
-	t.handleNewRelayerOnce(historySegments, true)
-
-	segments, err := getSegment(segmentStepLatest)
-	if err != nil {
-		logrus.Errorf("task %s getSegment err, %v", t.Name(), err)
-		return -1
-	}
-	//insert relayer data
-	t.handleNewRelayerOnce(segments, false)
+	t.handleNewRelayerOnce(segments)
 	logrus.Infof("task %s finish deal, time use %d(s)", t.Name(), time.Now().Unix()-startTime)
 	return 1
 }
@@ -72,15 +64,10 @@ func (t *RelayerDataTask) initdistRelayerMap() {
 	return
 }
 
-func (t *RelayerDataTask) handleNewRelayerOnce(segments []*segment, historyData bool) {
+func (t *RelayerDataTask) handleNewRelayerOnce(segments []*segment) {
 	t.initdistRelayerMap()
 	for _, v := range segments {
-		var relayersData []entity.IBCRelayer
-		if historyData {
-			relayersData = t.handleIbcTxHistory(v.StartTime, v.EndTime)
-		} else {
-			relayersData = t.handleIbcTxLatest(v.StartTime, v.EndTime)
-		}
+		relayersData := t.handleIbcTx(v.StartTime, v.EndTime)
 		if len(relayersData) > 0 {
 			relayersData = distinctRelayer(relayersData, t.distRelayerMap)
 			relayersData = filterDbExist(relayersData, t.distRelayerMap)
@@ -95,7 +82,7 @@ func (t *RelayerDataTask) handleNewRelayerOnce(segments []*segment, historyData
 	}
 }
 
-func (t *RelayerDataTask) handleIbcTxLatest(startTime, endTime int64) []entity.IBCRelayer {
+func (t *RelayerDataTask) handleIbcTx(startTime, endTime int64) []entity.IBCRelayer {
 	relayerDtos, err := ibcTxRepo.GetRelayerInfo(startTime, endTime)
 	if err != nil {
 		logrus.Errorf("get relayer info fail, %s", err.Error())
@@ -107,16 +94,3 @@ func (t *RelayerDataTask) handleIbcTxLatest(startTime, endTime int64) []entity.I
 	}
 	return relayers
 }
-
-func (t *RelayerDataTask) handleIbcTxHistory(startTime, endTime int64) []entity.IBCRelayer {
-	relayerDtos, err := ibcTxRepo.GetHistoryRelayerInfo(startTime, endTime)
-	if err != nil {
-		logrus.Errorf("get relayer info fail, %s", err.Error())
-		return nil
-	}
-	var relayers []entity.IBCRelayer
-	for _, val := range relayerDtos {
-		relayers = append(relayers, createRelayerData(val))
-	}
-	return relayers
-}
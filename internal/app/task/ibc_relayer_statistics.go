@@ -34,7 +34,7 @@ func (t *RelayerStatisticsTask) relayerTxsMapKey(statisticId, address, baseDenom
 }
 
 func (t *RelayerStatisticsTask) Switch() bool {
-	return global.Config.Task.SwitchIbcRelayerStatisticsTask
+	return global.Config().Task.SwitchIbcRelayerStatisticsTask
 }
 
 func (t *RelayerStatisticsTask) Run() int {
@@ -43,25 +43,12 @@ func (t *RelayerStatisticsTask) Run() int {
 		return -1
 	}
 
-	historySegments, err := getHistorySegment(segmentStepHistory)
+	segments, err := getRebuildSegments(segmentStepLatest, relayerStatisticsRepo.CopyToNew)
 	if err != nil {
-		logrus.Errorf("task %s getHistorySegment err, %v", t.Name(), err)
+		logrus.Errorf("task %s getRebuildSegments err, %v", t.Name(), err)
 		return -1
 	}
-	logrus.Infof("task %s deal history segment total: %d", t.Name(), len(historySegments))
 	startTime := time.Now().Unix()
-	if err = t.dealHistory(historySegments); err != nil {
-		logrus.Errorf("task %s dealHistory err, %v", t.Name(), err)
-		return -1
-	}
-	logrus.Infof("task %s finish dealHistory, time use %d(s)", t.Name(), time.Now().Unix()-startTime)
-
-	segments, err := getSegment(segmentStepLatest)
-	if err != nil {
-		logrus.Errorf("task %s getSegment err, %v", t.Name(), err)
-		return -1
-	}
-	startTime = time.Now().Unix()
 	logrus.Infof("task %s deal segment total: %d", t.Name(), len(segments))
 	if err = t.deal(segments, opInsert); err != nil {
 		logrus.Errorf("task %s deal err, %v", t.Name(), err)
@@ -105,29 +92,7 @@ func (t *RelayerStatisticsTask) saveData(relayerStaticsMap map[string]Statistic,
 	return nil
 }
 
-// dealHistory 处理历史记录，针对ex_ibc_tx
-func (t *RelayerStatisticsTask) dealHistory(segments []*segment) error {
-	for _, v := range segments {
-		relayerSuccessTxs, err := ibcTxRepo.CountHistoryRelayerSuccessPacketTxs(v.StartTime, v.EndTime)
-		if err != nil {
-			logrus.Error("Count History RelayerSuccessPacketTxs  have fail, ", err.Error())
-			continue
-		}
-		relayerAmounts, err := ibcTxRepo.CountHistoryRelayerPacketAmount(v.StartTime, v.EndTime)
-		if err != nil {
-			logrus.Error("Count History RelayerPacketAmount  have fail, ", err.Error())
-			continue
-		}
-		aggr := t.aggr(relayerSuccessTxs, relayerAmounts)
-		if err = t.saveData(aggr, v.StartTime, v.EndTime, opInsert); err != nil {
-			return err
-		}
-		logrus.Debugf("dealHistory task %s scan ex_ibc_tx finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
-	}
-	return nil
-}
-
-// deal 处理最新的记录，针对ex_ibc_tx_latest
+// deal 处理所有的记录，针对ex_ibc_tx_latest和ex_ibc_tx
 func (t *RelayerStatisticsTask) deal(segments []*segment, op int) error {
 	for _, v := range segments {
 		relayerSuccessTxs, err := ibcTxRepo.CountRelayerSuccessPacketTxs(v.StartTime, v.EndTime)
@@ -143,7 +108,7 @@ func (t *RelayerStatisticsTask) deal(segments []*segment, op int) error {
 		if err := t.saveData(aggr, v.StartTime, v.EndTime, op); err != nil {
 			return err
 		}
-		logrus.Debugf("deal task %s scan ex_ibc_tx_latest finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
+		logrus.Debugf("deal task %s scan ibc txs finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
 	}
 	return nil
 }
@@ -1,7 +1,7 @@
 package task
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"math"
 	"strconv"
@@ -12,7 +12,7 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/datasource"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/task/fsmtool"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/qiniu/qmgo"
@@ -72,8 +72,8 @@ func (t *IbcRelayerCronTask) Name() string {
 	return "ibc_relayer_task"
 }
 func (t *IbcRelayerCronTask) Cron() int {
-	if taskConf.CronTimeRelayerTask > 0 {
-		return taskConf.CronTimeRelayerTask
+	if taskConf().CronTimeRelayerTask > 0 {
+		return taskConf().CronTimeRelayerTask
 	}
 	return ThreeMinute
 }
@@ -127,23 +127,16 @@ func filterDbExist(relayers []entity.IBCRelayer, distRelayerMap map[string]bool)
 	}
 	return distinctArr
 }
-func getSrcChainAddress(info *dto.GetRelayerInfoDTO, historyData bool) []string {
+func getSrcChainAddress(info *dto.GetRelayerInfoDTO) []string {
 	//查询relayer在原链所有地址
 	var (
 		chainAAddress []string
 		msgPacketId   string
 	)
 
-	if historyData {
-		ibcTx, err := ibcTxRepo.GetHistoryOneRelayerScTxPacketId(info)
-		if err == nil {
-			msgPacketId = ibcTx.ScTxInfo.Msg.CommonMsg().PacketId
-		}
-	} else {
-		ibcTx, err := ibcTxRepo.GetOneRelayerScTxPacketId(info)
-		if err == nil {
-			msgPacketId = ibcTx.ScTxInfo.Msg.CommonMsg().PacketId
-		}
+	ibcTx, err := ibcTxRepo.GetOneRelayerScTxPacketId(info)
+	if err == nil {
+		msgPacketId = ibcTx.ScTxInfo.Msg.CommonMsg().PacketId
 	}
 	if msgPacketId != "" {
 		scAddr, err := txRepo.GetRelayerScChainAddr(msgPacketId, info.ScChainId)
@@ -306,61 +299,21 @@ func (t *IbcRelayerCronTask) cacheChainUnbondTimeFromLcd() {
 	group := sync.WaitGroup{}
 	group.Add(len(configList))
 	for _, val := range configList {
-		baseUrl := strings.ReplaceAll(fmt.Sprintf("%s%s", val.Lcd, val.LcdApiPath.ParamsPath), entity.ParamsModulePathPlaceholder, entity.StakeModule)
-		go func(baseUrl, chainId string) {
-			getStakeParams(baseUrl, chainId)
+		go func(cf *entity.ChainConfig) {
+			getStakeParams(cf)
 			group.Done()
-		}(baseUrl, val.ChainId)
+		}(val)
 	}
 	group.Wait()
 }
 
-func getStakeParams(baseUrl, chainId string) {
-	bz, err := utils.HttpGet(baseUrl)
-	if err != nil {
-		logrus.Errorf(" staking %s params error, %v", baseUrl, err)
-		return
-	}
-
-	var stakeparams vo.StakeParams
-	err = json.Unmarshal(bz, &stakeparams)
+func getStakeParams(cf *entity.ChainConfig) {
+	stakeparams, err := datasource.Of(cf).StakingParams(context.Background())
 	if err != nil {
-		logrus.Errorf("unmarshal staking params error, %v", err)
+		logrus.Errorf(" staking %s params error, %v", cf.ChainId, err)
 		return
 	}
-	_ = unbondTimeCache.SetUnbondTime(chainId, stakeparams.Params.UnbondingTime)
-}
-
-func getConnectionFromLcd(baseUrl string) []string {
-	bz, err := utils.HttpGet(baseUrl)
-	if err != nil {
-		logrus.Error(err)
-		return nil
-	}
-
-	var connections vo.Connections
-	err = json.Unmarshal(bz, &connections)
-	if err != nil {
-		logrus.Error(err)
-		return nil
-	}
-	return connections.ConnectionPaths
-}
-
-func getChannelFromLcd(baseUrl string) []vo.LcdChannel {
-	bz, err := utils.HttpGet(baseUrl)
-	if err != nil {
-		logrus.Error(err)
-		return nil
-	}
-
-	var connections vo.ConnectionChannels
-	err = json.Unmarshal(bz, &connections)
-	if err != nil {
-		logrus.Error(err)
-		return nil
-	}
-	return connections.Channels
+	_ = unbondTimeCache.SetUnbondTime(cf.ChainId, stakeparams.Params.UnbondingTime)
 }
 
 func (t *IbcRelayerCronTask) handleToUnknow(relayer *entity.IBCRelayer, paths []*entity.ChannelPath, updateTime, timePeriod int64) {
@@ -765,7 +718,7 @@ func (t *IbcRelayerCronTask) getChannelClient(chainId, channelId string) (string
 	}
 
 	port := chainConf.GetPortId(channelId)
-	state, err := queryClientState(chainConf.Lcd, chainConf.LcdApiPath.ClientStatePath, port, channelId)
+	state, err := queryClientState(chainConf, port, channelId)
 	if err != nil {
 		return "", err
 	}
@@ -814,7 +767,7 @@ func (t *IbcRelayerCronTask) todayStatistics() error {
 		logrus.Errorf("task %s todayStatistics error, %v", t.Name(), err)
 		return err
 	}
-	relayerDataTask.handleNewRelayerOnce(segments, false)
+	relayerDataTask.handleNewRelayerOnce(segments)
 
 	return nil
 }
@@ -838,7 +791,7 @@ func (t *IbcRelayerCronTask) yesterdayStatistics() error {
 		logrus.Errorf("task %s todayStatistics error, %v", t.Name(), err)
 		return err
 	}
-	relayerDataTask.handleNewRelayerOnce(segments, false)
+	relayerDataTask.handleNewRelayerOnce(segments)
 
 	_ = statisticsCheckRepo.Incr(t.Name(), mmdd)
 	return nil
@@ -861,15 +814,7 @@ func checkAndUpdateRelayerSrcChainAddr() {
 				DcChainId:      relayer.ChainB,
 				DcChannel:      relayer.ChannelB,
 				DcChainAddress: relayer.ChainBAddress,
-			}, false)
-			historyAddrs := getSrcChainAddress(&dto.GetRelayerInfoDTO{
-				ScChainId:      relayer.ChainA,
-				ScChannel:      relayer.ChannelA,
-				DcChainId:      relayer.ChainB,
-				DcChannel:      relayer.ChannelB,
-				DcChainAddress: relayer.ChainBAddress,
-			}, true)
-			addrs = append(addrs, historyAddrs...)
+			})
 			if len(addrs) > 0 {
 				addrs = utils.DistinctSliceStr(addrs)
 				if err := relayerRepo.UpdateSrcAddress(relayer.RelayerId, addrs); err != nil && !qmgo.IsDup(err) {
@@ -18,8 +18,8 @@ func (t *IbcStatisticCronTask) Name() string {
 	return "ibc_statistic_task"
 }
 func (t *IbcStatisticCronTask) Cron() int {
-	if taskConf.CronTimeStatisticTask > 0 {
-		return taskConf.CronTimeStatisticTask
+	if taskConf().CronTimeStatisticTask > 0 {
+		return taskConf().CronTimeStatisticTask
 	}
 	return EveryMinute
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/qiniu/qmgo"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
@@ -21,8 +22,8 @@ func (t *IbcSyncAcknowledgeTxTask) Name() string {
 }
 
 func (t *IbcSyncAcknowledgeTxTask) Cron() int {
-	if taskConf.CronTimeSyncAckTxTask > 0 {
-		return taskConf.CronTimeSyncAckTxTask
+	if taskConf().CronTimeSyncAckTxTask > 0 {
+		return taskConf().CronTimeSyncAckTxTask
 	}
 	return ThreeMinute
 }
@@ -54,7 +55,7 @@ func (t *IbcSyncAcknowledgeTxTask) Run() int {
 			return err
 		}
 		for _, val := range txs {
-			err := SaveRecvPacketTx(val, history)
+			err := SaveRecvPacketTx(ibcTxRepo, val, history)
 			if err != nil && err != qmgo.ErrNoSuchDocuments {
 				logrus.Warnf("task %s SaveRecvPacketTx failed %s, chain_id:%s packet_id:%s",
 					t.Name(),
@@ -104,9 +105,12 @@ func (t *IbcSyncAcknowledgeTxTask) SaveAcknowledgeTx(ibcTx *entity.ExIbcTx, hist
 			MsgAmount: nil,
 			Msg:       getMsgByType(*ackTx, constant.MsgTypeAcknowledgement, packetId),
 		}
+		loadFailureCategory(ibcTx)
 		return ibcTxRepo.UpdateOne(ibcTx.RecordId, history, bson.M{
 			"$set": bson.M{
 				"refunded_tx_info": ibcTx.RefundedTxInfo,
+				"failure_category": ibcTx.FailureCategory,
+				"failure_reason":   ibcTx.FailureReason,
 			},
 		})
 	}
@@ -122,7 +126,7 @@ func getMsgByType(tx entity.Tx, msgType, packetId string) *model.TxMsg {
 	return nil
 }
 
-func SaveRecvPacketTx(ibcTx *entity.ExIbcTx, history bool) error {
+func SaveRecvPacketTx(writer repository.IExIbcTxRepo, ibcTx *entity.ExIbcTx, history bool) error {
 	packetId := ibcTx.ScTxInfo.Msg.CommonMsg().PacketId
 	recvTxs, err := txRepo.GetRecvPacketTxs(ibcTx.DcChainId, packetId)
 	if err != nil {
@@ -181,10 +185,13 @@ func SaveRecvPacketTx(ibcTx *entity.ExIbcTx, history bool) error {
 			MsgAmount: nil,
 			Msg:       getMsgByType(*recvTx, constant.MsgTypeRecvPacket, packetId),
 		}
-		return ibcTxRepo.UpdateOne(ibcTx.RecordId, history, bson.M{
+		loadFailureCategory(ibcTx)
+		return writer.UpdateOne(ibcTx.RecordId, history, bson.M{
 			"$set": bson.M{
 				"dc_tx_info":       ibcTx.DcTxInfo,
 				"dc_connection_id": ibcTx.DcConnectionId,
+				"failure_category": ibcTx.FailureCategory,
+				"failure_reason":   ibcTx.FailureReason,
 			},
 		})
 	} else {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
 	"github.com/sirupsen/logrus"
@@ -24,15 +25,15 @@ func (t *IbcSyncTransferTxTask) Name() string {
 }
 
 func (t *IbcSyncTransferTxTask) Cron() int {
-	if taskConf.CronTimeSyncTransferTxTask > 0 {
-		return taskConf.CronTimeSyncTransferTxTask
+	if taskConf().CronTimeSyncTransferTxTask > 0 {
+		return taskConf().CronTimeSyncTransferTxTask
 	}
 	return ThreeMinute
 }
 
 func (t *IbcSyncTransferTxTask) workerNum() int {
-	if global.Config.Task.SyncTransferTxWorkerNum > 0 {
-		return global.Config.Task.SyncTransferTxWorkerNum
+	if global.Config().Task.SyncTransferTxWorkerNum > 0 {
+		return global.Config().Task.SyncTransferTxWorkerNum
 	}
 	return syncTransferTxTaskWorkerNum
 }
@@ -100,6 +101,10 @@ func (w *syncTransferTxWorker) exec() {
 			continue
 		}
 
+		if !lockChainTask(chainId, w.taskName) {
+			logrus.Infof("task %s worker %s chain %s is re-indexing", w.taskName, w.workerName, chainId)
+			continue
+		}
 		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chainId)
 		startTime := time.Now().Unix()
 		if err = w.parseChainIbcTx(chainId); err != nil {
@@ -107,13 +112,14 @@ func (w *syncTransferTxWorker) exec() {
 		} else {
 			logrus.Infof("task %s worker %s parse chain %s tx end,time use: %d(s)", w.taskName, w.workerName, chainId, time.Now().Unix()-startTime)
 		}
+		unlockChainTask(chainId, w.taskName)
 	}
 }
 
 func (w *syncTransferTxWorker) parseChainIbcTx(chainId string) error {
 	totalParseTx := 0
 	//const limit = 500
-	maxParseTx := global.Config.Task.SingleChainSyncTransferTxMax
+	maxParseTx := global.Config().Task.SingleChainSyncTransferTxMax
 	if maxParseTx <= 0 {
 		maxParseTx = defaultMaxHandlerTx
 	}
@@ -125,6 +131,9 @@ func (w *syncTransferTxWorker) parseChainIbcTx(chainId string) error {
 	if taskRecord.Status == entity.TaskRecordStatusClose {
 		return nil
 	}
+	if err = w.verifyBlocks(chainId, taskRecord); err != nil {
+		return err
+	}
 
 	denomMap, err := w.getChainDenomMap(chainId)
 	if err != nil {
@@ -164,11 +173,13 @@ func (w *syncTransferTxWorker) parseChainIbcTx(chainId string) error {
 			}
 		}
 
+		parsedHeight := taskRecord.Height
 		taskRecord.Height = txList[len(txList)-1].Height
 		if err = taskRecordRepo.UpdateHeight(taskRecord.TaskName, taskRecord.Height); err != nil {
 			logrus.Errorf("task %s worker %s taskRecordRepo.UpdateHeight %s error, %v", w.taskName, w.workerName, chainId, err)
 			return err
 		}
+		w.saveVerifiedBlocks(chainId, parsedHeight, taskRecord.Height)
 
 		totalParseTx += len(txList)
 		if len(txList) < constant.DefaultLimit || totalParseTx >= maxParseTx {
@@ -229,7 +240,7 @@ func (w *syncTransferTxWorker) handleSourceTx(chainId string, txList []*entity.T
 			recordId := utils.Md5(recordIdStr)
 			nowUnix := time.Now().Unix()
 			createAt := nowUnix
-			if global.Config.Task.CreateAtUseTxTime {
+			if global.Config().Task.CreateAtUseTxTime {
 				createAt = tx.Time
 			}
 
@@ -273,12 +284,14 @@ func (w *syncTransferTxWorker) handleSourceTx(chainId string, txList []*entity.T
 				},
 				BaseDenom:        baseDemom,
 				BaseDenomChainId: baseDenomChainId,
+				PacketMemo:       model.ParsePacketMemo(transferTxMsg.Memo, transferTxMsg.Receiver),
 				RetryTimes:       0,
 				NextTryTime:      nowUnix,
 				CreateAt:         createAt,
 				UpdateAt:         createAt,
 			}
 			w.setClientId(exIbcTx) // set ScClientId, DcClientId
+			loadFailureCategory(exIbcTx)
 			ibcTxList = append(ibcTxList, exIbcTx)
 		}
 	}
@@ -0,0 +1,216 @@
+package task
+
+import (
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/sirupsen/logrus"
+)
+
+// verifyBlocks compare the hashes of the last sync_verify_blocks blocks parsed before with the current hashes in
+// sync_<chain>_block. If a block was re-indexed or rolled back by the sync service, the ibc txs of the chain from the
+// first divergent height are rolled back, and the task record goes back to parse them again.
+func (w *syncTransferTxWorker) verifyBlocks(chainId string, taskRecord *entity.IbcTaskRecord) error {
+	// the statistics of the last rollback failed to be recalculated
+	if taskRecord.RollbackTime > 0 {
+		if err := w.recalculateStatistics(chainId, taskRecord); err != nil {
+			return err
+		}
+	}
+
+	window := int64(taskConf().SyncVerifyBlocks)
+	if window <= 0 || taskRecord.Height <= 0 {
+		return nil
+	}
+
+	start := taskRecord.Height - window + 1
+	verified, err := verifiedBlockRepo.FindByHeightRange(chainId, start, taskRecord.Height)
+	if err != nil {
+		logrus.Errorf("task %s worker %s verifiedBlockRepo.FindByHeightRange %s error, %v", w.taskName, w.workerName, chainId, err)
+		return err
+	}
+	if len(verified) == 0 {
+		return nil
+	}
+	blocks, err := syncBlockRepo.FindByHeightRange(chainId, verified[0].Height, taskRecord.Height)
+	if err != nil {
+		logrus.Errorf("task %s worker %s syncBlockRepo.FindByHeightRange %s error, %v", w.taskName, w.workerName, chainId, err)
+		return err
+	}
+
+	divergedHeight := findDivergedHeight(verified, blocks)
+	if divergedHeight == 0 {
+		return nil
+	}
+	logrus.Warningf("task %s worker %s chain %s block %d diverged", w.taskName, w.workerName, chainId, divergedHeight)
+
+	if err = w.rollback(chainId, divergedHeight, taskRecord); err != nil {
+		return err
+	}
+	if err = verifiedBlockRepo.DeleteFromHeight(chainId, divergedHeight); err != nil {
+		logrus.Errorf("task %s worker %s verifiedBlockRepo.DeleteFromHeight %s error, %v", w.taskName, w.workerName, chainId, err)
+		return err
+	}
+	taskRecord.Height = divergedHeight - 1
+	if err = taskRecordRepo.UpdateHeight(taskRecord.TaskName, taskRecord.Height); err != nil {
+		logrus.Errorf("task %s worker %s taskRecordRepo.UpdateHeight %s error, %v", w.taskName, w.workerName, chainId, err)
+		return err
+	}
+	logrus.Warningf("task %s worker %s chain %s rolled back to height %d", w.taskName, w.workerName, chainId, taskRecord.Height)
+
+	if taskRecord.RollbackTime > 0 {
+		return w.recalculateStatistics(chainId, taskRecord)
+	}
+	return nil
+}
+
+// findDivergedHeight the first verified height whose block is missing or has another hash now, 0 if none
+func findDivergedHeight(verified []*entity.IbcVerifiedBlock, blocks []*entity.SyncBlock) int64 {
+	hashMap := make(map[int64]string, len(blocks))
+	for _, v := range blocks {
+		hashMap[v.Height] = v.Hash
+	}
+	for _, v := range verified {
+		if hash, ok := hashMap[v.Height]; !ok || hash != v.Hash {
+			return v.Height
+		}
+	}
+	return 0
+}
+
+// rollback delete the ibc txs sent from the chain at or above the height, they are parsed again from the current
+// txs. The ibc txs received, acknowledged or refunded on the chain at or above the height are set processing to be
+// related again. The earliest create time of them is saved in the task record before they are changed, so that the
+// statistics are recalculated from it even if the recalculation fails or the process exits.
+func (w *syncTransferTxWorker) rollback(chainId string, height int64, taskRecord *entity.IbcTaskRecord) error {
+	for _, history := range []bool{false, true} {
+		txs, err := ibcTxRepo.FindByHeightRange(chainId, height, 0, history)
+		if err != nil {
+			logrus.Errorf("task %s worker %s ibcTxRepo.FindByHeightRange %s error, %v", w.taskName, w.workerName, chainId, err)
+			return err
+		}
+		if len(txs) == 0 {
+			continue
+		}
+
+		var deleteIds, resetIds []string
+		rollbackTime := taskRecord.RollbackTime
+		for _, v := range txs {
+			if v.ScChainId == chainId && v.ScTxInfo != nil && v.ScTxInfo.Height >= height {
+				deleteIds = append(deleteIds, v.RecordId)
+			} else {
+				resetIds = append(resetIds, v.RecordId)
+			}
+			if rollbackTime == 0 || v.CreateAt < rollbackTime {
+				rollbackTime = v.CreateAt
+			}
+		}
+		if rollbackTime != taskRecord.RollbackTime {
+			if err = taskRecordRepo.UpdateRollbackTime(taskRecord.TaskName, rollbackTime); err != nil {
+				logrus.Errorf("task %s worker %s taskRecordRepo.UpdateRollbackTime %s error, %v", w.taskName, w.workerName, chainId, err)
+				return err
+			}
+			taskRecord.RollbackTime = rollbackTime
+		}
+
+		if len(deleteIds) > 0 {
+			if history {
+				err = ibcTxRepo.DeleteHistoryByRecordIds(deleteIds)
+			} else {
+				err = ibcTxRepo.DeleteByRecordIds(deleteIds)
+			}
+			if err != nil {
+				logrus.Errorf("task %s worker %s delete rolled back txs of %s error, %v", w.taskName, w.workerName, chainId, err)
+				return err
+			}
+		}
+		if len(resetIds) > 0 {
+			if err = ibcTxRepo.ResetRelayed(resetIds, history); err != nil {
+				logrus.Errorf("task %s worker %s ibcTxRepo.ResetRelayed %s error, %v", w.taskName, w.workerName, chainId, err)
+				return err
+			}
+		}
+		logrus.Warningf("task %s worker %s chain %s rollback from height %d, history: %t, deleted: %d, reset: %d",
+			w.taskName, w.workerName, chainId, height, history, len(deleteIds), len(resetIds))
+	}
+	return nil
+}
+
+// recalculateStatistics recalculate the statistics from the day of the rollback time except the days which have
+// archived ibc txs, the rollback time of the task record is cleared only if all of them succeed, otherwise they are
+// recalculated again in the next run
+func (w *syncTransferTxWorker) recalculateStatistics(chainId string, taskRecord *entity.IbcTaskRecord) error {
+	segments, err := recalculateSegments(taskRecord.RollbackTime)
+	if err != nil {
+		logrus.Errorf("task %s worker %s chain %s recalculateSegments error, %v", w.taskName, w.workerName, chainId, err)
+		return err
+	}
+	for _, v := range []struct {
+		name string
+		deal func(segments []*segment, op int) error
+	}{
+		{channelStatisticsTask.Name(), channelStatisticsTask.deal},
+		{relayerStatisticsTask.Name(), relayerStatisticsTask.deal},
+		{tokenStatisticsTask.Name(), tokenStatisticsTask.deal},
+		{chainFlowStatisticsTask.Name(), chainFlowStatisticsTask.deal},
+	} {
+		if err = v.deal(segments, opUpdate); err != nil {
+			logrus.Errorf("task %s worker %s chain %s recalculate %s after rollback error, %v", w.taskName, w.workerName, chainId, v.name, err)
+			return err
+		}
+	}
+
+	if err = taskRecordRepo.UpdateRollbackTime(taskRecord.TaskName, 0); err != nil {
+		logrus.Errorf("task %s worker %s taskRecordRepo.UpdateRollbackTime %s error, %v", w.taskName, w.workerName, chainId, err)
+		return err
+	}
+	taskRecord.RollbackTime = 0
+	return nil
+}
+
+// saveVerifiedBlocks save the hashes of the parsed blocks, only the last sync_verify_blocks blocks are kept
+func (w *syncTransferTxWorker) saveVerifiedBlocks(chainId string, fromHeight, toHeight int64) {
+	window := int64(taskConf().SyncVerifyBlocks)
+	if window <= 0 || toHeight <= fromHeight {
+		return
+	}
+
+	start := toHeight - window + 1
+	if start <= fromHeight {
+		start = fromHeight + 1
+	}
+	blocks, err := syncBlockRepo.FindByHeightRange(chainId, start, toHeight)
+	if err != nil {
+		logrus.Errorf("task %s worker %s syncBlockRepo.FindByHeightRange %s error, %v", w.taskName, w.workerName, chainId, err)
+		return
+	}
+
+	now := time.Now().Unix()
+	verified := make([]*entity.IbcVerifiedBlock, 0, len(blocks))
+	for _, v := range blocks {
+		verified = append(verified, &entity.IbcVerifiedBlock{ChainId: chainId, Height: v.Height, Hash: v.Hash, CreateAt: now})
+	}
+	if err = verifiedBlockRepo.SaveByHeightRange(chainId, start, toHeight, verified); err != nil {
+		logrus.Errorf("task %s worker %s verifiedBlockRepo.SaveByHeightRange %s error, %v", w.taskName, w.workerName, chainId, err)
+		return
+	}
+	if err = verifiedBlockRepo.DeleteBeforeHeight(chainId, toHeight-window+1); err != nil {
+		logrus.Errorf("task %s worker %s verifiedBlockRepo.DeleteBeforeHeight %s error, %v", w.taskName, w.workerName, chainId, err)
+	}
+}
+
+// daySegments the segments of every day from the day of startTime to today
+func daySegments(startTime int64) []*segment {
+	start := time.Unix(startTime, 0)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	todayStart, _ := todayUnix()
+
+	var segments []*segment
+	for ; day.Unix() <= todayStart; day = day.AddDate(0, 0, 1) {
+		segments = append(segments, &segment{
+			StartTime: day.Unix(),
+			EndTime:   time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 59, time.Local).Unix(),
+		})
+	}
+	return segments
+}
@@ -0,0 +1,70 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+func Test_daySegments(t *testing.T) {
+	segments := daySegments(time.Now().AddDate(0, 0, -2).Unix())
+	if len(segments) != 3 {
+		t.Fatalf("expect 3 segments, got %d", len(segments))
+	}
+	todayStart, todayEnd := todayUnix()
+	if last := segments[2]; last.StartTime != todayStart || last.EndTime != todayEnd {
+		t.Fatalf("unexpected last segment %+v", last)
+	}
+	yesterdayStart, yesterdayEnd := yesterdayUnix()
+	if segments[1].StartTime != yesterdayStart || segments[1].EndTime != yesterdayEnd {
+		t.Fatalf("unexpected segment %+v", segments[1])
+	}
+}
+
+func Test_findDivergedHeight(t *testing.T) {
+	verified := []*entity.IbcVerifiedBlock{{Height: 10, Hash: "a"}, {Height: 11, Hash: "b"}, {Height: 12, Hash: "c"}}
+	for _, v := range []struct {
+		blocks []*entity.SyncBlock
+		height int64
+	}{
+		{[]*entity.SyncBlock{{Height: 10, Hash: "a"}, {Height: 11, Hash: "b"}, {Height: 12, Hash: "c"}}, 0},
+		{[]*entity.SyncBlock{{Height: 10, Hash: "a"}, {Height: 11, Hash: "x"}, {Height: 12, Hash: "y"}}, 11},
+		{[]*entity.SyncBlock{{Height: 10, Hash: "a"}, {Height: 12, Hash: "c"}}, 11},
+		{nil, 10},
+	} {
+		if height := findDivergedHeight(verified, v.blocks); height != v.height {
+			t.Fatalf("expect diverged height %d, got %d", v.height, height)
+		}
+	}
+}
+
+func Test_verifyBlocks(t *testing.T) {
+	w := &syncTransferTxWorker{taskName: "ibc_sync_transfer_tx_task", workerName: "test"}
+	taskRecord, err := w.checkTaskRecord("bigbang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.verifyBlocks("bigbang", taskRecord); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_rollback(t *testing.T) {
+	w := &syncTransferTxWorker{taskName: "ibc_sync_transfer_tx_task", workerName: "test"}
+	taskRecord, err := w.checkTaskRecord("bigbang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.rollback("bigbang", taskRecord.Height+1, taskRecord); err != nil {
+		t.Fatal(err)
+	}
+	if taskRecord.RollbackTime > 0 {
+		if err = w.recalculateStatistics("bigbang", taskRecord); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if taskRecord.RollbackTime != 0 {
+		t.Fatalf("expect the rollback time cleared, got %d", taskRecord.RollbackTime)
+	}
+}
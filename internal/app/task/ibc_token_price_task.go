@@ -19,8 +19,8 @@ func (t *TokenPriceTask) Name() string {
 }
 
 func (t *TokenPriceTask) Cron() int {
-	if taskConf.CronTimeTokenPriceTask > 0 {
-		return taskConf.CronTimeTokenPriceTask
+	if taskConf().CronTimeTokenPriceTask > 0 {
+		return taskConf().CronTimeTokenPriceTask
 	}
 	return ThreeMinute
 }
@@ -44,7 +44,7 @@ func (t *TokenPriceTask) Run() int {
 	}
 
 	ids := strings.Join(coinIds, ",")
-	url := fmt.Sprintf("%s?ids=%s&vs_currencies=usd", global.Config.Spi.CoingeckoPriceUrl, ids)
+	url := fmt.Sprintf("%s?ids=%s&vs_currencies=usd", global.Config().Spi.CoingeckoPriceUrl, ids)
 	bz, err := utils.HttpGet(url)
 	if err != nil {
 		logrus.Errorf("task %s run error, %v", t.Name(), err)
@@ -10,6 +10,6 @@ import (
 var tokenPriceTask TokenPriceTask
 
 func TestTokenPriceTaskRun(t *testing.T) {
-	global.Config = &conf.Config{Spi: conf.Spi{CoingeckoPriceUrl: "https://api.coingecko.com/api/v3/simple/price"}}
+	global.SetConfig(&conf.Config{Spi: conf.Spi{CoingeckoPriceUrl: "https://api.coingecko.com/api/v3/simple/price"}})
 	tokenPriceTask.Run()
 }
@@ -19,7 +19,7 @@ func (t *TokenStatisticsTask) Name() string {
 }
 
 func (t *TokenStatisticsTask) Switch() bool {
-	return global.Config.Task.SwitchIbcTokenStatisticsTask
+	return global.Config().Task.SwitchIbcTokenStatisticsTask
 }
 
 func (t *TokenStatisticsTask) Run() int {
@@ -33,20 +33,14 @@ func (t *TokenStatisticsTask) Run() int {
 		return -1
 	}
 
-	historySegments, err := getHistorySegment(segmentStepHistory)
-	if err != nil {
-		logrus.Errorf("task %s getHistorySegment err, %v", t.Name(), err)
-		return -1
-	}
-	logrus.Infof("task %s deal history segment total: %d", t.Name(), len(historySegments))
-	if err = t.dealHistory(historySegments); err != nil {
-		logrus.Errorf("task %s dealHistory err, %v", t.Name(), err)
-		return -1
-	}
-
-	segments, err := getSegment(segmentStepLatest)
+	segments, err := getRebuildSegments(segmentStepLatest, func(endTime int64) error {
+		if err := tokenTraceStatisticsRepo.CopyToNew(endTime); err != nil {
+			return err
+		}
+		return tokenStatisticsRepo.CopyToNew(endTime)
+	})
 	if err != nil {
-		logrus.Errorf("task %s getSegment err, %v", t.Name(), err)
+		logrus.Errorf("task %s getRebuildSegments err, %v", t.Name(), err)
 		return -1
 	}
 	logrus.Infof("task %s deal segment total: %d", t.Name(), len(segments))
@@ -68,38 +62,7 @@ func (t *TokenStatisticsTask) Run() int {
 	return 1
 }
 
-// dealHistory 处理历史记录，针对ex_ibc_tx
-func (t *TokenStatisticsTask) dealHistory(segments []*segment) error {
-	for _, v := range segments {
-		transferTxs, err := ibcTxRepo.CountBaseDenomHistoryTransferTxs(v.StartTime, v.EndTime)
-		if err != nil {
-			logrus.Errorf("task %s CountBaseDenomHistoryTransferTxs err, %v", t.Name(), err)
-			return err
-		}
-
-		if len(transferTxs) > 0 {
-			if err = t.saveTokenTransferData(transferTxs, v.StartTime, v.EndTime, opInsert); err != nil {
-				return err
-			}
-		}
-
-		traceReceiveTxs, err := ibcTxRepo.CountIBCTokenHistoryRecvTxs(v.StartTime, v.EndTime)
-		if err != nil {
-			logrus.Errorf("task %s CountIBCTokenHistoryRecvTxs err, %v", t.Name(), err)
-			return err
-		}
-
-		if len(traceReceiveTxs) > 0 {
-			if err = t.saveTraceReceiveData(traceReceiveTxs, v.StartTime, v.EndTime, opInsert); err != nil {
-				return err
-			}
-		}
-		logrus.Debugf("dealHistory task %s scan ex_ibc_tx finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
-	}
-	return nil
-}
-
-// deal 处理最新的记录，针对ex_ibc_tx_latest
+// deal 处理所有的记录，针对ex_ibc_tx_latest和ex_ibc_tx
 func (t *TokenStatisticsTask) deal(segments []*segment, op int) error {
 	for _, v := range segments {
 		transferTxs, err := ibcTxRepo.CountBaseDenomTransferTxs(v.StartTime, v.EndTime)
@@ -125,7 +88,7 @@ func (t *TokenStatisticsTask) deal(segments []*segment, op int) error {
 				return err
 			}
 		}
-		logrus.Debugf("deal task %s scan ex_ibc_tx_latest finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
+		logrus.Debugf("deal task %s scan ibc txs finish segment [%v:%v]", t.Name(), v.StartTime, v.EndTime)
 	}
 	return nil
 }
@@ -1,8 +1,7 @@
 package task
 
 import (
-	"crypto/sha256"
-	"encoding/json"
+	"context"
 	"fmt"
 	"math"
 	"strings"
@@ -12,9 +11,8 @@ import (
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/constant"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/vo"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/pkg/datasource"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
-	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils/bech32"
 	v8 "github.com/go-redis/redis/v8"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
@@ -22,13 +20,12 @@ import (
 )
 
 type TokenTask struct {
-	chainIds         []string                  // 系统支持的chain列表
-	chainLcdMap      map[string]string         // chain lcd地址
-	chainLcdApiMap   map[string]entity.ApiPath // chain lcd api地址
-	escrowAddressMap map[string][]string       // chain ibc跨链托管地址
-	baseDenomList    entity.IBCBaseDenomList   // 所有的base denom
-	ibcChainDenomMap map[string][]string       // chain id 和其对应的跨链denom的映射关系
-	ibcReceiveTxsMap map[string]int64          // ibc hash token denom的recv txs
+	chainIds         []string                       // 系统支持的chain列表
+	chainMap         map[string]*entity.ChainConfig // chain配置
+	escrowAddressMap map[string][]string            // chain ibc跨链托管地址
+	baseDenomList    entity.IBCBaseDenomList        // 所有的base denom
+	ibcChainDenomMap map[string][]string            // chain id 和其对应的跨链denom的映射关系
+	ibcReceiveTxsMap map[string]int64               // ibc hash token denom的recv txs
 }
 
 func (t *TokenTask) Name() string {
@@ -222,13 +219,11 @@ func (t *TokenTask) analyzeChainConf() error {
 	}
 
 	chainIds := make([]string, 0, len(configList))
-	chainLcdMap := make(map[string]string)
-	chainLcdApiMap := make(map[string]entity.ApiPath)
+	chainMap := make(map[string]*entity.ChainConfig)
 	escrowAddressMap := make(map[string][]string)
 	for _, v := range configList {
 		chainIds = append(chainIds, v.ChainId)
-		chainLcdMap[v.ChainId] = v.Lcd
-		chainLcdApiMap[v.ChainId] = v.LcdApiPath
+		chainMap[v.ChainId] = v
 		address, err := t.analyzeChainEscrowAddress(v.IbcInfo, v.AddrPrefix)
 		if err != nil {
 			continue
@@ -236,8 +231,7 @@ func (t *TokenTask) analyzeChainConf() error {
 		escrowAddressMap[v.ChainId] = address
 	}
 	t.chainIds = chainIds
-	t.chainLcdMap = chainLcdMap
-	t.chainLcdApiMap = chainLcdApiMap
+	t.chainMap = chainMap
 	t.escrowAddressMap = escrowAddressMap
 	return nil
 }
@@ -257,14 +251,7 @@ func (t *TokenTask) analyzeChainEscrowAddress(info []*entity.IbcInfo, addrPrefix
 }
 
 func (t *TokenTask) getEscrowAddress(portID, channelID, addrPrefix string) (string, error) {
-	contents := fmt.Sprintf("%s/%s", portID, channelID)
-	const version = "ics20-1"
-	preImage := []byte(version)
-	preImage = append(preImage, 0)
-	preImage = append(preImage, contents...)
-	hash := sha256.Sum256(preImage)
-
-	addr, err := bech32.ConvertAndEncode(addrPrefix, hash[:20])
+	addr, err := calculateEscrowAddress(portID, channelID, addrPrefix)
 	if err != nil {
 		logrus.Errorf("task %s getEscrowAddress error, %v", t.Name(), err)
 		return "", err
@@ -303,49 +290,18 @@ func (t *TokenTask) setDenomSupply(existedTokenList, newTokenList entity.IBCToke
 }
 
 func (t *TokenTask) getSupplyFromLcd(chainId string) {
-	lcd := t.chainLcdMap[chainId]
-	apiPath := t.chainLcdApiMap[chainId].SupplyPath
 	denoms := t.ibcChainDenomMap[chainId]
-	baseUrl := fmt.Sprintf("%s%s", lcd, apiPath)
-	limit := 500
-	key := ""
-
-	for {
-		var url string
-		if key == "" {
-			url = fmt.Sprintf("%s?pagination.limit=%d", baseUrl, limit)
-		} else {
-			url = fmt.Sprintf("%s?pagination.limit=%d&pagination.key=%s", baseUrl, limit, key)
-		}
-
-		bz, err := utils.HttpGet(url)
-		if err != nil {
-			logrus.Errorf("task %s chain: %s setSupply error, %v", t.Name(), chainId, err)
-			return
-		}
-
-		var supplyResp vo.SupplyResp
-		err = json.Unmarshal(bz, &supplyResp)
-		if err != nil {
-			logrus.Errorf("task %s chain: %s setSupply error, %v", t.Name(), chainId, err)
-			return
-		}
-
-		// 第一页查询成功时，清除之前的老数据
-		if key == "" {
-			_, _ = denomDataRepo.DelSupply(chainId)
-		}
-
-		for _, v := range supplyResp.Supply { // ibc denom 和 链原生denom的amount 存下来
-			if strings.HasPrefix(v.Denom, constant.IBCTokenPrefix) || utils.InArray(denoms, v.Denom) {
-				_ = denomDataRepo.SetSupply(chainId, v.Denom, v.Amount)
-			}
-		}
+	supply, err := datasource.Of(t.chainMap[chainId]).Supply(context.Background())
+	if err != nil {
+		logrus.Errorf("task %s chain: %s setSupply error, %v", t.Name(), chainId, err)
+		return
+	}
 
-		if supplyResp.Pagination.NextKey == nil {
-			break
-		} else {
-			key = *supplyResp.Pagination.NextKey
+	// 查询成功时，清除之前的老数据
+	_, _ = denomDataRepo.DelSupply(chainId)
+	for _, v := range supply { // ibc denom 和 链原生denom的amount 存下来
+		if strings.HasPrefix(v.Denom, constant.IBCTokenPrefix) || utils.InArray(denoms, v.Denom) {
+			_ = denomDataRepo.SetSupply(chainId, v.Denom, v.Amount)
 		}
 	}
 }
@@ -408,63 +364,31 @@ func (t *TokenTask) setIbcTransferAmount(existedTokenList, newTokenList entity.I
 
 func (t *TokenTask) getTransAmountFromLcd(chainId string, addrList []string) {
 	denomTransAmountMap := make(map[string]decimal.Decimal)
-	lcd := t.chainLcdMap[chainId]
-	apiPath := t.chainLcdApiMap[chainId].BalancesPath
+	client := datasource.Of(t.chainMap[chainId])
 	for _, addr := range addrList { // 一条链上的所有地址都要查询一遍，并按denom分组计数
-		limit := 500
-		key := ""
-		earlyTermination := false
-		baseUrl := strings.ReplaceAll(fmt.Sprintf("%s%s", lcd, apiPath), entity.ApiBalancesPathPlaceholder, addr)
-
-		for { // 计算地址上所锁定的denom的数量
-			var url string
-			if key == "" {
-				url = fmt.Sprintf("%s?pagination.limit=%d", baseUrl, limit)
-			} else {
-				url = fmt.Sprintf("%s?pagination.limit=%d&pagination.key=%s", baseUrl, limit, key)
-			}
-
-			bz, err := utils.HttpGet(url)
-			if err != nil {
-				if isConnectionErr(err) {
-					earlyTermination = true
-				}
-				logrus.Errorf("task %s chain: %s getTransAmountFromLcd error, %v", t.Name(), chainId, err)
+		balances, err := client.Balances(context.Background(), addr)
+		if err != nil {
+			logrus.Errorf("task %s chain: %s getTransAmountFromLcd error, %v", t.Name(), chainId, err)
+			if isConnectionErr(err) {
 				break
 			}
+			continue
+		}
 
-			var balancesResp vo.BalancesResp
-			err = json.Unmarshal(bz, &balancesResp)
+		for _, v := range balances { // 计算地址上所锁定的denom的数量
+			amount, err := decimal.NewFromString(v.Amount)
 			if err != nil {
 				logrus.Errorf("task %s chain: %s getTransAmountFromLcd error, %v", t.Name(), chainId, err)
-				break
-			}
-
-			for _, v := range balancesResp.Balances {
-				amount, err := decimal.NewFromString(v.Amount)
-				if err != nil {
-					logrus.Errorf("task %s chain: %s getTransAmountFromLcd error, %v", t.Name(), chainId, err)
-					continue
-				}
-
-				d, ok := denomTransAmountMap[v.Denom]
-				if !ok {
-					denomTransAmountMap[v.Denom] = amount
-				} else {
-					denomTransAmountMap[v.Denom] = d.Add(amount)
-				}
+				continue
 			}
 
-			if balancesResp.Pagination.NextKey == nil {
-				break
+			d, ok := denomTransAmountMap[v.Denom]
+			if !ok {
+				denomTransAmountMap[v.Denom] = amount
 			} else {
-				key = *balancesResp.Pagination.NextKey
+				denomTransAmountMap[v.Denom] = d.Add(amount)
 			}
 		}
-
-		if earlyTermination {
-			break
-		}
 	}
 
 	if len(denomTransAmountMap) > 0 {
@@ -0,0 +1,424 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/dto"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultArchiveBatchSize       = 1000
+	defaultArchiveRestoreKeepDays = 7
+)
+
+// IbcTxArchiveTask export the ibc txs of ex_ibc_tx older than archive.max_age_days into the files of the object storage,
+// which are partitioned by the sc chain and the month of the tx time, then remove them from mongo. The files are listed
+// in ibc_tx_archive and the manifest.json of the partition, the tx hashes of them are kept in ibc_tx_archive_hash.
+type IbcTxArchiveTask struct {
+}
+
+var _ Task = new(IbcTxArchiveTask)
+
+func (t *IbcTxArchiveTask) Name() string {
+	return "ibc_tx_archive_task"
+}
+
+func (t *IbcTxArchiveTask) Switch() bool {
+	return global.Config().Task.SwitchIbcTxArchiveTask
+}
+
+func (t *IbcTxArchiveTask) Cron() int {
+	if taskConf().CronTimeIbcTxArchiveTask > 0 {
+		return taskConf().CronTimeIbcTxArchiveTask
+	}
+	return OneDay
+}
+
+func (t *IbcTxArchiveTask) batchSize() int64 {
+	if global.Config().Archive.BatchSize > 0 {
+		return int64(global.Config().Archive.BatchSize)
+	}
+	return defaultArchiveBatchSize
+}
+
+func (t *IbcTxArchiveTask) Run() int {
+	if !t.Switch() {
+		logrus.Infof("task %s closed", t.Name())
+		return 1
+	}
+	maxAgeDays := global.Config().Archive.MaxAgeDays
+	if maxAgeDays <= 0 {
+		logrus.Infof("task %s archive.max_age_days is not set", t.Name())
+		return 1
+	}
+
+	txTime := time.Now().AddDate(0, 0, -maxAgeDays).Unix()
+	partitions, err := ibcTxRepo.AggrArchivePartitions(txTime)
+	if err != nil {
+		logrus.Errorf("task %s AggrArchivePartitions error, %v", t.Name(), err)
+		return -1
+	}
+
+	res := 1
+	for _, v := range partitions {
+		if t.isRestored(v) {
+			logrus.Infof("task %s partition %s %s is restored, skip it", t.Name(), v.ChainId, v.Month)
+			continue
+		}
+		if err = t.archive(v.ChainId, v.Month, txTime); err != nil {
+			logrus.Errorf("task %s archive partition %s %s error, %v", t.Name(), v.ChainId, v.Month, err)
+			res = -1
+		}
+	}
+	return res
+}
+
+// isRestored the restored partitions are kept in mongo for archive.restore_keep_days before they are archived again
+func (t *IbcTxArchiveTask) isRestored(partition *dto.ArchivePartitionDTO) bool {
+	keepDays := global.Config().Archive.RestoreKeepDays
+	if keepDays <= 0 {
+		keepDays = defaultArchiveRestoreKeepDays
+	}
+	files, err := archiveRepo.FindByPartition(partition.ChainId, partition.Month)
+	if err != nil {
+		logrus.Errorf("task %s archiveRepo.FindByPartition error, %v", t.Name(), err)
+		return true
+	}
+	keepTime := time.Now().AddDate(0, 0, -keepDays).Unix()
+	for _, v := range files {
+		if v.Status == entity.ArchiveStatusRestoring || (v.Status == entity.ArchiveStatusRestored && v.RestoreAt > keepTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// archive export the txs of the partition before txTime into a new file, the restored files of the partition are
+// replaced by it. The file is recorded pending before anything is written and it's archived after its txs are removed
+// from mongo, so a failed run is finished by the next one, see finishPending.
+func (t *IbcTxArchiveTask) archive(chainId, month string, txTime int64) error {
+	startTime, endTime, err := entity.ArchiveMonthRange(month)
+	if err != nil {
+		return err
+	}
+	if endTime >= txTime {
+		endTime = txTime - 1
+	}
+	if err = t.finishPending(chainId, month); err != nil {
+		return err
+	}
+
+	writer, err := repository.NewArchiveFileWriter()
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	key := repository.ArchiveFileKey(chainId, month)
+	archive := &entity.IBCTxArchive{Key: key, ChainId: chainId, Month: month, Status: entity.ArchiveStatusPending}
+	if err = archiveRepo.Insert(archive); err != nil {
+		return err
+	}
+	batchSize := t.batchSize()
+	for skip := int64(0); ; skip += batchSize {
+		txs, err := ibcTxRepo.FindHistoryToArchive(chainId, startTime, endTime, skip, batchSize)
+		if err != nil {
+			return err
+		}
+		// a batch is a gzip member, the hashes of its txs point to the offset of it
+		offset, err := writer.Offset()
+		if err != nil {
+			return err
+		}
+		var hashes []*entity.IBCTxArchiveHash
+		for _, v := range txs {
+			if err = writer.Write(v); err != nil {
+				return err
+			}
+			for _, info := range []*entity.TxInfo{v.ScTxInfo, v.DcTxInfo, v.RefundedTxInfo} {
+				if info != nil && info.Hash != "" {
+					hashes = append(hashes, &entity.IBCTxArchiveHash{Hash: info.Hash, RecordId: v.RecordId, ChainId: chainId, Month: month, Key: key, Offset: offset})
+				}
+			}
+			if archive.MinTxTime == 0 || v.TxTime < archive.MinTxTime {
+				archive.MinTxTime = v.TxTime
+			}
+			if v.TxTime > archive.MaxTxTime {
+				archive.MaxTxTime = v.TxTime
+			}
+			if v.CreateAt > archive.MaxCreateAt {
+				archive.MaxCreateAt = v.CreateAt
+			}
+		}
+		for _, v := range chunkHashes(hashes, int(batchSize)) {
+			if err = archiveHashRepo.InsertBatch(v); err != nil {
+				return err
+			}
+		}
+		archive.Txs += int64(len(txs))
+		if int64(len(txs)) < batchSize {
+			break
+		}
+	}
+	if archive.Txs == 0 {
+		return t.discardPending(archive)
+	}
+
+	if err = writer.Finish(); err != nil {
+		return err
+	}
+	archive.Size, archive.Sha256 = writer.Size(), writer.Sha256()
+	if err = archiveFileRepo.Put(key, writer); err != nil {
+		return err
+	}
+	if err = archiveFileRepo.Verify(key, archive.Size, archive.Sha256); err != nil {
+		return err
+	}
+	// the sha256 is saved only after the file is verified, the txs of a pending file without it are still in mongo
+	if err = archiveRepo.Update(archive); err != nil {
+		return err
+	}
+	if err = t.removeRestored(chainId, month); err != nil {
+		return err
+	}
+
+	// 文件校验通过后再删除 mongo 中的数据
+	if err = t.deleteArchived(writer.Range); err != nil {
+		return err
+	}
+	archive.Status = entity.ArchiveStatusArchived
+	if err = archiveRepo.Update(archive); err != nil {
+		return err
+	}
+	if err = t.putManifest(chainId, month); err != nil {
+		return err
+	}
+	logrus.Infof("task %s archive partition %s %s, txs: %d, size: %d, key: %s", t.Name(), chainId, month, archive.Txs, archive.Size, key)
+	return nil
+}
+
+// finishPending finish the pending files of the partition left by a failed run. A file verified is archived after its
+// txs are removed from mongo, the others are discarded and their txs are archived again.
+func (t *IbcTxArchiveTask) finishPending(chainId, month string) error {
+	files, err := archiveRepo.FindByPartition(chainId, month)
+	if err != nil {
+		return err
+	}
+	for _, v := range files {
+		if v.Status != entity.ArchiveStatusPending {
+			continue
+		}
+		if v.Sha256 == "" {
+			if err = t.discardPending(v); err != nil {
+				return err
+			}
+			logrus.Infof("task %s discard the pending file %s", t.Name(), v.Key)
+			continue
+		}
+
+		if err = archiveFileRepo.Verify(v.Key, v.Size, v.Sha256); err != nil {
+			return err
+		}
+		key, sum := v.Key, v.Sha256
+		err = t.deleteArchived(func(fn func(tx *entity.ExIbcTx) error) error {
+			return archiveFileRepo.Read(key, sum, fn)
+		})
+		if err != nil {
+			return err
+		}
+		if err = archiveRepo.UpdateStatus(v.Key, entity.ArchiveStatusArchived, 0); err != nil {
+			return err
+		}
+		if err = t.removeRestored(chainId, month); err != nil {
+			return err
+		}
+		if err = t.putManifest(chainId, month); err != nil {
+			return err
+		}
+		logrus.Infof("task %s finish the pending file %s", t.Name(), v.Key)
+	}
+	return nil
+}
+
+// discardPending remove the pending file whose txs are not removed from mongo
+func (t *IbcTxArchiveTask) discardPending(file *entity.IBCTxArchive) error {
+	if err := archiveFileRepo.Delete(file.Key); err != nil {
+		return err
+	}
+	if err := archiveHashRepo.DeleteByKey(file.Key); err != nil {
+		return err
+	}
+	return archiveRepo.Delete(file.Key)
+}
+
+// deleteArchived remove the txs of the archived file from ex_ibc_tx batch by batch, scan reads the txs of the file
+func (t *IbcTxArchiveTask) deleteArchived(scan func(fn func(tx *entity.ExIbcTx) error) error) error {
+	batchSize := int(t.batchSize())
+	recordIds := make([]string, 0, batchSize)
+	err := scan(func(tx *entity.ExIbcTx) error {
+		recordIds = append(recordIds, tx.RecordId)
+		if len(recordIds) < batchSize {
+			return nil
+		}
+		if err := ibcTxRepo.DeleteHistoryByRecordIds(recordIds); err != nil {
+			return err
+		}
+		recordIds = recordIds[:0]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(recordIds) == 0 {
+		return nil
+	}
+	return ibcTxRepo.DeleteHistoryByRecordIds(recordIds)
+}
+
+// removeRestored remove the restored files of the partition, their txs are archived again in the new file
+func (t *IbcTxArchiveTask) removeRestored(chainId, month string) error {
+	files, err := archiveRepo.FindByPartition(chainId, month)
+	if err != nil {
+		return err
+	}
+	for _, v := range files {
+		if v.Status != entity.ArchiveStatusRestored {
+			continue
+		}
+		if err = archiveFileRepo.Delete(v.Key); err != nil {
+			return err
+		}
+		if err = archiveHashRepo.DeleteByKey(v.Key); err != nil {
+			return err
+		}
+		if err = archiveRepo.Delete(v.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putManifest the manifest lists the files of the partition except the pending ones
+func (t *IbcTxArchiveTask) putManifest(chainId, month string) error {
+	files, err := archiveRepo.FindByPartition(chainId, month)
+	if err != nil {
+		return err
+	}
+	manifest := &entity.ArchiveManifest{ChainId: chainId, Month: month}
+	for _, v := range files {
+		if v.Status != entity.ArchiveStatusPending {
+			manifest.Files = append(manifest.Files, v)
+		}
+	}
+	return archiveFileRepo.PutManifest(manifest)
+}
+
+// Restore insert the archived txs of the partition into ex_ibc_tx again, the files are kept and marked restored
+func (t *IbcTxArchiveTask) Restore(chainId, month string) int {
+	files, err := archiveRepo.FindByPartition(chainId, month)
+	if err != nil {
+		logrus.Errorf("task %s archiveRepo.FindByPartition error, %v", t.Name(), err)
+		return -1
+	}
+	if len(files) == 0 {
+		logrus.Errorf("task %s partition %s %s is not archived", t.Name(), chainId, month)
+		return -1
+	}
+
+	res := 1
+	for _, v := range files {
+		// the txs of a pending file are removed from mongo by the archive task first
+		if v.Status == entity.ArchiveStatusRestored || v.Status == entity.ArchiveStatusPending {
+			continue
+		}
+		if err = archiveRepo.UpdateStatus(v.Key, entity.ArchiveStatusRestoring, 0); err != nil {
+			logrus.Errorf("task %s archiveRepo.UpdateStatus error, %v", t.Name(), err)
+			return -1
+		}
+
+		restored, err := t.restoreFile(v)
+		if err != nil {
+			logrus.Errorf("task %s restore %s error, %v", t.Name(), v.Key, err)
+			_ = archiveRepo.UpdateStatus(v.Key, entity.ArchiveStatusArchived, 0)
+			res = -1
+			continue
+		}
+		if err = archiveRepo.UpdateStatus(v.Key, entity.ArchiveStatusRestored, time.Now().Unix()); err != nil {
+			logrus.Errorf("task %s archiveRepo.UpdateStatus error, %v", t.Name(), err)
+			res = -1
+		}
+		logrus.Infof("task %s restore %s, txs: %d", t.Name(), v.Key, restored)
+	}
+
+	if err = t.putManifest(chainId, month); err != nil {
+		logrus.Errorf("task %s putManifest error, %v", t.Name(), err)
+		res = -1
+	}
+	return res
+}
+
+// RestoreWithParam restore the partition of the chain at the month(UTC) of the unix time
+func (t *IbcTxArchiveTask) RestoreWithParam(chainId string, unixTime int64) int {
+	if chainId == "" || unixTime <= 0 {
+		logrus.Errorf("task %s the chain and the time are required", t.Name())
+		return -1
+	}
+	return t.Restore(chainId, time.Unix(unixTime, 0).UTC().Format(entity.ArchiveMonthFormat))
+}
+
+// restoreFile the txs of the file replace the ones of the same record id in ex_ibc_tx, so a file can be restored again
+func (t *IbcTxArchiveTask) restoreFile(file *entity.IBCTxArchive) (int, error) {
+	batchSize := int(t.batchSize())
+	var total int
+	batch := make([]*entity.ExIbcTx, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		recordIds := make([]string, 0, len(batch))
+		for _, v := range batch {
+			recordIds = append(recordIds, v.RecordId)
+		}
+		if err := ibcTxRepo.DeleteHistoryByRecordIds(recordIds); err != nil {
+			return err
+		}
+		if err := ibcTxRepo.InsertBatchHistory(batch); err != nil {
+			return err
+		}
+		total += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := archiveFileRepo.Read(file.Key, file.Sha256, func(tx *entity.ExIbcTx) error {
+		batch = append(batch, tx)
+		if len(batch) < batchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil && int64(total) != file.Txs {
+		err = fmt.Errorf("%d txs restored, expect %d", total, file.Txs)
+	}
+	return total, err
+}
+
+func chunkHashes(hashes []*entity.IBCTxArchiveHash, size int) [][]*entity.IBCTxArchiveHash {
+	var chunks [][]*entity.IBCTxArchiveHash
+	for start := 0; start < len(hashes); start += size {
+		end := start + size
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		chunks = append(chunks, hashes[start:end])
+	}
+	return chunks
+}
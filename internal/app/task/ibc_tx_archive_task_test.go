@@ -0,0 +1,23 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
+)
+
+func Test_IbcTxArchiveTask(t *testing.T) {
+	new(IbcTxArchiveTask).Run()
+}
+
+func Test_IbcTxArchiveRestore(t *testing.T) {
+	new(IbcTxArchiveTask).Restore("bigbang", "2022-01")
+}
+
+func Test_ChunkHashes(t *testing.T) {
+	hashes := make([]*entity.IBCTxArchiveHash, 5)
+	chunks := chunkHashes(hashes, 2)
+	if len(chunks) != 3 || len(chunks[2]) != 1 {
+		t.Fatalf("unexpected chunks %v", chunks)
+	}
+}
@@ -1,8 +1,11 @@
 package task
 
 import (
+	"math"
+	"strings"
 	"time"
 
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/global"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/sirupsen/logrus"
@@ -18,12 +21,12 @@ func (t *IbcTxMigrateTask) Name() string {
 }
 
 func (t *IbcTxMigrateTask) Switch() bool {
-	return global.Config.Task.SwitchIbcTxMigrateTask
+	return global.Config().Task.SwitchIbcTxMigrateTask
 }
 
 func (t *IbcTxMigrateTask) Cron() int {
-	if taskConf.CronTimeIbcTxMigrateTask > 0 {
-		return taskConf.CronTimeIbcTxMigrateTask
+	if taskConf().CronTimeIbcTxMigrateTask > 0 {
+		return taskConf().CronTimeIbcTxMigrateTask
 	}
 	return EveryHour
 }
@@ -34,78 +37,118 @@ func (t *IbcTxMigrateTask) Run() int {
 		return 1
 	}
 
-	err1 := t.migrateSetting()
-	err2 := t.migrateNormal()
+	policy := newTieringPolicy(global.Config().Tiering)
+	err1 := t.migrateSetting(policy)
+	err2 := t.migrateAged(policy)
+	err3 := t.migrateOverflow(policy)
 
-	if err1 != nil || err2 != nil {
+	if err1 != nil || err2 != nil || err3 != nil {
 		return -1
 	}
 	return 1
 }
 
-func (t *IbcTxMigrateTask) migrateSetting() error {
-	const limit = 1000
-	status := []entity.IbcTxStatus{entity.IbcTxStatusSetting}
+// tieringPolicy the policy of moving the ibc txs from ex_ibc_tx_latest to ex_ibc_tx, see conf.Tiering
+type tieringPolicy struct {
+	hotMaxTxs  int64
+	hotMaxAge  time.Duration
+	coldStatus []entity.IbcTxStatus
+	batchSize  int64
+}
+
+func newTieringPolicy(c conf.Tiering) tieringPolicy {
+	policy := tieringPolicy{
+		hotMaxTxs:  defaultHotMaxTxs,
+		hotMaxAge:  time.Duration(c.HotMaxAgeDays) * 24 * time.Hour,
+		coldStatus: entity.IbcTxUsefulStatus,
+		batchSize:  defaultTieringBatchSize,
+	}
+	if c.HotMaxTxs > 0 {
+		policy.hotMaxTxs = c.HotMaxTxs
+	}
+	if c.BatchSize > 0 {
+		policy.batchSize = int64(c.BatchSize)
+	}
+
+	statusMap := map[string]entity.IbcTxStatus{
+		"success":    entity.IbcTxStatusSuccess,
+		"failed":     entity.IbcTxStatusFailed,
+		"processing": entity.IbcTxStatusProcessing,
+		"refunded":   entity.IbcTxStatusRefunded,
+	}
+	var coldStatus []entity.IbcTxStatus
+	for _, v := range strings.Split(c.ColdStatus, ",") {
+		if status, ok := statusMap[strings.TrimSpace(v)]; ok {
+			coldStatus = append(coldStatus, status)
+		}
+	}
+	if len(coldStatus) > 0 {
+		policy.coldStatus = coldStatus
+	}
+	return policy
+}
+
+// migrate move the txs found by find in batches, until the batches are done or less than a batch is found
+func (t *IbcTxMigrateTask) migrate(kind string, batchSize int64, batch int64, find func() ([]*entity.ExIbcTx, error)) error {
 	totalMigrate := 0
-	for {
-		txList, err := ibcTxRepo.FindByStatus(status, limit)
+	for ; batch > 0; batch-- {
+		txList, err := find()
 		if err != nil {
-			logrus.Errorf("task %s find setting txs error, %v", t.Name(), err)
+			logrus.Errorf("task %s find %s txs error, %v", t.Name(), kind, err)
 			return err
 		}
 
 		if err = ibcTxRepo.Migrate(txList); err != nil {
-			logrus.Errorf("task %s migrate setting txs error, %v", t.Name(), err)
+			logrus.Errorf("task %s migrate %s txs error, %v", t.Name(), kind, err)
 			return err
 		}
 
 		totalMigrate += len(txList)
-		if len(txList) < limit {
+		if int64(len(txList)) < batchSize {
 			break
 		} else {
 			time.Sleep(200 * time.Millisecond) // avoid master-slave delay problem
 		}
 	}
 
-	logrus.Infof("task %s migrate %d setting txs", t.Name(), totalMigrate)
+	logrus.Infof("task %s migrate %d %s txs", t.Name(), totalMigrate, kind)
 	return nil
 }
 
-func (t *IbcTxMigrateTask) migrateNormal() error {
-	const limit = 1000
-	status := entity.IbcTxUsefulStatus
-	count, err := ibcTxRepo.CountByStatus(status)
-	if err != nil {
-		logrus.Errorf("task %s find count normal txs error, %v", t.Name(), err)
-		return err
-	}
+// migrateSetting the txs of setting status are always moved
+func (t *IbcTxMigrateTask) migrateSetting(policy tieringPolicy) error {
+	status := []entity.IbcTxStatus{entity.IbcTxStatusSetting}
+	return t.migrate("setting", policy.batchSize, math.MaxInt64, func() ([]*entity.ExIbcTx, error) {
+		return ibcTxRepo.FindByStatus(status, policy.batchSize)
+	})
+}
 
-	batch := (count - ibcTxCount) / limit
-	if batch <= 0 {
+// migrateAged the txs of the cold status older than hot_max_age_days
+func (t *IbcTxMigrateTask) migrateAged(policy tieringPolicy) error {
+	if policy.hotMaxAge <= 0 {
 		return nil
 	}
 
-	totalMigrate := 0
-	for ; batch > 0; batch-- {
-		txList, err := ibcTxRepo.FindByStatus(status, limit)
-		if err != nil {
-			logrus.Errorf("task %s find mormal txs error, %v", t.Name(), err)
-			return err
-		}
+	txTime := time.Now().Add(-policy.hotMaxAge).Unix()
+	return t.migrate("aged", policy.batchSize, math.MaxInt64, func() ([]*entity.ExIbcTx, error) {
+		return ibcTxRepo.FindByStatusBefore(policy.coldStatus, txTime, policy.batchSize)
+	})
+}
 
-		if err = ibcTxRepo.Migrate(txList); err != nil {
-			logrus.Errorf("task %s migrate mormal txs error, %v", t.Name(), err)
-			return err
-		}
+// migrateOverflow the oldest txs of the cold status over hot_max_txs
+func (t *IbcTxMigrateTask) migrateOverflow(policy tieringPolicy) error {
+	count, err := ibcTxRepo.CountByStatus(policy.coldStatus)
+	if err != nil {
+		logrus.Errorf("task %s find count overflow txs error, %v", t.Name(), err)
+		return err
+	}
 
-		totalMigrate += len(txList)
-		if len(txList) < limit {
-			break
-		} else {
-			time.Sleep(200 * time.Millisecond) // avoid master-slave delay problem
-		}
+	batch := (count - policy.hotMaxTxs) / policy.batchSize
+	if batch <= 0 {
+		return nil
 	}
 
-	logrus.Infof("task %s migrate %d mormal txs", t.Name(), totalMigrate)
-	return nil
+	return t.migrate("overflow", policy.batchSize, batch, func() ([]*entity.ExIbcTx, error) {
+		return ibcTxRepo.FindByStatus(policy.coldStatus, policy.batchSize)
+	})
 }
@@ -1,19 +1,29 @@
 package task
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
+)
 
 func Test_IbcTxMigrate(t *testing.T) {
 	new(IbcTxMigrateTask).Run()
 }
 
 func Test_MigrateSetting(t *testing.T) {
-	if err := new(IbcTxMigrateTask).migrateSetting(); err != nil {
+	if err := new(IbcTxMigrateTask).migrateSetting(newTieringPolicy(conf.Tiering{})); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_MigrateAged(t *testing.T) {
+	if err := new(IbcTxMigrateTask).migrateAged(newTieringPolicy(conf.Tiering{HotMaxAgeDays: 180})); err != nil {
 		t.Fatal(err)
 	}
 }
 
-func Test_MigrateNormal(t *testing.T) {
-	if err := new(IbcTxMigrateTask).migrateNormal(); err != nil {
+func Test_MigrateOverflow(t *testing.T) {
+	if err := new(IbcTxMigrateTask).migrateOverflow(newTieringPolicy(conf.Tiering{})); err != nil {
 		t.Fatal(err)
 	}
 }
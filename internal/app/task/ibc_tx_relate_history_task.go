@@ -20,15 +20,15 @@ func (t *IbcTxRelateHistoryTask) Name() string {
 }
 
 func (t *IbcTxRelateHistoryTask) Cron() int {
-	if taskConf.CronTimeIbcTxRelateTask > 0 {
-		return taskConf.CronTimeIbcTxRelateTask
+	if taskConf().CronTimeIbcTxRelateTask > 0 {
+		return taskConf().CronTimeIbcTxRelateTask
 	}
 	return ThreeMinute
 }
 
 func (t *IbcTxRelateHistoryTask) workerNum() int {
-	if global.Config.Task.IbcTxRelateWorkerNum > 0 {
-		return global.Config.Task.IbcTxRelateWorkerNum
+	if global.Config().Task.IbcTxRelateWorkerNum > 0 {
+		return global.Config().Task.IbcTxRelateWorkerNum
 	}
 	return ibcTxRelateTaskWorkerNum
 }
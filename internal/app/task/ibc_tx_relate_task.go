@@ -27,15 +27,15 @@ func (t *IbcTxRelateTask) Name() string {
 }
 
 func (t *IbcTxRelateTask) Cron() int {
-	if taskConf.CronTimeIbcTxRelateTask > 0 {
-		return taskConf.CronTimeIbcTxRelateTask
+	if taskConf().CronTimeIbcTxRelateTask > 0 {
+		return taskConf().CronTimeIbcTxRelateTask
 	}
 	return ThreeMinute
 }
 
 func (t *IbcTxRelateTask) workerNum() int {
-	if global.Config.Task.IbcTxRelateWorkerNum > 0 {
-		return global.Config.Task.IbcTxRelateWorkerNum
+	if global.Config().Task.IbcTxRelateWorkerNum > 0 {
+		return global.Config().Task.IbcTxRelateWorkerNum
 	}
 	return ibcTxRelateTaskWorkerNum
 }
@@ -105,6 +105,10 @@ func (w *ibcTxRelateWorker) exec() {
 			continue
 		}
 
+		if !lockChainTask(chainId, w.taskName) {
+			logrus.Infof("task %s worker %s chain %s is re-indexing", w.taskName, w.workerName, chainId)
+			continue
+		}
 		logrus.Infof("task %s worker %s get chain: %v", w.taskName, w.workerName, chainId)
 		startTime := time.Now().Unix()
 		if err = w.relateTx(chainId); err != nil {
@@ -112,6 +116,7 @@ func (w *ibcTxRelateWorker) exec() {
 		} else {
 			logrus.Infof("task %s worker %s relate chain %s tx end,time use: %d(s)", w.taskName, w.workerName, chainId, time.Now().Unix()-startTime)
 		}
+		unlockChainTask(chainId, w.taskName)
 	}
 }
 
@@ -125,7 +130,7 @@ func (w *ibcTxRelateWorker) getChain() (string, error) {
 func (w *ibcTxRelateWorker) relateTx(chainId string) error {
 	totalRelateTx := 0
 	//const limit = 500
-	maxParseTx := global.Config.Task.SingleChainIbcTxRelateMax
+	maxParseTx := global.Config().Task.SingleChainIbcTxRelateMax
 	if maxParseTx <= 0 {
 		maxParseTx = defaultMaxHandlerTx
 	}
@@ -638,6 +643,7 @@ func (w *ibcTxRelateWorker) getToBeRelatedTxs(chainId string, limit int64) ([]*e
 }
 
 func (w *ibcTxRelateWorker) updateIbcTx(ibcTx *entity.ExIbcTx, repaired bool) error {
+	loadFailureCategory(ibcTx)
 	if w.target == ibcTxTargetHistory {
 		return ibcTxRepo.UpdateIbcHistoryTx(ibcTx, repaired)
 	}
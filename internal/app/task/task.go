@@ -2,6 +2,7 @@ package task
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/conf"
@@ -21,8 +22,8 @@ type Task interface {
 }
 
 var (
-	tasks    []Task
-	taskConf conf.Task
+	tasks         []Task
+	taskConfValue atomic.Value
 )
 
 func RegisterTasks(task ...Task) {
@@ -34,8 +35,17 @@ func GetTasks() []Task {
 	return tasks
 }
 
+// LoadTaskConf publish the task config, it's replaced as a whole when the config is reloaded
 func LoadTaskConf(taskCfg conf.Task) {
-	taskConf = taskCfg
+	taskConfValue.Store(&taskCfg)
+}
+
+// taskConf the current task config, it must not be modified
+func taskConf() *conf.Task {
+	if cfg, ok := taskConfValue.Load().(*conf.Task); ok {
+		return cfg
+	}
+	return &conf.Task{}
 }
 
 func Start() {
@@ -50,23 +60,30 @@ func Start() {
 	}
 
 	c := cron.New(cron.WithSeconds())
-	if taskConf.CronJobRelayerAddr == "" {
-		taskConf.CronJobRelayerAddr = ThreeHourCronJobTime
+	cronJobRelayerAddr := taskConf().CronJobRelayerAddr
+	if cronJobRelayerAddr == "" {
+		cronJobRelayerAddr = ThreeHourCronJobTime
 	}
-	_, err := c.AddFunc(taskConf.CronJobRelayerAddr, checkAndUpdateRelayerSrcChainAddr)
+	_, err := c.AddFunc(cronJobRelayerAddr, checkAndUpdateRelayerSrcChainAddr)
 	if err != nil {
 		logrus.Fatal("cron job err", err)
 	}
 	c.Start()
 }
 
-func RunOnce(task Task) {
-	redisLockExpireTime := time.Duration(RedisLockExpireTime) * time.Second
-	if taskConf.RedisLockExpireTime > 0 {
-		redisLockExpireTime = time.Duration(taskConf.RedisLockExpireTime) * time.Second
+// redisLockExpiration the expiration of the redis lock of the cron task
+func redisLockExpiration() time.Duration {
+	if taskConf().RedisLockExpireTime > 0 {
+		return time.Duration(taskConf().RedisLockExpireTime) * time.Second
 	}
+	return time.Duration(RedisLockExpireTime) * time.Second
+}
+
+func RunOnce(task Task) {
+	redisLockExpireTime := redisLockExpiration()
 
-	utils.RunTimer(task.Cron(), utils.Sec, func() {
+	// task.Cron() 每次执行前重新计算，配置热加载后生效
+	utils.RunTimerFunc(task.Cron, utils.Sec, func() {
 		//lock redis mux
 		lockKey := fmt.Sprintf("%s:%s", "task", task.Name())
 		if err := cache.GetRedisClient().Lock(lockKey, time.Now().Unix(), redisLockExpireTime); err != nil {
@@ -77,6 +94,9 @@ func RunOnce(task Task) {
 		logrus.Infof("task %s start", task.Name())
 		metricValue := task.Run()
 		monitor.SetCronTaskStatusMetricValue(task.Name(), float64(metricValue))
+		if metricValue == 1 {
+			_ = taskStatusCache.SetLastSuccess(task.Name(), time.Now().Unix())
+		}
 		//unlock redis mux
 		cache.GetRedisClient().Del(lockKey)
 		logrus.Infof("task %s end, time use %d(s), exec status: %d", task.Name(), time.Now().Unix()-startTime, metricValue)
@@ -116,18 +136,79 @@ func OneOffTaskRun(task OneOffTask) {
 		logrus.Infof("one-off task %s closed", task.Name())
 		return
 	}
-	lockKey := fmt.Sprintf("%s:%s", "one_off_task", task.Name())
-	if err := cache.GetRedisClient().Lock(lockKey, time.Now().Unix(), OneOffTaskLockTime*time.Second); err != nil {
-		logrus.Errorf("one-off task %s has been executed, err:%v", task.Name(), err.Error())
+	dryRun := taskConf().OneOffTaskDryRun
+	if _, ok := task.(DryRunOneOffTask); dryRun && !ok {
+		logrus.Warnf("one-off task %s doesn't support dry-run, skip", task.Name())
+		return
+	}
+
+	if !dryRun {
+		done, err := oneOffTaskCache.IsDone(task.Name())
+		if err != nil {
+			logrus.Errorf("one-off task %s check done error, %v", task.Name(), err)
+			return
+		}
+		if done {
+			logrus.Infof("one-off task %s has been executed, reset its checkpoint to run it again", task.Name())
+			return
+		}
+	}
+	unlock, err := LockOneOffTask(task.Name())
+	if err != nil {
+		logrus.Errorf("one-off task %s is running, err:%v", task.Name(), err.Error())
 		return
 	}
+	defer unlock()
+
+	if dryRun {
+		dryRunTask, err := NewDryRunTask(task)
+		if err != nil {
+			logrus.Errorf("one-off task %s enable dry-run error, %v", task.Name(), err)
+			return
+		}
+		task = dryRunTask
+	}
 	logrus.Infof("one-off task %s start", task.Name())
 	startTime := time.Now().Unix()
 	res := task.Run()
 
-	if res != 1 { // 为避免错误操作、重启、扩容等因素带来的风险，one-ff task 执行成功时不释放锁
-		_, _ = cache.GetRedisClient().Del(lockKey)
+	// 为避免错误操作、重启、扩容等因素带来的风险，one-off task 执行成功后不再执行. dry-run 不修改数据，不标记
+	if res == 1 && !dryRun {
+		if err := oneOffTaskCache.SetDone(task.Name(), OneOffTaskLockTime*time.Second); err != nil {
+			logrus.Errorf("one-off task %s set done error, %v", task.Name(), err)
+		}
 	}
 
-	logrus.Infof("one-off task %s end, time use %d(s), exec status: %d", task.Name(), time.Now().Unix()-startTime, res)
+	logrus.Infof("one-off task %s end, time use %d(s), exec status: %d, dry run: %t", task.Name(), time.Now().Unix()-startTime, res, dryRun)
+}
+
+// LockOneOffTask take the running lock of the one-off task, which is shared by the runs on startup, by the http api
+// and by the command line. The lock is refreshed until the returned unlock is called.
+func LockOneOffTask(taskName string) (func(), error) {
+	runningLockTime := OneOffRunLockTime * time.Second
+	if err := oneOffTaskCache.LockRunning(taskName, runningLockTime); err != nil {
+		return nil, err
+	}
+	stop := make(chan struct{})
+	go refreshOneOffTaskLock(taskName, runningLockTime, stop)
+	return func() {
+		close(stop)
+		_ = oneOffTaskCache.UnlockRunning(taskName)
+	}, nil
+}
+
+// refreshOneOffTaskLock keep the running lock of the one-off task until stop is closed
+func refreshOneOffTaskLock(taskName string, expiration time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(expiration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !oneOffTaskCache.RefreshRunning(taskName, expiration) {
+				logrus.Warnf("one-off task %s refresh running lock failed", taskName)
+			}
+		}
+	}
 }
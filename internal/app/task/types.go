@@ -2,6 +2,7 @@ package task
 
 import (
 	"fmt"
+	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/model/entity"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/repository/cache"
 	"github.com/bianjieai/iobscan-ibc-explorer-backend/internal/app/utils"
@@ -14,6 +15,7 @@ const (
 	OneDay               = 86400
 	RedisLockExpireTime  = 300
 	OneOffTaskLockTime   = 86400 * 30
+	OneOffRunLockTime    = 300 // the running lock of the one-off task, refreshed every third of it
 	ThreeHourCronJobTime = "0 0 */6 * * ?"
 	statisticsCheckTimes = 5
 )
@@ -22,14 +24,15 @@ const (
 	opInsert = 1
 	opUpdate = 2
 
-	ibcTxCount = 500000
+	defaultHotMaxTxs        = 500000
+	defaultTieringBatchSize = 1000
 
 	fixCreateAtErrTime = 1656950400
 
 	replaceHolderOffset  = "OFFSET"
 	replaceHolderLimit   = "LIMIT"
-	replaceHolderChannel = "CHANNEL"
-	replaceHolderPort    = "PORT"
+	replaceHolderChannel = entity.ApiChannelPathPlaceholder
+	replaceHolderPort    = entity.ApiPortPathPlaceholder
 
 	syncTransferTxTaskWorkerNum    = 5
 	ibcTxRelateTaskWorkerNum       = 5
@@ -58,6 +61,9 @@ var (
 	baseDenomCache      cache.BaseDenomCacheRepo
 	storageCache        cache.StorageCacheRepo
 	lcdTxDataCacheRepo  cache.LcdTxDataCacheRepo
+	taskStatusCache     cache.TaskStatusCacheRepo
+	oneOffTaskCache     cache.OneOffTaskCacheRepo
+	chainTaskCache      cache.ChainTaskCacheRepo
 
 	// mongo
 	tokenRepo                repository.ITokenRepo                = new(repository.TokenRepo)
@@ -80,8 +86,27 @@ var (
 	taskRecordRepo           repository.ITaskRecordRepo           = new(repository.TaskRecordRepo)
 	syncTaskRepo             repository.ISyncTaskRepo             = new(repository.SyncTaskRepo)
 	syncBlockRepo            repository.ISyncBlockRepo            = new(repository.SyncBlockRepo)
+	verifiedBlockRepo        repository.IVerifiedBlockRepo        = new(repository.VerifiedBlockRepo)
 	txNewRepo                repository.ITxNewRepo                = new(repository.TxNewRepo)
 	chainRegistryRepo        repository.IChainRegistryRepo        = new(repository.ChainRegistryRepo)
+	taskCheckpointRepo       repository.ITaskCheckpointRepo       = new(repository.TaskCheckpointRepo)
+	taskDryRunReportRepo     repository.ITaskDryRunReportRepo     = new(repository.TaskDryRunReportRepo)
+	chainFlowStatisticsRepo  repository.IChainFlowStatisticsRepo  = new(repository.ChainFlowStatisticsRepo)
+	nftTxRepo                repository.IExIbcNftTxRepo           = new(repository.ExIbcNftTxRepo)
+	nftClassRepo             repository.INftClassRepo             = new(repository.NftClassRepo)
+	icaTxRepo                repository.IExIbcIcaTxRepo           = new(repository.ExIbcIcaTxRepo)
+	icaAccountRepo           repository.IIcaAccountRepo           = new(repository.IcaAccountRepo)
+	packetRepo               repository.IExIbcPacketRepo          = new(repository.ExIbcPacketRepo)
+	escrowReconcileRepo      repository.IEscrowReconcileRepo      = new(repository.EscrowReconcileRepo)
+	escrowDiscrepancyRepo    repository.IEscrowDiscrepancyRepo    = new(repository.EscrowDiscrepancyRepo)
+	largeTransferAlertRepo   repository.ILargeTransferAlertRepo   = new(repository.LargeTransferAlertRepo)
+	transferBaselineRepo     repository.ITransferBaselineRepo     = new(repository.TransferBaselineRepo)
+	chainOnboardingRepo      repository.IChainOnboardingRepo      = new(repository.ChainOnboardingRepo)
+	chainRegistryDiffRepo    repository.IChainRegistryDiffRepo    = new(repository.ChainRegistryDiffRepo)
+	reindexJobRepo           repository.IReindexJobRepo           = new(repository.ReindexJobRepo)
+	archiveRepo              repository.IIbcTxArchiveRepo         = new(repository.IbcTxArchiveRepo)
+	archiveHashRepo          repository.IIbcTxArchiveHashRepo     = new(repository.IbcTxArchiveHashRepo)
+	archiveFileRepo          repository.IIbcTxArchiveFileRepo     = new(repository.IbcTxArchiveFileRepo)
 	relayerStatisticsTask    RelayerStatisticsTask
 )
 
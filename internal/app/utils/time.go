@@ -3,12 +3,17 @@ package utils
 import "time"
 
 func RunTimer(num int, uint Unit, fn func()) {
+	RunTimerFunc(func() int { return num }, uint, fn)
+}
+
+// RunTimerFunc the same as RunTimer, but the interval is evaluated before every run, so it can be changed at runtime
+func RunTimerFunc(numFn func() int, uint Unit, fn func()) {
 	go func() {
 		// run once right now
 		fn()
 		for {
 			now := time.Now()
-			next := now.Add(ParseDuration(num, uint))
+			next := now.Add(ParseDuration(numFn(), uint))
 			next = TruncateTime(next, uint)
 			t := time.NewTimer(next.Sub(now))
 			select {